@@ -0,0 +1,263 @@
+package apperror
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+
+	"jobApps/internal/database"
+	"jobApps/logger"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgconn"
+	"github.com/jackc/pgx/v4"
+)
+
+// ContentType is the media type of every error body (RFC 9457)
+const ContentType = "application/problem+json"
+
+// Kind is the machine-readable class of an error
+type Kind string
+
+const (
+	KindValidation           Kind = "validation"
+	KindNotFound             Kind = "not_found"
+	KindConflict             Kind = "conflict"
+	KindPreconditionFailed   Kind = "precondition_failed"
+	KindPreconditionRequired Kind = "precondition_required"
+	KindUnauthorized         Kind = "unauthorized"
+	KindForbidden            Kind = "forbidden"
+	KindUnsupportedMedia     Kind = "unsupported_media_type"
+	KindInternal             Kind = "internal"
+	KindUnavailable          Kind = "unavailable"
+	KindTimeout              Kind = "timeout"
+)
+
+var kindStatus = map[Kind]int{
+	KindValidation:           http.StatusBadRequest,
+	KindNotFound:             http.StatusNotFound,
+	KindConflict:             http.StatusConflict,
+	KindPreconditionFailed:   http.StatusPreconditionFailed,
+	KindPreconditionRequired: http.StatusPreconditionRequired,
+	KindUnauthorized:         http.StatusUnauthorized,
+	KindForbidden:            http.StatusForbidden,
+	KindUnsupportedMedia:     http.StatusUnsupportedMediaType,
+	KindInternal:             http.StatusInternalServerError,
+	KindUnavailable:          http.StatusServiceUnavailable,
+	KindTimeout:              http.StatusGatewayTimeout,
+}
+
+// FieldError describes a single invalid input field
+type FieldError struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+// Error is a typed application error. Err holds the underlying cause and is
+// only ever logged, never sent to the client.
+type Error struct {
+	Kind   Kind
+	Detail string
+	Fields []FieldError
+	Err    error
+}
+
+func (e *Error) Error() string {
+	if e.Err != nil {
+		return fmt.Sprintf("%s: %s: %v", e.Kind, e.Detail, e.Err)
+	}
+	return fmt.Sprintf("%s: %s", e.Kind, e.Detail)
+}
+
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// Status returns the HTTP status code for the error kind
+func (e *Error) Status() int {
+	if status, ok := kindStatus[e.Kind]; ok {
+		return status
+	}
+	return http.StatusInternalServerError
+}
+
+// IsKind reports whether err is an *Error of the given kind
+func IsKind(err error, kind Kind) bool {
+	var appErr *Error
+	return errors.As(err, &appErr) && appErr.Kind == kind
+}
+
+// Validation reports invalid client input
+func Validation(detail string, fields ...FieldError) *Error {
+	return &Error{Kind: KindValidation, Detail: detail, Fields: fields}
+}
+
+// NotFound reports a missing resource
+func NotFound(detail string) *Error {
+	return &Error{Kind: KindNotFound, Detail: detail}
+}
+
+// Conflict reports a request clashing with the current state, e.g. duplicates
+func Conflict(detail string) *Error {
+	return &Error{Kind: KindConflict, Detail: detail}
+}
+
+// PreconditionFailed reports an If-Match header that does not match the
+// current version of the resource
+func PreconditionFailed(detail string) *Error {
+	return &Error{Kind: KindPreconditionFailed, Detail: detail}
+}
+
+// PreconditionRequired reports a write without the If-Match header it needs
+func PreconditionRequired(detail string) *Error {
+	return &Error{Kind: KindPreconditionRequired, Detail: detail}
+}
+
+// Unauthorized reports missing or invalid credentials
+func Unauthorized(detail string) *Error {
+	return &Error{Kind: KindUnauthorized, Detail: detail}
+}
+
+// Forbidden reports an authenticated caller without the required role
+func Forbidden(detail string) *Error {
+	return &Error{Kind: KindForbidden, Detail: detail}
+}
+
+// Internal wraps an unexpected error behind a generic message
+func Internal(err error) *Error {
+	return &Error{Kind: KindInternal, Detail: "an unexpected error occurred", Err: err}
+}
+
+// UnsupportedMediaType reports a request body in a format the route does not
+// accept
+func UnsupportedMediaType(detail string) *Error {
+	return &Error{Kind: KindUnsupportedMedia, Detail: detail}
+}
+
+// Unavailable reports a dependency that is too slow or down; the client may
+// retry later
+func Unavailable(detail string, err error) *Error {
+	return &Error{Kind: KindUnavailable, Detail: detail, Err: err}
+}
+
+// Timeout reports a request that ran out of time before it could finish
+func Timeout(detail string, err error) *Error {
+	return &Error{Kind: KindTimeout, Detail: detail, Err: err}
+}
+
+// Postgres error codes, see https://www.postgresql.org/docs/current/errcodes-appendix.html
+const (
+	pgUniqueViolation     = "23505"
+	pgForeignKeyViolation = "23503"
+	pgNotNullViolation    = "23502"
+	pgCheckViolation      = "23514"
+	pgStringTooLong       = "22001"
+	pgInvalidText         = "22P02"
+	pgSerialization       = "40001"
+	pgDeadlock            = "40P01"
+)
+
+// constraintMessages gives friendly details for known unique constraints
+var constraintMessages = map[string]string{
+	"users_email_key":         "email ID already exist",
+	"users_phonenumber_key":   "user's Phonenumber already exist",
+	"companies_slug_key":      "a company with this name already exists",
+	"company_recruiters_pkey": "the user is already a recruiter of this company",
+	"skills_name_key":         "a skill with this name already exists",
+	"skill_aliases_pkey":      "the alias already names a skill",
+}
+
+// FromDB translates database errors into typed errors. resource names the
+// entity for not-found messages, e.g. "career".
+func FromDB(err error, resource string) error {
+	if err == nil {
+		return nil
+	}
+
+	var appErr *Error
+	if errors.As(err, &appErr) {
+		return appErr
+	}
+
+	switch {
+	case errors.Is(err, database.ErrQueryTimeout):
+		return Unavailable("the database did not respond in time", err)
+	case errors.Is(err, context.DeadlineExceeded):
+		return Timeout("the request took too long to complete", err)
+	case errors.Is(err, context.Canceled):
+		return Unavailable("the request was cancelled", err)
+	}
+
+	if errors.Is(err, pgx.ErrNoRows) {
+		return &Error{Kind: KindNotFound, Detail: resource + " not found", Err: err}
+	}
+
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) {
+		switch pgErr.Code {
+		case pgUniqueViolation:
+			detail, ok := constraintMessages[pgErr.ConstraintName]
+			if !ok {
+				detail = resource + " already exists"
+			}
+			return &Error{Kind: KindConflict, Detail: detail, Err: err}
+		case pgForeignKeyViolation:
+			return &Error{Kind: KindConflict, Detail: resource + " is referenced by or references another resource", Err: err}
+		case pgNotNullViolation, pgCheckViolation, pgStringTooLong, pgInvalidText:
+			return &Error{Kind: KindValidation, Detail: "invalid " + resource + " data", Err: err}
+		case pgSerialization, pgDeadlock:
+			// only reached once the transaction retries are used up
+			return &Error{Kind: KindConflict, Detail: resource + " was modified concurrently, please retry", Err: err}
+		}
+	}
+
+	return Internal(err)
+}
+
+// Problem is the RFC 9457 problem details body
+type Problem struct {
+	Type      string       `json:"type"`
+	Title     string       `json:"title"`
+	Status    int          `json:"status"`
+	Detail    string       `json:"detail,omitempty"`
+	Instance  string       `json:"instance,omitempty"`
+	Code      Kind         `json:"code"`
+	RequestID string       `json:"request_id,omitempty"`
+	Errors    []FieldError `json:"errors,omitempty"`
+}
+
+// Write aborts the request with an application/problem+json body for err.
+// Errors that are not *Error are treated as internal errors.
+func Write(c *gin.Context, err error) {
+	var appErr *Error
+	if !errors.As(err, &appErr) {
+		appErr = Internal(err)
+	}
+
+	status := appErr.Status()
+	problem := Problem{
+		Type:      "/problems/" + string(appErr.Kind),
+		Title:     http.StatusText(status),
+		Status:    status,
+		Detail:    appErr.Detail,
+		Instance:  c.Request.URL.Path,
+		Code:      appErr.Kind,
+		RequestID: c.GetString(logger.RequestIDKey),
+		Errors:    appErr.Fields,
+	}
+
+	log := logger.FromContext(c.Request.Context())
+	if status >= http.StatusInternalServerError {
+		log.Error("request failed", slog.Int("status", status), slog.String("code", string(appErr.Kind)), slog.Any("error", err))
+	} else {
+		log.Warn("request rejected", slog.Int("status", status), slog.String("code", string(appErr.Kind)), slog.String("detail", appErr.Detail))
+	}
+
+	if appErr.Kind == KindUnavailable {
+		c.Header("Retry-After", "1")
+	}
+	c.Header("Content-Type", ContentType)
+	c.AbortWithStatusJSON(status, problem)
+}
@@ -0,0 +1,111 @@
+// Package audit describes who made a change and what changed. The actor
+// travels in the request context from the HTTP middleware to the service,
+// which writes an audit_events row in the same transaction as the change.
+package audit
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+)
+
+// Actions recorded in audit_events
+const (
+	ActionCreate  = "create"
+	ActionUpdate  = "update"
+	ActionDelete  = "delete"
+	ActionRestore = "restore"
+	ActionRevert  = "revert"
+	// ActionClose is recorded by the expiry job when a career's enddate has
+	// passed, with the "system" role and no email
+	ActionClose  = "close"
+	ActionExtend = "extend"
+	// ActionPublish is recorded by the publish job, like ActionClose
+	ActionPublish = "publish"
+)
+
+// Resource types recorded in audit_events
+const (
+	ResourceUser    = "user"
+	ResourceCareer  = "career"
+	ResourceProfile = "profile"
+	ResourceCompany = "company"
+	ResourceSkill   = "skill"
+
+	// ResourceSavedCareer events are identified by the jobid of the saved
+	// career; the actor is the user who saved it
+	ResourceSavedCareer = "saved_career"
+	ResourceSavedSearch = "saved_search"
+)
+
+// Actor is the caller behind a change. Email and Role come from the JWT
+// claims and are empty before authentication.
+type Actor struct {
+	Email     string
+	Role      string
+	RequestID string
+	IP        string
+}
+
+type ctxKey struct{}
+
+// WithActor returns a copy of ctx carrying actor
+func WithActor(ctx context.Context, actor Actor) context.Context {
+	return context.WithValue(ctx, ctxKey{}, actor)
+}
+
+// ActorFrom returns the actor stored in ctx, or the zero Actor
+func ActorFrom(ctx context.Context) Actor {
+	actor, _ := ctx.Value(ctxKey{}).(Actor)
+	return actor
+}
+
+// Change is the before and after value of one field
+type Change struct {
+	Before any `json:"before"`
+	After  any `json:"after"`
+}
+
+// Diff compares the JSON forms of before and after field by field and
+// returns the fields that differ. A nil side, as for a creation, counts as
+// every field being null.
+func Diff(before, after any) (json.RawMessage, error) {
+	old, err := fields(before)
+	if err != nil {
+		return nil, err
+	}
+	updated, err := fields(after)
+	if err != nil {
+		return nil, err
+	}
+
+	changes := map[string]Change{}
+	for name, value := range updated {
+		if previous, ok := old[name]; !ok || !bytes.Equal(previous, value) {
+			changes[name] = Change{Before: previous, After: value}
+		}
+	}
+	for name, previous := range old {
+		if _, ok := updated[name]; !ok {
+			changes[name] = Change{Before: previous}
+		}
+	}
+	// map keys are sorted by encoding/json, so equal diffs encode the same
+	return json.Marshal(changes)
+}
+
+func fields(document any) (map[string]json.RawMessage, error) {
+	if document == nil {
+		return nil, nil
+	}
+	data, err := json.Marshal(document)
+	if err != nil {
+		return nil, fmt.Errorf("encoding audit document: %w", err)
+	}
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return nil, fmt.Errorf("audit document %T is not a JSON object: %w", document, err)
+	}
+	return fields, nil
+}
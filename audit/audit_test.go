@@ -0,0 +1,38 @@
+package audit
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+type document struct {
+	Name    string  `json:"name"`
+	Age     int     `json:"age"`
+	Removed *string `json:"removed,omitempty"`
+}
+
+func TestDiff(t *testing.T) {
+	removed := "gone"
+	changes, err := Diff(document{Name: "a", Age: 1, Removed: &removed}, document{Name: "a", Age: 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `{"age":{"before":1,"after":2},"removed":{"before":"gone","after":null}}`
+	if string(changes) != want {
+		t.Errorf("Diff = %s, want %s", changes, want)
+	}
+}
+
+func TestDiffOfACreation(t *testing.T) {
+	changes, err := Diff(nil, document{Name: "a"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var fields map[string]Change
+	if err := json.Unmarshal(changes, &fields); err != nil {
+		t.Fatal(err)
+	}
+	if len(fields) != 2 || fields["name"].Before != nil || fields["name"].After != "a" {
+		t.Errorf("Diff(nil, doc) = %s", changes)
+	}
+}
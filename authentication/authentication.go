@@ -1,10 +1,11 @@
 package authentication
 
 import (
-	"errors"
-	"net/http"
 	"time"
 
+	"jobApps/apperror"
+	"jobApps/audit"
+
 	"github.com/dgrijalva/jwt-go"
 	"github.com/gin-gonic/gin"
 )
@@ -14,8 +15,7 @@ func AuthMiddleware() gin.HandlerFunc {
 	return func(c *gin.Context) {
 		tokenString := c.GetHeader("Authorization")
 		if tokenString == "" {
-			c.String(http.StatusUnauthorized, "Token is Misssing")
-			c.Abort()
+			apperror.Write(c, apperror.Unauthorized("Token is Misssing"))
 			return
 		}
 		for index, char := range tokenString {
@@ -29,46 +29,58 @@ func AuthMiddleware() gin.HandlerFunc {
 		})
 
 		if err != nil || !token.Valid {
-			c.String(http.StatusUnauthorized, "Invalid token")
-			c.Abort()
+			apperror.Write(c, apperror.Unauthorized("Invalid token"))
 			return
 		}
 		// Check whether token is expired or not
 		check, ok := claims["exp"].(int64)
 		if ok && check < time.Now().Unix() {
-			c.String(http.StatusUnauthorized, "Expired token")
-			c.Abort()
+			apperror.Write(c, apperror.Unauthorized("Expired token"))
 			return
 		}
 
 		c.Set("email", claims["email"])
 		c.Set("role", claims["role"])
+
+		// changes made by this request are audited under the token's identity
+		actor := audit.ActorFrom(c.Request.Context())
+		actor.Email = c.GetString("email")
+		actor.Role = c.GetString("role")
+		c.Request = c.Request.WithContext(audit.WithActor(c.Request.Context(), actor))
 	}
 }
 
 // AdminAuth verifies if the user is an admin
 func AdminAuth(c *gin.Context) error {
-	role, exists := c.Get("role")
-	if !exists || role.(string) != "admin" {
-		return errors.New("only admins can access this endpoint")
+	if c.GetString("role") != "admin" {
+		return apperror.Forbidden("only admins can access this endpoint")
 	}
 	return nil
 }
 
 // UserAuth verifies if the user is a regular user
 func UserAuth(c *gin.Context) error {
-	role, exists := c.Get("role")
-	if !exists || role.(string) != "user" {
-		return errors.New("user admins can access this endpoint")
+	if c.GetString("role") != "user" {
+		return apperror.Forbidden("only users can access this endpoint")
+	}
+	return nil
+}
+
+// RecruiterAuth verifies if the user is an admin or a recruiter. Which
+// company's careers a recruiter may manage is checked by the service.
+func RecruiterAuth(c *gin.Context) error {
+	role := c.GetString("role")
+	if role != "recruiter" && role != "admin" {
+		return apperror.Forbidden("only recruiters and admins can access this endpoint")
 	}
 	return nil
 }
 
-// CommonAuth verifies if the user is either an admin or a regular user
+// CommonAuth verifies if the user is an admin, a recruiter or a regular user
 func CommonAuth(c *gin.Context) error {
-	role, exists := c.Get("role")
-	if !exists || (role.(string) != "user" && role.(string) != "admin") {
-		return errors.New("only users and admins have access to this endpoint")
+	role := c.GetString("role")
+	if role != "user" && role != "admin" && role != "recruiter" {
+		return apperror.Forbidden("only users, recruiters and admins have access to this endpoint")
 	}
 	return nil
 }
@@ -0,0 +1,378 @@
+// Package careerimport reads the careers of the CSV and JSON Lines files
+// recruiters upload, renaming their columns to the fields of a career
+// request. Every row is read into a dto.CreateCareerRequest, so imported
+// careers are checked by the same rules as those posted one at a time.
+package careerimport
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"jobApps/apperror"
+	"jobApps/dto"
+	"jobApps/geo"
+)
+
+// Formats of an import file
+const (
+	CSV   = "csv"
+	JSONL = "jsonl"
+)
+
+// MediaTypes are the media types of each format
+var MediaTypes = map[string]string{
+	"text/csv":                CSV,
+	"application/csv":         CSV,
+	"application/jsonl":       JSONL,
+	"application/x-jsonlines": JSONL,
+	"application/jsonlines":   JSONL,
+	"application/x-ndjson":    JSONL,
+}
+
+// FormatOf returns the format of a Content-Type header, or false if it is
+// not one an import reads
+func FormatOf(contentType string) (string, bool) {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		mediaType = contentType
+	}
+	format, ok := MediaTypes[strings.ToLower(mediaType)]
+	return format, ok
+}
+
+// maxLine is the longest JSON line read, leaving room for long descriptions
+const maxLine = 4 << 20
+
+type kind int
+
+const (
+	text kind = iota
+	integer
+	boolean
+	date
+	list
+	places
+)
+
+// fields are the career request fields a column can map to
+var fields = map[string]kind{
+	"company": text, "position": text, "jobtype": text, "description": text,
+	"startdate": date, "enddate": date, "publish_at": date,
+	"salary_min": integer, "salary_max": integer, "salary_currency": text, "pay_period": text,
+	"equity": boolean, "salary_visible": boolean,
+	"locations": places, "remote_policy": text, "remote_countries": list,
+	"seniority": text,
+}
+
+// Row is one career of a file
+type Row struct {
+	// Line is the line of the file the row starts on
+	Line    int
+	Request dto.CreateCareerRequest
+	// Err is why the row could not be read; Request is incomplete then
+	Err error
+}
+
+// File is what Parse read
+type File struct {
+	Rows []Row
+	// Ignored are the columns that map to no career field
+	Ignored []string
+}
+
+// Parse reads the careers of a file in format, CSV or JSONL. A CSV file
+// starts with a header row; each JSON line is an object. Columns are matched
+// to fields by name, ignoring case, spaces, hyphens and underscores, after
+// mapping renames them: {"Job Title": "position"} reads the Job Title column
+// as the position.
+func Parse(r io.Reader, format string, mapping map[string]string) (File, error) {
+	renames := make(map[string]string, len(mapping))
+	for from, to := range mapping {
+		field, ok := byKey[normalise(to)]
+		if !ok {
+			return File{}, apperror.Validation(fmt.Sprintf("%q is not a career field", to),
+				apperror.FieldError{Field: "map[" + from + "]", Message: "must name a career field"})
+		}
+		renames[normalise(from)] = field
+	}
+	// rename returns the field of a column, or "" if it has none
+	rename := func(column string) string {
+		if field, ok := renames[normalise(column)]; ok {
+			return field
+		}
+		return byKey[normalise(column)]
+	}
+
+	switch format {
+	case CSV:
+		return parseCSV(r, rename)
+	case JSONL:
+		return parseJSONL(r, rename)
+	default:
+		return File{}, apperror.Validation(fmt.Sprintf("%q is not an import format", format),
+			apperror.FieldError{Field: "format", Message: "must be one of csv jsonl"})
+	}
+}
+
+// normalise turns a column name such as "Salary Min" or "Start-Date" into
+// the key of its field, salarymin or startdate
+func normalise(column string) string {
+	return strings.NewReplacer(" ", "", "-", "", "_", "").Replace(strings.ToLower(column))
+}
+
+// byKey finds the field of a normalised column name
+var byKey = func() map[string]string {
+	keys := make(map[string]string, len(fields))
+	for field := range fields {
+		keys[normalise(field)] = field
+	}
+	return keys
+}()
+
+func parseCSV(r io.Reader, rename func(string) string) (File, error) {
+	reader := csv.NewReader(r)
+	header, err := reader.Read()
+	if errors.Is(err, io.EOF) {
+		return File{}, apperror.Validation("the file is empty")
+	}
+	if err != nil {
+		return File{}, csvError(err)
+	}
+	// spreadsheets often start the file with a byte order mark
+	header[0] = strings.TrimPrefix(header[0], "\ufeff")
+
+	var file File
+	columns := make([]string, len(header))
+	seen := map[string]string{}
+	for i, name := range header {
+		field := rename(name)
+		if field == "" {
+			file.Ignored = append(file.Ignored, name)
+			continue
+		}
+		if other, ok := seen[field]; ok {
+			return File{}, apperror.Validation(fmt.Sprintf("the columns %q and %q are both %s", other, name, field))
+		}
+		seen[field], columns[i] = name, field
+	}
+
+	for {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			return file, nil
+		}
+		if err != nil && !errors.Is(err, csv.ErrFieldCount) {
+			return File{}, csvError(err)
+		}
+		line, _ := reader.FieldPos(0)
+		row := Row{Line: line}
+		if err != nil {
+			row.Err = apperror.Validation(fmt.Sprintf("the row has %d columns, the header %d", len(record), len(header)))
+			file.Rows = append(file.Rows, row)
+			continue
+		}
+
+		values := map[string]any{}
+		var invalid []apperror.FieldError
+		for i, cell := range record {
+			if columns[i] == "" || strings.TrimSpace(cell) == "" {
+				continue
+			}
+			value, err := parseCell(fields[columns[i]], cell)
+			if err != nil {
+				invalid = append(invalid, apperror.FieldError{Field: columns[i], Message: err.Error()})
+				continue
+			}
+			values[columns[i]] = value
+		}
+		if len(invalid) > 0 {
+			row.Err = apperror.Validation("the row could not be read", invalid...)
+		} else {
+			row.Request, row.Err = decode(values)
+		}
+		file.Rows = append(file.Rows, row)
+	}
+}
+
+func csvError(err error) error {
+	var parseErr *csv.ParseError
+	if errors.As(err, &parseErr) {
+		return apperror.Validation(fmt.Sprintf("the file is not valid CSV: line %d: %v", parseErr.Line, parseErr.Err))
+	}
+	return apperror.Validation("the file could not be read: " + err.Error())
+}
+
+// parseCell reads a CSV cell as a value of kind
+func parseCell(k kind, cell string) (any, error) {
+	cell = strings.TrimSpace(cell)
+	switch k {
+	case integer:
+		n, err := strconv.ParseInt(cell, 10, 64)
+		if err != nil {
+			return nil, errors.New("must be a whole number")
+		}
+		return n, nil
+	case boolean:
+		switch strings.ToLower(cell) {
+		case "true", "yes", "y", "1":
+			return true, nil
+		case "false", "no", "n", "0":
+			return false, nil
+		}
+		return nil, errors.New("must be true or false")
+	case date:
+		return parseDate(cell)
+	case list:
+		var items []string
+		for _, item := range strings.FieldsFunc(cell, func(r rune) bool { return r == ',' || r == ';' }) {
+			if item = strings.TrimSpace(item); item != "" {
+				items = append(items, item)
+			}
+		}
+		return items, nil
+	case places:
+		var locations geo.Locations
+		for _, place := range strings.Split(cell, ";") {
+			if place = strings.TrimSpace(place); place == "" {
+				continue
+			}
+			// "Portland, US" names the country as ParseNear does
+			city, country, _ := strings.Cut(place, ",")
+			locations = append(locations, geo.Location{City: strings.TrimSpace(city), Country: strings.TrimSpace(country)})
+		}
+		return locations, nil
+	default:
+		return cell, nil
+	}
+}
+
+// parseDate reads a date such as 2027-01-31, as midnight UTC, or an RFC 3339
+// time
+func parseDate(value string) (time.Time, error) {
+	if t, err := time.Parse(time.DateOnly, value); err == nil {
+		return t, nil
+	}
+	t, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return time.Time{}, errors.New("must be a date such as 2027-01-31 or an RFC 3339 time")
+	}
+	return t, nil
+}
+
+func parseJSONL(r io.Reader, rename func(string) string) (File, error) {
+	var file File
+	ignored := map[string]bool{}
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(nil, maxLine)
+	for line := 1; scanner.Scan(); line++ {
+		if len(bytes.TrimSpace(scanner.Bytes())) == 0 {
+			continue
+		}
+		row := Row{Line: line}
+		var object map[string]json.RawMessage
+		if err := json.Unmarshal(scanner.Bytes(), &object); err != nil {
+			row.Err = apperror.Validation("the line is not a JSON object")
+			file.Rows = append(file.Rows, row)
+			continue
+		}
+
+		values := map[string]any{}
+		var invalid []apperror.FieldError
+		keys := make([]string, 0, len(object))
+		for key := range object {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			value, field := object[key], rename(key)
+			if field == "" {
+				ignored[key] = true
+				continue
+			}
+			if _, ok := values[field]; ok {
+				invalid = append(invalid, apperror.FieldError{Field: field, Message: "is given more than once"})
+				continue
+			}
+			// dates may be given without a time, as in CSV files
+			var s string
+			if fields[field] == date && json.Unmarshal(value, &s) == nil {
+				t, err := parseDate(s)
+				if err != nil {
+					invalid = append(invalid, apperror.FieldError{Field: field, Message: err.Error()})
+					continue
+				}
+				values[field] = t
+				continue
+			}
+			values[field] = value
+		}
+		if len(invalid) > 0 {
+			row.Err = apperror.Validation("the row could not be read", invalid...)
+		} else {
+			row.Request, row.Err = decode(values)
+		}
+		file.Rows = append(file.Rows, row)
+	}
+	if err := scanner.Err(); err != nil {
+		if errors.Is(err, bufio.ErrTooLong) {
+			return File{}, apperror.Validation(fmt.Sprintf("a line is longer than %d bytes", maxLine))
+		}
+		return File{}, apperror.Validation("the file could not be read: " + err.Error())
+	}
+	if len(file.Rows) == 0 {
+		return File{}, apperror.Validation("the file is empty")
+	}
+	for key := range ignored {
+		file.Ignored = append(file.Ignored, key)
+	}
+	sort.Strings(file.Ignored)
+	return file, nil
+}
+
+// decode reads the values of a row into a career request
+func decode(values map[string]any) (dto.CreateCareerRequest, error) {
+	var request dto.CreateCareerRequest
+	data, err := json.Marshal(values)
+	if err != nil {
+		return request, apperror.Internal(fmt.Errorf("encoding import row: %w", err))
+	}
+	if err := json.Unmarshal(data, &request); err != nil {
+		var typeErr *json.UnmarshalTypeError
+		if errors.As(err, &typeErr) {
+			return request, apperror.Validation("the row could not be read",
+				apperror.FieldError{Field: typeErr.Field, Message: typeMessage(typeErr.Type)})
+		}
+		return request, apperror.Validation("the row could not be read: " + err.Error())
+	}
+	return request, nil
+}
+
+// typeMessage says what a JSON value of type t must be
+func typeMessage(t reflect.Type) string {
+	if t == reflect.TypeOf(time.Time{}) {
+		return "must be a date such as 2027-01-31 or an RFC 3339 time"
+	}
+	switch t.Kind() {
+	case reflect.Int64:
+		return "must be a whole number"
+	case reflect.Bool:
+		return "must be true or false"
+	case reflect.Slice:
+		return "must be an array"
+	case reflect.Struct:
+		return "must be an object"
+	default:
+		return "must be a string"
+	}
+}
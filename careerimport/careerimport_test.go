@@ -0,0 +1,139 @@
+package careerimport
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"jobApps/apperror"
+	"jobApps/geo"
+)
+
+func TestParseCSV(t *testing.T) {
+	file := "\ufeffCompany,Job Title,Job-Type,Description,Start Date,End Date,Salary Min,Salary Max,Salary Currency,Pay Period,Equity,Locations,Remote Countries,Notes\n" +
+		"Acme,Backend Engineer,Full-time,\"Build APIs, and more\",2026-01-01,2026-12-31,100000,120000,USD,yearly,yes,\"Berlin, DE; Portland, US\",\"DE, US\",internal\n" +
+		"Acme,Frontend Engineer,Full-time,Build UIs,2026-01-01,2026-12-31,lots,,,,maybe,,,\n" +
+		"Acme,Too short\n"
+	parsed, err := Parse(strings.NewReader(file), CSV, map[string]string{"Job Title": "position"})
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	if want := []string{"Notes"}; !reflect.DeepEqual(parsed.Ignored, want) {
+		t.Errorf("Ignored = %q, want %q", parsed.Ignored, want)
+	}
+	if len(parsed.Rows) != 3 {
+		t.Fatalf("got %d rows, want 3", len(parsed.Rows))
+	}
+
+	first := parsed.Rows[0]
+	if first.Err != nil || first.Line != 2 {
+		t.Fatalf("first row = line %d, error %v", first.Line, first.Err)
+	}
+	request := first.Request
+	if request.Company != "Acme" || request.Position != "Backend Engineer" || request.Jobtype != "Full-time" || request.Description != "Build APIs, and more" {
+		t.Errorf("first row = %+v", request)
+	}
+	if !request.Startdate.Equal(time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)) || !request.Enddate.Equal(time.Date(2026, 12, 31, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("dates = %v, %v", request.Startdate, request.Enddate)
+	}
+	if request.SalaryMin == nil || *request.SalaryMin != 100000 || request.SalaryMax == nil || *request.SalaryMax != 120000 || !request.Equity {
+		t.Errorf("salary = %v-%v, equity %v", request.SalaryMin, request.SalaryMax, request.Equity)
+	}
+	if want := (geo.Locations{{City: "Berlin", Country: "DE"}, {City: "Portland", Country: "US"}}); !reflect.DeepEqual(request.Locations, want) {
+		t.Errorf("Locations = %+v, want %+v", request.Locations, want)
+	}
+	if want := []string{"DE", "US"}; !reflect.DeepEqual(request.RemoteCountries, want) {
+		t.Errorf("RemoteCountries = %q, want %q", request.RemoteCountries, want)
+	}
+
+	var appErr *apperror.Error
+	if second := parsed.Rows[1]; !errors.As(second.Err, &appErr) || second.Line != 3 {
+		t.Fatalf("second row = line %d, error %v", second.Line, second.Err)
+	}
+	if fields := fieldNames(appErr.Fields); !reflect.DeepEqual(fields, []string{"salary_min", "equity"}) {
+		t.Errorf("second row errors on %q", fields)
+	}
+	if third := parsed.Rows[2]; third.Err == nil || third.Line != 4 {
+		t.Errorf("third row = line %d, error %v", third.Line, third.Err)
+	}
+}
+
+func TestParseJSONL(t *testing.T) {
+	file := `{"company":"Acme","position":"Backend Engineer","jobtype":"Full-time","description":"Build APIs","startdate":"2026-01-01","enddate":"2026-12-31T00:00:00Z","team":"core"}` + "\n" +
+		"\n" +
+		"[1, 2]\n" +
+		`{"company":"Acme","salary_min":"lots","startdate":"soon"}` + "\n" +
+		`{"Company":"Acme","company":"Globex"}` + "\n"
+	parsed, err := Parse(strings.NewReader(file), JSONL, nil)
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	if want := []string{"team"}; !reflect.DeepEqual(parsed.Ignored, want) {
+		t.Errorf("Ignored = %q, want %q", parsed.Ignored, want)
+	}
+	if len(parsed.Rows) != 4 {
+		t.Fatalf("got %d rows, want 4", len(parsed.Rows))
+	}
+	if first := parsed.Rows[0]; first.Err != nil || first.Request.Position != "Backend Engineer" || !first.Request.Startdate.Equal(time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("first row = %+v, error %v", first.Request, first.Err)
+	}
+	if notObject := parsed.Rows[1]; notObject.Err == nil || notObject.Line != 3 {
+		t.Errorf("second row = line %d, error %v", notObject.Line, notObject.Err)
+	}
+
+	var appErr *apperror.Error
+	if !errors.As(parsed.Rows[2].Err, &appErr) {
+		t.Fatalf("third row error = %v", parsed.Rows[2].Err)
+	}
+	if fields := fieldNames(appErr.Fields); !reflect.DeepEqual(fields, []string{"startdate"}) {
+		t.Errorf("third row errors on %q", fields)
+	}
+	if !errors.As(parsed.Rows[3].Err, &appErr) || !reflect.DeepEqual(fieldNames(appErr.Fields), []string{"company"}) {
+		t.Errorf("fourth row error = %v", parsed.Rows[3].Err)
+	}
+}
+
+func TestParseRejectsFiles(t *testing.T) {
+	tests := []struct {
+		name, format, file string
+		mapping            map[string]string
+	}{
+		{"empty CSV", CSV, "", nil},
+		{"empty JSONL", JSONL, "\n\n", nil},
+		{"duplicate columns", CSV, "position,Job Title\nx,y\n", map[string]string{"Job Title": "position"}},
+		{"unknown field", CSV, "Title\nx\n", map[string]string{"Title": "headline"}},
+		{"bad quoting", CSV, "company\n\"Acme\n", nil},
+		{"unknown format", "xlsx", "company\n", nil},
+	}
+	for _, test := range tests {
+		_, err := Parse(strings.NewReader(test.file), test.format, test.mapping)
+		var appErr *apperror.Error
+		if !errors.As(err, &appErr) || appErr.Kind != apperror.KindValidation {
+			t.Errorf("%s: error = %v, want a validation error", test.name, err)
+		}
+	}
+}
+
+func TestFormatOf(t *testing.T) {
+	tests := map[string]string{
+		"text/csv; charset=utf-8": CSV,
+		"application/x-ndjson":    JSONL,
+		"application/jsonl":       JSONL,
+		"application/json":        "",
+	}
+	for contentType, want := range tests {
+		if format, _ := FormatOf(contentType); format != want {
+			t.Errorf("FormatOf(%q) = %q, want %q", contentType, format, want)
+		}
+	}
+}
+
+func fieldNames(errs []apperror.FieldError) []string {
+	var names []string
+	for _, err := range errs {
+		names = append(names, err.Field)
+	}
+	return names
+}
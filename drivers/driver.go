@@ -4,19 +4,16 @@ import (
 	"context"
 	_ "embed"
 	"fmt"
-	"jobApps/helper"
+	"log/slog"
 	"os"
 
 	"github.com/jackc/pgx/v4"
+	"github.com/jackc/pgx/v4/pgxpool"
 )
 
-func DataBaseConnection() (*pgx.Conn, error) {
-	err := helper.Configure(".env")
-	if err != nil {
-		fmt.Println("error is loading env file")
-		return nil, err
-	}
-
+// DataBaseConnection opens a connection pool, so requests and their
+// transactions do not queue behind each other on a single connection
+func DataBaseConnection() (*pgxpool.Pool, error) {
 	host := os.Getenv("DB_HOST")
 	port := os.Getenv("DB_PORT")
 	password := os.Getenv("DB_PASSWORD")
@@ -25,14 +22,14 @@ func DataBaseConnection() (*pgx.Conn, error) {
 
 	connectionURI := fmt.Sprintf("postgresql://%s:%s@%s:%s/%s", user, password, host, port, dbname)
 
-	conn, err := pgx.Connect(context.Background(), connectionURI)
+	pool, err := pgxpool.Connect(context.Background(), connectionURI)
 	if err != nil {
 		return nil, err
 	}
 
-	fmt.Println("Database Connected Successfully!!!...")
+	slog.Info("Database Connected Successfully", slog.String("host", host), slog.String("database", dbname))
 
-	return conn, nil
+	return pool, nil
 }
 
 func TableCreation(conn *pgx.Conn) (err error) {
@@ -71,7 +68,7 @@ func TableCreation(conn *pgx.Conn) (err error) {
 
 	_, err = conn.Exec(context.Background(), createTablesQuery)
 	if err != nil {
-		fmt.Println("Database creation Failed:", err)
+		slog.Error("Database creation Failed", slog.Any("error", err))
 		return
 	}
 
@@ -0,0 +1,698 @@
+package dto
+
+import (
+	"database/sql"
+	"slices"
+	"strings"
+	"time"
+
+	"jobApps/geo"
+	"jobApps/internal/database"
+	"jobApps/slug"
+)
+
+// Request bodies accepted by the API. They are kept separate from the sqlc
+// *Params types so validation rules and the wire format can evolve without
+// touching generated code. Max lengths mirror the VARCHAR sizes in sql/migrations.
+
+type SignUpRequest struct {
+	Username    string `json:"username" validate:"required,notblank,max=255"`
+	Email       string `json:"email" validate:"required,email,max=255"`
+	Phonenumber string `json:"phonenumber" validate:"required,e164,max=20"`
+	// bcrypt only uses the first 72 bytes of a password
+	Password string `json:"password" validate:"required,min=8,max=72,password"`
+	Role     string `json:"role" validate:"required,oneof=user admin recruiter"`
+}
+
+// ToParams normalises the request into sqlc parameters. The password must
+// already be hashed.
+func (r SignUpRequest) ToParams(hashedPassword string) database.CreateUserParams {
+	return database.CreateUserParams{
+		Username:    strings.TrimSpace(r.Username),
+		Email:       normaliseEmail(r.Email),
+		Phonenumber: strings.TrimSpace(r.Phonenumber),
+		Password:    hashedPassword,
+		Role:        r.Role,
+	}
+}
+
+type LoginRequest struct {
+	Email    string `json:"email" validate:"required,email,max=255"`
+	Password string `json:"password" validate:"required,max=72"`
+}
+
+// NormalisedEmail returns the email the way it is stored
+func (r LoginRequest) NormalisedEmail() string {
+	return normaliseEmail(r.Email)
+}
+
+// CreateCareerRequest posts a career. Its description is Markdown, which is
+// stored along with its rendered HTML and excerpt.
+type CreateCareerRequest struct {
+	Company     string    `json:"company" validate:"required,notblank,max=255"`
+	Position    string    `json:"position" validate:"required,notblank,max=255"`
+	Jobtype     string    `json:"jobtype" validate:"required,notblank,max=255"`
+	Description string    `json:"description" validate:"required,notblank,max=100000"`
+	Startdate   time.Time `json:"startdate" validate:"required"`
+	Enddate     time.Time `json:"enddate" validate:"required,gtefield=Startdate"`
+
+	// a salary is given as a whole range with its currency and pay period,
+	// or not at all
+	SalaryMin      *int64  `json:"salary_min" validate:"required_with=SalaryMax SalaryCurrency PayPeriod,omitempty,gte=0"`
+	SalaryMax      *int64  `json:"salary_max" validate:"required_with=SalaryMin,omitempty,gtefield=SalaryMin"`
+	SalaryCurrency *string `json:"salary_currency" validate:"required_with=SalaryMin,omitempty,iso4217"`
+	PayPeriod      *string `json:"pay_period" validate:"required_with=SalaryMin,omitempty,oneof=hourly monthly yearly"`
+	Equity         bool    `json:"equity"`
+	// SalaryVisible defaults to true; a hidden salary is only shown to admins
+	// and the company's recruiters
+	SalaryVisible *bool `json:"salary_visible"`
+
+	// locations without lat and lon are geocoded from the gazetteer;
+	// RemotePolicy defaults to onsite
+	Locations       geo.Locations `json:"locations" validate:"max=20,dive"`
+	RemotePolicy    string        `json:"remote_policy" validate:"omitempty,oneof=onsite hybrid remote"`
+	RemoteCountries []string      `json:"remote_countries" validate:"max=250,unique,dive,iso3166_1_alpha2"`
+
+	Seniority *string `json:"seniority" validate:"omitempty,oneof=intern junior mid senior lead"`
+
+	// PublishAt defaults to the Startdate, or now if that has passed; until
+	// then the career is hidden from users
+	PublishAt *time.Time `json:"publish_at"`
+}
+
+func (r CreateCareerRequest) ToParams() database.CreateCareerParams {
+	publishAt := time.Now()
+	if r.PublishAt != nil {
+		publishAt = *r.PublishAt
+	} else if r.Startdate.After(publishAt) {
+		publishAt = r.Startdate
+	}
+	return database.CreateCareerParams{
+		Company:     strings.TrimSpace(r.Company),
+		Position:    strings.TrimSpace(r.Position),
+		Jobtype:     strings.TrimSpace(r.Jobtype),
+		Description: strings.TrimSpace(r.Description),
+		Startdate:   r.Startdate,
+		Enddate:     r.Enddate,
+
+		SalaryMin:      r.SalaryMin,
+		SalaryMax:      r.SalaryMax,
+		SalaryCurrency: r.SalaryCurrency,
+		PayPeriod:      r.PayPeriod,
+		Equity:         r.Equity,
+		SalaryVisible:  r.SalaryVisible == nil || *r.SalaryVisible,
+
+		Locations:       trimLocations(r.Locations),
+		RemotePolicy:    stringOr(r.RemotePolicy, "onsite"),
+		RemoteCountries: nonNil(r.RemoteCountries),
+
+		Seniority: r.Seniority,
+
+		PublishAt: publishAt,
+	}
+}
+
+// UpdateCareerRequest only changes the fields that are present in the body
+type UpdateCareerRequest struct {
+	Company     *string `json:"company" validate:"omitempty,notblank,max=255"`
+	Position    *string `json:"position" validate:"omitempty,notblank,max=255"`
+	Jobtype     *string `json:"jobtype" validate:"omitempty,notblank,max=255"`
+	Description *string `json:"description" validate:"omitempty,notblank,max=100000"`
+}
+
+// ToParams merges the request over the existing career
+func (r UpdateCareerRequest) ToParams(existing database.Career) database.UpdateCareerByJobIdParams {
+	return database.UpdateCareerByJobIdParams{
+		Company:     valueOr(r.Company, existing.Company),
+		Position:    valueOr(r.Position, existing.Position),
+		Jobtype:     valueOr(r.Jobtype, existing.Jobtype),
+		Description: valueOr(r.Description, existing.Description),
+		Jobid:       existing.Jobid,
+	}
+}
+
+// ExtendCareerRequest moves a career's enddate later
+type ExtendCareerRequest struct {
+	Enddate time.Time `json:"enddate" validate:"required"`
+}
+
+// CareerDocument is the patchable form of a career. A PATCH is applied to the
+// document of the current row and the result is validated as a whole, so
+// fields can be cleared and dates changed, while jobid and version stay
+// read-only.
+type CareerDocument struct {
+	Company     string    `json:"company" validate:"required,notblank,max=255"`
+	Position    string    `json:"position" validate:"required,notblank,max=255"`
+	Jobtype     string    `json:"jobtype" validate:"required,notblank,max=255"`
+	Description string    `json:"description" validate:"max=100000"`
+	Startdate   time.Time `json:"startdate" validate:"required"`
+	Enddate     time.Time `json:"enddate" validate:"required,gtefield=Startdate"`
+
+	// a salary is given as a whole range with its currency and pay period,
+	// or not at all
+	SalaryMin      *int64  `json:"salary_min" validate:"required_with=SalaryMax SalaryCurrency PayPeriod,omitempty,gte=0"`
+	SalaryMax      *int64  `json:"salary_max" validate:"required_with=SalaryMin,omitempty,gtefield=SalaryMin"`
+	SalaryCurrency *string `json:"salary_currency" validate:"required_with=SalaryMin,omitempty,iso4217"`
+	PayPeriod      *string `json:"pay_period" validate:"required_with=SalaryMin,omitempty,oneof=hourly monthly yearly"`
+	Equity         bool    `json:"equity"`
+	SalaryVisible  bool    `json:"salary_visible"`
+
+	Locations       geo.Locations `json:"locations" validate:"max=20,dive"`
+	RemotePolicy    string        `json:"remote_policy" validate:"required,oneof=onsite hybrid remote"`
+	RemoteCountries []string      `json:"remote_countries" validate:"max=250,unique,dive,iso3166_1_alpha2"`
+
+	Seniority *string `json:"seniority" validate:"omitempty,oneof=intern junior mid senior lead"`
+}
+
+func NewCareerDocument(career database.Career) CareerDocument {
+	return CareerDocument{
+		Company:     career.Company,
+		Position:    career.Position,
+		Jobtype:     career.Jobtype,
+		Description: career.Description,
+		Startdate:   career.Startdate,
+		Enddate:     career.Enddate,
+
+		SalaryMin:      career.SalaryMin,
+		SalaryMax:      career.SalaryMax,
+		SalaryCurrency: career.SalaryCurrency,
+		PayPeriod:      career.PayPeriod,
+		Equity:         career.Equity,
+		SalaryVisible:  career.SalaryVisible,
+
+		Locations:       career.Locations,
+		RemotePolicy:    career.RemotePolicy,
+		RemoteCountries: career.RemoteCountries,
+
+		Seniority: career.Seniority,
+	}
+}
+
+// Changes lists the columns whose value differs from existing
+func (d CareerDocument) Changes(existing database.Career) []database.Assignment {
+	var set []database.Assignment
+	set = appendChanged(set, "company", strings.TrimSpace(d.Company), existing.Company)
+	set = appendChanged(set, "position", strings.TrimSpace(d.Position), existing.Position)
+	set = appendChanged(set, "jobtype", strings.TrimSpace(d.Jobtype), existing.Jobtype)
+	set = appendChanged(set, "description", strings.TrimSpace(d.Description), existing.Description)
+	if !d.Startdate.Equal(existing.Startdate) {
+		set = append(set, database.Assignment{Column: "startdate", Value: d.Startdate})
+	}
+	if !d.Enddate.Equal(existing.Enddate) {
+		set = append(set, database.Assignment{Column: "enddate", Value: d.Enddate})
+	}
+	set = appendChangedPointer(set, "salary_min", d.SalaryMin, existing.SalaryMin)
+	set = appendChangedPointer(set, "salary_max", d.SalaryMax, existing.SalaryMax)
+	set = appendChangedPointer(set, "salary_currency", d.SalaryCurrency, existing.SalaryCurrency)
+	set = appendChangedPointer(set, "pay_period", d.PayPeriod, existing.PayPeriod)
+	if d.Equity != existing.Equity {
+		set = append(set, database.Assignment{Column: "equity", Value: d.Equity})
+	}
+	if d.SalaryVisible != existing.SalaryVisible {
+		set = append(set, database.Assignment{Column: "salary_visible", Value: d.SalaryVisible})
+	}
+	if locations := trimLocations(d.Locations); !locations.Equal(existing.Locations) {
+		set = append(set, database.Assignment{Column: "locations", Value: locations})
+	}
+	set = appendChanged(set, "remote_policy", d.RemotePolicy, existing.RemotePolicy)
+	if !slices.Equal(d.RemoteCountries, existing.RemoteCountries) {
+		set = append(set, database.Assignment{Column: "remote_countries", Value: nonNil(d.RemoteCountries)})
+	}
+	set = appendChangedPointer(set, "seniority", d.Seniority, existing.Seniority)
+	return set
+}
+
+type CreateProfileRequest struct {
+	Fullname string `json:"fullname" validate:"required,notblank,max=255"`
+	Age      int32  `json:"age" validate:"required,gte=1,lte=150"`
+	Gender   string `json:"gender" validate:"required,notblank,max=10"`
+	Address  string `json:"address" validate:"required,notblank,max=255"`
+
+	// what the candidate is looking for, used to recommend careers.
+	// Locations are where they are willing to work, the first being where
+	// they live; RemotePreference defaults to any. ExpectedSalary is yearly
+	// and is given together with its currency.
+	Locations        geo.Locations `json:"locations" validate:"max=10,dive"`
+	RemotePreference string        `json:"remote_preference" validate:"omitempty,oneof=onsite hybrid remote any"`
+	ExpectedSalary   *int64        `json:"expected_salary" validate:"required_with=SalaryCurrency,omitempty,gte=0"`
+	SalaryCurrency   *string       `json:"salary_currency" validate:"required_with=ExpectedSalary,omitempty,iso4217"`
+	YearsExperience  *int16        `json:"years_experience" validate:"omitempty,gte=0,lte=60"`
+}
+
+// ToParams builds the profile of owner, who supplies the user ID and phone number
+func (r CreateProfileRequest) ToParams(owner database.User) database.CreateProfileParams {
+	return database.CreateProfileParams{
+		Userid:      owner.Userid,
+		Fullname:    strings.TrimSpace(r.Fullname),
+		Age:         r.Age,
+		Gender:      strings.TrimSpace(r.Gender),
+		Address:     strings.TrimSpace(r.Address),
+		Phonenumber: owner.Phonenumber,
+
+		Locations:        trimLocations(r.Locations),
+		RemotePreference: stringOr(r.RemotePreference, "any"),
+		ExpectedSalary:   r.ExpectedSalary,
+		SalaryCurrency:   r.SalaryCurrency,
+		YearsExperience:  r.YearsExperience,
+	}
+}
+
+// UpdateProfileRequest only changes the fields that are present in the body
+type UpdateProfileRequest struct {
+	Fullname *string `json:"fullname" validate:"omitempty,notblank,max=255"`
+	Age      *int32  `json:"age" validate:"omitempty,gte=1,lte=150"`
+	Gender   *string `json:"gender" validate:"omitempty,notblank,max=10"`
+	Address  *string `json:"address" validate:"omitempty,notblank,max=255"`
+}
+
+// ToParams merges the request over the existing profile
+func (r UpdateProfileRequest) ToParams(existing database.Profile) database.UpdateProfileByuserIdParams {
+	age := existing.Age
+	if r.Age != nil {
+		age = *r.Age
+	}
+	return database.UpdateProfileByuserIdParams{
+		Fullname: valueOr(r.Fullname, existing.Fullname),
+		Age:      age,
+		Gender:   valueOr(r.Gender, existing.Gender),
+		Address:  valueOr(r.Address, existing.Address),
+		Userid:   existing.Userid,
+	}
+}
+
+// ProfileDocument is the patchable form of a profile. Unlike the create and
+// update requests it allows an age of 0 and an empty gender or address.
+type ProfileDocument struct {
+	Fullname string `json:"fullname" validate:"required,notblank,max=255"`
+	Age      int32  `json:"age" validate:"gte=0,lte=150"`
+	Gender   string `json:"gender" validate:"max=10"`
+	Address  string `json:"address" validate:"max=255"`
+
+	Locations        geo.Locations `json:"locations" validate:"max=10,dive"`
+	RemotePreference string        `json:"remote_preference" validate:"required,oneof=onsite hybrid remote any"`
+	ExpectedSalary   *int64        `json:"expected_salary" validate:"required_with=SalaryCurrency,omitempty,gte=0"`
+	SalaryCurrency   *string       `json:"salary_currency" validate:"required_with=ExpectedSalary,omitempty,iso4217"`
+	YearsExperience  *int16        `json:"years_experience" validate:"omitempty,gte=0,lte=60"`
+}
+
+func NewProfileDocument(profile database.Profile) ProfileDocument {
+	return ProfileDocument{
+		Fullname: profile.Fullname,
+		Age:      profile.Age,
+		Gender:   profile.Gender,
+		Address:  profile.Address,
+
+		Locations:        profile.Locations,
+		RemotePreference: profile.RemotePreference,
+		ExpectedSalary:   profile.ExpectedSalary,
+		SalaryCurrency:   profile.SalaryCurrency,
+		YearsExperience:  profile.YearsExperience,
+	}
+}
+
+// Changes lists the columns whose value differs from existing
+func (d ProfileDocument) Changes(existing database.Profile) []database.Assignment {
+	var set []database.Assignment
+	set = appendChanged(set, "fullname", strings.TrimSpace(d.Fullname), existing.Fullname)
+	if d.Age != existing.Age {
+		set = append(set, database.Assignment{Column: "age", Value: d.Age})
+	}
+	set = appendChanged(set, "gender", strings.TrimSpace(d.Gender), existing.Gender)
+	set = appendChanged(set, "address", strings.TrimSpace(d.Address), existing.Address)
+	if locations := trimLocations(d.Locations); !locations.Equal(existing.Locations) {
+		set = append(set, database.Assignment{Column: "locations", Value: locations})
+	}
+	set = appendChanged(set, "remote_preference", d.RemotePreference, existing.RemotePreference)
+	set = appendChangedPointer(set, "expected_salary", d.ExpectedSalary, existing.ExpectedSalary)
+	set = appendChangedPointer(set, "salary_currency", d.SalaryCurrency, existing.SalaryCurrency)
+	set = appendChangedPointer(set, "years_experience", d.YearsExperience, existing.YearsExperience)
+	return set
+}
+
+type CreateCompanyRequest struct {
+	Name        string `json:"name" validate:"required,notblank,max=255"`
+	Website     string `json:"website" validate:"omitempty,url,max=255"`
+	LogoURL     string `json:"logo_url" validate:"omitempty,url,max=255"`
+	Description string `json:"description" validate:"max=1000"`
+}
+
+// ToParams derives the company's slug from its name
+func (r CreateCompanyRequest) ToParams() database.CreateCompanyParams {
+	name := strings.TrimSpace(r.Name)
+	return database.CreateCompanyParams{
+		Name:        name,
+		Slug:        slug.Company(name),
+		Website:     strings.TrimSpace(r.Website),
+		LogoURL:     strings.TrimSpace(r.LogoURL),
+		Description: strings.TrimSpace(r.Description),
+	}
+}
+
+type AddRecruiterRequest struct {
+	Userid int64 `json:"userid" validate:"required,gte=1"`
+}
+
+type CreateSkillRequest struct {
+	Name    string   `json:"name" validate:"required,notblank,max=100"`
+	Aliases []string `json:"aliases" validate:"max=20,unique,dive,required,notblank,max=100"`
+}
+
+type AddSkillAliasRequest struct {
+	Alias string `json:"alias" validate:"required,notblank,max=100"`
+}
+
+// DefaultSkillLimit is the number of skills GET /skills returns by default
+const DefaultSkillLimit = 20
+
+// SkillsQuery holds the query parameters of GET /skills, which autocompletes
+// skill names and aliases starting with prefix
+type SkillsQuery struct {
+	Prefix string `form:"prefix" json:"prefix" validate:"max=100"`
+	Limit  int32  `form:"limit" json:"limit" validate:"omitempty,gte=1,lte=100"`
+}
+
+func (r SkillsQuery) ToParams() database.ListSkillsParams {
+	params := database.ListSkillsParams{Prefix: strings.TrimSpace(r.Prefix), MaxRows: r.Limit}
+	if params.MaxRows == 0 {
+		params.MaxRows = DefaultSkillLimit
+	}
+	return params
+}
+
+// CareerSkillRequest tags a career with a skill, given by name or alias.
+// Required defaults to true; false means nice to have.
+type CareerSkillRequest struct {
+	Skill    string `json:"skill" validate:"required,notblank,max=100"`
+	Required *bool  `json:"required"`
+	MinYears *int16 `json:"min_years" validate:"omitempty,gte=0,lte=50"`
+}
+
+// SetCareerSkillsRequest replaces the skills of a career; an empty list
+// removes them all
+type SetCareerSkillsRequest struct {
+	Skills []CareerSkillRequest `json:"skills" validate:"required,max=50,dive"`
+}
+
+// ProfileSkillRequest tags a profile with a skill, given by name or alias
+type ProfileSkillRequest struct {
+	Skill       string `json:"skill" validate:"required,notblank,max=100"`
+	Proficiency string `json:"proficiency" validate:"required,oneof=beginner intermediate advanced expert"`
+}
+
+// SetProfileSkillsRequest replaces the skills of a profile; an empty list
+// removes them all
+type SetProfileSkillsRequest struct {
+	Skills []ProfileSkillRequest `json:"skills" validate:"required,max=100,dive"`
+}
+
+// AuditQuery holds the query parameters of GET /audit. since and until are
+// RFC 3339 times; before_id pages back from the last event of the previous page.
+type AuditQuery struct {
+	Actor        string     `form:"actor" json:"actor" validate:"omitempty,max=255"`
+	ResourceType string     `form:"resource_type" json:"resource_type" validate:"omitempty,oneof=user career profile company skill saved_career saved_search"`
+	ResourceID   *int64     `form:"resource_id" json:"resource_id" validate:"omitempty,gte=1"`
+	Since        *time.Time `form:"since" json:"since"`
+	Until        *time.Time `form:"until" json:"until"`
+	BeforeID     *int64     `form:"before_id" json:"before_id" validate:"omitempty,gte=1"`
+	Limit        int32      `form:"limit" json:"limit" validate:"omitempty,min=1,max=1000"`
+	Format       string     `form:"format" json:"format" validate:"omitempty,oneof=json csv"`
+}
+
+// DefaultAuditLimit is the page size when limit is not given
+const DefaultAuditLimit = 100
+
+// ToParams turns the filters into sqlc parameters
+func (r AuditQuery) ToParams() database.ListAuditEventsParams {
+	params := database.ListAuditEventsParams{
+		Actor:        nullString(normaliseEmail(r.Actor)),
+		ResourceType: nullString(r.ResourceType),
+		Since:        r.Since,
+		Until:        r.Until,
+		MaxRows:      r.Limit,
+	}
+	if r.ResourceID != nil {
+		params.ResourceID = sql.NullInt64{Int64: *r.ResourceID, Valid: true}
+	}
+	if r.BeforeID != nil {
+		params.BeforeID = sql.NullInt64{Int64: *r.BeforeID, Valid: true}
+	}
+	if params.MaxRows == 0 {
+		params.MaxRows = DefaultAuditLimit
+	}
+	return params
+}
+
+// DefaultRadiusKm is the search radius when near is given without radius_km
+const DefaultRadiusKm = 50
+
+// CareersQuery holds the query parameters of GET /careers. The salary bounds
+// are yearly amounts in currency, which defaults to the base currency. near
+// is "lat,lon" or a city name from the gazetteer. skills is a comma-separated
+// list of skill names or aliases; careers need all of them unless skill_match
+// is any. Every word of q must appear in the position, company or
+// description. Careers whose enddate has passed are only listed with
+// include_expired.
+type CareersQuery struct {
+	Q          string   `form:"q" json:"q" validate:"max=255"`
+	Jobtype    string   `form:"jobtype" json:"jobtype" validate:"max=255"`
+	SalaryMin  *int64   `form:"salary_min" json:"salary_min" validate:"omitempty,gte=0"`
+	SalaryMax  *int64   `form:"salary_max" json:"salary_max" validate:"omitempty,gte=0"`
+	Currency   string   `form:"currency" json:"currency" validate:"omitempty,iso4217"`
+	Near       string   `form:"near" json:"near" validate:"required_with=RadiusKm,max=255"`
+	RadiusKm   *float64 `form:"radius_km" json:"radius_km" validate:"omitempty,gt=0,lte=20040"`
+	Skills     string   `form:"skills" json:"skills" validate:"max=1000"`
+	SkillMatch string   `form:"skill_match" json:"skill_match" validate:"omitempty,oneof=all any"`
+
+	IncludeExpired bool `form:"include_expired" json:"include_expired"`
+}
+
+// SkillNames splits the skills parameter
+func (r CareersQuery) SkillNames() []string {
+	var names []string
+	for _, name := range strings.Split(r.Skills, ",") {
+		if name = strings.TrimSpace(name); name != "" {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+// ToParams turns the filters into sqlc parameters, comparing salaries in
+// currency unless the query names one. near is the parsed near parameter and
+// skillIDs the skills the names in the skills parameter resolve to.
+func (r CareersQuery) ToParams(currency string, near *geo.Point, skillIDs []int64) database.ListCareersParams {
+	params := database.ListCareersParams{
+		Currency:  currency,
+		RadiusKm:  DefaultRadiusKm,
+		Keywords:  nonNil(strings.Fields(r.Q)),
+		SkillIds:  nonNil(skillIDs),
+		AllSkills: r.SkillMatch != "any",
+
+		IncludeExpired: r.IncludeExpired,
+	}
+	if jobtype := strings.TrimSpace(r.Jobtype); jobtype != "" {
+		params.Jobtype = sql.NullString{String: jobtype, Valid: true}
+	}
+	if near != nil {
+		params.NearLat = sql.NullFloat64{Float64: near.Lat, Valid: true}
+		params.NearLon = sql.NullFloat64{Float64: near.Lon, Valid: true}
+	}
+	if r.RadiusKm != nil {
+		params.RadiusKm = *r.RadiusKm
+	}
+	if r.Currency != "" {
+		params.Currency = r.Currency
+	}
+	if r.SalaryMin != nil {
+		params.SalaryMin = sql.NullInt64{Int64: *r.SalaryMin, Valid: true}
+	}
+	if r.SalaryMax != nil {
+		params.SalaryMax = sql.NullInt64{Int64: *r.SalaryMax, Valid: true}
+	}
+	return params
+}
+
+// DefaultRecommendationLimit is the number of recommendations returned by
+// default
+const DefaultRecommendationLimit = 20
+
+// RecommendationsQuery holds the query parameters of
+// GET /me/recommended-careers and GET /careers/:id/recommended-candidates
+type RecommendationsQuery struct {
+	Limit    int32    `form:"limit" json:"limit" validate:"omitempty,gte=1,lte=100"`
+	MinScore *float64 `form:"min_score" json:"min_score" validate:"omitempty,gte=0,lte=1"`
+}
+
+// SaveCareerRequest saves a career for the caller, or changes the note and
+// list of a career they saved already; fields left out stay as they are. An
+// empty list takes the career out of its list.
+type SaveCareerRequest struct {
+	Note *string `json:"note" validate:"omitempty,max=1000"`
+	List *string `json:"list" validate:"omitempty,max=100"`
+}
+
+// SavedCareersQuery holds the query parameters of GET /me/saved-careers;
+// list only shows the careers in that list
+type SavedCareersQuery struct {
+	List string `form:"list" json:"list" validate:"max=100"`
+}
+
+// ImportCareersQuery configures a career import. Format is read from the
+// Content-Type when it is not given, and Mode defaults to atomic. Mapping is
+// taken from the map[column]=field parameters.
+type ImportCareersQuery struct {
+	Format  string            `form:"format" json:"format" validate:"omitempty,oneof=csv jsonl"`
+	Mode    string            `form:"mode" json:"mode" validate:"omitempty,oneof=atomic best_effort"`
+	DryRun  bool              `form:"dry_run" json:"dry_run"`
+	Async   bool              `form:"async" json:"async"`
+	Mapping map[string]string `form:"-" json:"map"`
+}
+
+func (r ImportCareersQuery) ToParams(rows int, ignored []string, createdBy string) database.CreateCareerImportParams {
+	return database.CreateCareerImportParams{
+		Format:         r.Format,
+		Mode:           stringOr(r.Mode, "atomic"),
+		DryRun:         r.DryRun,
+		TotalRows:      int32(rows),
+		IgnoredColumns: nonNil(ignored),
+		CreatedBy:      createdBy,
+	}
+}
+
+// SavedSearchRequest creates or replaces a saved search. The filters are
+// those of GET /careers of the same names, keywords being q; alerts defaults
+// to true.
+type SavedSearchRequest struct {
+	Name      string   `json:"name" validate:"required,notblank,max=100"`
+	Keywords  string   `json:"keywords" validate:"max=255"`
+	Jobtype   string   `json:"jobtype" validate:"max=255"`
+	Near      string   `json:"near" validate:"required_with=RadiusKm,max=255"`
+	RadiusKm  *float64 `json:"radius_km" validate:"omitempty,gt=0,lte=20040"`
+	SalaryMin *int64   `json:"salary_min" validate:"omitempty,gte=0"`
+	Currency  *string  `json:"currency" validate:"omitempty,iso4217"`
+	Alerts    *bool    `json:"alerts"`
+}
+
+// Query is the GET /careers query the search runs
+func (r SavedSearchRequest) Query() CareersQuery {
+	query := CareersQuery{
+		Q:         strings.TrimSpace(r.Keywords),
+		Jobtype:   strings.TrimSpace(r.Jobtype),
+		Near:      strings.TrimSpace(r.Near),
+		RadiusKm:  r.RadiusKm,
+		SalaryMin: r.SalaryMin,
+	}
+	if r.Currency != nil {
+		query.Currency = *r.Currency
+	}
+	return query
+}
+
+// ToCreateParams stores the search for userid, alerting about careers
+// published after lastPublishedSeq
+func (r SavedSearchRequest) ToCreateParams(userid, lastPublishedSeq int64, unsubscribeToken string) database.CreateSavedSearchParams {
+	query := r.Query()
+	return database.CreateSavedSearchParams{
+		Userid:           userid,
+		Name:             strings.TrimSpace(r.Name),
+		Keywords:         query.Q,
+		Jobtype:          query.Jobtype,
+		Near:             query.Near,
+		RadiusKm:         r.RadiusKm,
+		SalaryMin:        r.SalaryMin,
+		Currency:         r.Currency,
+		Alerts:           r.Alerts == nil || *r.Alerts,
+		UnsubscribeToken: unsubscribeToken,
+		LastPublishedSeq: lastPublishedSeq,
+	}
+}
+
+func (r SavedSearchRequest) ToUpdateParams(existing database.SavedSearch) database.UpdateSavedSearchParams {
+	query := r.Query()
+	return database.UpdateSavedSearchParams{
+		ID:        existing.ID,
+		Userid:    existing.Userid,
+		Name:      strings.TrimSpace(r.Name),
+		Keywords:  query.Q,
+		Jobtype:   query.Jobtype,
+		Near:      query.Near,
+		RadiusKm:  r.RadiusKm,
+		SalaryMin: r.SalaryMin,
+		Currency:  r.Currency,
+		Alerts:    r.Alerts == nil || *r.Alerts,
+	}
+}
+
+// UnsubscribeQuery holds the query parameters of GET and POST /alerts/unsubscribe
+type UnsubscribeQuery struct {
+	Token string `form:"token" json:"token" validate:"required,max=255"`
+}
+
+// RevisionsQuery holds the query parameters of GET /careers/:id/revisions.
+// at is an RFC 3339 time; only revisions saved by then are listed.
+type RevisionsQuery struct {
+	At *time.Time `form:"at" json:"at"`
+}
+
+// RevisionDiffQuery holds the query parameters of
+// GET /careers/:id/revisions/:rev/diff. from defaults to the previous revision.
+type RevisionDiffQuery struct {
+	From *int32 `form:"from" json:"from" validate:"omitempty,gte=1"`
+}
+
+func nullString(value string) sql.NullString {
+	return sql.NullString{String: value, Valid: value != ""}
+}
+
+// appendChangedPointer compares nullable columns, where nil is NULL
+func appendChangedPointer[T comparable](set []database.Assignment, column string, value, existing *T) []database.Assignment {
+	if value == nil && existing == nil || value != nil && existing != nil && *value == *existing {
+		return set
+	}
+	return append(set, database.Assignment{Column: column, Value: value})
+}
+
+func appendChanged(set []database.Assignment, column, value, existing string) []database.Assignment {
+	if value == existing {
+		return set
+	}
+	return append(set, database.Assignment{Column: column, Value: value})
+}
+
+// trimLocations trims the text of each location; the result is never nil
+func trimLocations(locations geo.Locations) geo.Locations {
+	trimmed := make(geo.Locations, len(locations))
+	for i, location := range locations {
+		location.City = strings.TrimSpace(location.City)
+		location.Region = strings.TrimSpace(location.Region)
+		trimmed[i] = location
+	}
+	return trimmed
+}
+
+// nonNil returns an empty slice for nil, since NOT NULL array columns reject
+// the NULL a nil slice is sent as
+func nonNil[T any](values []T) []T {
+	if values == nil {
+		return []T{}
+	}
+	return values
+}
+
+func stringOr(value, fallback string) string {
+	if value == "" {
+		return fallback
+	}
+	return value
+}
+
+func normaliseEmail(email string) string {
+	return strings.ToLower(strings.TrimSpace(email))
+}
+
+func valueOr(value *string, fallback string) string {
+	if value == nil {
+		return fallback
+	}
+	return strings.TrimSpace(*value)
+}
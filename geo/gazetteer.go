@@ -0,0 +1,116 @@
+package geo
+
+import (
+	_ "embed"
+	"encoding/csv"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// cities.csv is the gazetteer: name, region, country, lat, lon and population
+// of the cities that can be geocoded without a network lookup
+//
+//go:embed cities.csv
+var citiesCSV string
+
+// City is an entry of the gazetteer
+type City struct {
+	Name       string
+	Region     string
+	Country    string
+	Point      Point
+	Population int64
+}
+
+var cities = mustLoadCities(citiesCSV)
+
+func mustLoadCities(data string) []City {
+	records, err := csv.NewReader(strings.NewReader(data)).ReadAll()
+	if err != nil {
+		panic(fmt.Sprintf("geo: reading cities.csv: %v", err))
+	}
+	list := make([]City, 0, len(records)-1)
+	for i, record := range records[1:] {
+		lat, errLat := strconv.ParseFloat(record[3], 64)
+		lon, errLon := strconv.ParseFloat(record[4], 64)
+		population, errPopulation := strconv.ParseInt(record[5], 10, 64)
+		if errLat != nil || errLon != nil || errPopulation != nil {
+			panic(fmt.Sprintf("geo: cities.csv line %d is malformed", i+2))
+		}
+		list = append(list, City{
+			Name:       record[0],
+			Region:     record[1],
+			Country:    record[2],
+			Point:      Point{Lat: lat, Lon: lon},
+			Population: population,
+		})
+	}
+	return list
+}
+
+// LookupCity finds a city by name, ignoring case. region and country narrow
+// the search when they are not empty; of several matches the most populous
+// wins, so "Portland" is Portland, Oregon.
+func LookupCity(name, region, country string) (City, bool) {
+	var found City
+	ok := false
+	for _, city := range cities {
+		if !strings.EqualFold(city.Name, strings.TrimSpace(name)) ||
+			region != "" && !strings.EqualFold(city.Region, strings.TrimSpace(region)) ||
+			country != "" && !strings.EqualFold(city.Country, strings.TrimSpace(country)) {
+			continue
+		}
+		if !ok || city.Population > found.Population {
+			found, ok = city, true
+		}
+	}
+	return found, ok
+}
+
+// Geocode fills in the coordinates, and a missing region or country, of l
+// from the gazetteer. Locations that already have coordinates are returned
+// as they are. ok is false when the city is not in the gazetteer.
+func Geocode(l Location) (Location, bool) {
+	if _, known := l.Point(); known {
+		return l, true
+	}
+	city, ok := LookupCity(l.City, l.Region, l.Country)
+	if !ok {
+		return l, false
+	}
+	lat, lon := city.Point.Lat, city.Point.Lon
+	l.Lat, l.Lon = &lat, &lon
+	if l.Region == "" {
+		l.Region = city.Region
+	}
+	if l.Country == "" {
+		l.Country = city.Country
+	}
+	return l, true
+}
+
+// ParseNear reads the near query parameter, either "lat,lon" in degrees or
+// a city name optionally followed by a country code, e.g. "Portland, US".
+func ParseNear(near string) (Point, error) {
+	first, second, pair := strings.Cut(near, ",")
+	if pair {
+		lat, errLat := strconv.ParseFloat(strings.TrimSpace(first), 64)
+		lon, errLon := strconv.ParseFloat(strings.TrimSpace(second), 64)
+		if errLat == nil && errLon == nil {
+			if lat < -90 || lat > 90 || lon < -180 || lon > 180 {
+				return Point{}, fmt.Errorf("%q is not a valid coordinate", near)
+			}
+			return Point{Lat: lat, Lon: lon}, nil
+		}
+	}
+	country := ""
+	if pair {
+		country = strings.TrimSpace(second)
+	}
+	city, ok := LookupCity(first, "", country)
+	if !ok {
+		return Point{}, fmt.Errorf("%q is neither lat,lon nor a known city", near)
+	}
+	return city.Point, nil
+}
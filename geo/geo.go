@@ -0,0 +1,90 @@
+// Package geo holds career locations, the great-circle distance used by the
+// radius search and an offline gazetteer for geocoding city names.
+package geo
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"math"
+	"slices"
+)
+
+// EarthRadiusKm is the mean radius used by DistanceKm and the haversine_km
+// SQL function
+const EarthRadiusKm = 6371.0088
+
+// Location is one place a career is based. Lat and Lon are filled in from
+// the gazetteer when they are not given.
+type Location struct {
+	City    string   `json:"city" validate:"required,notblank,max=100"`
+	Region  string   `json:"region" validate:"max=100"`
+	Country string   `json:"country" validate:"omitempty,iso3166_1_alpha2"`
+	Lat     *float64 `json:"lat" validate:"required_with=Lon,omitempty,latitude"`
+	Lon     *float64 `json:"lon" validate:"required_with=Lat,omitempty,longitude"`
+}
+
+// Locations is stored as a JSON array in the career.locations column
+type Locations []Location
+
+// Value encodes the locations as JSON; nil is stored as an empty array
+func (l Locations) Value() (driver.Value, error) {
+	if l == nil {
+		return []byte("[]"), nil
+	}
+	return json.Marshal([]Location(l))
+}
+
+// Scan decodes the JSON array read from the database
+func (l *Locations) Scan(src any) error {
+	switch src := src.(type) {
+	case []byte:
+		return json.Unmarshal(src, l)
+	case string:
+		return json.Unmarshal([]byte(src), l)
+	case nil:
+		*l = Locations{}
+		return nil
+	default:
+		return fmt.Errorf("geo: cannot scan %T into Locations", src)
+	}
+}
+
+// Equal reports whether l and other list the same places in the same order;
+// nil and empty are equal
+func (l Locations) Equal(other Locations) bool {
+	return slices.EqualFunc(l, other, func(a, b Location) bool {
+		return a.City == b.City && a.Region == b.Region && a.Country == b.Country &&
+			equalFloat(a.Lat, b.Lat) && equalFloat(a.Lon, b.Lon)
+	})
+}
+
+func equalFloat(a, b *float64) bool {
+	return a == nil && b == nil || a != nil && b != nil && *a == *b
+}
+
+// Point is a position in degrees
+type Point struct {
+	Lat, Lon float64
+}
+
+// Point returns the coordinates of l, which are only known once l is
+// geocoded
+func (l Location) Point() (Point, bool) {
+	if l.Lat == nil || l.Lon == nil {
+		return Point{}, false
+	}
+	return Point{Lat: *l.Lat, Lon: *l.Lon}, true
+}
+
+// DistanceKm is the haversine distance between a and b in kilometres
+func DistanceKm(a, b Point) float64 {
+	lat1, lat2 := radians(a.Lat), radians(b.Lat)
+	dLat, dLon := radians(b.Lat-a.Lat), radians(b.Lon-a.Lon)
+	h := math.Pow(math.Sin(dLat/2), 2) + math.Cos(lat1)*math.Cos(lat2)*math.Pow(math.Sin(dLon/2), 2)
+	return 2 * EarthRadiusKm * math.Asin(math.Sqrt(h))
+}
+
+func radians(degrees float64) float64 {
+	return degrees * math.Pi / 180
+}
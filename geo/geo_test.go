@@ -0,0 +1,66 @@
+package geo
+
+import (
+	"math"
+	"testing"
+)
+
+func TestDistanceKm(t *testing.T) {
+	tests := []struct {
+		name string
+		a, b Point
+		want float64
+	}{
+		{"same point", Point{52.52, 13.405}, Point{52.52, 13.405}, 0},
+		{"New York to London", Point{40.7128, -74.0060}, Point{51.5074, -0.1278}, 5570},
+		{"quarter meridian", Point{0, 0}, Point{90, 0}, 10008},
+		{"across the antimeridian", Point{0, 179.5}, Point{0, -179.5}, 111},
+	}
+	for _, tt := range tests {
+		if got := DistanceKm(tt.a, tt.b); math.Abs(got-tt.want) > 1 {
+			t.Errorf("%s: DistanceKm = %.1f, want %.0f", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestLookupCity(t *testing.T) {
+	tests := []struct {
+		name, region, country string
+		want                  string
+	}{
+		{"portland", "", "", "Oregon"},
+		{"Portland", "maine", "", "Maine"},
+		{" Portland ", "", "us", "Oregon"},
+		{"Berlin", "", "DE", "Berlin"},
+	}
+	for _, tt := range tests {
+		city, ok := LookupCity(tt.name, tt.region, tt.country)
+		if !ok || city.Region != tt.want {
+			t.Errorf("LookupCity(%q, %q, %q) = %+v, %v, want region %s", tt.name, tt.region, tt.country, city, ok, tt.want)
+		}
+	}
+	if city, ok := LookupCity("Berlin", "", "US"); ok {
+		t.Errorf("LookupCity(Berlin, US) = %+v, want no match", city)
+	}
+}
+
+func TestParseNear(t *testing.T) {
+	tests := map[string]Point{
+		"52.52,13.405":  {52.52, 13.405},
+		" -33.9, 18.4 ": {-33.9, 18.4},
+		"Tokyo":         {35.6762, 139.6503},
+		"Portland, US":  {45.5152, -122.6784},
+	}
+	for near, want := range tests {
+		got, err := ParseNear(near)
+		if err != nil || got != want {
+			t.Errorf("ParseNear(%q) = %v, %v, want %v", near, got, err, want)
+		}
+	}
+	// the part after the comma is a country, not a region
+	for _, near := range []string{"", "91,0", "0,181", "Atlantis", "Portland,Maine"} {
+		if got, err := ParseNear(near); err == nil {
+			t.Errorf("ParseNear(%q) = %v, want an error", near, got)
+		}
+	}
+}
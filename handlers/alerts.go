@@ -0,0 +1,149 @@
+package handlers
+
+import (
+	"net/http"
+
+	"jobApps/apperror"
+	"jobApps/authentication"
+	"jobApps/dto"
+	"jobApps/validation"
+
+	"github.com/gin-gonic/gin"
+)
+
+func (db DbConnection) CreateSavedSearch(g *gin.Context) {
+	if err := authentication.UserAuth(g); err != nil {
+		apperror.Write(g, err)
+		return
+	}
+
+	var request dto.SavedSearchRequest
+	if err := validation.BindJSON(g, &request); err != nil {
+		apperror.Write(g, err)
+		return
+	}
+
+	search, err := db.Service.CreateSavedSearch(g.Request.Context(), request)
+	if err != nil {
+		apperror.Write(g, err)
+		return
+	}
+	g.JSON(http.StatusOK, gin.H{
+		"status":  200,
+		"message": "saved search created successfully",
+		"data":    search,
+	})
+}
+
+func (db DbConnection) GetSavedSearches(g *gin.Context) {
+	if err := authentication.UserAuth(g); err != nil {
+		apperror.Write(g, err)
+		return
+	}
+
+	searches, err := db.Service.SavedSearches(g.Request.Context())
+	if err != nil {
+		apperror.Write(g, err)
+		return
+	}
+	g.JSON(http.StatusOK, gin.H{
+		"status":  200,
+		"message": "saved searches retrieved successfully",
+		"data":    searches,
+	})
+}
+
+func (db DbConnection) UpdateSavedSearch(g *gin.Context) {
+	if err := authentication.UserAuth(g); err != nil {
+		apperror.Write(g, err)
+		return
+	}
+
+	searchID, err := pathID(g)
+	if err != nil {
+		apperror.Write(g, err)
+		return
+	}
+	var request dto.SavedSearchRequest
+	if err := validation.BindJSON(g, &request); err != nil {
+		apperror.Write(g, err)
+		return
+	}
+
+	search, err := db.Service.UpdateSavedSearch(g.Request.Context(), searchID, request)
+	if err != nil {
+		apperror.Write(g, err)
+		return
+	}
+	g.JSON(http.StatusOK, gin.H{
+		"status":  200,
+		"message": "saved search updated successfully",
+		"data":    search,
+	})
+}
+
+func (db DbConnection) DeleteSavedSearch(g *gin.Context) {
+	if err := authentication.UserAuth(g); err != nil {
+		apperror.Write(g, err)
+		return
+	}
+
+	searchID, err := pathID(g)
+	if err != nil {
+		apperror.Write(g, err)
+		return
+	}
+
+	if err := db.Service.DeleteSavedSearch(g.Request.Context(), searchID); err != nil {
+		apperror.Write(g, err)
+		return
+	}
+	g.JSON(http.StatusOK, gin.H{
+		"status":  200,
+		"message": "saved search deleted successfully",
+	})
+}
+
+// ConfirmUnsubscribe is linked from job alert digests, so it needs no login:
+// the unsubscribe token in the link identifies the saved search. It only
+// shows the search, since mail scanners and link previews follow links;
+// unsubscribing takes a POST to the same URL.
+func (db DbConnection) ConfirmUnsubscribe(g *gin.Context) {
+	var query dto.UnsubscribeQuery
+	if err := validation.BindQuery(g, &query); err != nil {
+		apperror.Write(g, err)
+		return
+	}
+
+	search, err := db.Service.SavedSearchByToken(g.Request.Context(), query.Token)
+	if err != nil {
+		apperror.Write(g, err)
+		return
+	}
+	g.JSON(http.StatusOK, gin.H{
+		"status":  200,
+		"message": "send a POST request to this URL to unsubscribe from the alerts of " + search.Name,
+		"data":    search,
+	})
+}
+
+// Unsubscribe turns off the alerts of the saved search with the token in the
+// query, also as the one-click unsubscribe of RFC 8058
+func (db DbConnection) Unsubscribe(g *gin.Context) {
+	var query dto.UnsubscribeQuery
+	if err := validation.BindQuery(g, &query); err != nil {
+		apperror.Write(g, err)
+		return
+	}
+
+	search, err := db.Service.Unsubscribe(g.Request.Context(), query.Token)
+	if err != nil {
+		apperror.Write(g, err)
+		return
+	}
+	g.JSON(http.StatusOK, gin.H{
+		"status":  200,
+		"message": "unsubscribed from the alerts of " + search.Name,
+		"data":    search,
+	})
+}
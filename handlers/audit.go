@@ -0,0 +1,82 @@
+package handlers
+
+import (
+	"encoding/csv"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"jobApps/apperror"
+	"jobApps/authentication"
+	"jobApps/dto"
+	"jobApps/internal/database"
+	"jobApps/validation"
+
+	"github.com/gin-gonic/gin"
+)
+
+var auditCSVHeader = []string{"id", "occurred_at", "actor_email", "actor_role", "action", "resource_type", "resource_id", "changes", "request_id", "ip"}
+
+func (db DbConnection) GetAuditEvents(g *gin.Context) {
+	if err := authentication.AdminAuth(g); err != nil {
+		apperror.Write(g, err)
+		return
+	}
+
+	var query dto.AuditQuery
+	if err := validation.BindQuery(g, &query); err != nil {
+		apperror.Write(g, err)
+		return
+	}
+
+	events, err := db.Service.AuditEvents(g.Request.Context(), query.ToParams())
+	if err != nil {
+		apperror.Write(g, err)
+		return
+	}
+
+	if query.Format == "csv" {
+		writeAuditCSV(g, events)
+		return
+	}
+	g.JSON(http.StatusOK, gin.H{
+		"status":  200,
+		"message": "audit events retrieved successfully",
+		"data":    events,
+	})
+}
+
+// writeAuditCSV sends the events as a CSV attachment
+func writeAuditCSV(g *gin.Context, events []database.AuditEvent) {
+	g.Header("Content-Type", "text/csv; charset=utf-8")
+	g.Header("Content-Disposition", `attachment; filename="audit.csv"`)
+	g.Status(http.StatusOK)
+
+	w := csv.NewWriter(g.Writer)
+	_ = w.Write(auditCSVHeader)
+	for _, event := range events {
+		_ = w.Write([]string{
+			strconv.FormatInt(event.ID, 10),
+			event.OccurredAt.UTC().Format(time.RFC3339Nano),
+			csvCell(event.ActorEmail),
+			csvCell(event.ActorRole),
+			event.Action,
+			event.ResourceType,
+			strconv.FormatInt(event.ResourceID, 10),
+			string(event.Changes),
+			csvCell(event.RequestID),
+			csvCell(event.IP),
+		})
+	}
+	w.Flush()
+}
+
+// csvCell keeps spreadsheets from evaluating caller-supplied values as
+// formulas
+func csvCell(value string) string {
+	if value != "" && strings.ContainsRune("=+-@\t\r", rune(value[0])) {
+		return "'" + value
+	}
+	return value
+}
@@ -0,0 +1,136 @@
+package handlers
+
+import (
+	"net/http"
+
+	"jobApps/apperror"
+	"jobApps/authentication"
+	"jobApps/dto"
+	"jobApps/validation"
+
+	"github.com/gin-gonic/gin"
+)
+
+func (db DbConnection) CreateCompany(g *gin.Context) {
+	if err := authentication.AdminAuth(g); err != nil {
+		apperror.Write(g, err)
+		return
+	}
+
+	var request dto.CreateCompanyRequest
+	if err := validation.BindJSON(g, &request); err != nil {
+		apperror.Write(g, err)
+		return
+	}
+
+	company, err := db.Service.CreateCompany(g.Request.Context(), request)
+	if err != nil {
+		apperror.Write(g, err)
+		return
+	}
+	g.JSON(http.StatusOK, gin.H{
+		"status":  200,
+		"message": "company created successfully",
+		"data":    company,
+	})
+}
+
+func (db DbConnection) GetAllCompanies(g *gin.Context) {
+	if err := authentication.CommonAuth(g); err != nil {
+		apperror.Write(g, err)
+		return
+	}
+
+	companies, err := db.Service.Companies(g.Request.Context())
+	if err != nil {
+		apperror.Write(g, err)
+		return
+	}
+	g.JSON(http.StatusOK, gin.H{
+		"status":  200,
+		"message": "All companies were retrieved successfully",
+		"data":    companies,
+	})
+}
+
+func (db DbConnection) GetCompanyBySlug(g *gin.Context) {
+	if err := authentication.CommonAuth(g); err != nil {
+		apperror.Write(g, err)
+		return
+	}
+
+	company, err := db.Service.CompanyPage(g.Request.Context(), g.Param("slug"))
+	if err != nil {
+		apperror.Write(g, err)
+		return
+	}
+	g.JSON(http.StatusOK, gin.H{
+		"status":  200,
+		"message": "company retrieved successfully",
+		"data":    company,
+	})
+}
+
+func (db DbConnection) GetCompanyRecruiters(g *gin.Context) {
+	if err := authentication.AdminAuth(g); err != nil {
+		apperror.Write(g, err)
+		return
+	}
+
+	recruiters, err := db.Service.CompanyRecruiters(g.Request.Context(), g.Param("slug"))
+	if err != nil {
+		apperror.Write(g, err)
+		return
+	}
+	g.JSON(http.StatusOK, gin.H{
+		"status":  200,
+		"message": "company recruiters retrieved successfully",
+		"data":    recruiters,
+	})
+}
+
+func (db DbConnection) AddCompanyRecruiter(g *gin.Context) {
+	if err := authentication.AdminAuth(g); err != nil {
+		apperror.Write(g, err)
+		return
+	}
+
+	var request dto.AddRecruiterRequest
+	if err := validation.BindJSON(g, &request); err != nil {
+		apperror.Write(g, err)
+		return
+	}
+
+	recruiter, err := db.Service.AddRecruiter(g.Request.Context(), g.Param("slug"), request.Userid)
+	if err != nil {
+		apperror.Write(g, err)
+		return
+	}
+	g.JSON(http.StatusOK, gin.H{
+		"status":  200,
+		"message": "recruiter added successfully",
+		"data":    recruiter,
+	})
+}
+
+func (db DbConnection) RemoveCompanyRecruiter(g *gin.Context) {
+	if err := authentication.AdminAuth(g); err != nil {
+		apperror.Write(g, err)
+		return
+	}
+
+	userid, err := pathID(g)
+	if err != nil {
+		apperror.Write(g, err)
+		return
+	}
+
+	if err := db.Service.RemoveRecruiter(g.Request.Context(), g.Param("slug"), userid); err != nil {
+		apperror.Write(g, err)
+		return
+	}
+	g.JSON(http.StatusOK, gin.H{
+		"status":  200,
+		"message": "recruiter removed successfully",
+	})
+}
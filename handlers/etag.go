@@ -0,0 +1,93 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"hash/fnv"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"jobApps/apperror"
+	"jobApps/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+// etag is the strong entity tag of a resource version as sent to the
+// caller, e.g. "3-9b2f41c07e1d5a83". Admins and a company's recruiters see
+// fields other callers do not, so the tag carries a hash of the
+// representation besides the version If-Match compares.
+func etag(version int32, representation any) string {
+	hash := fnv.New64a()
+	if err := json.NewEncoder(hash).Encode(representation); err != nil {
+		// the response cannot be encoded either
+		return `"` + strconv.FormatInt(int64(version), 10) + `"`
+	}
+	return fmt.Sprintf(`"%d-%x"`, version, hash.Sum64())
+}
+
+// listETag is a weak entity tag of a list as sent to the caller. It hashes
+// the whole representation, since the caller's role, the requested currency
+// and company renames change the body without bumping any item's version.
+func listETag(items any) string {
+	hash := fnv.New64a()
+	if err := json.NewEncoder(hash).Encode(items); err != nil {
+		// the response cannot be encoded either
+		return `W/"0"`
+	}
+	return fmt.Sprintf(`W/"%x"`, hash.Sum64())
+}
+
+// notModified sets the ETag header and, when If-None-Match already holds the
+// tag, answers 304 Not Modified and reports true
+func notModified(g *gin.Context, tag string) bool {
+	g.Header("ETag", tag)
+
+	header := g.GetHeader("If-None-Match")
+	if header == "" {
+		return false
+	}
+	// If-None-Match uses the weak comparison
+	for _, candidate := range strings.Split(header, ",") {
+		candidate = strings.TrimSpace(candidate)
+		if candidate == "*" || strings.TrimPrefix(candidate, "W/") == strings.TrimPrefix(tag, "W/") {
+			g.Status(http.StatusNotModified)
+			return true
+		}
+	}
+	return false
+}
+
+// ifMatch reads the versions a write may apply to from If-Match. "*" matches
+// any version. Without the header every version matches, unless
+// RequireIfMatch is set.
+func (db DbConnection) ifMatch(g *gin.Context) (service.Versions, error) {
+	header := g.GetHeader("If-Match")
+	if header == "" {
+		if db.RequireIfMatch {
+			return nil, apperror.PreconditionRequired("the If-Match header is required; send the ETag of the version you are changing")
+		}
+		return nil, nil
+	}
+
+	versions := service.Versions{}
+	for _, candidate := range strings.Split(header, ",") {
+		candidate = strings.TrimSpace(candidate)
+		if candidate == "*" {
+			return nil, nil
+		}
+		// If-Match uses the strong comparison, so weak tags never match
+		if !strings.HasPrefix(candidate, `"`) {
+			continue
+		}
+		// only the version of a tag is compared, so a tag read by another
+		// role still matches
+		number, _, _ := strings.Cut(strings.Trim(candidate, `"`), "-")
+		version, err := strconv.ParseInt(number, 10, 32)
+		if err == nil {
+			versions = append(versions, int32(version))
+		}
+	}
+	return versions, nil
+}
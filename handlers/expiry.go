@@ -0,0 +1,47 @@
+package handlers
+
+import (
+	"net/http"
+
+	"jobApps/apperror"
+	"jobApps/authentication"
+	"jobApps/dto"
+	"jobApps/validation"
+
+	"github.com/gin-gonic/gin"
+)
+
+func (db DbConnection) ExtendCareer(g *gin.Context) {
+	if err := authentication.RecruiterAuth(g); err != nil {
+		apperror.Write(g, err)
+		return
+	}
+
+	jobId, err := pathID(g)
+	if err != nil {
+		apperror.Write(g, err)
+		return
+	}
+	var request dto.ExtendCareerRequest
+	if err := validation.BindJSON(g, &request); err != nil {
+		apperror.Write(g, err)
+		return
+	}
+	ifMatch, err := db.ifMatch(g)
+	if err != nil {
+		apperror.Write(g, err)
+		return
+	}
+
+	career, err := db.Service.ExtendCareer(g.Request.Context(), jobId, ifMatch, request)
+	if err != nil {
+		apperror.Write(g, err)
+		return
+	}
+	g.Header("ETag", etag(career.Version, career))
+	g.JSON(http.StatusOK, gin.H{
+		"status":  200,
+		"message": "career extended successfully",
+		"data":    career,
+	})
+}
@@ -1,22 +1,21 @@
 package handlers
 
 import (
-	"bytes"
-	"context"
-	"encoding/json"
 	"fmt"
-	"jobApps/internal/database"
+	"io"
+	"jobApps/apperror"
+	"jobApps/dto"
+	"jobApps/patch"
+	"jobApps/service"
+	"jobApps/validation"
 	"net/http"
-	"regexp"
 	"strconv"
-	"strings"
 	"time"
 
 	"jobApps/authentication"
 
 	"github.com/gin-gonic/gin"
 	"github.com/golang-jwt/jwt"
-	"golang.org/x/crypto/bcrypt"
 )
 
 type Controllers interface {
@@ -27,105 +26,27 @@ type Controllers interface {
 }
 
 type DbConnection struct {
-	Query *database.Queries
+	Service *service.Service
+	// RequireIfMatch rejects updates and deletes without an If-Match header
+	RequireIfMatch bool
 }
 
-func ControllerInstance(q *database.Queries) *DbConnection {
+func ControllerInstance(svc *service.Service) *DbConnection {
 	return &DbConnection{
-		Query: q,
+		Service: svc,
 	}
 }
 
 func (db DbConnection) SignUp(g *gin.Context) {
-	var users database.CreateUserParams
-	if err := g.BindJSON(&users); err != nil {
-		g.JSON(http.StatusBadRequest, gin.H{
-			"status": 400,
-			"error":  err.Error(),
-		})
+	var request dto.SignUpRequest
+	if err := validation.BindJSON(g, &request); err != nil {
+		apperror.Write(g, err)
 		return
 	}
 
-	//validates correct email format
-	emailRegex := regexp.MustCompile(`^[a-z0-9._%+\-]+@[a-z0-9.\-]+\.[a-z]{2,4}$`)
-	if !emailRegex.MatchString(users.Email) {
-		g.JSON(http.StatusBadRequest, gin.H{
-			"status": 400,
-			"error":  "invalid email format",
-		})
-		return
-	}
-	if users.Username == "" {
-		g.JSON(http.StatusBadRequest, gin.H{
-			"status": 400,
-			"Error":  "Username field should not be empty",
-		})
-		return
-	}
-
-	if users.Role != "user" && users.Role != "admin" {
-		g.JSON(http.StatusBadRequest, gin.H{
-			"status": 400,
-			"Error":  "Invalid value for role field.Only 'user' and 'admin' are allowed.",
-		})
-		return
-	}
-	//password should have minimum 8 character
-	if len(users.Password) < 8 {
-		g.JSON(http.StatusBadRequest, gin.H{
-			"Error":  "Password should be more than 8 characters",
-			"status": 400,
-		})
-		return
-	}
-
-	//passwords are stored in hashing method in the database
-	password, err := bcrypt.GenerateFromPassword([]byte(users.Password), bcrypt.DefaultCost)
+	usersData, err := db.Service.SignUp(g.Request.Context(), request)
 	if err != nil {
-		g.JSON(http.StatusInternalServerError, gin.H{
-			"Error":  "failed to hashing the password",
-			"status": 500,
-		})
-		return
-	}
-	users.Password = string(password)
-
-	// Validate phone number
-	phoneNumber := strings.TrimSpace(users.Phonenumber)
-	phoneRegex := regexp.MustCompile(`^[0-9]{10}$`)
-	if !phoneRegex.MatchString(phoneNumber) {
-		g.JSON(http.StatusBadRequest, gin.H{
-			"Error":  "Invalid phone number format",
-			"status": 400,
-		})
-		return
-	}
-
-	_, err = db.Query.GetUserByEmail(context.Background(), users.Email)
-	if err == nil {
-		g.JSON(http.StatusInternalServerError, gin.H{
-			"Error":  "email ID already exist",
-			"status": 500,
-		})
-		return
-	}
-
-	_, err = db.Query.GetUserByPhoneNumber(context.Background(), users.Phonenumber)
-	if err == nil {
-		g.JSON(http.StatusInternalServerError, gin.H{
-			"Error":  "user's Phonenumber already exist",
-			"status": 500,
-		})
-		return
-	}
-
-	usersData, err := db.Query.CreateUser(context.Background(), users)
-	if err != nil {
-		fmt.Println("Error", err)
-		g.JSON(http.StatusInternalServerError, gin.H{
-			"Error":  "failed to create user",
-			"status": 500,
-		})
+		apperror.Write(g, err)
 		return
 	}
 
@@ -133,49 +54,29 @@ func (db DbConnection) SignUp(g *gin.Context) {
 }
 
 func (db DbConnection) Login(g *gin.Context) {
-	var users database.CreateUserParams
-	if err := g.BindJSON(&users); err != nil {
-		g.JSON(http.StatusBadRequest, gin.H{
-			"status": 400,
-			"error":  err.Error(),
-		})
-		return
-	}
-
-	// Retrieve user data based on existing email
-	userData, err := db.Query.GetUserByEmail(context.Background(), users.Email)
-	if err != nil {
-		g.JSON(http.StatusUnauthorized, gin.H{
-			"status": 401,
-			"error":  "email does not exists",
-		})
+	var request dto.LoginRequest
+	if err := validation.BindJSON(g, &request); err != nil {
+		apperror.Write(g, err)
 		return
 	}
 
-	// Compare the stored hashed password with the provided password
-	err = bcrypt.CompareHashAndPassword([]byte(userData.Password), []byte(users.Password))
+	userData, err := db.Service.Login(g.Request.Context(), request)
 	if err != nil {
-		g.JSON(http.StatusUnauthorized, gin.H{
-			"status": 401,
-			"error":  "password not matching",
-		})
+		apperror.Write(g, err)
 		return
 	}
 
 	// Generate JWT token
 	token := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
-		"email": users.Email,
+		"email": userData.Email,
 		"role":  userData.Role,
 		"exp":   time.Now().Add(time.Hour * 24).Unix(),
 	})
 
 	tokenString, err := token.SignedString([]byte("secret"))
-	
+
 	if err != nil {
-		g.JSON(http.StatusInternalServerError, gin.H{
-			"status": 500,
-			"error":  "Failed to generate token",
-		})
+		apperror.Write(g, apperror.Internal(fmt.Errorf("signing token: %w", err)))
 		return
 	}
 
@@ -188,19 +89,14 @@ func (db DbConnection) Login(g *gin.Context) {
 }
 
 func (db DbConnection) GetAllUsersEmail(g *gin.Context) {
-	err := authentication.AdminAuth(g)
-	if err != nil {
-		g.JSON(http.StatusUnauthorized, gin.H{"error": "Only admins can access this endpoint"})
+	if err := authentication.AdminAuth(g); err != nil {
+		apperror.Write(g, err)
 		return
 	}
 
-	usersEmail, err := db.Query.GetallusersEmail(context.Background())
+	usersEmail, err := db.Service.UserEmails(g.Request.Context())
 	if err != nil {
-		g.JSON(http.StatusBadRequest, gin.H{
-			"status":  400,
-			"error":   "Failed to get users Email ",
-			"message": err.Error(),
-		})
+		apperror.Write(g, err)
 		return
 	}
 	g.JSON(http.StatusOK, gin.H{
@@ -211,84 +107,23 @@ func (db DbConnection) GetAllUsersEmail(g *gin.Context) {
 }
 
 func (db DbConnection) CreateCareer(g *gin.Context) {
-	err := authentication.AdminAuth(g)
-	if err != nil {
-		g.JSON(http.StatusUnauthorized, gin.H{"error": "Only admins can access this endpoint"})
-		return
-	}
-
-	var career database.CreateCareerParams
-
-	if err := g.BindJSON(&career); err != nil {
-		g.JSON(http.StatusBadRequest, gin.H{
-			"status":  400,
-			"error":   err.Error(),
-			"message": "Failed to bind JSON data",
-		})
-		return
-	}
-
-	// Validate required fields
-	missingFields := []string{}
-	if career.Company == "" {
-		missingFields = append(missingFields, "Company")
-	}
-	if career.Position == "" {
-		missingFields = append(missingFields, "Position")
-	}
-	if career.Jobtype == "" {
-		missingFields = append(missingFields, "Jobtype")
-	}
-	if career.Description == "" {
-		missingFields = append(missingFields, "Description")
-	}
-	if career.Startdate.IsZero() {
-		missingFields = append(missingFields, "Startdate")
-	}
-	if career.Enddate.IsZero() {
-		missingFields = append(missingFields, "Enddate")
-	}
-
-	if len(missingFields) > 0 {
-		g.JSON(http.StatusBadRequest, gin.H{
-			"status":        400,
-			"error":         "Missing fields",
-			"missingFields": missingFields,
-		})
+	if err := authentication.RecruiterAuth(g); err != nil {
+		apperror.Write(g, err)
 		return
 	}
 
-	if career.Startdate.After(career.Enddate) {
-		g.JSON(http.StatusBadRequest, gin.H{
-			"status":  400,
-			"error":   "Invalid date range",
-			"message": "Start date cannot be greater than end date",
-		})
-		return
-	}
-	_, err = db.Query.CreateCareer(context.Background(), career)
-	if err != nil {
-		g.JSON(http.StatusInternalServerError, gin.H{
-			"status":  500,
-			"error":   "Failed to create career post",
-			"message": err.Error(),
-		})
+	var request dto.CreateCareerRequest
+	if err := validation.BindJSON(g, &request); err != nil {
+		apperror.Write(g, err)
 		return
 	}
 
-	byteData, err := json.Marshal(&career)
+	career, err := db.Service.CreateCareer(g.Request.Context(), request)
 	if err != nil {
-		g.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		apperror.Write(g, err)
 		return
 	}
-
-	// Send POST request to webhook server
-	_, err = http.Post("http://localhost:9000/webhook", "application/json", bytes.NewBuffer(byteData))
-	if err != nil {
-		g.JSON(http.StatusInternalServerError, gin.H{"error": " Error sending webhook notification"})
-		return
-	}
-
+	g.Header("ETag", etag(career.Version, career))
 	g.JSON(http.StatusOK, gin.H{
 		"status":  200,
 		"message": "Career post created successfully",
@@ -297,23 +132,21 @@ func (db DbConnection) CreateCareer(g *gin.Context) {
 }
 
 func (db DbConnection) GetCareerByJobId(g *gin.Context) {
-	err := authentication.CommonAuth(g)
-	if err != nil {
-		g.JSON(http.StatusUnauthorized, gin.H{"error": "either admin or user can access this endpoint"})
+	if err := authentication.CommonAuth(g); err != nil {
+		apperror.Write(g, err)
 		return
 	}
-	jobId, err := strconv.Atoi(g.Param("id"))
+	jobId, err := pathID(g)
 	if err != nil {
-		g.JSON(http.StatusBadRequest, gin.H{"error": "error on converting string to integer"})
+		apperror.Write(g, err)
 		return
 	}
-	career, err := db.Query.GetCareerByJobId(context.Background(), int64(jobId))
+	career, err := db.Service.Career(g.Request.Context(), jobId)
 	if err != nil {
-		g.JSON(http.StatusBadRequest, gin.H{
-			"status":  400,
-			"error":   "Failed to get a career details",
-			"message": err.Error(),
-		})
+		apperror.Write(g, err)
+		return
+	}
+	if notModified(g, etag(career.Version, career)) {
 		return
 	}
 	g.JSON(http.StatusOK, gin.H{
@@ -324,18 +157,21 @@ func (db DbConnection) GetCareerByJobId(g *gin.Context) {
 }
 
 func (db DbConnection) GetAllCareers(g *gin.Context) {
-	err := authentication.CommonAuth(g)
-	if err != nil {
-		g.JSON(http.StatusUnauthorized, gin.H{"error": "either admin or user can access this endpoint"})
+	if err := authentication.CommonAuth(g); err != nil {
+		apperror.Write(g, err)
+		return
+	}
+	var query dto.CareersQuery
+	if err := validation.BindQuery(g, &query); err != nil {
+		apperror.Write(g, err)
 		return
 	}
-	careers, err := db.Query.GetAllCareerDetails(context.Background())
+	careers, err := db.Service.Careers(g.Request.Context(), query)
 	if err != nil {
-		g.JSON(http.StatusBadRequest, gin.H{
-			"status":  400,
-			"error":   "Failed to get all career details",
-			"message": err.Error(),
-		})
+		apperror.Write(g, err)
+		return
+	}
+	if notModified(g, listETag(careers)) {
 		return
 	}
 	g.JSON(http.StatusOK, gin.H{
@@ -346,80 +182,100 @@ func (db DbConnection) GetAllCareers(g *gin.Context) {
 }
 
 func (db DbConnection) UpdateCareerById(g *gin.Context) {
-	err := authentication.AdminAuth(g)
-	if err != nil {
-		g.JSON(http.StatusUnauthorized, gin.H{"error": "only admin can access this endpoint"})
+	if err := authentication.RecruiterAuth(g); err != nil {
+		apperror.Write(g, err)
 		return
 	}
 
-	var career database.UpdateCareerByJobIdParams
-	if err := g.BindJSON(&career); err != nil {
-		g.JSON(http.StatusBadRequest, gin.H{
-			"status":  400,
-			"error":   err.Error(),
-			"message": "Failed to bind JSON data",
-		})
+	var request dto.UpdateCareerRequest
+	if err := validation.BindJSON(g, &request); err != nil {
+		apperror.Write(g, err)
 		return
 	}
 
-	jobId, err := strconv.Atoi(g.Param("id"))
+	jobId, err := pathID(g)
 	if err != nil {
-		g.JSON(http.StatusBadRequest, gin.H{"error": "error on converting string to integer"})
+		apperror.Write(g, err)
 		return
 	}
 
-	career.Jobid = int64(jobId)
+	ifMatch, err := db.ifMatch(g)
+	if err != nil {
+		apperror.Write(g, err)
+		return
+	}
 
-	existingCareerDetail, _ := db.Query.GetCareerByJobId(context.Background(), career.Jobid)
+	careerDetail, err := db.Service.UpdateCareer(g.Request.Context(), jobId, ifMatch, request)
+	if err != nil {
+		apperror.Write(g, err)
+		return
+	}
+	g.Header("ETag", etag(careerDetail.Version, careerDetail))
+	g.JSON(http.StatusOK, gin.H{
+		"status":  200,
+		"message": " career details were retrieved successfully",
+		"data":    careerDetail,
+	})
+}
 
-	if career.Company == "" {
-		career.Company = existingCareerDetail.Company
+func (db DbConnection) PatchCareerById(g *gin.Context) {
+	if err := authentication.RecruiterAuth(g); err != nil {
+		apperror.Write(g, err)
+		return
 	}
-	if career.Position == "" {
-		career.Position = existingCareerDetail.Position
+
+	jobId, err := pathID(g)
+	if err != nil {
+		apperror.Write(g, err)
+		return
 	}
-	if career.Jobtype == "" {
-		career.Jobtype = existingCareerDetail.Jobtype
+
+	request, err := readPatch(g)
+	if err != nil {
+		apperror.Write(g, err)
+		return
 	}
-	if career.Description == "" {
-		career.Description = existingCareerDetail.Description
+
+	ifMatch, err := db.ifMatch(g)
+	if err != nil {
+		apperror.Write(g, err)
+		return
 	}
-	careerDetail, err := db.Query.UpdateCareerByJobId(context.Background(), career)
+
+	careerDetail, err := db.Service.PatchCareer(g.Request.Context(), jobId, ifMatch, request)
 	if err != nil {
-		g.JSON(http.StatusBadRequest, gin.H{
-			"status":  400,
-			"error":   "Failed to Update career details",
-			"message": err.Error(),
-		})
+		apperror.Write(g, err)
 		return
 	}
+	g.Header("ETag", etag(careerDetail.Version, careerDetail))
 	g.JSON(http.StatusOK, gin.H{
 		"status":  200,
-		"message": " career details were retrieved successfully",
+		"message": "career details were patched successfully",
 		"data":    careerDetail,
 	})
 }
 
 func (db DbConnection) DeleteCareerById(g *gin.Context) {
-	err := authentication.AdminAuth(g)
+	if err := authentication.RecruiterAuth(g); err != nil {
+		apperror.Write(g, err)
+		return
+	}
+
+	jobId, err := pathID(g)
 	if err != nil {
-		g.JSON(http.StatusUnauthorized, gin.H{"error": "only admin can access this endpoint"})
+		apperror.Write(g, err)
 		return
 	}
 
-	jobId, err := strconv.Atoi(g.Param("id"))
+	ifMatch, err := db.ifMatch(g)
 	if err != nil {
-		g.JSON(http.StatusBadRequest, gin.H{"error": "error on converting string to integer"})
+		apperror.Write(g, err)
 		return
 	}
 
-	career, err := db.Query.DeleteCareerByJobId(context.Background(), int64(jobId))
+	career, err := db.Service.DeleteCareer(g.Request.Context(), jobId, ifMatch)
 	if err != nil {
-		g.JSON(http.StatusBadRequest, gin.H{
-			"status":  400,
-			"error":   "Failed to delete a career detail",
-			"message": err.Error(),
-		})
+		apperror.Write(g, err)
 		return
 	}
 	g.JSON(http.StatusOK, gin.H{
@@ -430,54 +286,23 @@ func (db DbConnection) DeleteCareerById(g *gin.Context) {
 }
 
 func (db DbConnection) CreateProfile(g *gin.Context) {
-	err := authentication.UserAuth(g)
-	if err != nil {
-		g.JSON(http.StatusUnauthorized, gin.H{"error": "Only users can access this endpoint"})
+	if err := authentication.UserAuth(g); err != nil {
+		apperror.Write(g, err)
 		return
 	}
 
-	var profile database.CreateProfileParams
-	if err := g.BindJSON(&profile); err != nil {
-		g.JSON(http.StatusBadRequest, gin.H{
-			"status":  400,
-			"error":   err.Error(),
-			"message": "Failed to bind JSON data",
-		})
+	var request dto.CreateProfileRequest
+	if err := validation.BindJSON(g, &request); err != nil {
+		apperror.Write(g, err)
 		return
 	}
-	// Validate required fields
-	missingFields := []string{}
-	if profile.Fullname == "" {
-		missingFields = append(missingFields, "Fullname")
-	}
-	if profile.Address == "" {
-		missingFields = append(missingFields, "Address")
-	}
-	if profile.Gender == "" {
-		missingFields = append(missingFields, "Gender")
-	}
-	if profile.Age == 0 {
-		missingFields = append(missingFields, "Age")
-	}
 
-	if len(missingFields) > 0 {
-		g.JSON(http.StatusBadRequest, gin.H{
-			"status":        400,
-			"error":         "Missing fields",
-			"missingFields": missingFields,
-		})
-		return
-	}
-
-	_, err = db.Query.CreateProfile(context.Background(), profile)
+	profile, err := db.Service.CreateProfile(g.Request.Context(), g.GetString("email"), request)
 	if err != nil {
-		g.JSON(http.StatusInternalServerError, gin.H{
-			"status":  500,
-			"error":   "Failed to create profile",
-			"message": err.Error(),
-		})
+		apperror.Write(g, err)
 		return
 	}
+	g.Header("ETag", etag(profile.Version, profile))
 
 	// Respond with success message
 	g.JSON(http.StatusOK, gin.H{
@@ -488,23 +313,21 @@ func (db DbConnection) CreateProfile(g *gin.Context) {
 }
 
 func (db DbConnection) GetProfileById(g *gin.Context) {
-	err := authentication.CommonAuth(g)
-	if err != nil {
-		g.JSON(http.StatusUnauthorized, gin.H{"error": "either admin or user can access this endpoint"})
+	if err := authentication.CommonAuth(g); err != nil {
+		apperror.Write(g, err)
 		return
 	}
-	profileid, err := strconv.Atoi(g.Param("id"))
+	profileid, err := pathID(g)
 	if err != nil {
-		g.JSON(http.StatusBadRequest, gin.H{"error": "error on converting string to integer"})
+		apperror.Write(g, err)
 		return
 	}
-	profile, err := db.Query.GetProfileByuserId(context.Background(), int64(profileid))
+	profile, err := db.Service.Profile(g.Request.Context(), profileid)
 	if err != nil {
-		g.JSON(http.StatusBadRequest, gin.H{
-			"status":  400,
-			"error":   "Failed to get a Profile details",
-			"message": err.Error(),
-		})
+		apperror.Write(g, err)
+		return
+	}
+	if notModified(g, etag(profile.Version, profile)) {
 		return
 	}
 
@@ -516,18 +339,16 @@ func (db DbConnection) GetProfileById(g *gin.Context) {
 }
 
 func (db DbConnection) GetAllProfiles(g *gin.Context) {
-	err := authentication.CommonAuth(g)
-	if err != nil {
-		g.JSON(http.StatusUnauthorized, gin.H{"error": "either admin or user can access this endpoint"})
+	if err := authentication.CommonAuth(g); err != nil {
+		apperror.Write(g, err)
 		return
 	}
-	profile, err := db.Query.GetAllProfileDetails(context.Background())
+	profile, err := db.Service.Profiles(g.Request.Context())
 	if err != nil {
-		g.JSON(http.StatusBadRequest, gin.H{
-			"status":  400,
-			"error":   "Failed to get all profile details",
-			"message": err.Error(),
-		})
+		apperror.Write(g, err)
+		return
+	}
+	if notModified(g, listETag(profile)) {
 		return
 	}
 	g.JSON(http.StatusOK, gin.H{
@@ -538,83 +359,120 @@ func (db DbConnection) GetAllProfiles(g *gin.Context) {
 }
 
 func (db DbConnection) DeleteProfileById(g *gin.Context) {
-	err := authentication.UserAuth(g)
-	if err != nil {
-		g.JSON(http.StatusUnauthorized, gin.H{"error": "only user can access this endpoint"})
+	if err := authentication.UserAuth(g); err != nil {
+		apperror.Write(g, err)
 		return
 	}
 
-	jobId, err := strconv.Atoi(g.Param("id"))
+	userid, err := pathID(g)
+	if err != nil {
+		apperror.Write(g, err)
+		return
+	}
+	ifMatch, err := db.ifMatch(g)
 	if err != nil {
-		g.JSON(http.StatusBadRequest, gin.H{"error": "error on converting string to integer"})
+		apperror.Write(g, err)
 		return
 	}
-	career, err := db.Query.DeleteProfileByUserId(context.Background(), int64(jobId))
+
+	profile, err := db.Service.DeleteProfile(g.Request.Context(), userid, ifMatch)
 	if err != nil {
-		g.JSON(http.StatusBadRequest, gin.H{
-			"status":  400,
-			"error":   "Failed to delete a profile detail",
-			"message": err.Error(),
-		})
+		apperror.Write(g, err)
 		return
 	}
 	g.JSON(http.StatusOK, gin.H{
 		"status":       200,
 		"message":      "profile detail deleted successfully",
-		"deleted data": career,
+		"deleted data": profile,
 	})
 }
 
 func (db DbConnection) UpdateProfileById(g *gin.Context) {
-	err := authentication.UserAuth(g)
-	if err != nil {
-		g.JSON(http.StatusUnauthorized, gin.H{"error": "only user can access this endpoint"})
+	if err := authentication.UserAuth(g); err != nil {
+		apperror.Write(g, err)
 		return
 	}
 
-	var profile database.UpdateProfileByuserIdParams
-	if err := g.BindJSON(&profile); err != nil {
-		g.JSON(http.StatusBadRequest, gin.H{
-			"status":  400,
-			"error":   err.Error(),
-			"message": "Failed to bind JSON data",
-		})
+	var request dto.UpdateProfileRequest
+	if err := validation.BindJSON(g, &request); err != nil {
+		apperror.Write(g, err)
 		return
 	}
-	userid, err := strconv.Atoi(g.Param("id"))
+	userid, err := pathID(g)
 	if err != nil {
-		g.JSON(http.StatusBadRequest, gin.H{"error": "error on converting string to integer"})
+		apperror.Write(g, err)
 		return
 	}
-	profile.Userid = int64(userid)
-	existingProfileDetail, _ := db.Query.GetProfileByuserId(context.Background(), profile.Userid)
-	if profile.Fullname == "" {
-		profile.Fullname = existingProfileDetail.Fullname
+	ifMatch, err := db.ifMatch(g)
+	if err != nil {
+		apperror.Write(g, err)
+		return
 	}
-	if profile.Address == "" {
-		profile.Address = existingProfileDetail.Address
 
+	profileDetail, err := db.Service.UpdateProfile(g.Request.Context(), userid, ifMatch, request)
+	if err != nil {
+		apperror.Write(g, err)
+		return
 	}
-	if profile.Gender == "" {
-		profile.Gender = existingProfileDetail.Gender
+	g.Header("ETag", etag(profileDetail.Version, profileDetail))
+	g.JSON(http.StatusOK, gin.H{
+		"status":  200,
+		"message": "profile details were retrieved successfully",
+		"data":    profileDetail,
+	})
+}
 
+func (db DbConnection) PatchProfileById(g *gin.Context) {
+	if err := authentication.UserAuth(g); err != nil {
+		apperror.Write(g, err)
+		return
 	}
-	if profile.Age == 0 {
-		profile.Age = existingProfileDetail.Age
 
+	userid, err := pathID(g)
+	if err != nil {
+		apperror.Write(g, err)
+		return
 	}
-	profileDetail, err := db.Query.UpdateProfileByuserId(context.Background(), profile)
+
+	request, err := readPatch(g)
 	if err != nil {
-		g.JSON(http.StatusBadRequest, gin.H{
-			"status":  400,
-			"error":   "Failed to Update profile details",
-			"message": err.Error(),
-		})
+		apperror.Write(g, err)
 		return
 	}
+
+	ifMatch, err := db.ifMatch(g)
+	if err != nil {
+		apperror.Write(g, err)
+		return
+	}
+
+	profileDetail, err := db.Service.PatchProfile(g.Request.Context(), userid, ifMatch, request)
+	if err != nil {
+		apperror.Write(g, err)
+		return
+	}
+	g.Header("ETag", etag(profileDetail.Version, profileDetail))
 	g.JSON(http.StatusOK, gin.H{
 		"status":  200,
-		"message": "profile details were retrieved successfully",
+		"message": "profile details were patched successfully",
 		"data":    profileDetail,
 	})
 }
+
+// readPatch parses the body of a PATCH request by its Content-Type
+func readPatch(g *gin.Context) (patch.Patch, error) {
+	body, err := io.ReadAll(g.Request.Body)
+	if err != nil {
+		return patch.Patch{}, apperror.Validation("reading request body: " + err.Error())
+	}
+	return patch.Parse(g.GetHeader("Content-Type"), body)
+}
+
+// pathID parses the :id route parameter
+func pathID(g *gin.Context) (int64, error) {
+	id, err := strconv.ParseInt(g.Param("id"), 10, 64)
+	if err != nil {
+		return 0, apperror.Validation("id must be an integer", apperror.FieldError{Field: "id", Message: "must be an integer"})
+	}
+	return id, nil
+}
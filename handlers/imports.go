@@ -0,0 +1,96 @@
+package handlers
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+
+	"jobApps/apperror"
+	"jobApps/authentication"
+	"jobApps/careerimport"
+	"jobApps/dto"
+	"jobApps/validation"
+
+	"github.com/gin-gonic/gin"
+)
+
+// maxImportSize is the largest file an import reads
+const maxImportSize = 32 << 20
+
+func (db DbConnection) ImportCareers(g *gin.Context) {
+	if err := authentication.RecruiterAuth(g); err != nil {
+		apperror.Write(g, err)
+		return
+	}
+
+	var query dto.ImportCareersQuery
+	if err := validation.BindQuery(g, &query); err != nil {
+		apperror.Write(g, err)
+		return
+	}
+	query.Mapping = g.QueryMap("map")
+	if query.Format == "" {
+		format, ok := careerimport.FormatOf(g.GetHeader("Content-Type"))
+		if !ok {
+			apperror.Write(g, apperror.UnsupportedMediaType("imports accept text/csv or application/jsonl"))
+			return
+		}
+		query.Format = format
+	}
+
+	file, err := io.ReadAll(http.MaxBytesReader(g.Writer, g.Request.Body, maxImportSize))
+	if err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			apperror.Write(g, apperror.Validation(fmt.Sprintf("the file is larger than %d MiB", maxImportSize>>20)))
+			return
+		}
+		apperror.Write(g, apperror.Validation("reading request body: "+err.Error()))
+		return
+	}
+
+	job, async, err := db.Service.ImportCareers(g.Request.Context(), query, bytes.NewReader(file))
+	if err != nil {
+		apperror.Write(g, err)
+		return
+	}
+	if async {
+		g.Header("Location", fmt.Sprintf("/api/v1/careers/import/%d", job.ID))
+		g.JSON(http.StatusAccepted, gin.H{
+			"status":  202,
+			"message": "career import started",
+			"data":    job,
+		})
+		return
+	}
+	g.JSON(http.StatusOK, gin.H{
+		"status":  200,
+		"message": "careers imported",
+		"data":    job,
+	})
+}
+
+func (db DbConnection) GetCareerImport(g *gin.Context) {
+	if err := authentication.RecruiterAuth(g); err != nil {
+		apperror.Write(g, err)
+		return
+	}
+
+	id, err := pathID(g)
+	if err != nil {
+		apperror.Write(g, err)
+		return
+	}
+	job, err := db.Service.CareerImport(g.Request.Context(), id)
+	if err != nil {
+		apperror.Write(g, err)
+		return
+	}
+	g.JSON(http.StatusOK, gin.H{
+		"status":  200,
+		"message": "career import retrieved successfully",
+		"data":    job,
+	})
+}
@@ -0,0 +1,20 @@
+package handlers
+
+import (
+	"jobApps/apperror"
+	"jobApps/authentication"
+	"jobApps/metrics"
+
+	"github.com/gin-gonic/gin"
+)
+
+var metricsHandler = metrics.Handler()
+
+// GetMetrics serves the expvar counters and runtime memstats to admins
+func (db DbConnection) GetMetrics(g *gin.Context) {
+	if err := authentication.AdminAuth(g); err != nil {
+		apperror.Write(g, err)
+		return
+	}
+	metricsHandler(g)
+}
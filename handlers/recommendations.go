@@ -0,0 +1,65 @@
+package handlers
+
+import (
+	"net/http"
+
+	"jobApps/apperror"
+	"jobApps/authentication"
+	"jobApps/dto"
+	"jobApps/validation"
+
+	"github.com/gin-gonic/gin"
+)
+
+func (db DbConnection) GetRecommendedCareers(g *gin.Context) {
+	if err := authentication.UserAuth(g); err != nil {
+		apperror.Write(g, err)
+		return
+	}
+
+	var query dto.RecommendationsQuery
+	if err := validation.BindQuery(g, &query); err != nil {
+		apperror.Write(g, err)
+		return
+	}
+
+	recommendations, err := db.Service.RecommendedCareers(g.Request.Context(), query)
+	if err != nil {
+		apperror.Write(g, err)
+		return
+	}
+	g.JSON(http.StatusOK, gin.H{
+		"status":  200,
+		"message": "recommended careers retrieved successfully",
+		"data":    recommendations,
+	})
+}
+
+func (db DbConnection) GetRecommendedCandidates(g *gin.Context) {
+	if err := authentication.AdminAuth(g); err != nil {
+		apperror.Write(g, err)
+		return
+	}
+
+	jobId, err := pathID(g)
+	if err != nil {
+		apperror.Write(g, err)
+		return
+	}
+	var query dto.RecommendationsQuery
+	if err := validation.BindQuery(g, &query); err != nil {
+		apperror.Write(g, err)
+		return
+	}
+
+	recommendations, err := db.Service.RecommendedCandidates(g.Request.Context(), jobId, query)
+	if err != nil {
+		apperror.Write(g, err)
+		return
+	}
+	g.JSON(http.StatusOK, gin.H{
+		"status":  200,
+		"message": "recommended candidates retrieved successfully",
+		"data":    recommendations,
+	})
+}
@@ -0,0 +1,148 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"jobApps/apperror"
+	"jobApps/authentication"
+	"jobApps/dto"
+	"jobApps/validation"
+
+	"github.com/gin-gonic/gin"
+)
+
+func pathRevision(g *gin.Context) (int32, error) {
+	revision, err := strconv.ParseInt(g.Param("rev"), 10, 32)
+	if err != nil || revision < 1 {
+		return 0, apperror.Validation("rev must be a positive integer", apperror.FieldError{Field: "rev", Message: "must be a positive integer"})
+	}
+	return int32(revision), nil
+}
+
+func (db DbConnection) GetCareerRevisions(g *gin.Context) {
+	if err := authentication.RecruiterAuth(g); err != nil {
+		apperror.Write(g, err)
+		return
+	}
+
+	jobId, err := pathID(g)
+	if err != nil {
+		apperror.Write(g, err)
+		return
+	}
+	var query dto.RevisionsQuery
+	if err := validation.BindQuery(g, &query); err != nil {
+		apperror.Write(g, err)
+		return
+	}
+
+	revisions, err := db.Service.CareerRevisions(g.Request.Context(), jobId, query.At)
+	if err != nil {
+		apperror.Write(g, err)
+		return
+	}
+	g.JSON(http.StatusOK, gin.H{
+		"status":  200,
+		"message": "career revisions retrieved successfully",
+		"data":    revisions,
+	})
+}
+
+func (db DbConnection) GetCareerRevision(g *gin.Context) {
+	if err := authentication.RecruiterAuth(g); err != nil {
+		apperror.Write(g, err)
+		return
+	}
+
+	jobId, err := pathID(g)
+	if err != nil {
+		apperror.Write(g, err)
+		return
+	}
+	rev, err := pathRevision(g)
+	if err != nil {
+		apperror.Write(g, err)
+		return
+	}
+
+	revision, err := db.Service.CareerRevision(g.Request.Context(), jobId, rev)
+	if err != nil {
+		apperror.Write(g, err)
+		return
+	}
+	g.JSON(http.StatusOK, gin.H{
+		"status":  200,
+		"message": "career revision retrieved successfully",
+		"data":    revision,
+	})
+}
+
+func (db DbConnection) GetCareerRevisionDiff(g *gin.Context) {
+	if err := authentication.RecruiterAuth(g); err != nil {
+		apperror.Write(g, err)
+		return
+	}
+
+	jobId, err := pathID(g)
+	if err != nil {
+		apperror.Write(g, err)
+		return
+	}
+	rev, err := pathRevision(g)
+	if err != nil {
+		apperror.Write(g, err)
+		return
+	}
+	var query dto.RevisionDiffQuery
+	if err := validation.BindQuery(g, &query); err != nil {
+		apperror.Write(g, err)
+		return
+	}
+
+	diff, err := db.Service.CareerRevisionDiff(g.Request.Context(), jobId, query.From, rev)
+	if err != nil {
+		apperror.Write(g, err)
+		return
+	}
+	g.JSON(http.StatusOK, gin.H{
+		"status":  200,
+		"message": "career revision diff retrieved successfully",
+		"data":    diff,
+	})
+}
+
+func (db DbConnection) RevertCareer(g *gin.Context) {
+	if err := authentication.AdminAuth(g); err != nil {
+		apperror.Write(g, err)
+		return
+	}
+
+	jobId, err := pathID(g)
+	if err != nil {
+		apperror.Write(g, err)
+		return
+	}
+	rev, err := pathRevision(g)
+	if err != nil {
+		apperror.Write(g, err)
+		return
+	}
+	ifMatch, err := db.ifMatch(g)
+	if err != nil {
+		apperror.Write(g, err)
+		return
+	}
+
+	career, err := db.Service.RevertCareer(g.Request.Context(), jobId, ifMatch, rev)
+	if err != nil {
+		apperror.Write(g, err)
+		return
+	}
+	g.Header("ETag", etag(career.Version, career))
+	g.JSON(http.StatusOK, gin.H{
+		"status":  200,
+		"message": "career reverted successfully",
+		"data":    career,
+	})
+}
@@ -0,0 +1,129 @@
+package handlers
+
+import (
+	"net/http"
+
+	"jobApps/apperror"
+	"jobApps/authentication"
+	"jobApps/dto"
+	"jobApps/validation"
+
+	"github.com/gin-gonic/gin"
+)
+
+func (db DbConnection) SaveCareer(g *gin.Context) {
+	if err := authentication.UserAuth(g); err != nil {
+		apperror.Write(g, err)
+		return
+	}
+
+	jobId, err := pathID(g)
+	if err != nil {
+		apperror.Write(g, err)
+		return
+	}
+	var request dto.SaveCareerRequest
+	if err := validation.BindJSON(g, &request); err != nil {
+		apperror.Write(g, err)
+		return
+	}
+
+	saved, err := db.Service.SaveCareer(g.Request.Context(), jobId, request)
+	if err != nil {
+		apperror.Write(g, err)
+		return
+	}
+	g.JSON(http.StatusOK, gin.H{
+		"status":  200,
+		"message": "career saved successfully",
+		"data":    saved,
+	})
+}
+
+func (db DbConnection) UnsaveCareer(g *gin.Context) {
+	if err := authentication.UserAuth(g); err != nil {
+		apperror.Write(g, err)
+		return
+	}
+
+	jobId, err := pathID(g)
+	if err != nil {
+		apperror.Write(g, err)
+		return
+	}
+
+	if err := db.Service.UnsaveCareer(g.Request.Context(), jobId); err != nil {
+		apperror.Write(g, err)
+		return
+	}
+	g.JSON(http.StatusOK, gin.H{
+		"status":  200,
+		"message": "career unsaved successfully",
+	})
+}
+
+func (db DbConnection) GetSavedCareers(g *gin.Context) {
+	if err := authentication.UserAuth(g); err != nil {
+		apperror.Write(g, err)
+		return
+	}
+
+	var query dto.SavedCareersQuery
+	if err := validation.BindQuery(g, &query); err != nil {
+		apperror.Write(g, err)
+		return
+	}
+
+	saved, err := db.Service.SavedCareers(g.Request.Context(), query)
+	if err != nil {
+		apperror.Write(g, err)
+		return
+	}
+	g.JSON(http.StatusOK, gin.H{
+		"status":  200,
+		"message": "saved careers retrieved successfully",
+		"data":    saved,
+	})
+}
+
+func (db DbConnection) GetSavedCareerLists(g *gin.Context) {
+	if err := authentication.UserAuth(g); err != nil {
+		apperror.Write(g, err)
+		return
+	}
+
+	lists, err := db.Service.SavedCareerLists(g.Request.Context())
+	if err != nil {
+		apperror.Write(g, err)
+		return
+	}
+	g.JSON(http.StatusOK, gin.H{
+		"status":  200,
+		"message": "saved career lists retrieved successfully",
+		"data":    lists,
+	})
+}
+
+func (db DbConnection) GetCareerSaves(g *gin.Context) {
+	if err := authentication.AdminAuth(g); err != nil {
+		apperror.Write(g, err)
+		return
+	}
+
+	jobId, err := pathID(g)
+	if err != nil {
+		apperror.Write(g, err)
+		return
+	}
+
+	saves, err := db.Service.CareerSaves(g.Request.Context(), jobId)
+	if err != nil {
+		apperror.Write(g, err)
+		return
+	}
+	g.JSON(http.StatusOK, gin.H{
+		"status":  200,
+		"message": "career saves retrieved successfully",
+		"data":    saves,
+	})
+}
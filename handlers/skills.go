@@ -0,0 +1,213 @@
+package handlers
+
+import (
+	"net/http"
+
+	"jobApps/apperror"
+	"jobApps/authentication"
+	"jobApps/dto"
+	"jobApps/validation"
+
+	"github.com/gin-gonic/gin"
+)
+
+func (db DbConnection) CreateSkill(g *gin.Context) {
+	if err := authentication.AdminAuth(g); err != nil {
+		apperror.Write(g, err)
+		return
+	}
+
+	var request dto.CreateSkillRequest
+	if err := validation.BindJSON(g, &request); err != nil {
+		apperror.Write(g, err)
+		return
+	}
+
+	skill, err := db.Service.CreateSkill(g.Request.Context(), request)
+	if err != nil {
+		apperror.Write(g, err)
+		return
+	}
+	g.JSON(http.StatusOK, gin.H{
+		"status":  200,
+		"message": "skill created successfully",
+		"data":    skill,
+	})
+}
+
+func (db DbConnection) GetSkills(g *gin.Context) {
+	if err := authentication.CommonAuth(g); err != nil {
+		apperror.Write(g, err)
+		return
+	}
+
+	var query dto.SkillsQuery
+	if err := validation.BindQuery(g, &query); err != nil {
+		apperror.Write(g, err)
+		return
+	}
+
+	skills, err := db.Service.Skills(g.Request.Context(), query)
+	if err != nil {
+		apperror.Write(g, err)
+		return
+	}
+	g.JSON(http.StatusOK, gin.H{
+		"status":  200,
+		"message": "skills retrieved successfully",
+		"data":    skills,
+	})
+}
+
+func (db DbConnection) AddSkillAlias(g *gin.Context) {
+	if err := authentication.AdminAuth(g); err != nil {
+		apperror.Write(g, err)
+		return
+	}
+
+	skillID, err := pathID(g)
+	if err != nil {
+		apperror.Write(g, err)
+		return
+	}
+	var request dto.AddSkillAliasRequest
+	if err := validation.BindJSON(g, &request); err != nil {
+		apperror.Write(g, err)
+		return
+	}
+
+	skill, err := db.Service.AddSkillAlias(g.Request.Context(), skillID, request)
+	if err != nil {
+		apperror.Write(g, err)
+		return
+	}
+	g.JSON(http.StatusOK, gin.H{
+		"status":  200,
+		"message": "skill alias added successfully",
+		"data":    skill,
+	})
+}
+
+func (db DbConnection) GetCareerSkills(g *gin.Context) {
+	if err := authentication.CommonAuth(g); err != nil {
+		apperror.Write(g, err)
+		return
+	}
+
+	jobId, err := pathID(g)
+	if err != nil {
+		apperror.Write(g, err)
+		return
+	}
+
+	skills, err := db.Service.CareerSkills(g.Request.Context(), jobId)
+	if err != nil {
+		apperror.Write(g, err)
+		return
+	}
+	if notModified(g, etag(skills.Version, skills)) {
+		return
+	}
+	g.JSON(http.StatusOK, gin.H{
+		"status":  200,
+		"message": "career skills retrieved successfully",
+		"data":    skills,
+	})
+}
+
+func (db DbConnection) SetCareerSkills(g *gin.Context) {
+	if err := authentication.RecruiterAuth(g); err != nil {
+		apperror.Write(g, err)
+		return
+	}
+
+	var request dto.SetCareerSkillsRequest
+	if err := validation.BindJSON(g, &request); err != nil {
+		apperror.Write(g, err)
+		return
+	}
+	jobId, err := pathID(g)
+	if err != nil {
+		apperror.Write(g, err)
+		return
+	}
+	ifMatch, err := db.ifMatch(g)
+	if err != nil {
+		apperror.Write(g, err)
+		return
+	}
+
+	skills, err := db.Service.SetCareerSkills(g.Request.Context(), jobId, ifMatch, request)
+	if err != nil {
+		apperror.Write(g, err)
+		return
+	}
+	g.Header("ETag", etag(skills.Version, skills))
+	g.JSON(http.StatusOK, gin.H{
+		"status":  200,
+		"message": "career skills updated successfully",
+		"data":    skills,
+	})
+}
+
+func (db DbConnection) GetProfileSkills(g *gin.Context) {
+	if err := authentication.CommonAuth(g); err != nil {
+		apperror.Write(g, err)
+		return
+	}
+
+	userid, err := pathID(g)
+	if err != nil {
+		apperror.Write(g, err)
+		return
+	}
+
+	skills, err := db.Service.ProfileSkills(g.Request.Context(), userid)
+	if err != nil {
+		apperror.Write(g, err)
+		return
+	}
+	if notModified(g, etag(skills.Version, skills)) {
+		return
+	}
+	g.JSON(http.StatusOK, gin.H{
+		"status":  200,
+		"message": "profile skills retrieved successfully",
+		"data":    skills,
+	})
+}
+
+func (db DbConnection) SetProfileSkills(g *gin.Context) {
+	if err := authentication.UserAuth(g); err != nil {
+		apperror.Write(g, err)
+		return
+	}
+
+	var request dto.SetProfileSkillsRequest
+	if err := validation.BindJSON(g, &request); err != nil {
+		apperror.Write(g, err)
+		return
+	}
+	userid, err := pathID(g)
+	if err != nil {
+		apperror.Write(g, err)
+		return
+	}
+	ifMatch, err := db.ifMatch(g)
+	if err != nil {
+		apperror.Write(g, err)
+		return
+	}
+
+	skills, err := db.Service.SetProfileSkills(g.Request.Context(), userid, ifMatch, request)
+	if err != nil {
+		apperror.Write(g, err)
+		return
+	}
+	g.Header("ETag", etag(skills.Version, skills))
+	g.JSON(http.StatusOK, gin.H{
+		"status":  200,
+		"message": "profile skills updated successfully",
+		"data":    skills,
+	})
+}
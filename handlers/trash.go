@@ -0,0 +1,126 @@
+package handlers
+
+import (
+	"net/http"
+
+	"jobApps/apperror"
+	"jobApps/authentication"
+
+	"github.com/gin-gonic/gin"
+)
+
+func (db DbConnection) GetTrash(g *gin.Context) {
+	if err := authentication.AdminAuth(g); err != nil {
+		apperror.Write(g, err)
+		return
+	}
+
+	trash, err := db.Service.Trash(g.Request.Context())
+	if err != nil {
+		apperror.Write(g, err)
+		return
+	}
+	g.JSON(http.StatusOK, gin.H{
+		"status":  200,
+		"message": "trash retrieved successfully",
+		"data":    trash,
+	})
+}
+
+func (db DbConnection) RestoreCareerById(g *gin.Context) {
+	if err := authentication.AdminAuth(g); err != nil {
+		apperror.Write(g, err)
+		return
+	}
+
+	jobId, err := pathID(g)
+	if err != nil {
+		apperror.Write(g, err)
+		return
+	}
+
+	career, err := db.Service.RestoreCareer(g.Request.Context(), jobId)
+	if err != nil {
+		apperror.Write(g, err)
+		return
+	}
+	g.Header("ETag", etag(career.Version, career))
+	g.JSON(http.StatusOK, gin.H{
+		"status":  200,
+		"message": "career restored successfully",
+		"data":    career,
+	})
+}
+
+func (db DbConnection) RestoreProfileById(g *gin.Context) {
+	if err := authentication.AdminAuth(g); err != nil {
+		apperror.Write(g, err)
+		return
+	}
+
+	userid, err := pathID(g)
+	if err != nil {
+		apperror.Write(g, err)
+		return
+	}
+
+	profile, err := db.Service.RestoreProfile(g.Request.Context(), userid)
+	if err != nil {
+		apperror.Write(g, err)
+		return
+	}
+	g.Header("ETag", etag(profile.Version, profile))
+	g.JSON(http.StatusOK, gin.H{
+		"status":  200,
+		"message": "profile restored successfully",
+		"data":    profile,
+	})
+}
+
+func (db DbConnection) DeleteUserById(g *gin.Context) {
+	if err := authentication.AdminAuth(g); err != nil {
+		apperror.Write(g, err)
+		return
+	}
+
+	userid, err := pathID(g)
+	if err != nil {
+		apperror.Write(g, err)
+		return
+	}
+
+	user, err := db.Service.DeleteUser(g.Request.Context(), userid)
+	if err != nil {
+		apperror.Write(g, err)
+		return
+	}
+	g.JSON(http.StatusOK, gin.H{
+		"status":       200,
+		"message":      "user deleted successfully",
+		"deleted data": user,
+	})
+}
+
+func (db DbConnection) RestoreUserById(g *gin.Context) {
+	if err := authentication.AdminAuth(g); err != nil {
+		apperror.Write(g, err)
+		return
+	}
+
+	userid, err := pathID(g)
+	if err != nil {
+		apperror.Write(g, err)
+		return
+	}
+
+	user, err := db.Service.RestoreUser(g.Request.Context(), userid)
+	if err != nil {
+		apperror.Write(g, err)
+		return
+	}
+	g.JSON(http.StatusOK, gin.H{
+		"status":  200,
+		"message": "user restored successfully",
+		"data":    user,
+	})
+}
@@ -1,7 +1,10 @@
 package helper
 
 import (
-	"fmt"
+	"log/slog"
+	"os"
+	"strconv"
+	"time"
 
 	"github.com/joho/godotenv"
 )
@@ -11,8 +14,51 @@ var SigningKey = []byte("secret")
 func Configure(filename string) error {
 	err := godotenv.Load(filename)
 	if err != nil {
-		fmt.Printf("error at loading %s", filename)
+		slog.Error("error at loading env file", slog.String("file", filename), slog.Any("error", err))
 		return err
 	}
 	return nil
 }
+
+// DurationEnv reads a duration such as "10s" from the environment; "0"
+// disables whatever it configures
+func DurationEnv(name string, fallback time.Duration) time.Duration {
+	value := os.Getenv(name)
+	if value == "" {
+		return fallback
+	}
+	parsed, err := time.ParseDuration(value)
+	if err != nil || parsed < 0 {
+		slog.Warn("ignoring invalid "+name, slog.String("value", value), slog.Any("error", err))
+		return fallback
+	}
+	return parsed
+}
+
+// BoolEnv reads a boolean such as "true" or "0" from the environment
+func BoolEnv(name string, fallback bool) bool {
+	value := os.Getenv(name)
+	if value == "" {
+		return fallback
+	}
+	parsed, err := strconv.ParseBool(value)
+	if err != nil {
+		slog.Warn("ignoring invalid "+name, slog.String("value", value), slog.Any("error", err))
+		return fallback
+	}
+	return parsed
+}
+
+// IntEnv reads a non-negative integer from the environment
+func IntEnv(name string, fallback int) int {
+	value := os.Getenv(name)
+	if value == "" {
+		return fallback
+	}
+	parsed, err := strconv.Atoi(value)
+	if err != nil || parsed < 0 {
+		slog.Warn("ignoring invalid "+name, slog.String("value", value), slog.Any("error", err))
+		return fallback
+	}
+	return parsed
+}
@@ -6,27 +6,187 @@ package database
 
 import (
 	"database/sql"
+	"encoding/json"
 	"time"
+
+	"github.com/jackc/pgtype"
+	"jobApps/geo"
 )
 
+type AuditEvent struct {
+	ID           int64           `json:"id"`
+	OccurredAt   time.Time       `json:"occurred_at"`
+	ActorEmail   string          `json:"actor_email"`
+	ActorRole    string          `json:"actor_role"`
+	Action       string          `json:"action"`
+	ResourceType string          `json:"resource_type"`
+	ResourceID   int64           `json:"resource_id"`
+	Changes      json.RawMessage `json:"changes"`
+	RequestID    string          `json:"request_id"`
+	IP           string          `json:"ip"`
+}
+
 type Career struct {
-	Jobid       int64     `json:"jobid"`
-	Company     string    `json:"company"`
-	Position    string    `json:"position"`
-	Jobtype     string    `json:"jobtype"`
+	Jobid              int64         `json:"jobid"`
+	Company            string        `json:"company"`
+	Position           string        `json:"position"`
+	Jobtype            string        `json:"jobtype"`
+	Description        string        `json:"description"`
+	Startdate          time.Time     `json:"startdate"`
+	Enddate            time.Time     `json:"enddate"`
+	Version            int32         `json:"version"`
+	DeletedAt          *time.Time    `json:"deleted_at"`
+	CompanyID          int64         `json:"company_id"`
+	SalaryMin          *int64        `json:"salary_min"`
+	SalaryMax          *int64        `json:"salary_max"`
+	SalaryCurrency     *string       `json:"salary_currency"`
+	PayPeriod          *string       `json:"pay_period"`
+	Equity             bool          `json:"equity"`
+	SalaryVisible      bool          `json:"salary_visible"`
+	Locations          geo.Locations `json:"locations"`
+	RemotePolicy       string        `json:"remote_policy"`
+	RemoteCountries    []string      `json:"remote_countries"`
+	Seniority          *string       `json:"seniority"`
+	ClosedAt           *time.Time    `json:"closed_at"`
+	PublishAt          time.Time     `json:"publish_at"`
+	PublishedAt        *time.Time    `json:"published_at"`
+	PublishedSeq       *int64        `json:"published_seq"`
+	DescriptionHTML    string        `json:"description_html"`
+	DescriptionExcerpt string        `json:"description_excerpt"`
+}
+
+type CareerExpiryReminder struct {
+	Jobid   int64     `json:"jobid"`
+	Userid  int64     `json:"userid"`
+	Enddate time.Time `json:"enddate"`
+	SentAt  time.Time `json:"sent_at"`
+}
+
+type CareerImport struct {
+	ID             int64           `json:"id"`
+	Format         string          `json:"format"`
+	Mode           string          `json:"mode"`
+	DryRun         bool            `json:"dry_run"`
+	Status         string          `json:"status"`
+	TotalRows      int32           `json:"total_rows"`
+	ProcessedRows  int32           `json:"processed_rows"`
+	CreatedRows    int32           `json:"created_rows"`
+	FailedRows     int32           `json:"failed_rows"`
+	Jobids         []int64         `json:"jobids"`
+	Errors         json.RawMessage `json:"errors"`
+	IgnoredColumns []string        `json:"ignored_columns"`
+	CreatedBy      string          `json:"created_by"`
+	CreatedAt      time.Time       `json:"created_at"`
+	FinishedAt     *time.Time      `json:"finished_at"`
+}
+
+type CareerRevision struct {
+	Jobid           int64         `json:"jobid"`
+	Revision        int32         `json:"revision"`
+	Company         string        `json:"company"`
+	Position        string        `json:"position"`
+	Jobtype         string        `json:"jobtype"`
+	Description     string        `json:"description"`
+	Startdate       time.Time     `json:"startdate"`
+	Enddate         time.Time     `json:"enddate"`
+	AuthorEmail     string        `json:"author_email"`
+	CreatedAt       time.Time     `json:"created_at"`
+	SalaryMin       *int64        `json:"salary_min"`
+	SalaryMax       *int64        `json:"salary_max"`
+	SalaryCurrency  *string       `json:"salary_currency"`
+	PayPeriod       *string       `json:"pay_period"`
+	Equity          bool          `json:"equity"`
+	SalaryVisible   bool          `json:"salary_visible"`
+	Locations       geo.Locations `json:"locations"`
+	RemotePolicy    string        `json:"remote_policy"`
+	RemoteCountries []string      `json:"remote_countries"`
+	Seniority       *string       `json:"seniority"`
+}
+
+type CareerSkill struct {
+	Jobid    int64  `json:"jobid"`
+	SkillID  int64  `json:"skill_id"`
+	Required bool   `json:"required"`
+	MinYears *int16 `json:"min_years"`
+}
+
+type Company struct {
+	ID          int64     `json:"id"`
+	Name        string    `json:"name"`
+	Slug        string    `json:"slug"`
+	Website     string    `json:"website"`
+	LogoURL     string    `json:"logo_url"`
 	Description string    `json:"description"`
-	Startdate   time.Time `json:"startdate"`
-	Enddate     time.Time `json:"enddate"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+type CompanyRecruiter struct {
+	CompanyID int64 `json:"company_id"`
+	Userid    int64 `json:"userid"`
+}
+
+type ExchangeRate struct {
+	Currency    string         `json:"currency"`
+	UnitsPerUsd pgtype.Numeric `json:"units_per_usd"`
+	UpdatedAt   time.Time      `json:"updated_at"`
 }
 
 type Profile struct {
+	Profileid        int64         `json:"profileid"`
+	Userid           int64         `json:"userid"`
+	Fullname         string        `json:"fullname"`
+	Age              int32         `json:"age"`
+	Gender           string        `json:"gender"`
+	Address          string        `json:"address"`
+	Phonenumber      string        `json:"phonenumber"`
+	Version          int32         `json:"version"`
+	DeletedAt        *time.Time    `json:"deleted_at"`
+	Locations        geo.Locations `json:"locations"`
+	RemotePreference string        `json:"remote_preference"`
+	ExpectedSalary   *int64        `json:"expected_salary"`
+	SalaryCurrency   *string       `json:"salary_currency"`
+	YearsExperience  *int16        `json:"years_experience"`
+}
+
+type ProfileSkill struct {
 	Profileid   int64  `json:"profileid"`
-	Userid      int64  `json:"userid"`
-	Fullname    string `json:"fullname"`
-	Age         int32  `json:"age"`
-	Gender      string `json:"gender"`
-	Address     string `json:"address"`
-	Phonenumber string `json:"phonenumber"`
+	SkillID     int64  `json:"skill_id"`
+	Proficiency string `json:"proficiency"`
+}
+
+type SavedCareer struct {
+	Userid   int64     `json:"userid"`
+	Jobid    int64     `json:"jobid"`
+	Note     string    `json:"note"`
+	ListName *string   `json:"list_name"`
+	SavedAt  time.Time `json:"saved_at"`
+}
+
+type SavedSearch struct {
+	ID               int64     `json:"id"`
+	Userid           int64     `json:"userid"`
+	Name             string    `json:"name"`
+	Keywords         string    `json:"keywords"`
+	Jobtype          string    `json:"jobtype"`
+	Near             string    `json:"near"`
+	RadiusKm         *float64  `json:"radius_km"`
+	SalaryMin        *int64    `json:"salary_min"`
+	Currency         *string   `json:"currency"`
+	Alerts           bool      `json:"alerts"`
+	UnsubscribeToken string    `json:"unsubscribe_token"`
+	LastPublishedSeq int64     `json:"last_published_seq"`
+	CreatedAt        time.Time `json:"created_at"`
+}
+
+type Skill struct {
+	ID        int64     `json:"id"`
+	Name      string    `json:"name"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type SkillAlias struct {
+	Alias   string `json:"alias"`
+	SkillID int64  `json:"skill_id"`
 }
 
 type User struct {
@@ -38,4 +198,5 @@ type User struct {
 	Role        string       `json:"role"`
 	Createdat   sql.NullTime `json:"createdat"`
 	Updatedat   sql.NullTime `json:"updatedat"`
+	DeletedAt   *time.Time   `json:"deleted_at"`
 }
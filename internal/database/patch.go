@@ -0,0 +1,142 @@
+package database
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/jackc/pgx/v4"
+)
+
+// Hand-written queries for partial updates. sqlc cannot generate an UPDATE
+// whose SET list depends on the request, so the statement is built here from
+// a fixed allow-list of columns; values are always bound as parameters.
+
+// Assignment sets one column in a partial update
+type Assignment struct {
+	Column string
+	Value  any
+}
+
+// PatchQuerier holds the partial updates
+type PatchQuerier interface {
+	// PatchCareer sets the given columns of a career and bumps its version
+	PatchCareer(ctx context.Context, jobid int64, set []Assignment) (Career, error)
+	// PatchProfileByUserId sets the given columns of a user's profile and bumps
+	// its version
+	PatchProfileByUserId(ctx context.Context, userid int64, set []Assignment) (Profile, error)
+}
+
+var _ PatchQuerier = (*Queries)(nil)
+
+var (
+	careerPatchColumns = map[string]bool{
+		"company": true, "position": true, "jobtype": true, "description": true, "startdate": true, "enddate": true, "company_id": true,
+		"salary_min": true, "salary_max": true, "salary_currency": true, "pay_period": true, "equity": true, "salary_visible": true,
+		"locations": true, "remote_policy": true, "remote_countries": true, "seniority": true, "closed_at": true,
+		"description_html": true, "description_excerpt": true,
+	}
+	profilePatchColumns = map[string]bool{
+		"fullname": true, "age": true, "gender": true, "address": true,
+		"locations": true, "remote_preference": true, "expected_salary": true, "salary_currency": true, "years_experience": true,
+	}
+)
+
+// careerColumns and profileColumns list the columns in the order the
+// generated code scans them
+const (
+	careerColumns  = "jobid, company, position, jobtype, description, startdate, enddate, version, deleted_at, company_id, salary_min, salary_max, salary_currency, pay_period, equity, salary_visible, locations, remote_policy, remote_countries, seniority, closed_at, publish_at, published_at, published_seq, description_html, description_excerpt"
+	profileColumns = "profileid, userid, fullname, age, gender, address, phonenumber, version, deleted_at, locations, remote_preference, expected_salary, salary_currency, years_experience"
+)
+
+func (q *Queries) PatchCareer(ctx context.Context, jobid int64, set []Assignment) (Career, error) {
+	query, args, err := updateSQL("career", careerPatchColumns, set, "jobid", jobid, careerColumns)
+	if err != nil {
+		return Career{}, err
+	}
+	var i Career
+	err = q.db.QueryRow(ctx, query, args...).Scan(
+		&i.Jobid,
+		&i.Company,
+		&i.Position,
+		&i.Jobtype,
+		&i.Description,
+		&i.Startdate,
+		&i.Enddate,
+		&i.Version,
+		&i.DeletedAt,
+		&i.CompanyID,
+		&i.SalaryMin,
+		&i.SalaryMax,
+		&i.SalaryCurrency,
+		&i.PayPeriod,
+		&i.Equity,
+		&i.SalaryVisible,
+		&i.Locations,
+		&i.RemotePolicy,
+		&i.RemoteCountries,
+		&i.Seniority,
+		&i.ClosedAt,
+		&i.PublishAt,
+		&i.PublishedAt,
+		&i.PublishedSeq,
+		&i.DescriptionHTML,
+		&i.DescriptionExcerpt,
+	)
+	return i, err
+}
+
+func (q *Queries) PatchProfileByUserId(ctx context.Context, userid int64, set []Assignment) (Profile, error) {
+	query, args, err := updateSQL("profile", profilePatchColumns, set, "userid", userid, profileColumns)
+	if err != nil {
+		return Profile{}, err
+	}
+	var i Profile
+	err = q.db.QueryRow(ctx, query, args...).Scan(
+		&i.Profileid,
+		&i.Userid,
+		&i.Fullname,
+		&i.Age,
+		&i.Gender,
+		&i.Address,
+		&i.Phonenumber,
+		&i.Version,
+		&i.DeletedAt,
+		&i.Locations,
+		&i.RemotePreference,
+		&i.ExpectedSalary,
+		&i.SalaryCurrency,
+		&i.YearsExperience,
+	)
+	return i, err
+}
+
+// updateSQL builds
+//
+//	UPDATE table SET "col" = $1, ..., version = version + 1
+//	WHERE key = $n AND deleted_at IS NULL RETURNING returning
+//
+// rejecting any column that is not in allowed. Rows in the trash are left alone.
+func updateSQL(table string, allowed map[string]bool, set []Assignment, key string, keyValue any, returning string) (string, []any, error) {
+	if len(set) == 0 {
+		return "", nil, fmt.Errorf("updating %s: no columns to set", table)
+	}
+
+	assignments := make([]string, 0, len(set)+1)
+	args := make([]any, 0, len(set)+1)
+	seen := make(map[string]bool, len(set))
+	for _, assignment := range set {
+		if !allowed[assignment.Column] || seen[assignment.Column] {
+			return "", nil, fmt.Errorf("updating %s: column %q cannot be set", table, assignment.Column)
+		}
+		seen[assignment.Column] = true
+		args = append(args, assignment.Value)
+		assignments = append(assignments, fmt.Sprintf("%s = $%d", pgx.Identifier{assignment.Column}.Sanitize(), len(args)))
+	}
+	assignments = append(assignments, "version = version + 1")
+	args = append(args, keyValue)
+
+	query := fmt.Sprintf("UPDATE %s SET %s WHERE %s = $%d AND deleted_at IS NULL RETURNING %s",
+		pgx.Identifier{table}.Sanitize(), strings.Join(assignments, ", "), pgx.Identifier{key}.Sanitize(), len(args), returning)
+	return query, args, nil
+}
@@ -0,0 +1,40 @@
+package database
+
+import (
+	"testing"
+)
+
+func TestUpdateSQL(t *testing.T) {
+	query, args, err := updateSQL("career", careerPatchColumns, []Assignment{
+		{Column: "company", Value: "Acme"},
+		{Column: "description", Value: ""},
+	}, "jobid", int64(7), careerColumns)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := `UPDATE "career" SET "company" = $1, "description" = $2, version = version + 1 WHERE "jobid" = $3 AND deleted_at IS NULL RETURNING ` + careerColumns
+	if query != want {
+		t.Errorf("query =\n%s\nwant\n%s", query, want)
+	}
+	if len(args) != 3 || args[0] != "Acme" || args[1] != "" || args[2] != int64(7) {
+		t.Errorf("args = %v", args)
+	}
+}
+
+func TestUpdateSQLRejectsUnsafeColumns(t *testing.T) {
+	tests := map[string][]Assignment{
+		"no columns":        nil,
+		"read-only column":  {{Column: "version", Value: 1}},
+		"unknown column":    {{Column: `company" = 'x'; --`, Value: 1}},
+		"column set twice":  {{Column: "company", Value: "a"}, {Column: "company", Value: "b"}},
+		"other table field": {{Column: "fullname", Value: "x"}},
+	}
+	for name, set := range tests {
+		t.Run(name, func(t *testing.T) {
+			if query, _, err := updateSQL("career", careerPatchColumns, set, "jobid", int64(1), careerColumns); err == nil {
+				t.Errorf("updateSQL built %q, want an error", query)
+			}
+		})
+	}
+}
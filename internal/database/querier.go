@@ -0,0 +1,136 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.26.0
+
+package database
+
+import (
+	"context"
+	"time"
+)
+
+type Querier interface {
+	AddCareerSkill(ctx context.Context, arg AddCareerSkillParams) error
+	AddCompanyRecruiter(ctx context.Context, arg AddCompanyRecruiterParams) error
+	AddProfileSkill(ctx context.Context, arg AddProfileSkillParams) error
+	AddSkillAlias(ctx context.Context, arg AddSkillAliasParams) error
+	AdvanceSavedSearch(ctx context.Context, arg AdvanceSavedSearchParams) error
+	// CloseExpiredCareers stamps closed_at on the careers whose enddate has
+	// passed
+	CloseExpiredCareers(ctx context.Context) ([]Career, error)
+	CountCareerSaves(ctx context.Context, jobid int64) (int64, error)
+	CreateAuditEvent(ctx context.Context, arg CreateAuditEventParams) error
+	// CreateCareer publishes the career right away unless publish_at is still
+	// to come. publish_at is compared with the clock rather than now(), the
+	// start of the transaction, as it defaults to the time of the request.
+	CreateCareer(ctx context.Context, arg CreateCareerParams) (Career, error)
+	CreateCareerImport(ctx context.Context, arg CreateCareerImportParams) (CareerImport, error)
+	CreateCareerRevision(ctx context.Context, arg CreateCareerRevisionParams) error
+	CreateCompany(ctx context.Context, arg CreateCompanyParams) (Company, error)
+	CreateProfile(ctx context.Context, arg CreateProfileParams) (Profile, error)
+	CreateSavedCareer(ctx context.Context, arg CreateSavedCareerParams) (SavedCareer, error)
+	CreateSavedSearch(ctx context.Context, arg CreateSavedSearchParams) (SavedSearch, error)
+	CreateSkill(ctx context.Context, name string) (Skill, error)
+	CreateUser(ctx context.Context, arg CreateUserParams) (User, error)
+	DeleteCareerByJobId(ctx context.Context, jobid int64) (Career, error)
+	DeleteCareerSkills(ctx context.Context, jobid int64) error
+	DeleteProfileByUserId(ctx context.Context, userid int64) (Profile, error)
+	DeleteProfileSkills(ctx context.Context, profileid int64) error
+	DeleteProfilesOfUser(ctx context.Context, arg DeleteProfilesOfUserParams) error
+	DeleteSavedCareer(ctx context.Context, arg DeleteSavedCareerParams) (SavedCareer, error)
+	DeleteSavedSearch(ctx context.Context, arg DeleteSavedSearchParams) (SavedSearch, error)
+	DeleteUserById(ctx context.Context, userid int64) (User, error)
+	DisableSavedSearchAlerts(ctx context.Context, id int64) (SavedSearch, error)
+	ExtendCareer(ctx context.Context, arg ExtendCareerParams) (Career, error)
+	// FindSkill resolves a skill by its name or one of its aliases, ignoring case
+	FindSkill(ctx context.Context, name string) (Skill, error)
+	FinishCareerImport(ctx context.Context, arg FinishCareerImportParams) (CareerImport, error)
+	GetAllCareerDetails(ctx context.Context) ([]Career, error)
+	GetAllProfileDetails(ctx context.Context) ([]Profile, error)
+	GetCareerByJobId(ctx context.Context, jobid int64) (Career, error)
+	GetCareerByJobIdForUpdate(ctx context.Context, jobid int64) (Career, error)
+	GetCareerImport(ctx context.Context, id int64) (CareerImport, error)
+	GetCareerRevision(ctx context.Context, arg GetCareerRevisionParams) (CareerRevision, error)
+	GetCompanyBySlug(ctx context.Context, slug string) (Company, error)
+	GetDeletedCareerByJobIdForUpdate(ctx context.Context, jobid int64) (Career, error)
+	GetDeletedUserByIdForUpdate(ctx context.Context, userid int64) (User, error)
+	GetLastPublishedSeq(ctx context.Context) (int64, error)
+	GetLatestDeletedProfileByUserIdForUpdate(ctx context.Context, userid int64) (Profile, error)
+	GetProfileByuserId(ctx context.Context, userid int64) (Profile, error)
+	GetProfileByuserIdForUpdate(ctx context.Context, userid int64) (Profile, error)
+	GetSavedCareerForUpdate(ctx context.Context, arg GetSavedCareerForUpdateParams) (SavedCareer, error)
+	GetSavedSearchByToken(ctx context.Context, unsubscribeToken string) (SavedSearch, error)
+	GetSavedSearchByTokenForUpdate(ctx context.Context, unsubscribeToken string) (SavedSearch, error)
+	GetSavedSearchForUpdate(ctx context.Context, arg GetSavedSearchForUpdateParams) (SavedSearch, error)
+	GetSkill(ctx context.Context, id int64) (Skill, error)
+	GetUserByEmail(ctx context.Context, email string) (User, error)
+	GetUserByIdForUpdate(ctx context.Context, userid int64) (User, error)
+	GetUserByPhoneNumber(ctx context.Context, phonenumber string) (User, error)
+	GetallusersEmail(ctx context.Context) ([]string, error)
+	HasExchangeRate(ctx context.Context, currency string) (bool, error)
+	IsCompanyRecruiter(ctx context.Context, arg IsCompanyRecruiterParams) (bool, error)
+	ListAdmins(ctx context.Context) ([]ListAdminsRow, error)
+	// ListAlertingSearches lists the searches with alerts on of the users who
+	// are not deleted, grouped by user
+	ListAlertingSearches(ctx context.Context) ([]ListAlertingSearchesRow, error)
+	ListAuditEvents(ctx context.Context, arg ListAuditEventsParams) ([]AuditEvent, error)
+	ListCareerRevisions(ctx context.Context, arg ListCareerRevisionsParams) ([]CareerRevision, error)
+	ListCareerSkills(ctx context.Context, jobid int64) ([]ListCareerSkillsRow, error)
+	// ListCareers filters by salary, comparing yearly amounts in the currency
+	// given. Careers without a salary, or whose salary is hidden, never match a
+	// salary filter. Given a point, only careers with a location within
+	// radius_km of it are listed. Given skills, only careers tagged with all of
+	// them when all_skills is set, or with any of them otherwise, are listed.
+	// Every keyword must appear in the position, company or description, and
+	// published_after limits the list to careers published after that
+	// published_seq. Careers whose enddate has passed are left out unless
+	// include_expired is set, and careers not published yet unless
+	// include_unpublished is.
+	ListCareers(ctx context.Context, arg ListCareersParams) ([]Career, error)
+	ListCompanies(ctx context.Context) ([]Company, error)
+	ListCompanyRecruiters(ctx context.Context, companyID int64) ([]User, error)
+	ListDeletedCareers(ctx context.Context) ([]Career, error)
+	ListDeletedProfiles(ctx context.Context) ([]Profile, error)
+	ListDeletedUsers(ctx context.Context) ([]User, error)
+	ListExchangeRates(ctx context.Context) ([]ListExchangeRatesRow, error)
+	// ListExpiringCareers lists the open careers ending by until that the admin
+	// has not been reminded of for their current enddate
+	ListExpiringCareers(ctx context.Context, arg ListExpiringCareersParams) ([]Career, error)
+	ListOpenCareers(ctx context.Context) ([]Career, error)
+	ListOpenCareersByCompany(ctx context.Context, companyID int64) ([]Career, error)
+	ListProfileSkills(ctx context.Context, profileid int64) ([]ListProfileSkillsRow, error)
+	ListSavedCareerLists(ctx context.Context, userid int64) ([]ListSavedCareerListsRow, error)
+	// ListSavedCareers lists a user's saved careers, deleted ones included,
+	// newest save first. Given a list name, only the careers in that list are
+	// listed, ignoring case.
+	ListSavedCareers(ctx context.Context, arg ListSavedCareersParams) ([]ListSavedCareersRow, error)
+	ListSavedSearches(ctx context.Context, userid int64) ([]SavedSearch, error)
+	ListSkillAliases(ctx context.Context, skillID int64) ([]string, error)
+	// ListSkills lists the skills whose name or one of whose aliases starts with
+	// prefix, ignoring case
+	ListSkills(ctx context.Context, arg ListSkillsParams) ([]ListSkillsRow, error)
+	ListSkillsOfCareers(ctx context.Context, jobids []int64) ([]ListSkillsOfCareersRow, error)
+	ListSkillsOfProfiles(ctx context.Context, profileids []int64) ([]ListSkillsOfProfilesRow, error)
+	// PublishDueCareers publishes the careers whose publish_at has come
+	PublishDueCareers(ctx context.Context) ([]Career, error)
+	PurgeCareers(ctx context.Context, before time.Time) (int64, error)
+	PurgeProfiles(ctx context.Context, before time.Time) (int64, error)
+	PurgeUsers(ctx context.Context, before time.Time) (int64, error)
+	RemoveCompanyRecruiter(ctx context.Context, arg RemoveCompanyRecruiterParams) (int64, error)
+	RestoreCareerByJobId(ctx context.Context, jobid int64) (Career, error)
+	RestoreProfileById(ctx context.Context, profileid int64) (Profile, error)
+	RestoreProfilesOfUser(ctx context.Context, userid int64) error
+	RestoreUserById(ctx context.Context, userid int64) (User, error)
+	SaveExpiryReminder(ctx context.Context, arg SaveExpiryReminderParams) error
+	// TouchCareer bumps the version of a career whose skills changed
+	TouchCareer(ctx context.Context, jobid int64) (Career, error)
+	// TouchProfile bumps the version of a profile whose skills changed
+	TouchProfile(ctx context.Context, profileid int64) (Profile, error)
+	UpdateCareerByJobId(ctx context.Context, arg UpdateCareerByJobIdParams) (Career, error)
+	UpdateCareerImportProgress(ctx context.Context, arg UpdateCareerImportProgressParams) error
+	UpdateProfileByuserId(ctx context.Context, arg UpdateProfileByuserIdParams) (Profile, error)
+	UpdateSavedCareer(ctx context.Context, arg UpdateSavedCareerParams) (SavedCareer, error)
+	UpdateSavedSearch(ctx context.Context, arg UpdateSavedSearchParams) (SavedSearch, error)
+}
+
+var _ Querier = (*Queries)(nil)
@@ -7,24 +7,233 @@ package database
 
 import (
 	"context"
+	"database/sql"
+	"encoding/json"
 	"time"
+
+	"jobApps/geo"
 )
 
+const addCareerSkill = `-- name: AddCareerSkill :exec
+INSERT INTO career_skills (jobid, skill_id, required, min_years)
+VALUES ($1, $2, $3, $4)
+`
+
+type AddCareerSkillParams struct {
+	Jobid    int64  `json:"jobid"`
+	SkillID  int64  `json:"skill_id"`
+	Required bool   `json:"required"`
+	MinYears *int16 `json:"min_years"`
+}
+
+func (q *Queries) AddCareerSkill(ctx context.Context, arg AddCareerSkillParams) error {
+	_, err := q.db.Exec(ctx, addCareerSkill,
+		arg.Jobid,
+		arg.SkillID,
+		arg.Required,
+		arg.MinYears,
+	)
+	return err
+}
+
+const addCompanyRecruiter = `-- name: AddCompanyRecruiter :exec
+INSERT INTO company_recruiters (company_id, userid)
+VALUES ($1, $2)
+`
+
+type AddCompanyRecruiterParams struct {
+	CompanyID int64 `json:"company_id"`
+	Userid    int64 `json:"userid"`
+}
+
+func (q *Queries) AddCompanyRecruiter(ctx context.Context, arg AddCompanyRecruiterParams) error {
+	_, err := q.db.Exec(ctx, addCompanyRecruiter, arg.CompanyID, arg.Userid)
+	return err
+}
+
+const addProfileSkill = `-- name: AddProfileSkill :exec
+INSERT INTO profile_skills (profileid, skill_id, proficiency)
+VALUES ($1, $2, $3)
+`
+
+type AddProfileSkillParams struct {
+	Profileid   int64  `json:"profileid"`
+	SkillID     int64  `json:"skill_id"`
+	Proficiency string `json:"proficiency"`
+}
+
+func (q *Queries) AddProfileSkill(ctx context.Context, arg AddProfileSkillParams) error {
+	_, err := q.db.Exec(ctx, addProfileSkill, arg.Profileid, arg.SkillID, arg.Proficiency)
+	return err
+}
+
+const addSkillAlias = `-- name: AddSkillAlias :exec
+INSERT INTO skill_aliases (alias, skill_id)
+VALUES (lower($1), $2)
+`
+
+type AddSkillAliasParams struct {
+	Alias   string `json:"alias"`
+	SkillID int64  `json:"skill_id"`
+}
+
+func (q *Queries) AddSkillAlias(ctx context.Context, arg AddSkillAliasParams) error {
+	_, err := q.db.Exec(ctx, addSkillAlias, arg.Alias, arg.SkillID)
+	return err
+}
+
+const advanceSavedSearch = `-- name: AdvanceSavedSearch :exec
+UPDATE saved_searches
+SET last_published_seq = $1
+WHERE id = $2 AND last_published_seq < $1
+`
+
+type AdvanceSavedSearchParams struct {
+	LastPublishedSeq int64 `json:"last_published_seq"`
+	ID               int64 `json:"id"`
+}
+
+func (q *Queries) AdvanceSavedSearch(ctx context.Context, arg AdvanceSavedSearchParams) error {
+	_, err := q.db.Exec(ctx, advanceSavedSearch, arg.LastPublishedSeq, arg.ID)
+	return err
+}
+
+const closeExpiredCareers = `-- name: CloseExpiredCareers :many
+UPDATE career
+SET closed_at = now(), version = version + 1
+WHERE deleted_at IS NULL AND closed_at IS NULL AND enddate < current_date
+RETURNING jobid, company, position, jobtype, description, startdate, enddate, version, deleted_at, company_id, salary_min, salary_max, salary_currency, pay_period, equity, salary_visible, locations, remote_policy, remote_countries, seniority, closed_at, publish_at, published_at, published_seq, description_html, description_excerpt
+`
+
+// CloseExpiredCareers stamps closed_at on the careers whose enddate has
+// passed
+func (q *Queries) CloseExpiredCareers(ctx context.Context) ([]Career, error) {
+	rows, err := q.db.Query(ctx, closeExpiredCareers)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Career
+	for rows.Next() {
+		var i Career
+		if err := rows.Scan(
+			&i.Jobid,
+			&i.Company,
+			&i.Position,
+			&i.Jobtype,
+			&i.Description,
+			&i.Startdate,
+			&i.Enddate,
+			&i.Version,
+			&i.DeletedAt,
+			&i.CompanyID,
+			&i.SalaryMin,
+			&i.SalaryMax,
+			&i.SalaryCurrency,
+			&i.PayPeriod,
+			&i.Equity,
+			&i.SalaryVisible,
+			&i.Locations,
+			&i.RemotePolicy,
+			&i.RemoteCountries,
+			&i.Seniority,
+			&i.ClosedAt,
+			&i.PublishAt,
+			&i.PublishedAt,
+			&i.PublishedSeq,
+			&i.DescriptionHTML,
+			&i.DescriptionExcerpt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const countCareerSaves = `-- name: CountCareerSaves :one
+SELECT count(*) FROM saved_careers
+WHERE jobid = $1
+`
+
+func (q *Queries) CountCareerSaves(ctx context.Context, jobid int64) (int64, error) {
+	row := q.db.QueryRow(ctx, countCareerSaves, jobid)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
+const createAuditEvent = `-- name: CreateAuditEvent :exec
+INSERT INTO audit_events (actor_email, actor_role, action, resource_type, resource_id, changes, request_id, ip)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+`
+
+type CreateAuditEventParams struct {
+	ActorEmail   string          `json:"actor_email"`
+	ActorRole    string          `json:"actor_role"`
+	Action       string          `json:"action"`
+	ResourceType string          `json:"resource_type"`
+	ResourceID   int64           `json:"resource_id"`
+	Changes      json.RawMessage `json:"changes"`
+	RequestID    string          `json:"request_id"`
+	IP           string          `json:"ip"`
+}
+
+func (q *Queries) CreateAuditEvent(ctx context.Context, arg CreateAuditEventParams) error {
+	_, err := q.db.Exec(ctx, createAuditEvent,
+		arg.ActorEmail,
+		arg.ActorRole,
+		arg.Action,
+		arg.ResourceType,
+		arg.ResourceID,
+		arg.Changes,
+		arg.RequestID,
+		arg.IP,
+	)
+	return err
+}
+
 const createCareer = `-- name: CreateCareer :one
-INSERT INTO career (Company,Position,Jobtype,Description,StartDate,EndDate)
-VALUES ($1, $2,$3,$4,$5,$6)
-RETURNING jobid, company, position, jobtype, description, startdate, enddate
+INSERT INTO career (Company,Position,Jobtype,Description,StartDate,EndDate,company_id,
+    salary_min,salary_max,salary_currency,pay_period,equity,salary_visible,
+    locations,remote_policy,remote_countries,seniority,
+    publish_at,published_at,published_seq,description_html,description_excerpt)
+VALUES ($1, $2,$3,$4,$5,$6,$7,$8,$9,$10,$11,$12,$13,$14,$15,$16,$17,$18,
+    CASE WHEN $18 <= clock_timestamp() THEN clock_timestamp() END,
+    CASE WHEN $18 <= clock_timestamp() THEN nextval('career_published_seq') END,
+    $19,$20)
+RETURNING jobid, company, position, jobtype, description, startdate, enddate, version, deleted_at, company_id, salary_min, salary_max, salary_currency, pay_period, equity, salary_visible, locations, remote_policy, remote_countries, seniority, closed_at, publish_at, published_at, published_seq, description_html, description_excerpt
 `
 
 type CreateCareerParams struct {
-	Company     string    `json:"company"`
-	Position    string    `json:"position"`
-	Jobtype     string    `json:"jobtype"`
-	Description string    `json:"description"`
-	Startdate   time.Time `json:"startdate"`
-	Enddate     time.Time `json:"enddate"`
+	Company            string        `json:"company"`
+	Position           string        `json:"position"`
+	Jobtype            string        `json:"jobtype"`
+	Description        string        `json:"description"`
+	Startdate          time.Time     `json:"startdate"`
+	Enddate            time.Time     `json:"enddate"`
+	CompanyID          int64         `json:"company_id"`
+	SalaryMin          *int64        `json:"salary_min"`
+	SalaryMax          *int64        `json:"salary_max"`
+	SalaryCurrency     *string       `json:"salary_currency"`
+	PayPeriod          *string       `json:"pay_period"`
+	Equity             bool          `json:"equity"`
+	SalaryVisible      bool          `json:"salary_visible"`
+	Locations          geo.Locations `json:"locations"`
+	RemotePolicy       string        `json:"remote_policy"`
+	RemoteCountries    []string      `json:"remote_countries"`
+	Seniority          *string       `json:"seniority"`
+	PublishAt          time.Time     `json:"publish_at"`
+	DescriptionHTML    string        `json:"description_html"`
+	DescriptionExcerpt string        `json:"description_excerpt"`
 }
 
+// CreateCareer publishes the career right away unless publish_at is still
+// to come. publish_at is compared with the clock rather than now(), the
+// start of the transaction, as it defaults to the time of the request.
 func (q *Queries) CreateCareer(ctx context.Context, arg CreateCareerParams) (Career, error) {
 	row := q.db.QueryRow(ctx, createCareer,
 		arg.Company,
@@ -33,6 +242,20 @@ func (q *Queries) CreateCareer(ctx context.Context, arg CreateCareerParams) (Car
 		arg.Description,
 		arg.Startdate,
 		arg.Enddate,
+		arg.CompanyID,
+		arg.SalaryMin,
+		arg.SalaryMax,
+		arg.SalaryCurrency,
+		arg.PayPeriod,
+		arg.Equity,
+		arg.SalaryVisible,
+		arg.Locations,
+		arg.RemotePolicy,
+		arg.RemoteCountries,
+		arg.Seniority,
+		arg.PublishAt,
+		arg.DescriptionHTML,
+		arg.DescriptionExcerpt,
 	)
 	var i Career
 	err := row.Scan(
@@ -43,29 +266,197 @@ func (q *Queries) CreateCareer(ctx context.Context, arg CreateCareerParams) (Car
 		&i.Description,
 		&i.Startdate,
 		&i.Enddate,
+		&i.Version,
+		&i.DeletedAt,
+		&i.CompanyID,
+		&i.SalaryMin,
+		&i.SalaryMax,
+		&i.SalaryCurrency,
+		&i.PayPeriod,
+		&i.Equity,
+		&i.SalaryVisible,
+		&i.Locations,
+		&i.RemotePolicy,
+		&i.RemoteCountries,
+		&i.Seniority,
+		&i.ClosedAt,
+		&i.PublishAt,
+		&i.PublishedAt,
+		&i.PublishedSeq,
+		&i.DescriptionHTML,
+		&i.DescriptionExcerpt,
+	)
+	return i, err
+}
+
+const createCareerImport = `-- name: CreateCareerImport :one
+INSERT INTO career_imports (format, mode, dry_run, total_rows, ignored_columns, created_by)
+VALUES ($1, $2, $3, $4, $5, $6)
+RETURNING id, format, mode, dry_run, status, total_rows, processed_rows, created_rows, failed_rows, jobids, errors, ignored_columns, created_by, created_at, finished_at
+`
+
+type CreateCareerImportParams struct {
+	Format         string   `json:"format"`
+	Mode           string   `json:"mode"`
+	DryRun         bool     `json:"dry_run"`
+	TotalRows      int32    `json:"total_rows"`
+	IgnoredColumns []string `json:"ignored_columns"`
+	CreatedBy      string   `json:"created_by"`
+}
+
+func (q *Queries) CreateCareerImport(ctx context.Context, arg CreateCareerImportParams) (CareerImport, error) {
+	row := q.db.QueryRow(ctx, createCareerImport,
+		arg.Format,
+		arg.Mode,
+		arg.DryRun,
+		arg.TotalRows,
+		arg.IgnoredColumns,
+		arg.CreatedBy,
+	)
+	var i CareerImport
+	err := row.Scan(
+		&i.ID,
+		&i.Format,
+		&i.Mode,
+		&i.DryRun,
+		&i.Status,
+		&i.TotalRows,
+		&i.ProcessedRows,
+		&i.CreatedRows,
+		&i.FailedRows,
+		&i.Jobids,
+		&i.Errors,
+		&i.IgnoredColumns,
+		&i.CreatedBy,
+		&i.CreatedAt,
+		&i.FinishedAt,
+	)
+	return i, err
+}
+
+const createCareerRevision = `-- name: CreateCareerRevision :exec
+INSERT INTO career_revisions (jobid, revision, company, position, jobtype, description, startdate, enddate, author_email,
+    salary_min, salary_max, salary_currency, pay_period, equity, salary_visible,
+    locations, remote_policy, remote_countries, seniority)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18, $19)
+`
+
+type CreateCareerRevisionParams struct {
+	Jobid           int64         `json:"jobid"`
+	Revision        int32         `json:"revision"`
+	Company         string        `json:"company"`
+	Position        string        `json:"position"`
+	Jobtype         string        `json:"jobtype"`
+	Description     string        `json:"description"`
+	Startdate       time.Time     `json:"startdate"`
+	Enddate         time.Time     `json:"enddate"`
+	AuthorEmail     string        `json:"author_email"`
+	SalaryMin       *int64        `json:"salary_min"`
+	SalaryMax       *int64        `json:"salary_max"`
+	SalaryCurrency  *string       `json:"salary_currency"`
+	PayPeriod       *string       `json:"pay_period"`
+	Equity          bool          `json:"equity"`
+	SalaryVisible   bool          `json:"salary_visible"`
+	Locations       geo.Locations `json:"locations"`
+	RemotePolicy    string        `json:"remote_policy"`
+	RemoteCountries []string      `json:"remote_countries"`
+	Seniority       *string       `json:"seniority"`
+}
+
+func (q *Queries) CreateCareerRevision(ctx context.Context, arg CreateCareerRevisionParams) error {
+	_, err := q.db.Exec(ctx, createCareerRevision,
+		arg.Jobid,
+		arg.Revision,
+		arg.Company,
+		arg.Position,
+		arg.Jobtype,
+		arg.Description,
+		arg.Startdate,
+		arg.Enddate,
+		arg.AuthorEmail,
+		arg.SalaryMin,
+		arg.SalaryMax,
+		arg.SalaryCurrency,
+		arg.PayPeriod,
+		arg.Equity,
+		arg.SalaryVisible,
+		arg.Locations,
+		arg.RemotePolicy,
+		arg.RemoteCountries,
+		arg.Seniority,
+	)
+	return err
+}
+
+const createCompany = `-- name: CreateCompany :one
+INSERT INTO companies (name, slug, website, logo_url, description)
+VALUES ($1, $2, $3, $4, $5)
+RETURNING id, name, slug, website, logo_url, description, created_at
+`
+
+type CreateCompanyParams struct {
+	Name        string `json:"name"`
+	Slug        string `json:"slug"`
+	Website     string `json:"website"`
+	LogoURL     string `json:"logo_url"`
+	Description string `json:"description"`
+}
+
+func (q *Queries) CreateCompany(ctx context.Context, arg CreateCompanyParams) (Company, error) {
+	row := q.db.QueryRow(ctx, createCompany,
+		arg.Name,
+		arg.Slug,
+		arg.Website,
+		arg.LogoURL,
+		arg.Description,
+	)
+	var i Company
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.Slug,
+		&i.Website,
+		&i.LogoURL,
+		&i.Description,
+		&i.CreatedAt,
 	)
 	return i, err
 }
 
 const createProfile = `-- name: CreateProfile :one
-INSERT INTO profile (FullName,Age,Gender,Address)
-VALUES ($1, $2,$3,$4)
-RETURNING profileid, userid, fullname, age, gender, address, phonenumber
+INSERT INTO profile (UserID,FullName,Age,Gender,Address,PhoneNumber,
+    locations,remote_preference,expected_salary,salary_currency,years_experience)
+VALUES ($1, $2,$3,$4,$5,$6,$7,$8,$9,$10,$11)
+RETURNING profileid, userid, fullname, age, gender, address, phonenumber, version, deleted_at, locations, remote_preference, expected_salary, salary_currency, years_experience
 `
 
 type CreateProfileParams struct {
-	Fullname string `json:"fullname"`
-	Age      int32  `json:"age"`
-	Gender   string `json:"gender"`
-	Address  string `json:"address"`
+	Userid           int64         `json:"userid"`
+	Fullname         string        `json:"fullname"`
+	Age              int32         `json:"age"`
+	Gender           string        `json:"gender"`
+	Address          string        `json:"address"`
+	Phonenumber      string        `json:"phonenumber"`
+	Locations        geo.Locations `json:"locations"`
+	RemotePreference string        `json:"remote_preference"`
+	ExpectedSalary   *int64        `json:"expected_salary"`
+	SalaryCurrency   *string       `json:"salary_currency"`
+	YearsExperience  *int16        `json:"years_experience"`
 }
 
 func (q *Queries) CreateProfile(ctx context.Context, arg CreateProfileParams) (Profile, error) {
 	row := q.db.QueryRow(ctx, createProfile,
+		arg.Userid,
 		arg.Fullname,
 		arg.Age,
 		arg.Gender,
 		arg.Address,
+		arg.Phonenumber,
+		arg.Locations,
+		arg.RemotePreference,
+		arg.ExpectedSalary,
+		arg.SalaryCurrency,
+		arg.YearsExperience,
 	)
 	var i Profile
 	err := row.Scan(
@@ -76,14 +467,118 @@ func (q *Queries) CreateProfile(ctx context.Context, arg CreateProfileParams) (P
 		&i.Gender,
 		&i.Address,
 		&i.Phonenumber,
+		&i.Version,
+		&i.DeletedAt,
+		&i.Locations,
+		&i.RemotePreference,
+		&i.ExpectedSalary,
+		&i.SalaryCurrency,
+		&i.YearsExperience,
+	)
+	return i, err
+}
+
+const createSavedCareer = `-- name: CreateSavedCareer :one
+INSERT INTO saved_careers (userid, jobid, note, list_name)
+VALUES ($1, $2, $3, $4)
+RETURNING userid, jobid, note, list_name, saved_at
+`
+
+type CreateSavedCareerParams struct {
+	Userid   int64   `json:"userid"`
+	Jobid    int64   `json:"jobid"`
+	Note     string  `json:"note"`
+	ListName *string `json:"list_name"`
+}
+
+func (q *Queries) CreateSavedCareer(ctx context.Context, arg CreateSavedCareerParams) (SavedCareer, error) {
+	row := q.db.QueryRow(ctx, createSavedCareer,
+		arg.Userid,
+		arg.Jobid,
+		arg.Note,
+		arg.ListName,
+	)
+	var i SavedCareer
+	err := row.Scan(
+		&i.Userid,
+		&i.Jobid,
+		&i.Note,
+		&i.ListName,
+		&i.SavedAt,
+	)
+	return i, err
+}
+
+const createSavedSearch = `-- name: CreateSavedSearch :one
+INSERT INTO saved_searches (userid, name, keywords, jobtype, near, radius_km, salary_min, currency, alerts, unsubscribe_token, last_published_seq)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+RETURNING id, userid, name, keywords, jobtype, near, radius_km, salary_min, currency, alerts, unsubscribe_token, last_published_seq, created_at
+`
+
+type CreateSavedSearchParams struct {
+	Userid           int64    `json:"userid"`
+	Name             string   `json:"name"`
+	Keywords         string   `json:"keywords"`
+	Jobtype          string   `json:"jobtype"`
+	Near             string   `json:"near"`
+	RadiusKm         *float64 `json:"radius_km"`
+	SalaryMin        *int64   `json:"salary_min"`
+	Currency         *string  `json:"currency"`
+	Alerts           bool     `json:"alerts"`
+	UnsubscribeToken string   `json:"unsubscribe_token"`
+	LastPublishedSeq int64    `json:"last_published_seq"`
+}
+
+func (q *Queries) CreateSavedSearch(ctx context.Context, arg CreateSavedSearchParams) (SavedSearch, error) {
+	row := q.db.QueryRow(ctx, createSavedSearch,
+		arg.Userid,
+		arg.Name,
+		arg.Keywords,
+		arg.Jobtype,
+		arg.Near,
+		arg.RadiusKm,
+		arg.SalaryMin,
+		arg.Currency,
+		arg.Alerts,
+		arg.UnsubscribeToken,
+		arg.LastPublishedSeq,
+	)
+	var i SavedSearch
+	err := row.Scan(
+		&i.ID,
+		&i.Userid,
+		&i.Name,
+		&i.Keywords,
+		&i.Jobtype,
+		&i.Near,
+		&i.RadiusKm,
+		&i.SalaryMin,
+		&i.Currency,
+		&i.Alerts,
+		&i.UnsubscribeToken,
+		&i.LastPublishedSeq,
+		&i.CreatedAt,
 	)
 	return i, err
 }
 
+const createSkill = `-- name: CreateSkill :one
+INSERT INTO skills (name)
+VALUES ($1)
+RETURNING id, name, created_at
+`
+
+func (q *Queries) CreateSkill(ctx context.Context, name string) (Skill, error) {
+	row := q.db.QueryRow(ctx, createSkill, name)
+	var i Skill
+	err := row.Scan(&i.ID, &i.Name, &i.CreatedAt)
+	return i, err
+}
+
 const createUser = `-- name: CreateUser :one
 INSERT INTO users (Username,Email,PhoneNumber,Password,Role)
 VALUES ($1, $2,$3,$4,$5)
-RETURNING userid, username, email, phonenumber, password, role, createdat, updatedat
+RETURNING userid, username, email, phonenumber, password, role, createdat, updatedat, deleted_at
 `
 
 type CreateUserParams struct {
@@ -112,15 +607,16 @@ func (q *Queries) CreateUser(ctx context.Context, arg CreateUserParams) (User, e
 		&i.Role,
 		&i.Createdat,
 		&i.Updatedat,
+		&i.DeletedAt,
 	)
 	return i, err
 }
 
 const deleteCareerByJobId = `-- name: DeleteCareerByJobId :one
-DELETE
-FROM career
-WHERE jobid = $1
-RETURNING jobid, company, position, jobtype, description, startdate, enddate
+UPDATE career
+SET deleted_at = now()
+WHERE jobid = $1 AND deleted_at IS NULL
+RETURNING jobid, company, position, jobtype, description, startdate, enddate, version, deleted_at, company_id, salary_min, salary_max, salary_currency, pay_period, equity, salary_visible, locations, remote_policy, remote_countries, seniority, closed_at, publish_at, published_at, published_seq, description_html, description_excerpt
 `
 
 func (q *Queries) DeleteCareerByJobId(ctx context.Context, jobid int64) (Career, error) {
@@ -134,15 +630,44 @@ func (q *Queries) DeleteCareerByJobId(ctx context.Context, jobid int64) (Career,
 		&i.Description,
 		&i.Startdate,
 		&i.Enddate,
+		&i.Version,
+		&i.DeletedAt,
+		&i.CompanyID,
+		&i.SalaryMin,
+		&i.SalaryMax,
+		&i.SalaryCurrency,
+		&i.PayPeriod,
+		&i.Equity,
+		&i.SalaryVisible,
+		&i.Locations,
+		&i.RemotePolicy,
+		&i.RemoteCountries,
+		&i.Seniority,
+		&i.ClosedAt,
+		&i.PublishAt,
+		&i.PublishedAt,
+		&i.PublishedSeq,
+		&i.DescriptionHTML,
+		&i.DescriptionExcerpt,
 	)
 	return i, err
 }
 
+const deleteCareerSkills = `-- name: DeleteCareerSkills :exec
+DELETE FROM career_skills
+WHERE jobid = $1
+`
+
+func (q *Queries) DeleteCareerSkills(ctx context.Context, jobid int64) error {
+	_, err := q.db.Exec(ctx, deleteCareerSkills, jobid)
+	return err
+}
+
 const deleteProfileByUserId = `-- name: DeleteProfileByUserId :one
-DELETE
-FROM profile
-WHERE userid = $1
-RETURNING profileid, userid, fullname, age, gender, address, phonenumber
+UPDATE profile
+SET deleted_at = now()
+WHERE userid = $1 AND deleted_at IS NULL
+RETURNING profileid, userid, fullname, age, gender, address, phonenumber, version, deleted_at, locations, remote_preference, expected_salary, salary_currency, years_experience
 `
 
 func (q *Queries) DeleteProfileByUserId(ctx context.Context, userid int64) (Profile, error) {
@@ -156,12 +681,262 @@ func (q *Queries) DeleteProfileByUserId(ctx context.Context, userid int64) (Prof
 		&i.Gender,
 		&i.Address,
 		&i.Phonenumber,
+		&i.Version,
+		&i.DeletedAt,
+		&i.Locations,
+		&i.RemotePreference,
+		&i.ExpectedSalary,
+		&i.SalaryCurrency,
+		&i.YearsExperience,
+	)
+	return i, err
+}
+
+const deleteProfileSkills = `-- name: DeleteProfileSkills :exec
+DELETE FROM profile_skills
+WHERE profileid = $1
+`
+
+func (q *Queries) DeleteProfileSkills(ctx context.Context, profileid int64) error {
+	_, err := q.db.Exec(ctx, deleteProfileSkills, profileid)
+	return err
+}
+
+const deleteProfilesOfUser = `-- name: DeleteProfilesOfUser :exec
+UPDATE profile
+SET deleted_at = $2
+WHERE userid = $1 AND deleted_at IS NULL
+`
+
+type DeleteProfilesOfUserParams struct {
+	Userid    int64      `json:"userid"`
+	DeletedAt *time.Time `json:"deleted_at"`
+}
+
+func (q *Queries) DeleteProfilesOfUser(ctx context.Context, arg DeleteProfilesOfUserParams) error {
+	_, err := q.db.Exec(ctx, deleteProfilesOfUser, arg.Userid, arg.DeletedAt)
+	return err
+}
+
+const deleteSavedCareer = `-- name: DeleteSavedCareer :one
+DELETE FROM saved_careers
+WHERE userid = $1 AND jobid = $2
+RETURNING userid, jobid, note, list_name, saved_at
+`
+
+type DeleteSavedCareerParams struct {
+	Userid int64 `json:"userid"`
+	Jobid  int64 `json:"jobid"`
+}
+
+func (q *Queries) DeleteSavedCareer(ctx context.Context, arg DeleteSavedCareerParams) (SavedCareer, error) {
+	row := q.db.QueryRow(ctx, deleteSavedCareer, arg.Userid, arg.Jobid)
+	var i SavedCareer
+	err := row.Scan(
+		&i.Userid,
+		&i.Jobid,
+		&i.Note,
+		&i.ListName,
+		&i.SavedAt,
+	)
+	return i, err
+}
+
+const deleteSavedSearch = `-- name: DeleteSavedSearch :one
+DELETE FROM saved_searches
+WHERE id = $1 AND userid = $2
+RETURNING id, userid, name, keywords, jobtype, near, radius_km, salary_min, currency, alerts, unsubscribe_token, last_published_seq, created_at
+`
+
+type DeleteSavedSearchParams struct {
+	ID     int64 `json:"id"`
+	Userid int64 `json:"userid"`
+}
+
+func (q *Queries) DeleteSavedSearch(ctx context.Context, arg DeleteSavedSearchParams) (SavedSearch, error) {
+	row := q.db.QueryRow(ctx, deleteSavedSearch, arg.ID, arg.Userid)
+	var i SavedSearch
+	err := row.Scan(
+		&i.ID,
+		&i.Userid,
+		&i.Name,
+		&i.Keywords,
+		&i.Jobtype,
+		&i.Near,
+		&i.RadiusKm,
+		&i.SalaryMin,
+		&i.Currency,
+		&i.Alerts,
+		&i.UnsubscribeToken,
+		&i.LastPublishedSeq,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const deleteUserById = `-- name: DeleteUserById :one
+UPDATE users
+SET deleted_at = now()
+WHERE userid = $1 AND deleted_at IS NULL
+RETURNING userid, username, email, phonenumber, password, role, createdat, updatedat, deleted_at
+`
+
+func (q *Queries) DeleteUserById(ctx context.Context, userid int64) (User, error) {
+	row := q.db.QueryRow(ctx, deleteUserById, userid)
+	var i User
+	err := row.Scan(
+		&i.Userid,
+		&i.Username,
+		&i.Email,
+		&i.Phonenumber,
+		&i.Password,
+		&i.Role,
+		&i.Createdat,
+		&i.Updatedat,
+		&i.DeletedAt,
+	)
+	return i, err
+}
+
+const disableSavedSearchAlerts = `-- name: DisableSavedSearchAlerts :one
+UPDATE saved_searches
+SET alerts = false
+WHERE id = $1
+RETURNING id, userid, name, keywords, jobtype, near, radius_km, salary_min, currency, alerts, unsubscribe_token, last_published_seq, created_at
+`
+
+func (q *Queries) DisableSavedSearchAlerts(ctx context.Context, id int64) (SavedSearch, error) {
+	row := q.db.QueryRow(ctx, disableSavedSearchAlerts, id)
+	var i SavedSearch
+	err := row.Scan(
+		&i.ID,
+		&i.Userid,
+		&i.Name,
+		&i.Keywords,
+		&i.Jobtype,
+		&i.Near,
+		&i.RadiusKm,
+		&i.SalaryMin,
+		&i.Currency,
+		&i.Alerts,
+		&i.UnsubscribeToken,
+		&i.LastPublishedSeq,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const extendCareer = `-- name: ExtendCareer :one
+UPDATE career
+SET enddate = $1, closed_at = NULL, version = version + 1
+WHERE jobid = $2 AND deleted_at IS NULL
+RETURNING jobid, company, position, jobtype, description, startdate, enddate, version, deleted_at, company_id, salary_min, salary_max, salary_currency, pay_period, equity, salary_visible, locations, remote_policy, remote_countries, seniority, closed_at, publish_at, published_at, published_seq, description_html, description_excerpt
+`
+
+type ExtendCareerParams struct {
+	Enddate time.Time `json:"enddate"`
+	Jobid   int64     `json:"jobid"`
+}
+
+func (q *Queries) ExtendCareer(ctx context.Context, arg ExtendCareerParams) (Career, error) {
+	row := q.db.QueryRow(ctx, extendCareer, arg.Enddate, arg.Jobid)
+	var i Career
+	err := row.Scan(
+		&i.Jobid,
+		&i.Company,
+		&i.Position,
+		&i.Jobtype,
+		&i.Description,
+		&i.Startdate,
+		&i.Enddate,
+		&i.Version,
+		&i.DeletedAt,
+		&i.CompanyID,
+		&i.SalaryMin,
+		&i.SalaryMax,
+		&i.SalaryCurrency,
+		&i.PayPeriod,
+		&i.Equity,
+		&i.SalaryVisible,
+		&i.Locations,
+		&i.RemotePolicy,
+		&i.RemoteCountries,
+		&i.Seniority,
+		&i.ClosedAt,
+		&i.PublishAt,
+		&i.PublishedAt,
+		&i.PublishedSeq,
+		&i.DescriptionHTML,
+		&i.DescriptionExcerpt,
+	)
+	return i, err
+}
+
+const findSkill = `-- name: FindSkill :one
+SELECT skills.id, skills.name, skills.created_at FROM skills
+WHERE lower(skills.name) = lower($1)
+   OR skills.id = (SELECT skill_aliases.skill_id FROM skill_aliases WHERE skill_aliases.alias = lower($1))
+`
+
+// FindSkill resolves a skill by its name or one of its aliases, ignoring case
+func (q *Queries) FindSkill(ctx context.Context, name string) (Skill, error) {
+	row := q.db.QueryRow(ctx, findSkill, name)
+	var i Skill
+	err := row.Scan(&i.ID, &i.Name, &i.CreatedAt)
+	return i, err
+}
+
+const finishCareerImport = `-- name: FinishCareerImport :one
+UPDATE career_imports
+SET status = $2, processed_rows = $3, created_rows = $4, failed_rows = $5, jobids = $6, errors = $7, finished_at = now()
+WHERE id = $1
+RETURNING id, format, mode, dry_run, status, total_rows, processed_rows, created_rows, failed_rows, jobids, errors, ignored_columns, created_by, created_at, finished_at
+`
+
+type FinishCareerImportParams struct {
+	ID            int64           `json:"id"`
+	Status        string          `json:"status"`
+	ProcessedRows int32           `json:"processed_rows"`
+	CreatedRows   int32           `json:"created_rows"`
+	FailedRows    int32           `json:"failed_rows"`
+	Jobids        []int64         `json:"jobids"`
+	Errors        json.RawMessage `json:"errors"`
+}
+
+func (q *Queries) FinishCareerImport(ctx context.Context, arg FinishCareerImportParams) (CareerImport, error) {
+	row := q.db.QueryRow(ctx, finishCareerImport,
+		arg.ID,
+		arg.Status,
+		arg.ProcessedRows,
+		arg.CreatedRows,
+		arg.FailedRows,
+		arg.Jobids,
+		arg.Errors,
+	)
+	var i CareerImport
+	err := row.Scan(
+		&i.ID,
+		&i.Format,
+		&i.Mode,
+		&i.DryRun,
+		&i.Status,
+		&i.TotalRows,
+		&i.ProcessedRows,
+		&i.CreatedRows,
+		&i.FailedRows,
+		&i.Jobids,
+		&i.Errors,
+		&i.IgnoredColumns,
+		&i.CreatedBy,
+		&i.CreatedAt,
+		&i.FinishedAt,
 	)
 	return i, err
 }
 
 const getAllCareerDetails = `-- name: GetAllCareerDetails :many
-SELECT jobid, company, position, jobtype, description, startdate, enddate FROM career
+SELECT jobid, company, position, jobtype, description, startdate, enddate, version, deleted_at, company_id, salary_min, salary_max, salary_currency, pay_period, equity, salary_visible, locations, remote_policy, remote_countries, seniority, closed_at, publish_at, published_at, published_seq, description_html, description_excerpt FROM career
+WHERE deleted_at IS NULL
 `
 
 func (q *Queries) GetAllCareerDetails(ctx context.Context) ([]Career, error) {
@@ -181,6 +956,25 @@ func (q *Queries) GetAllCareerDetails(ctx context.Context) ([]Career, error) {
 			&i.Description,
 			&i.Startdate,
 			&i.Enddate,
+			&i.Version,
+			&i.DeletedAt,
+			&i.CompanyID,
+			&i.SalaryMin,
+			&i.SalaryMax,
+			&i.SalaryCurrency,
+			&i.PayPeriod,
+			&i.Equity,
+			&i.SalaryVisible,
+			&i.Locations,
+			&i.RemotePolicy,
+			&i.RemoteCountries,
+			&i.Seniority,
+			&i.ClosedAt,
+			&i.PublishAt,
+			&i.PublishedAt,
+			&i.PublishedSeq,
+			&i.DescriptionHTML,
+			&i.DescriptionExcerpt,
 		); err != nil {
 			return nil, err
 		}
@@ -193,7 +987,8 @@ func (q *Queries) GetAllCareerDetails(ctx context.Context) ([]Career, error) {
 }
 
 const getAllProfileDetails = `-- name: GetAllProfileDetails :many
-SELECT profileid, userid, fullname, age, gender, address, phonenumber FROM profile
+SELECT profileid, userid, fullname, age, gender, address, phonenumber, version, deleted_at, locations, remote_preference, expected_salary, salary_currency, years_experience FROM profile
+WHERE deleted_at IS NULL
 `
 
 func (q *Queries) GetAllProfileDetails(ctx context.Context) ([]Profile, error) {
@@ -213,6 +1008,13 @@ func (q *Queries) GetAllProfileDetails(ctx context.Context) ([]Profile, error) {
 			&i.Gender,
 			&i.Address,
 			&i.Phonenumber,
+			&i.Version,
+			&i.DeletedAt,
+			&i.Locations,
+			&i.RemotePreference,
+			&i.ExpectedSalary,
+			&i.SalaryCurrency,
+			&i.YearsExperience,
 		); err != nil {
 			return nil, err
 		}
@@ -225,8 +1027,8 @@ func (q *Queries) GetAllProfileDetails(ctx context.Context) ([]Profile, error) {
 }
 
 const getCareerByJobId = `-- name: GetCareerByJobId :one
-SELECT jobid, company, position, jobtype, description, startdate, enddate FROM career
-WHERE jobid = $1 LIMIT 1
+SELECT jobid, company, position, jobtype, description, startdate, enddate, version, deleted_at, company_id, salary_min, salary_max, salary_currency, pay_period, equity, salary_visible, locations, remote_policy, remote_countries, seniority, closed_at, publish_at, published_at, published_seq, description_html, description_excerpt FROM career
+WHERE jobid = $1 AND deleted_at IS NULL LIMIT 1
 `
 
 func (q *Queries) GetCareerByJobId(ctx context.Context, jobid int64) (Career, error) {
@@ -240,37 +1042,1839 @@ func (q *Queries) GetCareerByJobId(ctx context.Context, jobid int64) (Career, er
 		&i.Description,
 		&i.Startdate,
 		&i.Enddate,
+		&i.Version,
+		&i.DeletedAt,
+		&i.CompanyID,
+		&i.SalaryMin,
+		&i.SalaryMax,
+		&i.SalaryCurrency,
+		&i.PayPeriod,
+		&i.Equity,
+		&i.SalaryVisible,
+		&i.Locations,
+		&i.RemotePolicy,
+		&i.RemoteCountries,
+		&i.Seniority,
+		&i.ClosedAt,
+		&i.PublishAt,
+		&i.PublishedAt,
+		&i.PublishedSeq,
+		&i.DescriptionHTML,
+		&i.DescriptionExcerpt,
 	)
 	return i, err
 }
 
-const getProfileByuserId = `-- name: GetProfileByuserId :one
-SELECT profileid, userid, fullname, age, gender, address, phonenumber FROM profile
-WHERE userid = $1 LIMIT 1
+const getCareerByJobIdForUpdate = `-- name: GetCareerByJobIdForUpdate :one
+SELECT jobid, company, position, jobtype, description, startdate, enddate, version, deleted_at, company_id, salary_min, salary_max, salary_currency, pay_period, equity, salary_visible, locations, remote_policy, remote_countries, seniority, closed_at, publish_at, published_at, published_seq, description_html, description_excerpt FROM career
+WHERE jobid = $1 AND deleted_at IS NULL LIMIT 1
+FOR UPDATE
 `
 
-func (q *Queries) GetProfileByuserId(ctx context.Context, userid int64) (Profile, error) {
-	row := q.db.QueryRow(ctx, getProfileByuserId, userid)
-	var i Profile
+func (q *Queries) GetCareerByJobIdForUpdate(ctx context.Context, jobid int64) (Career, error) {
+	row := q.db.QueryRow(ctx, getCareerByJobIdForUpdate, jobid)
+	var i Career
 	err := row.Scan(
-		&i.Profileid,
+		&i.Jobid,
+		&i.Company,
+		&i.Position,
+		&i.Jobtype,
+		&i.Description,
+		&i.Startdate,
+		&i.Enddate,
+		&i.Version,
+		&i.DeletedAt,
+		&i.CompanyID,
+		&i.SalaryMin,
+		&i.SalaryMax,
+		&i.SalaryCurrency,
+		&i.PayPeriod,
+		&i.Equity,
+		&i.SalaryVisible,
+		&i.Locations,
+		&i.RemotePolicy,
+		&i.RemoteCountries,
+		&i.Seniority,
+		&i.ClosedAt,
+		&i.PublishAt,
+		&i.PublishedAt,
+		&i.PublishedSeq,
+		&i.DescriptionHTML,
+		&i.DescriptionExcerpt,
+	)
+	return i, err
+}
+
+const getCareerImport = `-- name: GetCareerImport :one
+SELECT id, format, mode, dry_run, status, total_rows, processed_rows, created_rows, failed_rows, jobids, errors, ignored_columns, created_by, created_at, finished_at FROM career_imports
+WHERE id = $1
+`
+
+func (q *Queries) GetCareerImport(ctx context.Context, id int64) (CareerImport, error) {
+	row := q.db.QueryRow(ctx, getCareerImport, id)
+	var i CareerImport
+	err := row.Scan(
+		&i.ID,
+		&i.Format,
+		&i.Mode,
+		&i.DryRun,
+		&i.Status,
+		&i.TotalRows,
+		&i.ProcessedRows,
+		&i.CreatedRows,
+		&i.FailedRows,
+		&i.Jobids,
+		&i.Errors,
+		&i.IgnoredColumns,
+		&i.CreatedBy,
+		&i.CreatedAt,
+		&i.FinishedAt,
+	)
+	return i, err
+}
+
+const getCareerRevision = `-- name: GetCareerRevision :one
+SELECT jobid, revision, company, position, jobtype, description, startdate, enddate, author_email, created_at, salary_min, salary_max, salary_currency, pay_period, equity, salary_visible, locations, remote_policy, remote_countries, seniority FROM career_revisions
+WHERE jobid = $1 AND revision = $2
+`
+
+type GetCareerRevisionParams struct {
+	Jobid    int64 `json:"jobid"`
+	Revision int32 `json:"revision"`
+}
+
+func (q *Queries) GetCareerRevision(ctx context.Context, arg GetCareerRevisionParams) (CareerRevision, error) {
+	row := q.db.QueryRow(ctx, getCareerRevision, arg.Jobid, arg.Revision)
+	var i CareerRevision
+	err := row.Scan(
+		&i.Jobid,
+		&i.Revision,
+		&i.Company,
+		&i.Position,
+		&i.Jobtype,
+		&i.Description,
+		&i.Startdate,
+		&i.Enddate,
+		&i.AuthorEmail,
+		&i.CreatedAt,
+		&i.SalaryMin,
+		&i.SalaryMax,
+		&i.SalaryCurrency,
+		&i.PayPeriod,
+		&i.Equity,
+		&i.SalaryVisible,
+		&i.Locations,
+		&i.RemotePolicy,
+		&i.RemoteCountries,
+		&i.Seniority,
+	)
+	return i, err
+}
+
+const getCompanyBySlug = `-- name: GetCompanyBySlug :one
+SELECT id, name, slug, website, logo_url, description, created_at FROM companies
+WHERE slug = $1
+`
+
+func (q *Queries) GetCompanyBySlug(ctx context.Context, slug string) (Company, error) {
+	row := q.db.QueryRow(ctx, getCompanyBySlug, slug)
+	var i Company
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.Slug,
+		&i.Website,
+		&i.LogoURL,
+		&i.Description,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const getDeletedCareerByJobIdForUpdate = `-- name: GetDeletedCareerByJobIdForUpdate :one
+SELECT jobid, company, position, jobtype, description, startdate, enddate, version, deleted_at, company_id, salary_min, salary_max, salary_currency, pay_period, equity, salary_visible, locations, remote_policy, remote_countries, seniority, closed_at, publish_at, published_at, published_seq, description_html, description_excerpt FROM career
+WHERE jobid = $1 AND deleted_at IS NOT NULL LIMIT 1
+FOR UPDATE
+`
+
+func (q *Queries) GetDeletedCareerByJobIdForUpdate(ctx context.Context, jobid int64) (Career, error) {
+	row := q.db.QueryRow(ctx, getDeletedCareerByJobIdForUpdate, jobid)
+	var i Career
+	err := row.Scan(
+		&i.Jobid,
+		&i.Company,
+		&i.Position,
+		&i.Jobtype,
+		&i.Description,
+		&i.Startdate,
+		&i.Enddate,
+		&i.Version,
+		&i.DeletedAt,
+		&i.CompanyID,
+		&i.SalaryMin,
+		&i.SalaryMax,
+		&i.SalaryCurrency,
+		&i.PayPeriod,
+		&i.Equity,
+		&i.SalaryVisible,
+		&i.Locations,
+		&i.RemotePolicy,
+		&i.RemoteCountries,
+		&i.Seniority,
+		&i.ClosedAt,
+		&i.PublishAt,
+		&i.PublishedAt,
+		&i.PublishedSeq,
+		&i.DescriptionHTML,
+		&i.DescriptionExcerpt,
+	)
+	return i, err
+}
+
+const getDeletedUserByIdForUpdate = `-- name: GetDeletedUserByIdForUpdate :one
+SELECT userid, username, email, phonenumber, password, role, createdat, updatedat, deleted_at FROM users
+WHERE userid = $1 AND deleted_at IS NOT NULL LIMIT 1
+FOR UPDATE
+`
+
+func (q *Queries) GetDeletedUserByIdForUpdate(ctx context.Context, userid int64) (User, error) {
+	row := q.db.QueryRow(ctx, getDeletedUserByIdForUpdate, userid)
+	var i User
+	err := row.Scan(
+		&i.Userid,
+		&i.Username,
+		&i.Email,
+		&i.Phonenumber,
+		&i.Password,
+		&i.Role,
+		&i.Createdat,
+		&i.Updatedat,
+		&i.DeletedAt,
+	)
+	return i, err
+}
+
+const getLastPublishedSeq = `-- name: GetLastPublishedSeq :one
+SELECT COALESCE(max(published_seq), 0)::bigint FROM career
+`
+
+func (q *Queries) GetLastPublishedSeq(ctx context.Context) (int64, error) {
+	row := q.db.QueryRow(ctx, getLastPublishedSeq)
+	var column_1 int64
+	err := row.Scan(&column_1)
+	return column_1, err
+}
+
+const getLatestDeletedProfileByUserIdForUpdate = `-- name: GetLatestDeletedProfileByUserIdForUpdate :one
+SELECT profileid, userid, fullname, age, gender, address, phonenumber, version, deleted_at, locations, remote_preference, expected_salary, salary_currency, years_experience FROM profile
+WHERE userid = $1 AND deleted_at IS NOT NULL
+ORDER BY deleted_at DESC, profileid DESC
+LIMIT 1
+FOR UPDATE
+`
+
+func (q *Queries) GetLatestDeletedProfileByUserIdForUpdate(ctx context.Context, userid int64) (Profile, error) {
+	row := q.db.QueryRow(ctx, getLatestDeletedProfileByUserIdForUpdate, userid)
+	var i Profile
+	err := row.Scan(
+		&i.Profileid,
+		&i.Userid,
+		&i.Fullname,
+		&i.Age,
+		&i.Gender,
+		&i.Address,
+		&i.Phonenumber,
+		&i.Version,
+		&i.DeletedAt,
+		&i.Locations,
+		&i.RemotePreference,
+		&i.ExpectedSalary,
+		&i.SalaryCurrency,
+		&i.YearsExperience,
+	)
+	return i, err
+}
+
+const getProfileByuserId = `-- name: GetProfileByuserId :one
+SELECT profileid, userid, fullname, age, gender, address, phonenumber, version, deleted_at, locations, remote_preference, expected_salary, salary_currency, years_experience FROM profile
+WHERE userid = $1 AND deleted_at IS NULL LIMIT 1
+`
+
+func (q *Queries) GetProfileByuserId(ctx context.Context, userid int64) (Profile, error) {
+	row := q.db.QueryRow(ctx, getProfileByuserId, userid)
+	var i Profile
+	err := row.Scan(
+		&i.Profileid,
+		&i.Userid,
+		&i.Fullname,
+		&i.Age,
+		&i.Gender,
+		&i.Address,
+		&i.Phonenumber,
+		&i.Version,
+		&i.DeletedAt,
+		&i.Locations,
+		&i.RemotePreference,
+		&i.ExpectedSalary,
+		&i.SalaryCurrency,
+		&i.YearsExperience,
+	)
+	return i, err
+}
+
+const getProfileByuserIdForUpdate = `-- name: GetProfileByuserIdForUpdate :one
+SELECT profileid, userid, fullname, age, gender, address, phonenumber, version, deleted_at, locations, remote_preference, expected_salary, salary_currency, years_experience FROM profile
+WHERE userid = $1 AND deleted_at IS NULL LIMIT 1
+FOR UPDATE
+`
+
+func (q *Queries) GetProfileByuserIdForUpdate(ctx context.Context, userid int64) (Profile, error) {
+	row := q.db.QueryRow(ctx, getProfileByuserIdForUpdate, userid)
+	var i Profile
+	err := row.Scan(
+		&i.Profileid,
+		&i.Userid,
+		&i.Fullname,
+		&i.Age,
+		&i.Gender,
+		&i.Address,
+		&i.Phonenumber,
+		&i.Version,
+		&i.DeletedAt,
+		&i.Locations,
+		&i.RemotePreference,
+		&i.ExpectedSalary,
+		&i.SalaryCurrency,
+		&i.YearsExperience,
+	)
+	return i, err
+}
+
+const getSavedCareerForUpdate = `-- name: GetSavedCareerForUpdate :one
+SELECT userid, jobid, note, list_name, saved_at FROM saved_careers
+WHERE userid = $1 AND jobid = $2
+FOR UPDATE
+`
+
+type GetSavedCareerForUpdateParams struct {
+	Userid int64 `json:"userid"`
+	Jobid  int64 `json:"jobid"`
+}
+
+func (q *Queries) GetSavedCareerForUpdate(ctx context.Context, arg GetSavedCareerForUpdateParams) (SavedCareer, error) {
+	row := q.db.QueryRow(ctx, getSavedCareerForUpdate, arg.Userid, arg.Jobid)
+	var i SavedCareer
+	err := row.Scan(
+		&i.Userid,
+		&i.Jobid,
+		&i.Note,
+		&i.ListName,
+		&i.SavedAt,
+	)
+	return i, err
+}
+
+const getSavedSearchByToken = `-- name: GetSavedSearchByToken :one
+SELECT id, userid, name, keywords, jobtype, near, radius_km, salary_min, currency, alerts, unsubscribe_token, last_published_seq, created_at FROM saved_searches
+WHERE unsubscribe_token = $1
+`
+
+func (q *Queries) GetSavedSearchByToken(ctx context.Context, unsubscribeToken string) (SavedSearch, error) {
+	row := q.db.QueryRow(ctx, getSavedSearchByToken, unsubscribeToken)
+	var i SavedSearch
+	err := row.Scan(
+		&i.ID,
+		&i.Userid,
+		&i.Name,
+		&i.Keywords,
+		&i.Jobtype,
+		&i.Near,
+		&i.RadiusKm,
+		&i.SalaryMin,
+		&i.Currency,
+		&i.Alerts,
+		&i.UnsubscribeToken,
+		&i.LastPublishedSeq,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const getSavedSearchByTokenForUpdate = `-- name: GetSavedSearchByTokenForUpdate :one
+SELECT id, userid, name, keywords, jobtype, near, radius_km, salary_min, currency, alerts, unsubscribe_token, last_published_seq, created_at FROM saved_searches
+WHERE unsubscribe_token = $1
+FOR UPDATE
+`
+
+func (q *Queries) GetSavedSearchByTokenForUpdate(ctx context.Context, unsubscribeToken string) (SavedSearch, error) {
+	row := q.db.QueryRow(ctx, getSavedSearchByTokenForUpdate, unsubscribeToken)
+	var i SavedSearch
+	err := row.Scan(
+		&i.ID,
+		&i.Userid,
+		&i.Name,
+		&i.Keywords,
+		&i.Jobtype,
+		&i.Near,
+		&i.RadiusKm,
+		&i.SalaryMin,
+		&i.Currency,
+		&i.Alerts,
+		&i.UnsubscribeToken,
+		&i.LastPublishedSeq,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const getSavedSearchForUpdate = `-- name: GetSavedSearchForUpdate :one
+SELECT id, userid, name, keywords, jobtype, near, radius_km, salary_min, currency, alerts, unsubscribe_token, last_published_seq, created_at FROM saved_searches
+WHERE id = $1 AND userid = $2
+FOR UPDATE
+`
+
+type GetSavedSearchForUpdateParams struct {
+	ID     int64 `json:"id"`
+	Userid int64 `json:"userid"`
+}
+
+func (q *Queries) GetSavedSearchForUpdate(ctx context.Context, arg GetSavedSearchForUpdateParams) (SavedSearch, error) {
+	row := q.db.QueryRow(ctx, getSavedSearchForUpdate, arg.ID, arg.Userid)
+	var i SavedSearch
+	err := row.Scan(
+		&i.ID,
+		&i.Userid,
+		&i.Name,
+		&i.Keywords,
+		&i.Jobtype,
+		&i.Near,
+		&i.RadiusKm,
+		&i.SalaryMin,
+		&i.Currency,
+		&i.Alerts,
+		&i.UnsubscribeToken,
+		&i.LastPublishedSeq,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const getSkill = `-- name: GetSkill :one
+SELECT id, name, created_at FROM skills
+WHERE id = $1
+`
+
+func (q *Queries) GetSkill(ctx context.Context, id int64) (Skill, error) {
+	row := q.db.QueryRow(ctx, getSkill, id)
+	var i Skill
+	err := row.Scan(&i.ID, &i.Name, &i.CreatedAt)
+	return i, err
+}
+
+const getUserByEmail = `-- name: GetUserByEmail :one
+SELECT userid, username, email, phonenumber, password, role, createdat, updatedat, deleted_at FROM users
+WHERE email = $1 AND deleted_at IS NULL LIMIT 1
+`
+
+func (q *Queries) GetUserByEmail(ctx context.Context, email string) (User, error) {
+	row := q.db.QueryRow(ctx, getUserByEmail, email)
+	var i User
+	err := row.Scan(
+		&i.Userid,
+		&i.Username,
+		&i.Email,
+		&i.Phonenumber,
+		&i.Password,
+		&i.Role,
+		&i.Createdat,
+		&i.Updatedat,
+		&i.DeletedAt,
+	)
+	return i, err
+}
+
+const getUserByIdForUpdate = `-- name: GetUserByIdForUpdate :one
+SELECT userid, username, email, phonenumber, password, role, createdat, updatedat, deleted_at FROM users
+WHERE userid = $1 AND deleted_at IS NULL LIMIT 1
+FOR UPDATE
+`
+
+func (q *Queries) GetUserByIdForUpdate(ctx context.Context, userid int64) (User, error) {
+	row := q.db.QueryRow(ctx, getUserByIdForUpdate, userid)
+	var i User
+	err := row.Scan(
+		&i.Userid,
+		&i.Username,
+		&i.Email,
+		&i.Phonenumber,
+		&i.Password,
+		&i.Role,
+		&i.Createdat,
+		&i.Updatedat,
+		&i.DeletedAt,
+	)
+	return i, err
+}
+
+const getUserByPhoneNumber = `-- name: GetUserByPhoneNumber :one
+SELECT userid, username, email, phonenumber, password, role, createdat, updatedat, deleted_at FROM users
+WHERE phonenumber = $1 AND deleted_at IS NULL LIMIT 1
+`
+
+func (q *Queries) GetUserByPhoneNumber(ctx context.Context, phonenumber string) (User, error) {
+	row := q.db.QueryRow(ctx, getUserByPhoneNumber, phonenumber)
+	var i User
+	err := row.Scan(
+		&i.Userid,
+		&i.Username,
+		&i.Email,
+		&i.Phonenumber,
+		&i.Password,
+		&i.Role,
+		&i.Createdat,
+		&i.Updatedat,
+		&i.DeletedAt,
+	)
+	return i, err
+}
+
+const getallusersEmail = `-- name: GetallusersEmail :many
+SELECT email FROM users WHERE role = 'user' AND deleted_at IS NULL
+`
+
+func (q *Queries) GetallusersEmail(ctx context.Context) ([]string, error) {
+	rows, err := q.db.Query(ctx, getallusersEmail)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []string
+	for rows.Next() {
+		var email string
+		if err := rows.Scan(&email); err != nil {
+			return nil, err
+		}
+		items = append(items, email)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const hasExchangeRate = `-- name: HasExchangeRate :one
+SELECT EXISTS (SELECT 1 FROM exchange_rates WHERE currency = $1)
+`
+
+func (q *Queries) HasExchangeRate(ctx context.Context, currency string) (bool, error) {
+	row := q.db.QueryRow(ctx, hasExchangeRate, currency)
+	var exists bool
+	err := row.Scan(&exists)
+	return exists, err
+}
+
+const isCompanyRecruiter = `-- name: IsCompanyRecruiter :one
+SELECT EXISTS (
+    SELECT 1 FROM company_recruiters
+    JOIN users ON users.userid = company_recruiters.userid
+    WHERE company_recruiters.company_id = $1 AND users.email = $2 AND users.deleted_at IS NULL
+)
+`
+
+type IsCompanyRecruiterParams struct {
+	CompanyID int64  `json:"company_id"`
+	Email     string `json:"email"`
+}
+
+func (q *Queries) IsCompanyRecruiter(ctx context.Context, arg IsCompanyRecruiterParams) (bool, error) {
+	row := q.db.QueryRow(ctx, isCompanyRecruiter, arg.CompanyID, arg.Email)
+	var exists bool
+	err := row.Scan(&exists)
+	return exists, err
+}
+
+const listAdmins = `-- name: ListAdmins :many
+SELECT userid, email FROM users
+WHERE role = 'admin' AND deleted_at IS NULL
+ORDER BY userid
+`
+
+type ListAdminsRow struct {
+	Userid int64  `json:"userid"`
+	Email  string `json:"email"`
+}
+
+func (q *Queries) ListAdmins(ctx context.Context) ([]ListAdminsRow, error) {
+	rows, err := q.db.Query(ctx, listAdmins)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListAdminsRow
+	for rows.Next() {
+		var i ListAdminsRow
+		if err := rows.Scan(&i.Userid, &i.Email); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listAlertingSearches = `-- name: ListAlertingSearches :many
+SELECT saved_searches.id, saved_searches.userid, saved_searches.name, saved_searches.keywords, saved_searches.jobtype, saved_searches.near, saved_searches.radius_km, saved_searches.salary_min, saved_searches.currency, saved_searches.alerts, saved_searches.unsubscribe_token, saved_searches.last_published_seq, saved_searches.created_at, users.username, users.email
+FROM saved_searches
+JOIN users ON users.userid = saved_searches.userid
+WHERE saved_searches.alerts AND users.deleted_at IS NULL
+ORDER BY saved_searches.userid, saved_searches.id
+`
+
+type ListAlertingSearchesRow struct {
+	SavedSearch SavedSearch `json:"saved_search"`
+	Username    string      `json:"username"`
+	Email       string      `json:"email"`
+}
+
+// ListAlertingSearches lists the searches with alerts on of the users who
+// are not deleted, grouped by user
+func (q *Queries) ListAlertingSearches(ctx context.Context) ([]ListAlertingSearchesRow, error) {
+	rows, err := q.db.Query(ctx, listAlertingSearches)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListAlertingSearchesRow
+	for rows.Next() {
+		var i ListAlertingSearchesRow
+		if err := rows.Scan(
+			&i.SavedSearch.ID,
+			&i.SavedSearch.Userid,
+			&i.SavedSearch.Name,
+			&i.SavedSearch.Keywords,
+			&i.SavedSearch.Jobtype,
+			&i.SavedSearch.Near,
+			&i.SavedSearch.RadiusKm,
+			&i.SavedSearch.SalaryMin,
+			&i.SavedSearch.Currency,
+			&i.SavedSearch.Alerts,
+			&i.SavedSearch.UnsubscribeToken,
+			&i.SavedSearch.LastPublishedSeq,
+			&i.SavedSearch.CreatedAt,
+			&i.Username,
+			&i.Email,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listAuditEvents = `-- name: ListAuditEvents :many
+SELECT id, occurred_at, actor_email, actor_role, action, resource_type, resource_id, changes, request_id, ip FROM audit_events
+WHERE ($1::text IS NULL OR actor_email = $1)
+  AND ($2::text IS NULL OR resource_type = $2)
+  AND ($3::bigint IS NULL OR resource_id = $3)
+  AND ($4::timestamptz IS NULL OR occurred_at >= $4)
+  AND ($5::timestamptz IS NULL OR occurred_at < $5)
+  AND ($6::bigint IS NULL OR id < $6)
+ORDER BY id DESC
+LIMIT $7
+`
+
+type ListAuditEventsParams struct {
+	Actor        sql.NullString `json:"actor"`
+	ResourceType sql.NullString `json:"resource_type"`
+	ResourceID   sql.NullInt64  `json:"resource_id"`
+	Since        *time.Time     `json:"since"`
+	Until        *time.Time     `json:"until"`
+	BeforeID     sql.NullInt64  `json:"before_id"`
+	MaxRows      int32          `json:"max_rows"`
+}
+
+func (q *Queries) ListAuditEvents(ctx context.Context, arg ListAuditEventsParams) ([]AuditEvent, error) {
+	rows, err := q.db.Query(ctx, listAuditEvents,
+		arg.Actor,
+		arg.ResourceType,
+		arg.ResourceID,
+		arg.Since,
+		arg.Until,
+		arg.BeforeID,
+		arg.MaxRows,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []AuditEvent
+	for rows.Next() {
+		var i AuditEvent
+		if err := rows.Scan(
+			&i.ID,
+			&i.OccurredAt,
+			&i.ActorEmail,
+			&i.ActorRole,
+			&i.Action,
+			&i.ResourceType,
+			&i.ResourceID,
+			&i.Changes,
+			&i.RequestID,
+			&i.IP,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listCareerRevisions = `-- name: ListCareerRevisions :many
+SELECT jobid, revision, company, position, jobtype, description, startdate, enddate, author_email, created_at, salary_min, salary_max, salary_currency, pay_period, equity, salary_visible, locations, remote_policy, remote_countries, seniority FROM career_revisions
+WHERE jobid = $1
+  AND ($2::timestamptz IS NULL OR created_at <= $2)
+ORDER BY revision DESC
+`
+
+type ListCareerRevisionsParams struct {
+	Jobid int64      `json:"jobid"`
+	At    *time.Time `json:"at"`
+}
+
+func (q *Queries) ListCareerRevisions(ctx context.Context, arg ListCareerRevisionsParams) ([]CareerRevision, error) {
+	rows, err := q.db.Query(ctx, listCareerRevisions, arg.Jobid, arg.At)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []CareerRevision
+	for rows.Next() {
+		var i CareerRevision
+		if err := rows.Scan(
+			&i.Jobid,
+			&i.Revision,
+			&i.Company,
+			&i.Position,
+			&i.Jobtype,
+			&i.Description,
+			&i.Startdate,
+			&i.Enddate,
+			&i.AuthorEmail,
+			&i.CreatedAt,
+			&i.SalaryMin,
+			&i.SalaryMax,
+			&i.SalaryCurrency,
+			&i.PayPeriod,
+			&i.Equity,
+			&i.SalaryVisible,
+			&i.Locations,
+			&i.RemotePolicy,
+			&i.RemoteCountries,
+			&i.Seniority,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listCareerSkills = `-- name: ListCareerSkills :many
+SELECT career_skills.skill_id, skills.name, career_skills.required, career_skills.min_years
+FROM career_skills
+JOIN skills ON skills.id = career_skills.skill_id
+WHERE career_skills.jobid = $1
+ORDER BY career_skills.required DESC, lower(skills.name)
+`
+
+type ListCareerSkillsRow struct {
+	SkillID  int64  `json:"skill_id"`
+	Name     string `json:"name"`
+	Required bool   `json:"required"`
+	MinYears *int16 `json:"min_years"`
+}
+
+func (q *Queries) ListCareerSkills(ctx context.Context, jobid int64) ([]ListCareerSkillsRow, error) {
+	rows, err := q.db.Query(ctx, listCareerSkills, jobid)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListCareerSkillsRow
+	for rows.Next() {
+		var i ListCareerSkillsRow
+		if err := rows.Scan(
+			&i.SkillID,
+			&i.Name,
+			&i.Required,
+			&i.MinYears,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listCareers = `-- name: ListCareers :many
+SELECT jobid, company, position, jobtype, description, startdate, enddate, version, deleted_at, company_id, salary_min, salary_max, salary_currency, pay_period, equity, salary_visible, locations, remote_policy, remote_countries, seniority, closed_at, publish_at, published_at, published_seq, description_html, description_excerpt FROM career
+WHERE deleted_at IS NULL
+  AND ($1::bigint IS NULL
+       OR (salary_visible AND yearly_salary(salary_max, pay_period, salary_currency, $2::text) >= $1))
+  AND ($3::bigint IS NULL
+       OR (salary_visible AND yearly_salary(salary_min, pay_period, salary_currency, $2::text) <= $3))
+  AND ($4::float8 IS NULL
+       OR EXISTS (SELECT 1 FROM jsonb_to_recordset(career.locations) AS location(lat float8, lon float8)
+                  WHERE haversine_km(location.lat, location.lon, $4, $5::float8) <= $6::float8))
+  AND NOT EXISTS (SELECT 1 FROM unnest($7::text[]) AS keyword
+                  WHERE strpos(lower(position || ' ' || company || ' ' || description), lower(keyword)) = 0)
+  AND ($8::text IS NULL OR lower(jobtype) = lower($8))
+  AND ($9::bigint IS NULL OR published_seq > $9)
+  AND ($10::bool OR enddate >= current_date)
+  AND ($11::bool OR published_at IS NOT NULL)
+  AND (COALESCE(cardinality($12::bigint[]), 0) = 0
+       OR (SELECT count(*) FROM career_skills
+           WHERE career_skills.jobid = career.jobid AND career_skills.skill_id = ANY($12::bigint[]))
+          >= CASE WHEN $13::bool THEN cardinality($12::bigint[]) ELSE 1 END)
+ORDER BY jobid
+`
+
+type ListCareersParams struct {
+	SalaryMin          sql.NullInt64   `json:"salary_min"`
+	Currency           string          `json:"currency"`
+	SalaryMax          sql.NullInt64   `json:"salary_max"`
+	NearLat            sql.NullFloat64 `json:"near_lat"`
+	NearLon            sql.NullFloat64 `json:"near_lon"`
+	RadiusKm           float64         `json:"radius_km"`
+	Keywords           []string        `json:"keywords"`
+	Jobtype            sql.NullString  `json:"jobtype"`
+	PublishedAfter     sql.NullInt64   `json:"published_after"`
+	IncludeExpired     bool            `json:"include_expired"`
+	IncludeUnpublished bool            `json:"include_unpublished"`
+	SkillIds           []int64         `json:"skill_ids"`
+	AllSkills          bool            `json:"all_skills"`
+}
+
+// ListCareers filters by salary, comparing yearly amounts in the currency
+// given. Careers without a salary, or whose salary is hidden, never match a
+// salary filter. Given a point, only careers with a location within
+// radius_km of it are listed. Given skills, only careers tagged with all of
+// them when all_skills is set, or with any of them otherwise, are listed.
+// Every keyword must appear in the position, company or description, and
+// published_after limits the list to careers published after that
+// published_seq. Careers whose enddate has passed are left out unless
+// include_expired is set, and careers not published yet unless
+// include_unpublished is.
+func (q *Queries) ListCareers(ctx context.Context, arg ListCareersParams) ([]Career, error) {
+	rows, err := q.db.Query(ctx, listCareers,
+		arg.SalaryMin,
+		arg.Currency,
+		arg.SalaryMax,
+		arg.NearLat,
+		arg.NearLon,
+		arg.RadiusKm,
+		arg.Keywords,
+		arg.Jobtype,
+		arg.PublishedAfter,
+		arg.IncludeExpired,
+		arg.IncludeUnpublished,
+		arg.SkillIds,
+		arg.AllSkills,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Career
+	for rows.Next() {
+		var i Career
+		if err := rows.Scan(
+			&i.Jobid,
+			&i.Company,
+			&i.Position,
+			&i.Jobtype,
+			&i.Description,
+			&i.Startdate,
+			&i.Enddate,
+			&i.Version,
+			&i.DeletedAt,
+			&i.CompanyID,
+			&i.SalaryMin,
+			&i.SalaryMax,
+			&i.SalaryCurrency,
+			&i.PayPeriod,
+			&i.Equity,
+			&i.SalaryVisible,
+			&i.Locations,
+			&i.RemotePolicy,
+			&i.RemoteCountries,
+			&i.Seniority,
+			&i.ClosedAt,
+			&i.PublishAt,
+			&i.PublishedAt,
+			&i.PublishedSeq,
+			&i.DescriptionHTML,
+			&i.DescriptionExcerpt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listCompanies = `-- name: ListCompanies :many
+SELECT id, name, slug, website, logo_url, description, created_at FROM companies
+ORDER BY name, id
+`
+
+func (q *Queries) ListCompanies(ctx context.Context) ([]Company, error) {
+	rows, err := q.db.Query(ctx, listCompanies)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Company
+	for rows.Next() {
+		var i Company
+		if err := rows.Scan(
+			&i.ID,
+			&i.Name,
+			&i.Slug,
+			&i.Website,
+			&i.LogoURL,
+			&i.Description,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listCompanyRecruiters = `-- name: ListCompanyRecruiters :many
+SELECT users.userid, users.username, users.email, users.phonenumber, users.password, users.role, users.createdat, users.updatedat, users.deleted_at FROM users
+JOIN company_recruiters ON company_recruiters.userid = users.userid
+WHERE company_recruiters.company_id = $1 AND users.deleted_at IS NULL
+ORDER BY users.userid
+`
+
+func (q *Queries) ListCompanyRecruiters(ctx context.Context, companyID int64) ([]User, error) {
+	rows, err := q.db.Query(ctx, listCompanyRecruiters, companyID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []User
+	for rows.Next() {
+		var i User
+		if err := rows.Scan(
+			&i.Userid,
+			&i.Username,
+			&i.Email,
+			&i.Phonenumber,
+			&i.Password,
+			&i.Role,
+			&i.Createdat,
+			&i.Updatedat,
+			&i.DeletedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listDeletedCareers = `-- name: ListDeletedCareers :many
+SELECT jobid, company, position, jobtype, description, startdate, enddate, version, deleted_at, company_id, salary_min, salary_max, salary_currency, pay_period, equity, salary_visible, locations, remote_policy, remote_countries, seniority, closed_at, publish_at, published_at, published_seq, description_html, description_excerpt FROM career
+WHERE deleted_at IS NOT NULL
+ORDER BY deleted_at DESC, jobid
+`
+
+func (q *Queries) ListDeletedCareers(ctx context.Context) ([]Career, error) {
+	rows, err := q.db.Query(ctx, listDeletedCareers)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Career
+	for rows.Next() {
+		var i Career
+		if err := rows.Scan(
+			&i.Jobid,
+			&i.Company,
+			&i.Position,
+			&i.Jobtype,
+			&i.Description,
+			&i.Startdate,
+			&i.Enddate,
+			&i.Version,
+			&i.DeletedAt,
+			&i.CompanyID,
+			&i.SalaryMin,
+			&i.SalaryMax,
+			&i.SalaryCurrency,
+			&i.PayPeriod,
+			&i.Equity,
+			&i.SalaryVisible,
+			&i.Locations,
+			&i.RemotePolicy,
+			&i.RemoteCountries,
+			&i.Seniority,
+			&i.ClosedAt,
+			&i.PublishAt,
+			&i.PublishedAt,
+			&i.PublishedSeq,
+			&i.DescriptionHTML,
+			&i.DescriptionExcerpt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listDeletedProfiles = `-- name: ListDeletedProfiles :many
+SELECT profileid, userid, fullname, age, gender, address, phonenumber, version, deleted_at, locations, remote_preference, expected_salary, salary_currency, years_experience FROM profile
+WHERE deleted_at IS NOT NULL
+ORDER BY deleted_at DESC, profileid
+`
+
+func (q *Queries) ListDeletedProfiles(ctx context.Context) ([]Profile, error) {
+	rows, err := q.db.Query(ctx, listDeletedProfiles)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Profile
+	for rows.Next() {
+		var i Profile
+		if err := rows.Scan(
+			&i.Profileid,
+			&i.Userid,
+			&i.Fullname,
+			&i.Age,
+			&i.Gender,
+			&i.Address,
+			&i.Phonenumber,
+			&i.Version,
+			&i.DeletedAt,
+			&i.Locations,
+			&i.RemotePreference,
+			&i.ExpectedSalary,
+			&i.SalaryCurrency,
+			&i.YearsExperience,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listDeletedUsers = `-- name: ListDeletedUsers :many
+SELECT userid, username, email, phonenumber, password, role, createdat, updatedat, deleted_at FROM users
+WHERE deleted_at IS NOT NULL
+ORDER BY deleted_at DESC, userid
+`
+
+func (q *Queries) ListDeletedUsers(ctx context.Context) ([]User, error) {
+	rows, err := q.db.Query(ctx, listDeletedUsers)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []User
+	for rows.Next() {
+		var i User
+		if err := rows.Scan(
+			&i.Userid,
+			&i.Username,
+			&i.Email,
+			&i.Phonenumber,
+			&i.Password,
+			&i.Role,
+			&i.Createdat,
+			&i.Updatedat,
+			&i.DeletedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listExchangeRates = `-- name: ListExchangeRates :many
+SELECT currency, units_per_usd::float8 AS units_per_usd FROM exchange_rates
+ORDER BY currency
+`
+
+type ListExchangeRatesRow struct {
+	Currency    string  `json:"currency"`
+	UnitsPerUsd float64 `json:"units_per_usd"`
+}
+
+func (q *Queries) ListExchangeRates(ctx context.Context) ([]ListExchangeRatesRow, error) {
+	rows, err := q.db.Query(ctx, listExchangeRates)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListExchangeRatesRow
+	for rows.Next() {
+		var i ListExchangeRatesRow
+		if err := rows.Scan(&i.Currency, &i.UnitsPerUsd); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listExpiringCareers = `-- name: ListExpiringCareers :many
+SELECT jobid, company, position, jobtype, description, startdate, enddate, version, deleted_at, company_id, salary_min, salary_max, salary_currency, pay_period, equity, salary_visible, locations, remote_policy, remote_countries, seniority, closed_at, publish_at, published_at, published_seq, description_html, description_excerpt FROM career
+WHERE deleted_at IS NULL AND enddate >= current_date AND enddate <= $1::date
+  AND NOT EXISTS (SELECT 1 FROM career_expiry_reminders
+                  WHERE career_expiry_reminders.jobid = career.jobid
+                    AND career_expiry_reminders.userid = $2
+                    AND career_expiry_reminders.enddate = career.enddate)
+ORDER BY enddate, jobid
+`
+
+type ListExpiringCareersParams struct {
+	Until  time.Time `json:"until"`
+	Userid int64     `json:"userid"`
+}
+
+// ListExpiringCareers lists the open careers ending by until that the admin
+// has not been reminded of for their current enddate
+func (q *Queries) ListExpiringCareers(ctx context.Context, arg ListExpiringCareersParams) ([]Career, error) {
+	rows, err := q.db.Query(ctx, listExpiringCareers, arg.Until, arg.Userid)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Career
+	for rows.Next() {
+		var i Career
+		if err := rows.Scan(
+			&i.Jobid,
+			&i.Company,
+			&i.Position,
+			&i.Jobtype,
+			&i.Description,
+			&i.Startdate,
+			&i.Enddate,
+			&i.Version,
+			&i.DeletedAt,
+			&i.CompanyID,
+			&i.SalaryMin,
+			&i.SalaryMax,
+			&i.SalaryCurrency,
+			&i.PayPeriod,
+			&i.Equity,
+			&i.SalaryVisible,
+			&i.Locations,
+			&i.RemotePolicy,
+			&i.RemoteCountries,
+			&i.Seniority,
+			&i.ClosedAt,
+			&i.PublishAt,
+			&i.PublishedAt,
+			&i.PublishedSeq,
+			&i.DescriptionHTML,
+			&i.DescriptionExcerpt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listOpenCareers = `-- name: ListOpenCareers :many
+SELECT jobid, company, position, jobtype, description, startdate, enddate, version, deleted_at, company_id, salary_min, salary_max, salary_currency, pay_period, equity, salary_visible, locations, remote_policy, remote_countries, seniority, closed_at, publish_at, published_at, published_seq, description_html, description_excerpt FROM career
+WHERE deleted_at IS NULL AND enddate >= current_date AND published_at IS NOT NULL
+ORDER BY jobid
+`
+
+func (q *Queries) ListOpenCareers(ctx context.Context) ([]Career, error) {
+	rows, err := q.db.Query(ctx, listOpenCareers)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Career
+	for rows.Next() {
+		var i Career
+		if err := rows.Scan(
+			&i.Jobid,
+			&i.Company,
+			&i.Position,
+			&i.Jobtype,
+			&i.Description,
+			&i.Startdate,
+			&i.Enddate,
+			&i.Version,
+			&i.DeletedAt,
+			&i.CompanyID,
+			&i.SalaryMin,
+			&i.SalaryMax,
+			&i.SalaryCurrency,
+			&i.PayPeriod,
+			&i.Equity,
+			&i.SalaryVisible,
+			&i.Locations,
+			&i.RemotePolicy,
+			&i.RemoteCountries,
+			&i.Seniority,
+			&i.ClosedAt,
+			&i.PublishAt,
+			&i.PublishedAt,
+			&i.PublishedSeq,
+			&i.DescriptionHTML,
+			&i.DescriptionExcerpt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listOpenCareersByCompany = `-- name: ListOpenCareersByCompany :many
+SELECT jobid, company, position, jobtype, description, startdate, enddate, version, deleted_at, company_id, salary_min, salary_max, salary_currency, pay_period, equity, salary_visible, locations, remote_policy, remote_countries, seniority, closed_at, publish_at, published_at, published_seq, description_html, description_excerpt FROM career
+WHERE company_id = $1 AND deleted_at IS NULL AND enddate >= current_date AND published_at IS NOT NULL
+ORDER BY startdate, jobid
+`
+
+func (q *Queries) ListOpenCareersByCompany(ctx context.Context, companyID int64) ([]Career, error) {
+	rows, err := q.db.Query(ctx, listOpenCareersByCompany, companyID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Career
+	for rows.Next() {
+		var i Career
+		if err := rows.Scan(
+			&i.Jobid,
+			&i.Company,
+			&i.Position,
+			&i.Jobtype,
+			&i.Description,
+			&i.Startdate,
+			&i.Enddate,
+			&i.Version,
+			&i.DeletedAt,
+			&i.CompanyID,
+			&i.SalaryMin,
+			&i.SalaryMax,
+			&i.SalaryCurrency,
+			&i.PayPeriod,
+			&i.Equity,
+			&i.SalaryVisible,
+			&i.Locations,
+			&i.RemotePolicy,
+			&i.RemoteCountries,
+			&i.Seniority,
+			&i.ClosedAt,
+			&i.PublishAt,
+			&i.PublishedAt,
+			&i.PublishedSeq,
+			&i.DescriptionHTML,
+			&i.DescriptionExcerpt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listProfileSkills = `-- name: ListProfileSkills :many
+SELECT profile_skills.skill_id, skills.name, profile_skills.proficiency
+FROM profile_skills
+JOIN skills ON skills.id = profile_skills.skill_id
+WHERE profile_skills.profileid = $1
+ORDER BY lower(skills.name)
+`
+
+type ListProfileSkillsRow struct {
+	SkillID     int64  `json:"skill_id"`
+	Name        string `json:"name"`
+	Proficiency string `json:"proficiency"`
+}
+
+func (q *Queries) ListProfileSkills(ctx context.Context, profileid int64) ([]ListProfileSkillsRow, error) {
+	rows, err := q.db.Query(ctx, listProfileSkills, profileid)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListProfileSkillsRow
+	for rows.Next() {
+		var i ListProfileSkillsRow
+		if err := rows.Scan(&i.SkillID, &i.Name, &i.Proficiency); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listSavedCareerLists = `-- name: ListSavedCareerLists :many
+SELECT list_name::text AS name, count(*) AS careers
+FROM saved_careers
+WHERE userid = $1 AND list_name IS NOT NULL
+GROUP BY list_name
+ORDER BY lower(list_name), list_name
+`
+
+type ListSavedCareerListsRow struct {
+	Name    string `json:"name"`
+	Careers int64  `json:"careers"`
+}
+
+func (q *Queries) ListSavedCareerLists(ctx context.Context, userid int64) ([]ListSavedCareerListsRow, error) {
+	rows, err := q.db.Query(ctx, listSavedCareerLists, userid)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListSavedCareerListsRow
+	for rows.Next() {
+		var i ListSavedCareerListsRow
+		if err := rows.Scan(&i.Name, &i.Careers); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listSavedCareers = `-- name: ListSavedCareers :many
+SELECT saved_careers.userid, saved_careers.jobid, saved_careers.note, saved_careers.list_name, saved_careers.saved_at, career.jobid, career.company, career.position, career.jobtype, career.description, career.startdate, career.enddate, career.version, career.deleted_at, career.company_id, career.salary_min, career.salary_max, career.salary_currency, career.pay_period, career.equity, career.salary_visible, career.locations, career.remote_policy, career.remote_countries, career.seniority, career.closed_at, career.publish_at, career.published_at, career.published_seq, career.description_html, career.description_excerpt
+FROM saved_careers
+JOIN career ON career.jobid = saved_careers.jobid
+WHERE saved_careers.userid = $1
+  AND ($2::text IS NULL OR lower(saved_careers.list_name) = lower($2))
+ORDER BY saved_careers.saved_at DESC, saved_careers.jobid
+`
+
+type ListSavedCareersParams struct {
+	Userid   int64          `json:"userid"`
+	ListName sql.NullString `json:"list_name"`
+}
+
+type ListSavedCareersRow struct {
+	SavedCareer SavedCareer `json:"saved_career"`
+	Career      Career      `json:"career"`
+}
+
+// ListSavedCareers lists a user's saved careers, deleted ones included,
+// newest save first. Given a list name, only the careers in that list are
+// listed, ignoring case.
+func (q *Queries) ListSavedCareers(ctx context.Context, arg ListSavedCareersParams) ([]ListSavedCareersRow, error) {
+	rows, err := q.db.Query(ctx, listSavedCareers, arg.Userid, arg.ListName)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListSavedCareersRow
+	for rows.Next() {
+		var i ListSavedCareersRow
+		if err := rows.Scan(
+			&i.SavedCareer.Userid,
+			&i.SavedCareer.Jobid,
+			&i.SavedCareer.Note,
+			&i.SavedCareer.ListName,
+			&i.SavedCareer.SavedAt,
+			&i.Career.Jobid,
+			&i.Career.Company,
+			&i.Career.Position,
+			&i.Career.Jobtype,
+			&i.Career.Description,
+			&i.Career.Startdate,
+			&i.Career.Enddate,
+			&i.Career.Version,
+			&i.Career.DeletedAt,
+			&i.Career.CompanyID,
+			&i.Career.SalaryMin,
+			&i.Career.SalaryMax,
+			&i.Career.SalaryCurrency,
+			&i.Career.PayPeriod,
+			&i.Career.Equity,
+			&i.Career.SalaryVisible,
+			&i.Career.Locations,
+			&i.Career.RemotePolicy,
+			&i.Career.RemoteCountries,
+			&i.Career.Seniority,
+			&i.Career.ClosedAt,
+			&i.Career.PublishAt,
+			&i.Career.PublishedAt,
+			&i.Career.PublishedSeq,
+			&i.Career.DescriptionHTML,
+			&i.Career.DescriptionExcerpt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listSavedSearches = `-- name: ListSavedSearches :many
+SELECT id, userid, name, keywords, jobtype, near, radius_km, salary_min, currency, alerts, unsubscribe_token, last_published_seq, created_at FROM saved_searches
+WHERE userid = $1
+ORDER BY id
+`
+
+func (q *Queries) ListSavedSearches(ctx context.Context, userid int64) ([]SavedSearch, error) {
+	rows, err := q.db.Query(ctx, listSavedSearches, userid)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []SavedSearch
+	for rows.Next() {
+		var i SavedSearch
+		if err := rows.Scan(
+			&i.ID,
+			&i.Userid,
+			&i.Name,
+			&i.Keywords,
+			&i.Jobtype,
+			&i.Near,
+			&i.RadiusKm,
+			&i.SalaryMin,
+			&i.Currency,
+			&i.Alerts,
+			&i.UnsubscribeToken,
+			&i.LastPublishedSeq,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listSkillAliases = `-- name: ListSkillAliases :many
+SELECT alias FROM skill_aliases
+WHERE skill_id = $1
+ORDER BY alias
+`
+
+func (q *Queries) ListSkillAliases(ctx context.Context, skillID int64) ([]string, error) {
+	rows, err := q.db.Query(ctx, listSkillAliases, skillID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []string
+	for rows.Next() {
+		var alias string
+		if err := rows.Scan(&alias); err != nil {
+			return nil, err
+		}
+		items = append(items, alias)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listSkills = `-- name: ListSkills :many
+SELECT skills.id, skills.name,
+    ARRAY(SELECT skill_aliases.alias FROM skill_aliases WHERE skill_aliases.skill_id = skills.id ORDER BY skill_aliases.alias)::text[] AS aliases
+FROM skills
+WHERE starts_with(lower(skills.name), lower($1))
+   OR EXISTS (SELECT 1 FROM skill_aliases
+              WHERE skill_aliases.skill_id = skills.id AND starts_with(skill_aliases.alias, lower($1)))
+ORDER BY lower(skills.name), skills.id
+LIMIT $2
+`
+
+type ListSkillsParams struct {
+	Prefix  string `json:"prefix"`
+	MaxRows int32  `json:"max_rows"`
+}
+
+type ListSkillsRow struct {
+	ID      int64    `json:"id"`
+	Name    string   `json:"name"`
+	Aliases []string `json:"aliases"`
+}
+
+// ListSkills lists the skills whose name or one of whose aliases starts with
+// prefix, ignoring case
+func (q *Queries) ListSkills(ctx context.Context, arg ListSkillsParams) ([]ListSkillsRow, error) {
+	rows, err := q.db.Query(ctx, listSkills, arg.Prefix, arg.MaxRows)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListSkillsRow
+	for rows.Next() {
+		var i ListSkillsRow
+		if err := rows.Scan(&i.ID, &i.Name, &i.Aliases); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listSkillsOfCareers = `-- name: ListSkillsOfCareers :many
+SELECT career_skills.jobid, career_skills.skill_id, skills.name, career_skills.required, career_skills.min_years
+FROM career_skills
+JOIN skills ON skills.id = career_skills.skill_id
+WHERE career_skills.jobid = ANY($1::bigint[])
+ORDER BY career_skills.jobid, career_skills.required DESC, lower(skills.name)
+`
+
+type ListSkillsOfCareersRow struct {
+	Jobid    int64  `json:"jobid"`
+	SkillID  int64  `json:"skill_id"`
+	Name     string `json:"name"`
+	Required bool   `json:"required"`
+	MinYears *int16 `json:"min_years"`
+}
+
+func (q *Queries) ListSkillsOfCareers(ctx context.Context, jobids []int64) ([]ListSkillsOfCareersRow, error) {
+	rows, err := q.db.Query(ctx, listSkillsOfCareers, jobids)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListSkillsOfCareersRow
+	for rows.Next() {
+		var i ListSkillsOfCareersRow
+		if err := rows.Scan(
+			&i.Jobid,
+			&i.SkillID,
+			&i.Name,
+			&i.Required,
+			&i.MinYears,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listSkillsOfProfiles = `-- name: ListSkillsOfProfiles :many
+SELECT profile_skills.profileid, profile_skills.skill_id, skills.name, profile_skills.proficiency
+FROM profile_skills
+JOIN skills ON skills.id = profile_skills.skill_id
+WHERE profile_skills.profileid = ANY($1::bigint[])
+ORDER BY profile_skills.profileid, lower(skills.name)
+`
+
+type ListSkillsOfProfilesRow struct {
+	Profileid   int64  `json:"profileid"`
+	SkillID     int64  `json:"skill_id"`
+	Name        string `json:"name"`
+	Proficiency string `json:"proficiency"`
+}
+
+func (q *Queries) ListSkillsOfProfiles(ctx context.Context, profileids []int64) ([]ListSkillsOfProfilesRow, error) {
+	rows, err := q.db.Query(ctx, listSkillsOfProfiles, profileids)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListSkillsOfProfilesRow
+	for rows.Next() {
+		var i ListSkillsOfProfilesRow
+		if err := rows.Scan(
+			&i.Profileid,
+			&i.SkillID,
+			&i.Name,
+			&i.Proficiency,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const publishDueCareers = `-- name: PublishDueCareers :many
+UPDATE career
+SET published_at = now(), published_seq = nextval('career_published_seq'), version = version + 1
+WHERE deleted_at IS NULL AND published_at IS NULL AND publish_at <= now()
+RETURNING jobid, company, position, jobtype, description, startdate, enddate, version, deleted_at, company_id, salary_min, salary_max, salary_currency, pay_period, equity, salary_visible, locations, remote_policy, remote_countries, seniority, closed_at, publish_at, published_at, published_seq, description_html, description_excerpt
+`
+
+// PublishDueCareers publishes the careers whose publish_at has come
+func (q *Queries) PublishDueCareers(ctx context.Context) ([]Career, error) {
+	rows, err := q.db.Query(ctx, publishDueCareers)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Career
+	for rows.Next() {
+		var i Career
+		if err := rows.Scan(
+			&i.Jobid,
+			&i.Company,
+			&i.Position,
+			&i.Jobtype,
+			&i.Description,
+			&i.Startdate,
+			&i.Enddate,
+			&i.Version,
+			&i.DeletedAt,
+			&i.CompanyID,
+			&i.SalaryMin,
+			&i.SalaryMax,
+			&i.SalaryCurrency,
+			&i.PayPeriod,
+			&i.Equity,
+			&i.SalaryVisible,
+			&i.Locations,
+			&i.RemotePolicy,
+			&i.RemoteCountries,
+			&i.Seniority,
+			&i.ClosedAt,
+			&i.PublishAt,
+			&i.PublishedAt,
+			&i.PublishedSeq,
+			&i.DescriptionHTML,
+			&i.DescriptionExcerpt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const purgeCareers = `-- name: PurgeCareers :execrows
+DELETE FROM career
+WHERE deleted_at < $1::timestamptz
+`
+
+func (q *Queries) PurgeCareers(ctx context.Context, before time.Time) (int64, error) {
+	result, err := q.db.Exec(ctx, purgeCareers, before)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const purgeProfiles = `-- name: PurgeProfiles :execrows
+DELETE FROM profile
+WHERE deleted_at < $1::timestamptz
+`
+
+func (q *Queries) PurgeProfiles(ctx context.Context, before time.Time) (int64, error) {
+	result, err := q.db.Exec(ctx, purgeProfiles, before)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const purgeUsers = `-- name: PurgeUsers :execrows
+DELETE FROM users
+WHERE deleted_at < $1::timestamptz
+`
+
+func (q *Queries) PurgeUsers(ctx context.Context, before time.Time) (int64, error) {
+	result, err := q.db.Exec(ctx, purgeUsers, before)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const removeCompanyRecruiter = `-- name: RemoveCompanyRecruiter :execrows
+DELETE FROM company_recruiters
+WHERE company_id = $1 AND userid = $2
+`
+
+type RemoveCompanyRecruiterParams struct {
+	CompanyID int64 `json:"company_id"`
+	Userid    int64 `json:"userid"`
+}
+
+func (q *Queries) RemoveCompanyRecruiter(ctx context.Context, arg RemoveCompanyRecruiterParams) (int64, error) {
+	result, err := q.db.Exec(ctx, removeCompanyRecruiter, arg.CompanyID, arg.Userid)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const restoreCareerByJobId = `-- name: RestoreCareerByJobId :one
+UPDATE career
+SET deleted_at = NULL, version = version + 1
+WHERE jobid = $1 AND deleted_at IS NOT NULL
+RETURNING jobid, company, position, jobtype, description, startdate, enddate, version, deleted_at, company_id, salary_min, salary_max, salary_currency, pay_period, equity, salary_visible, locations, remote_policy, remote_countries, seniority, closed_at, publish_at, published_at, published_seq, description_html, description_excerpt
+`
+
+func (q *Queries) RestoreCareerByJobId(ctx context.Context, jobid int64) (Career, error) {
+	row := q.db.QueryRow(ctx, restoreCareerByJobId, jobid)
+	var i Career
+	err := row.Scan(
+		&i.Jobid,
+		&i.Company,
+		&i.Position,
+		&i.Jobtype,
+		&i.Description,
+		&i.Startdate,
+		&i.Enddate,
+		&i.Version,
+		&i.DeletedAt,
+		&i.CompanyID,
+		&i.SalaryMin,
+		&i.SalaryMax,
+		&i.SalaryCurrency,
+		&i.PayPeriod,
+		&i.Equity,
+		&i.SalaryVisible,
+		&i.Locations,
+		&i.RemotePolicy,
+		&i.RemoteCountries,
+		&i.Seniority,
+		&i.ClosedAt,
+		&i.PublishAt,
+		&i.PublishedAt,
+		&i.PublishedSeq,
+		&i.DescriptionHTML,
+		&i.DescriptionExcerpt,
+	)
+	return i, err
+}
+
+const restoreProfileById = `-- name: RestoreProfileById :one
+UPDATE profile
+SET deleted_at = NULL, version = version + 1
+WHERE profileid = $1 AND deleted_at IS NOT NULL
+RETURNING profileid, userid, fullname, age, gender, address, phonenumber, version, deleted_at, locations, remote_preference, expected_salary, salary_currency, years_experience
+`
+
+func (q *Queries) RestoreProfileById(ctx context.Context, profileid int64) (Profile, error) {
+	row := q.db.QueryRow(ctx, restoreProfileById, profileid)
+	var i Profile
+	err := row.Scan(
+		&i.Profileid,
 		&i.Userid,
 		&i.Fullname,
 		&i.Age,
 		&i.Gender,
 		&i.Address,
 		&i.Phonenumber,
+		&i.Version,
+		&i.DeletedAt,
+		&i.Locations,
+		&i.RemotePreference,
+		&i.ExpectedSalary,
+		&i.SalaryCurrency,
+		&i.YearsExperience,
 	)
 	return i, err
 }
 
-const getUserByEmail = `-- name: GetUserByEmail :one
-SELECT userid, username, email, phonenumber, password, role, createdat, updatedat FROM users
-WHERE email = $1 LIMIT 1
+const restoreProfilesOfUser = `-- name: RestoreProfilesOfUser :exec
+UPDATE profile
+SET deleted_at = NULL, version = version + 1
+WHERE profile.userid = $1 AND profile.deleted_at = (SELECT u.deleted_at FROM users u WHERE u.userid = $1)
 `
 
-func (q *Queries) GetUserByEmail(ctx context.Context, email string) (User, error) {
-	row := q.db.QueryRow(ctx, getUserByEmail, email)
+func (q *Queries) RestoreProfilesOfUser(ctx context.Context, userid int64) error {
+	_, err := q.db.Exec(ctx, restoreProfilesOfUser, userid)
+	return err
+}
+
+const restoreUserById = `-- name: RestoreUserById :one
+UPDATE users
+SET deleted_at = NULL
+WHERE userid = $1 AND deleted_at IS NOT NULL
+RETURNING userid, username, email, phonenumber, password, role, createdat, updatedat, deleted_at
+`
+
+func (q *Queries) RestoreUserById(ctx context.Context, userid int64) (User, error) {
+	row := q.db.QueryRow(ctx, restoreUserById, userid)
 	var i User
 	err := row.Scan(
 		&i.Userid,
@@ -281,69 +2885,117 @@ func (q *Queries) GetUserByEmail(ctx context.Context, email string) (User, error
 		&i.Role,
 		&i.Createdat,
 		&i.Updatedat,
+		&i.DeletedAt,
 	)
 	return i, err
 }
 
-const getUserByPhoneNumber = `-- name: GetUserByPhoneNumber :one
-SELECT userid, username, email, phonenumber, password, role, createdat, updatedat FROM users
-WHERE phonenumber = $1 LIMIT 1
+const saveExpiryReminder = `-- name: SaveExpiryReminder :exec
+INSERT INTO career_expiry_reminders (jobid, userid, enddate)
+VALUES ($1, $2, $3)
+ON CONFLICT (jobid, userid) DO UPDATE SET enddate = EXCLUDED.enddate, sent_at = now()
 `
 
-func (q *Queries) GetUserByPhoneNumber(ctx context.Context, phonenumber string) (User, error) {
-	row := q.db.QueryRow(ctx, getUserByPhoneNumber, phonenumber)
-	var i User
+type SaveExpiryReminderParams struct {
+	Jobid   int64     `json:"jobid"`
+	Userid  int64     `json:"userid"`
+	Enddate time.Time `json:"enddate"`
+}
+
+func (q *Queries) SaveExpiryReminder(ctx context.Context, arg SaveExpiryReminderParams) error {
+	_, err := q.db.Exec(ctx, saveExpiryReminder, arg.Jobid, arg.Userid, arg.Enddate)
+	return err
+}
+
+const touchCareer = `-- name: TouchCareer :one
+UPDATE career
+SET version = version + 1
+WHERE jobid = $1 AND deleted_at IS NULL
+RETURNING jobid, company, position, jobtype, description, startdate, enddate, version, deleted_at, company_id, salary_min, salary_max, salary_currency, pay_period, equity, salary_visible, locations, remote_policy, remote_countries, seniority, closed_at, publish_at, published_at, published_seq, description_html, description_excerpt
+`
+
+// TouchCareer bumps the version of a career whose skills changed
+func (q *Queries) TouchCareer(ctx context.Context, jobid int64) (Career, error) {
+	row := q.db.QueryRow(ctx, touchCareer, jobid)
+	var i Career
 	err := row.Scan(
-		&i.Userid,
-		&i.Username,
-		&i.Email,
-		&i.Phonenumber,
-		&i.Password,
-		&i.Role,
-		&i.Createdat,
-		&i.Updatedat,
+		&i.Jobid,
+		&i.Company,
+		&i.Position,
+		&i.Jobtype,
+		&i.Description,
+		&i.Startdate,
+		&i.Enddate,
+		&i.Version,
+		&i.DeletedAt,
+		&i.CompanyID,
+		&i.SalaryMin,
+		&i.SalaryMax,
+		&i.SalaryCurrency,
+		&i.PayPeriod,
+		&i.Equity,
+		&i.SalaryVisible,
+		&i.Locations,
+		&i.RemotePolicy,
+		&i.RemoteCountries,
+		&i.Seniority,
+		&i.ClosedAt,
+		&i.PublishAt,
+		&i.PublishedAt,
+		&i.PublishedSeq,
+		&i.DescriptionHTML,
+		&i.DescriptionExcerpt,
 	)
 	return i, err
 }
 
-const getallusersEmail = `-- name: GetallusersEmail :many
-
-SELECT email FROM users WHERE role = 'user'
+const touchProfile = `-- name: TouchProfile :one
+UPDATE profile
+SET version = version + 1
+WHERE profileid = $1 AND deleted_at IS NULL
+RETURNING profileid, userid, fullname, age, gender, address, phonenumber, version, deleted_at, locations, remote_preference, expected_salary, salary_currency, years_experience
 `
 
-func (q *Queries) GetallusersEmail(ctx context.Context) ([]string, error) {
-	rows, err := q.db.Query(ctx, getallusersEmail)
-	if err != nil {
-		return nil, err
-	}
-	defer rows.Close()
-	var items []string
-	for rows.Next() {
-		var email string
-		if err := rows.Scan(&email); err != nil {
-			return nil, err
-		}
-		items = append(items, email)
-	}
-	if err := rows.Err(); err != nil {
-		return nil, err
-	}
-	return items, nil
+// TouchProfile bumps the version of a profile whose skills changed
+func (q *Queries) TouchProfile(ctx context.Context, profileid int64) (Profile, error) {
+	row := q.db.QueryRow(ctx, touchProfile, profileid)
+	var i Profile
+	err := row.Scan(
+		&i.Profileid,
+		&i.Userid,
+		&i.Fullname,
+		&i.Age,
+		&i.Gender,
+		&i.Address,
+		&i.Phonenumber,
+		&i.Version,
+		&i.DeletedAt,
+		&i.Locations,
+		&i.RemotePreference,
+		&i.ExpectedSalary,
+		&i.SalaryCurrency,
+		&i.YearsExperience,
+	)
+	return i, err
 }
 
 const updateCareerByJobId = `-- name: UpdateCareerByJobId :one
 UPDATE career
-SET company=$1,position=$2,jobtype=$3,description=$4
-WHERE jobid = $5
-RETURNING jobid, company, position, jobtype, description, startdate, enddate
+SET company=$1,position=$2,jobtype=$3,description=$4,company_id=$5,version=version+1,
+    description_html=$7,description_excerpt=$8
+WHERE jobid = $6 AND deleted_at IS NULL
+RETURNING jobid, company, position, jobtype, description, startdate, enddate, version, deleted_at, company_id, salary_min, salary_max, salary_currency, pay_period, equity, salary_visible, locations, remote_policy, remote_countries, seniority, closed_at, publish_at, published_at, published_seq, description_html, description_excerpt
 `
 
 type UpdateCareerByJobIdParams struct {
-	Company     string `json:"company"`
-	Position    string `json:"position"`
-	Jobtype     string `json:"jobtype"`
-	Description string `json:"description"`
-	Jobid       int64  `json:"jobid"`
+	Company            string `json:"company"`
+	Position           string `json:"position"`
+	Jobtype            string `json:"jobtype"`
+	Description        string `json:"description"`
+	CompanyID          int64  `json:"company_id"`
+	Jobid              int64  `json:"jobid"`
+	DescriptionHTML    string `json:"description_html"`
+	DescriptionExcerpt string `json:"description_excerpt"`
 }
 
 func (q *Queries) UpdateCareerByJobId(ctx context.Context, arg UpdateCareerByJobIdParams) (Career, error) {
@@ -352,7 +3004,10 @@ func (q *Queries) UpdateCareerByJobId(ctx context.Context, arg UpdateCareerByJob
 		arg.Position,
 		arg.Jobtype,
 		arg.Description,
+		arg.CompanyID,
 		arg.Jobid,
+		arg.DescriptionHTML,
+		arg.DescriptionExcerpt,
 	)
 	var i Career
 	err := row.Scan(
@@ -363,15 +3018,50 @@ func (q *Queries) UpdateCareerByJobId(ctx context.Context, arg UpdateCareerByJob
 		&i.Description,
 		&i.Startdate,
 		&i.Enddate,
+		&i.Version,
+		&i.DeletedAt,
+		&i.CompanyID,
+		&i.SalaryMin,
+		&i.SalaryMax,
+		&i.SalaryCurrency,
+		&i.PayPeriod,
+		&i.Equity,
+		&i.SalaryVisible,
+		&i.Locations,
+		&i.RemotePolicy,
+		&i.RemoteCountries,
+		&i.Seniority,
+		&i.ClosedAt,
+		&i.PublishAt,
+		&i.PublishedAt,
+		&i.PublishedSeq,
+		&i.DescriptionHTML,
+		&i.DescriptionExcerpt,
 	)
 	return i, err
 }
 
+const updateCareerImportProgress = `-- name: UpdateCareerImportProgress :exec
+UPDATE career_imports
+SET processed_rows = $2
+WHERE id = $1
+`
+
+type UpdateCareerImportProgressParams struct {
+	ID            int64 `json:"id"`
+	ProcessedRows int32 `json:"processed_rows"`
+}
+
+func (q *Queries) UpdateCareerImportProgress(ctx context.Context, arg UpdateCareerImportProgressParams) error {
+	_, err := q.db.Exec(ctx, updateCareerImportProgress, arg.ID, arg.ProcessedRows)
+	return err
+}
+
 const updateProfileByuserId = `-- name: UpdateProfileByuserId :one
 UPDATE profile
-SET FullName=$1,Age=$2,Gender=$3,Address=$4
-WHERE userid = $5
-RETURNING profileid, userid, fullname, age, gender, address, phonenumber
+SET FullName=$1,Age=$2,Gender=$3,Address=$4,version=version+1
+WHERE userid = $5 AND deleted_at IS NULL
+RETURNING profileid, userid, fullname, age, gender, address, phonenumber, version, deleted_at, locations, remote_preference, expected_salary, salary_currency, years_experience
 `
 
 type UpdateProfileByuserIdParams struct {
@@ -399,6 +3089,97 @@ func (q *Queries) UpdateProfileByuserId(ctx context.Context, arg UpdateProfileBy
 		&i.Gender,
 		&i.Address,
 		&i.Phonenumber,
+		&i.Version,
+		&i.DeletedAt,
+		&i.Locations,
+		&i.RemotePreference,
+		&i.ExpectedSalary,
+		&i.SalaryCurrency,
+		&i.YearsExperience,
+	)
+	return i, err
+}
+
+const updateSavedCareer = `-- name: UpdateSavedCareer :one
+UPDATE saved_careers
+SET note = $3, list_name = $4
+WHERE userid = $1 AND jobid = $2
+RETURNING userid, jobid, note, list_name, saved_at
+`
+
+type UpdateSavedCareerParams struct {
+	Userid   int64   `json:"userid"`
+	Jobid    int64   `json:"jobid"`
+	Note     string  `json:"note"`
+	ListName *string `json:"list_name"`
+}
+
+func (q *Queries) UpdateSavedCareer(ctx context.Context, arg UpdateSavedCareerParams) (SavedCareer, error) {
+	row := q.db.QueryRow(ctx, updateSavedCareer,
+		arg.Userid,
+		arg.Jobid,
+		arg.Note,
+		arg.ListName,
+	)
+	var i SavedCareer
+	err := row.Scan(
+		&i.Userid,
+		&i.Jobid,
+		&i.Note,
+		&i.ListName,
+		&i.SavedAt,
+	)
+	return i, err
+}
+
+const updateSavedSearch = `-- name: UpdateSavedSearch :one
+UPDATE saved_searches
+SET name = $3, keywords = $4, jobtype = $5, near = $6, radius_km = $7, salary_min = $8, currency = $9, alerts = $10
+WHERE id = $1 AND userid = $2
+RETURNING id, userid, name, keywords, jobtype, near, radius_km, salary_min, currency, alerts, unsubscribe_token, last_published_seq, created_at
+`
+
+type UpdateSavedSearchParams struct {
+	ID        int64    `json:"id"`
+	Userid    int64    `json:"userid"`
+	Name      string   `json:"name"`
+	Keywords  string   `json:"keywords"`
+	Jobtype   string   `json:"jobtype"`
+	Near      string   `json:"near"`
+	RadiusKm  *float64 `json:"radius_km"`
+	SalaryMin *int64   `json:"salary_min"`
+	Currency  *string  `json:"currency"`
+	Alerts    bool     `json:"alerts"`
+}
+
+func (q *Queries) UpdateSavedSearch(ctx context.Context, arg UpdateSavedSearchParams) (SavedSearch, error) {
+	row := q.db.QueryRow(ctx, updateSavedSearch,
+		arg.ID,
+		arg.Userid,
+		arg.Name,
+		arg.Keywords,
+		arg.Jobtype,
+		arg.Near,
+		arg.RadiusKm,
+		arg.SalaryMin,
+		arg.Currency,
+		arg.Alerts,
+	)
+	var i SavedSearch
+	err := row.Scan(
+		&i.ID,
+		&i.Userid,
+		&i.Name,
+		&i.Keywords,
+		&i.Jobtype,
+		&i.Near,
+		&i.RadiusKm,
+		&i.SalaryMin,
+		&i.Currency,
+		&i.Alerts,
+		&i.UnsubscribeToken,
+		&i.LastPublishedSeq,
+		&i.CreatedAt,
 	)
 	return i, err
 }
@@ -0,0 +1,944 @@
+package database_test
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"jobApps/apperror"
+	"jobApps/geo"
+	"jobApps/internal/database"
+	"jobApps/internal/testdb"
+	"jobApps/slug"
+
+	"github.com/jackc/pgx/v4"
+)
+
+func TestUserQueries(t *testing.T) {
+	db := testdb.New(t)
+	ctx := context.Background()
+
+	user := testdb.CreateUser(t, db.Queries, testdb.WithEmail("jane@example.com"))
+	admin := testdb.CreateUser(t, db.Queries, testdb.WithRole("admin"))
+
+	got, err := db.Queries.GetUserByEmail(ctx, "jane@example.com")
+	if err != nil || got.Userid != user.Userid {
+		t.Fatalf("GetUserByEmail = %+v, %v", got, err)
+	}
+	if !got.Createdat.Valid {
+		t.Error("createdat default was not applied")
+	}
+
+	got, err = db.Queries.GetUserByPhoneNumber(ctx, admin.Phonenumber)
+	if err != nil || got.Userid != admin.Userid {
+		t.Fatalf("GetUserByPhoneNumber = %+v, %v", got, err)
+	}
+
+	if _, err := db.Queries.GetUserByEmail(ctx, "nobody@example.com"); !errors.Is(err, pgx.ErrNoRows) {
+		t.Errorf("GetUserByEmail(unknown) error = %v, want pgx.ErrNoRows", err)
+	}
+
+	emails, err := db.Queries.GetallusersEmail(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(emails) != 1 || emails[0] != user.Email {
+		t.Errorf("GetallusersEmail = %v, want only the user role", emails)
+	}
+}
+
+func TestUserUniqueConstraintsMapToConflicts(t *testing.T) {
+	db := testdb.New(t)
+	ctx := context.Background()
+	existing := testdb.CreateUser(t, db.Queries)
+
+	tests := []struct {
+		name   string
+		params database.CreateUserParams
+		detail string
+	}{
+		{
+			name:   "email",
+			params: database.CreateUserParams{Username: "dup", Email: existing.Email, Phonenumber: "+14155559999", Password: "x", Role: "user"},
+			detail: "email ID already exist",
+		},
+		{
+			name:   "phone number",
+			params: database.CreateUserParams{Username: "dup", Email: "unique@example.com", Phonenumber: existing.Phonenumber, Password: "x", Role: "user"},
+			detail: "user's Phonenumber already exist",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// a failed statement aborts the transaction, so isolate it in a savepoint
+			tx, err := db.Tx.Begin(ctx)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer tx.Rollback(ctx)
+
+			_, err = db.Queries.WithTx(tx).CreateUser(ctx, tt.params)
+			var appErr *apperror.Error
+			if !errors.As(apperror.FromDB(err, "user"), &appErr) || appErr.Kind != apperror.KindConflict || appErr.Detail != tt.detail {
+				t.Errorf("CreateUser duplicate %s: error = %v, want conflict %q", tt.name, err, tt.detail)
+			}
+		})
+	}
+}
+
+func TestCareerQueries(t *testing.T) {
+	db := testdb.New(t)
+	ctx := context.Background()
+
+	start := time.Date(2026, time.January, 1, 0, 0, 0, 0, time.UTC)
+	career := testdb.CreateCareer(t, db.Queries, testdb.WithCompany("Acme"), testdb.WithDates(start, start.AddDate(0, 6, 0)))
+	long := testdb.CreateCareer(t, db.Queries, testdb.WithDescription("# Role\n\n"+strings.Repeat("Build services. ", 100)))
+	if !strings.HasPrefix(long.DescriptionHTML, "<h1>Role</h1>") || len(long.Description) <= 255 || long.DescriptionExcerpt == "" {
+		t.Errorf("CreateCareer with a long description = %+v", long)
+	}
+
+	got, err := db.Queries.GetCareerByJobId(ctx, career.Jobid)
+	if err != nil || got.Company != "Acme" || !got.Startdate.Equal(start) {
+		t.Fatalf("GetCareerByJobId = %+v, %v", got, err)
+	}
+
+	all, err := db.Queries.GetAllCareerDetails(ctx)
+	if err != nil || len(all) != 2 {
+		t.Fatalf("GetAllCareerDetails = %d careers, %v", len(all), err)
+	}
+
+	updated, err := db.Queries.UpdateCareerByJobId(ctx, database.UpdateCareerByJobIdParams{
+		Company:     "Acme Inc",
+		Position:    career.Position,
+		Jobtype:     "Contract",
+		Description: career.Description,
+		CompanyID:   career.CompanyID,
+		Jobid:       career.Jobid,
+
+		DescriptionHTML:    "<p>Build and run services</p>\n",
+		DescriptionExcerpt: "Build and run services",
+	})
+	if err != nil || updated.Company != "Acme Inc" || updated.Jobtype != "Contract" || updated.DescriptionExcerpt != "Build and run services" {
+		t.Fatalf("UpdateCareerByJobId = %+v, %v", updated, err)
+	}
+	patched, err := db.Queries.PatchCareer(ctx, career.Jobid, []database.Assignment{
+		{Column: "description", Value: "Run *services*"},
+		{Column: "description_html", Value: "<p>Run <em>services</em></p>\n"},
+		{Column: "description_excerpt", Value: "Run services"},
+	})
+	if err != nil || patched.DescriptionHTML != "<p>Run <em>services</em></p>\n" || patched.DescriptionExcerpt != "Run services" {
+		t.Fatalf("PatchCareer(description) = %+v, %v", patched, err)
+	}
+
+	deleted, err := db.Queries.DeleteCareerByJobId(ctx, career.Jobid)
+	if err != nil || deleted.Jobid != career.Jobid {
+		t.Fatalf("DeleteCareerByJobId = %+v, %v", deleted, err)
+	}
+	if _, err := db.Queries.GetCareerByJobId(ctx, career.Jobid); !errors.Is(err, pgx.ErrNoRows) {
+		t.Errorf("GetCareerByJobId after delete error = %v, want pgx.ErrNoRows", err)
+	}
+}
+
+func TestProfileQueries(t *testing.T) {
+	db := testdb.New(t)
+	ctx := context.Background()
+
+	owner := testdb.CreateUser(t, db.Queries)
+	profile := testdb.CreateProfile(t, db.Queries, owner, testdb.WithAge(41))
+	if profile.Userid != owner.Userid || profile.Phonenumber != owner.Phonenumber {
+		t.Fatalf("CreateProfile = %+v", profile)
+	}
+
+	got, err := db.Queries.GetProfileByuserId(ctx, owner.Userid)
+	if err != nil || got.Age != 41 {
+		t.Fatalf("GetProfileByuserId = %+v, %v", got, err)
+	}
+
+	updated, err := db.Queries.UpdateProfileByuserId(ctx, database.UpdateProfileByuserIdParams{
+		Fullname: "Renamed",
+		Age:      42,
+		Gender:   got.Gender,
+		Address:  got.Address,
+		Userid:   owner.Userid,
+	})
+	if err != nil || updated.Fullname != "Renamed" || updated.Age != 42 {
+		t.Fatalf("UpdateProfileByuserId = %+v, %v", updated, err)
+	}
+
+	all, err := db.Queries.GetAllProfileDetails(ctx)
+	if err != nil || len(all) != 1 {
+		t.Fatalf("GetAllProfileDetails = %d profiles, %v", len(all), err)
+	}
+
+	if _, err := db.Queries.DeleteProfileByUserId(ctx, owner.Userid); err != nil {
+		t.Fatalf("DeleteProfileByUserId: %v", err)
+	}
+	if _, err := db.Queries.GetProfileByuserId(ctx, owner.Userid); !errors.Is(err, pgx.ErrNoRows) {
+		t.Errorf("GetProfileByuserId after delete error = %v, want pgx.ErrNoRows", err)
+	}
+}
+
+func TestProfileRequiresExistingUser(t *testing.T) {
+	db := testdb.New(t)
+
+	_, err := db.Queries.CreateProfile(context.Background(), database.CreateProfileParams{
+		Userid: 999_999, Fullname: "Ghost", Age: 30, Gender: "other", Address: "Nowhere", Phonenumber: "+14155550000",
+		RemotePreference: "any",
+	})
+	if !apperror.IsKind(apperror.FromDB(err, "profile"), apperror.KindConflict) {
+		t.Errorf("CreateProfile for a missing user: error = %v, want a foreign key conflict", err)
+	}
+}
+
+func TestSoftDeleteQueries(t *testing.T) {
+	db := testdb.New(t)
+	ctx := context.Background()
+
+	owner := testdb.CreateUser(t, db.Queries, testdb.WithEmail("jane@example.com"))
+	testdb.CreateProfile(t, db.Queries, owner)
+
+	deleted, err := db.Queries.DeleteUserById(ctx, owner.Userid)
+	if err != nil || deleted.DeletedAt == nil {
+		t.Fatalf("DeleteUserById = %+v, %v", deleted, err)
+	}
+	if err := db.Queries.DeleteProfilesOfUser(ctx, database.DeleteProfilesOfUserParams{Userid: owner.Userid, DeletedAt: deleted.DeletedAt}); err != nil {
+		t.Fatalf("DeleteProfilesOfUser: %v", err)
+	}
+	if _, err := db.Queries.GetUserByEmail(ctx, "jane@example.com"); !errors.Is(err, pgx.ErrNoRows) {
+		t.Errorf("GetUserByEmail of a deleted user error = %v, want pgx.ErrNoRows", err)
+	}
+	if _, err := db.Queries.GetProfileByuserId(ctx, owner.Userid); !errors.Is(err, pgx.ErrNoRows) {
+		t.Errorf("GetProfileByuserId of a deleted user error = %v, want pgx.ErrNoRows", err)
+	}
+	if trashed, err := db.Queries.ListDeletedProfiles(ctx); err != nil || len(trashed) != 1 {
+		t.Fatalf("ListDeletedProfiles = %d profiles, %v", len(trashed), err)
+	}
+
+	// the partial unique index only covers active users
+	reused := testdb.CreateUser(t, db.Queries, testdb.WithEmail("jane@example.com"))
+
+	purged, err := db.Queries.PurgeUsers(ctx, time.Now().Add(time.Hour))
+	if err != nil || purged != 1 {
+		t.Fatalf("PurgeUsers = %d, %v", purged, err)
+	}
+	if trashed, err := db.Queries.ListDeletedProfiles(ctx); err != nil || len(trashed) != 0 {
+		t.Errorf("profiles left after purging their user = %d, %v", len(trashed), err)
+	}
+
+	if _, err := db.Queries.DeleteUserById(ctx, reused.Userid); err != nil {
+		t.Fatalf("DeleteUserById: %v", err)
+	}
+	testdb.CreateUser(t, db.Queries, testdb.WithEmail("jane@example.com"))
+	// last, as the failed statement aborts the test transaction
+	_, err = db.Queries.RestoreUserById(ctx, reused.Userid)
+	if !apperror.IsKind(apperror.FromDB(err, "user"), apperror.KindConflict) {
+		t.Errorf("RestoreUserById with a taken email: error = %v, want a unique conflict", err)
+	}
+}
+
+func TestCompanyQueries(t *testing.T) {
+	db := testdb.New(t)
+	ctx := context.Background()
+
+	acme := testdb.CreateCompany(t, db.Queries, "Acme")
+	// a failed statement aborts the transaction, so isolate it in a savepoint
+	tx, err := db.Tx.Begin(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = db.Queries.WithTx(tx).CreateCompany(ctx, database.CreateCompanyParams{Name: "ACME Inc", Slug: acme.Slug})
+	var appErr *apperror.Error
+	if !errors.As(apperror.FromDB(err, "company"), &appErr) || appErr.Kind != apperror.KindConflict {
+		t.Errorf("CreateCompany duplicate slug: error = %v, want a conflict", err)
+	}
+	tx.Rollback(ctx)
+
+	start := time.Now().UTC().Truncate(24*time.Hour).AddDate(0, 0, -30)
+	open := testdb.CreateCareer(t, db.Queries, testdb.WithCompany("acme"), testdb.WithDates(start, start.AddDate(1, 0, 0)))
+	testdb.CreateCareer(t, db.Queries, testdb.WithCompany("Acme"), testdb.WithDates(start, start.AddDate(0, 0, 1)))
+	if open.CompanyID != acme.ID {
+		t.Fatalf("career company = %d, want %d", open.CompanyID, acme.ID)
+	}
+	careers, err := db.Queries.ListOpenCareersByCompany(ctx, acme.ID)
+	if err != nil || len(careers) != 1 || careers[0].Jobid != open.Jobid {
+		t.Fatalf("ListOpenCareersByCompany = %+v, %v", careers, err)
+	}
+
+	recruiter := testdb.CreateUser(t, db.Queries, testdb.WithRole("recruiter"))
+	if err := db.Queries.AddCompanyRecruiter(ctx, database.AddCompanyRecruiterParams{CompanyID: acme.ID, Userid: recruiter.Userid}); err != nil {
+		t.Fatalf("AddCompanyRecruiter: %v", err)
+	}
+	member, err := db.Queries.IsCompanyRecruiter(ctx, database.IsCompanyRecruiterParams{CompanyID: acme.ID, Email: recruiter.Email})
+	if err != nil || !member {
+		t.Errorf("IsCompanyRecruiter = %v, %v, want true", member, err)
+	}
+	removed, err := db.Queries.RemoveCompanyRecruiter(ctx, database.RemoveCompanyRecruiterParams{CompanyID: acme.ID, Userid: recruiter.Userid})
+	if err != nil || removed != 1 {
+		t.Errorf("RemoveCompanyRecruiter = %d, %v", removed, err)
+	}
+}
+
+// TestCompanySlugMatchesGo checks the company_slug SQL function used to
+// deduplicate careers against slug.Company, which names new companies
+func TestCompanySlugMatchesGo(t *testing.T) {
+	db := testdb.New(t)
+
+	for _, name := range []string{"Acme", "ACME Inc", " acme ", "Acme, Inc.", "Acme Co", "Costco", "Initech Software Ltd.", "Müller & Söhne GmbH", "AT&T", "Яндекс", "ООО Ромашка", "腾讯", "!!!", "???"} {
+		var got string
+		if err := db.Tx.QueryRow(context.Background(), "SELECT company_slug($1)", name).Scan(&got); err != nil {
+			t.Fatalf("company_slug(%q): %v", name, err)
+		}
+		if want := slug.Company(name); got != want {
+			t.Errorf("company_slug(%q) = %q, slug.Company = %q", name, got, want)
+		}
+	}
+}
+
+func TestCareerSalaryQueries(t *testing.T) {
+	db := testdb.New(t)
+	ctx := context.Background()
+
+	yearly := testdb.CreateCareer(t, db.Queries, testdb.WithSalary(100000, 150000, "USD", "yearly"))
+	monthly := testdb.CreateCareer(t, db.Queries, testdb.WithSalary(5000, 6000, "EUR", "monthly"))
+	testdb.CreateCareer(t, db.Queries)
+
+	tests := []struct {
+		params database.ListCareersParams
+		want   []int64
+	}{
+		{database.ListCareersParams{Currency: "USD"}, nil},
+		{database.ListCareersParams{Currency: "USD", SalaryMin: sql.NullInt64{Int64: 100000, Valid: true}}, []int64{yearly.Jobid}},
+		{database.ListCareersParams{Currency: "USD", SalaryMax: sql.NullInt64{Int64: 80000, Valid: true}}, []int64{monthly.Jobid}},
+		{database.ListCareersParams{Currency: "EUR", SalaryMin: sql.NullInt64{Int64: 60000, Valid: true}}, []int64{yearly.Jobid, monthly.Jobid}},
+	}
+	for _, tt := range tests {
+		careers, err := db.Queries.ListCareers(ctx, tt.params)
+		if err != nil {
+			t.Fatalf("ListCareers(%+v): %v", tt.params, err)
+		}
+		if tt.want == nil {
+			if len(careers) != 3 {
+				t.Errorf("ListCareers without a filter = %d careers, want 3", len(careers))
+			}
+			continue
+		}
+		var got []int64
+		for _, career := range careers {
+			got = append(got, career.Jobid)
+		}
+		if fmt.Sprint(got) != fmt.Sprint(tt.want) {
+			t.Errorf("ListCareers(%+v) = %v, want %v", tt.params, got, tt.want)
+		}
+	}
+
+	if known, err := db.Queries.HasExchangeRate(ctx, "NOK"); err != nil || known {
+		t.Errorf("HasExchangeRate(NOK) = %v, %v, want false", known, err)
+	}
+}
+
+func TestCareerLocationQueries(t *testing.T) {
+	db := testdb.New(t)
+	ctx := context.Background()
+
+	at := func(city string, lat, lon float64) geo.Location {
+		return geo.Location{City: city, Lat: &lat, Lon: &lon}
+	}
+	berlin := testdb.CreateCareer(t, db.Queries, testdb.WithLocations(at("Berlin", 52.52, 13.405)))
+	both := testdb.CreateCareer(t, db.Queries, testdb.WithLocations(at("Paris", 48.8566, 2.3522), at("Potsdam", 52.3906, 13.0645)))
+	remote := testdb.CreateCareer(t, db.Queries, testdb.WithRemote("remote", "DE", "FR"))
+
+	tests := []struct {
+		lat, lon, radius float64
+		want             []int64
+	}{
+		{52.52, 13.405, 50, []int64{berlin.Jobid, both.Jobid}},
+		{52.52, 13.405, 10, []int64{berlin.Jobid}},
+		{48.86, 2.35, 5, []int64{both.Jobid}},
+		{0, 0, 1000, nil},
+	}
+	for _, tt := range tests {
+		params := database.ListCareersParams{
+			Currency: "USD",
+			NearLat:  sql.NullFloat64{Float64: tt.lat, Valid: true},
+			NearLon:  sql.NullFloat64{Float64: tt.lon, Valid: true},
+			RadiusKm: tt.radius,
+		}
+		careers, err := db.Queries.ListCareers(ctx, params)
+		if err != nil {
+			t.Fatalf("ListCareers(%+v): %v", params, err)
+		}
+		var got []int64
+		for _, career := range careers {
+			got = append(got, career.Jobid)
+		}
+		if fmt.Sprint(got) != fmt.Sprint(tt.want) {
+			t.Errorf("ListCareers near %v,%v within %v km = %v, want %v", tt.lat, tt.lon, tt.radius, got, tt.want)
+		}
+	}
+
+	got, err := db.Queries.GetCareerByJobId(ctx, both.Jobid)
+	if err != nil || !got.Locations.Equal(both.Locations) || len(got.Locations) != 2 {
+		t.Errorf("GetCareerByJobId locations = %+v, %v", got.Locations, err)
+	}
+	if got, err := db.Queries.GetCareerByJobId(ctx, remote.Jobid); err != nil || got.RemotePolicy != "remote" || fmt.Sprint(got.RemoteCountries) != "[DE FR]" || got.Locations == nil {
+		t.Errorf("GetCareerByJobId remote = %+v, %v", got, err)
+	}
+
+	// allowed countries only make sense for careers that can be done remotely
+	_, err = db.Queries.PatchCareer(ctx, berlin.Jobid, []database.Assignment{{Column: "remote_countries", Value: []string{"DE"}}})
+	if !apperror.IsKind(apperror.FromDB(err, "career"), apperror.KindValidation) {
+		t.Errorf("PatchCareer onsite with remote_countries = %v, want a check violation", err)
+	}
+}
+
+func TestSkillQueries(t *testing.T) {
+	db := testdb.New(t)
+	ctx := context.Background()
+
+	// seeded by 0009_skills.sql
+	goSkill, err := db.Queries.FindSkill(ctx, "GoLang")
+	if err != nil || goSkill.Name != "Go" {
+		t.Fatalf("FindSkill(GoLang) = %+v, %v, want Go", goSkill, err)
+	}
+	postgres, err := db.Queries.FindSkill(ctx, "postgresql")
+	if err != nil || postgres.Name != "PostgreSQL" {
+		t.Fatalf("FindSkill(postgresql) = %+v, %v", postgres, err)
+	}
+	if _, err := db.Queries.FindSkill(ctx, "COBOL"); !errors.Is(err, pgx.ErrNoRows) {
+		t.Errorf("FindSkill(COBOL) error = %v, want pgx.ErrNoRows", err)
+	}
+
+	skills, err := db.Queries.ListSkills(ctx, database.ListSkillsParams{Prefix: "K8", MaxRows: 10})
+	if err != nil || len(skills) != 1 || skills[0].Name != "Kubernetes" || fmt.Sprint(skills[0].Aliases) != "[k8s]" {
+		t.Errorf("ListSkills(K8) = %+v, %v", skills, err)
+	}
+
+	withGo := testdb.CreateCareer(t, db.Queries)
+	withBoth := testdb.CreateCareer(t, db.Queries)
+	testdb.CreateCareer(t, db.Queries)
+	three := int16(3)
+	for _, link := range []database.AddCareerSkillParams{
+		{Jobid: withGo.Jobid, SkillID: goSkill.ID, Required: true},
+		{Jobid: withBoth.Jobid, SkillID: postgres.ID, Required: true},
+		{Jobid: withBoth.Jobid, SkillID: goSkill.ID, Required: false, MinYears: &three},
+	} {
+		if err := db.Queries.AddCareerSkill(ctx, link); err != nil {
+			t.Fatalf("AddCareerSkill(%+v): %v", link, err)
+		}
+	}
+	links, err := db.Queries.ListCareerSkills(ctx, withBoth.Jobid)
+	if err != nil || len(links) != 2 || links[0].Name != "PostgreSQL" || links[1].MinYears == nil || *links[1].MinYears != 3 {
+		t.Errorf("ListCareerSkills = %+v, %v, want required PostgreSQL then Go", links, err)
+	}
+
+	tests := []struct {
+		ids  []int64
+		all  bool
+		want []int64
+	}{
+		{[]int64{goSkill.ID}, true, []int64{withGo.Jobid, withBoth.Jobid}},
+		{[]int64{goSkill.ID, postgres.ID}, true, []int64{withBoth.Jobid}},
+		{[]int64{goSkill.ID, postgres.ID}, false, []int64{withGo.Jobid, withBoth.Jobid}},
+	}
+	for _, tt := range tests {
+		careers, err := db.Queries.ListCareers(ctx, database.ListCareersParams{Currency: "USD", SkillIds: tt.ids, AllSkills: tt.all})
+		if err != nil {
+			t.Fatalf("ListCareers(%v): %v", tt.ids, err)
+		}
+		var got []int64
+		for _, career := range careers {
+			got = append(got, career.Jobid)
+		}
+		if fmt.Sprint(got) != fmt.Sprint(tt.want) {
+			t.Errorf("ListCareers(skills %v, all %v) = %v, want %v", tt.ids, tt.all, got, tt.want)
+		}
+	}
+	if careers, err := db.Queries.ListCareers(ctx, database.ListCareersParams{Currency: "USD", SkillIds: []int64{}}); err != nil || len(careers) != 3 {
+		t.Errorf("ListCareers without skills = %d careers, %v, want 3", len(careers), err)
+	}
+
+	touched, err := db.Queries.TouchCareer(ctx, withGo.Jobid)
+	if err != nil || touched.Version != withGo.Version+1 {
+		t.Errorf("TouchCareer version = %d, %v, want %d", touched.Version, err, withGo.Version+1)
+	}
+	if err := db.Queries.DeleteCareerSkills(ctx, withBoth.Jobid); err != nil {
+		t.Fatalf("DeleteCareerSkills: %v", err)
+	}
+	if links, err := db.Queries.ListCareerSkills(ctx, withBoth.Jobid); err != nil || len(links) != 0 {
+		t.Errorf("ListCareerSkills after delete = %+v, %v", links, err)
+	}
+
+	user := testdb.CreateUser(t, db.Queries)
+	profile := testdb.CreateProfile(t, db.Queries, user)
+	if err := db.Queries.AddProfileSkill(ctx, database.AddProfileSkillParams{Profileid: profile.Profileid, SkillID: goSkill.ID, Proficiency: "expert"}); err != nil {
+		t.Fatalf("AddProfileSkill: %v", err)
+	}
+	if links, err := db.Queries.ListProfileSkills(ctx, profile.Profileid); err != nil || len(links) != 1 || links[0].Proficiency != "expert" {
+		t.Errorf("ListProfileSkills = %+v, %v", links, err)
+	}
+
+	// names are unique ignoring case; this aborts the transaction, so it goes last
+	_, err = db.Queries.CreateSkill(ctx, "GO")
+	var appErr *apperror.Error
+	if !errors.As(apperror.FromDB(err, "skill"), &appErr) || appErr.Kind != apperror.KindConflict {
+		t.Errorf("CreateSkill(GO) = %v, want a conflict", err)
+	}
+}
+
+func TestRecommendationQueries(t *testing.T) {
+	db := testdb.New(t)
+	ctx := context.Background()
+
+	rates, err := db.Queries.ListExchangeRates(ctx)
+	if err != nil || len(rates) == 0 {
+		t.Fatalf("ListExchangeRates = %+v, %v", rates, err)
+	}
+	for _, rate := range rates {
+		if rate.Currency == "EUR" && rate.UnitsPerUsd != 0.92 {
+			t.Errorf("EUR rate = %v, want 0.92", rate.UnitsPerUsd)
+		}
+	}
+
+	open := testdb.CreateCareer(t, db.Queries, testdb.WithSeniority("senior"))
+	closed := testdb.CreateCareer(t, db.Queries, testdb.WithDates(time.Now().AddDate(-1, 0, 0), time.Now().AddDate(0, 0, -1)))
+	deleted := testdb.CreateCareer(t, db.Queries)
+	if _, err := db.Queries.DeleteCareerByJobId(ctx, deleted.Jobid); err != nil {
+		t.Fatalf("DeleteCareerByJobId: %v", err)
+	}
+	careers, err := db.Queries.ListOpenCareers(ctx)
+	if err != nil {
+		t.Fatalf("ListOpenCareers: %v", err)
+	}
+	for _, career := range careers {
+		if career.Jobid == closed.Jobid || career.Jobid == deleted.Jobid {
+			t.Errorf("ListOpenCareers lists career %d, which is closed or deleted", career.Jobid)
+		}
+	}
+	if len(careers) == 0 || careers[len(careers)-1].Jobid != open.Jobid || *careers[len(careers)-1].Seniority != "senior" {
+		t.Errorf("ListOpenCareers = %+v, want career %d last", careers, open.Jobid)
+	}
+
+	goSkill, err := db.Queries.FindSkill(ctx, "Go")
+	if err != nil {
+		t.Fatalf("FindSkill(Go): %v", err)
+	}
+	if err := db.Queries.AddCareerSkill(ctx, database.AddCareerSkillParams{Jobid: open.Jobid, SkillID: goSkill.ID, Required: true}); err != nil {
+		t.Fatalf("AddCareerSkill: %v", err)
+	}
+	careerSkills, err := db.Queries.ListSkillsOfCareers(ctx, []int64{open.Jobid, closed.Jobid})
+	if err != nil || len(careerSkills) != 1 || careerSkills[0].Jobid != open.Jobid || careerSkills[0].Name != "Go" {
+		t.Errorf("ListSkillsOfCareers = %+v, %v", careerSkills, err)
+	}
+
+	berlin, _ := geo.Geocode(geo.Location{City: "Berlin"})
+	profile := testdb.CreateProfile(t, db.Queries, testdb.CreateUser(t, db.Queries),
+		testdb.WithPreferences("remote", berlin), testdb.WithExpectedSalary(80000, "EUR"), testdb.WithExperience(4))
+	if profile.RemotePreference != "remote" || len(profile.Locations) != 1 || *profile.ExpectedSalary != 80000 || *profile.YearsExperience != 4 {
+		t.Errorf("CreateProfile with preferences = %+v", profile)
+	}
+	if err := db.Queries.AddProfileSkill(ctx, database.AddProfileSkillParams{Profileid: profile.Profileid, SkillID: goSkill.ID, Proficiency: "advanced"}); err != nil {
+		t.Fatalf("AddProfileSkill: %v", err)
+	}
+	profileSkills, err := db.Queries.ListSkillsOfProfiles(ctx, []int64{profile.Profileid})
+	if err != nil || len(profileSkills) != 1 || profileSkills[0].Proficiency != "advanced" {
+		t.Errorf("ListSkillsOfProfiles = %+v, %v", profileSkills, err)
+	}
+
+	patched, err := db.Queries.PatchProfileByUserId(ctx, profile.Userid, []database.Assignment{
+		{Column: "remote_preference", Value: "any"},
+		{Column: "years_experience", Value: (*int16)(nil)},
+	})
+	if err != nil || patched.RemotePreference != "any" || patched.YearsExperience != nil {
+		t.Errorf("PatchProfileByUserId = %+v, %v", patched, err)
+	}
+
+	// an expected salary needs its currency; this aborts the transaction, so
+	// it goes last
+	_, err = db.Queries.PatchProfileByUserId(ctx, profile.Userid, []database.Assignment{{Column: "salary_currency", Value: (*string)(nil)}})
+	if !apperror.IsKind(apperror.FromDB(err, "profile"), apperror.KindValidation) {
+		t.Errorf("clearing the salary currency alone: error = %v, want a validation error", err)
+	}
+}
+
+func TestSavedCareerQueries(t *testing.T) {
+	db := testdb.New(t)
+	ctx := context.Background()
+
+	user := testdb.CreateUser(t, db.Queries)
+	other := testdb.CreateUser(t, db.Queries)
+	first := testdb.CreateCareer(t, db.Queries)
+	second := testdb.CreateCareer(t, db.Queries)
+	toApply := "To apply"
+
+	saved, err := db.Queries.CreateSavedCareer(ctx, database.CreateSavedCareerParams{Userid: user.Userid, Jobid: first.Jobid, Note: "ask about visas", ListName: &toApply})
+	if err != nil || saved.Note != "ask about visas" || *saved.ListName != toApply || saved.SavedAt.IsZero() {
+		t.Fatalf("CreateSavedCareer = %+v, %v", saved, err)
+	}
+	for _, arg := range []database.CreateSavedCareerParams{
+		{Userid: user.Userid, Jobid: second.Jobid},
+		{Userid: other.Userid, Jobid: first.Jobid, ListName: &toApply},
+	} {
+		if _, err := db.Queries.CreateSavedCareer(ctx, arg); err != nil {
+			t.Fatalf("CreateSavedCareer(%+v): %v", arg, err)
+		}
+	}
+	_, err = db.Queries.CreateSavedCareer(ctx, database.CreateSavedCareerParams{Userid: user.Userid, Jobid: first.Jobid})
+	if !apperror.IsKind(apperror.FromDB(err, "saved career"), apperror.KindConflict) {
+		t.Errorf("saving a career twice: error = %v, want a conflict", err)
+	}
+
+	if _, err := db.Queries.DeleteCareerByJobId(ctx, second.Jobid); err != nil {
+		t.Fatalf("DeleteCareerByJobId: %v", err)
+	}
+	rows, err := db.Queries.ListSavedCareers(ctx, database.ListSavedCareersParams{Userid: user.Userid})
+	if err != nil || len(rows) != 2 || rows[0].Career.Jobid != second.Jobid || rows[0].Career.DeletedAt == nil {
+		t.Errorf("ListSavedCareers = %+v, %v; want the deleted career first", rows, err)
+	}
+	rows, err = db.Queries.ListSavedCareers(ctx, database.ListSavedCareersParams{Userid: user.Userid, ListName: sql.NullString{String: "TO APPLY", Valid: true}})
+	if err != nil || len(rows) != 1 || rows[0].SavedCareer.Jobid != first.Jobid {
+		t.Errorf("ListSavedCareers(TO APPLY) = %+v, %v", rows, err)
+	}
+	lists, err := db.Queries.ListSavedCareerLists(ctx, user.Userid)
+	if err != nil || len(lists) != 1 || lists[0].Name != toApply || lists[0].Careers != 1 {
+		t.Errorf("ListSavedCareerLists = %+v, %v", lists, err)
+	}
+	if saves, err := db.Queries.CountCareerSaves(ctx, first.Jobid); err != nil || saves != 2 {
+		t.Errorf("CountCareerSaves = %d, %v; want 2", saves, err)
+	}
+
+	updated, err := db.Queries.UpdateSavedCareer(ctx, database.UpdateSavedCareerParams{Userid: user.Userid, Jobid: first.Jobid, Note: "applied"})
+	if err != nil || updated.Note != "applied" || updated.ListName != nil {
+		t.Errorf("UpdateSavedCareer = %+v, %v", updated, err)
+	}
+	if _, err := db.Queries.DeleteSavedCareer(ctx, database.DeleteSavedCareerParams{Userid: other.Userid, Jobid: first.Jobid}); err != nil {
+		t.Errorf("DeleteSavedCareer: %v", err)
+	}
+	if _, err := db.Queries.DeleteSavedCareer(ctx, database.DeleteSavedCareerParams{Userid: other.Userid, Jobid: first.Jobid}); !errors.Is(err, pgx.ErrNoRows) {
+		t.Errorf("deleting an unsaved career: error = %v, want no rows", err)
+	}
+
+	// a blank list name is rejected; this aborts the transaction, so it goes
+	// last
+	blank := ""
+	_, err = db.Queries.UpdateSavedCareer(ctx, database.UpdateSavedCareerParams{Userid: user.Userid, Jobid: first.Jobid, ListName: &blank})
+	if !apperror.IsKind(apperror.FromDB(err, "saved career"), apperror.KindValidation) {
+		t.Errorf("a blank list name: error = %v, want a validation error", err)
+	}
+}
+
+func TestSavedSearchQueries(t *testing.T) {
+	db := testdb.New(t)
+	ctx := context.Background()
+
+	first := testdb.CreateCareer(t, db.Queries, testdb.WithCompany("Initech Payments"))
+	second := testdb.CreateCareer(t, db.Queries, testdb.WithCompany("Initech Labs"))
+	testdb.CreateCareer(t, db.Queries, testdb.WithCompany("Globex"))
+
+	keywords := database.ListCareersParams{Currency: "USD", Keywords: []string{"INITECH", "software"}, SkillIds: []int64{}}
+	careers, err := db.Queries.ListCareers(ctx, keywords)
+	if err != nil || len(careers) != 2 {
+		t.Errorf("ListCareers(initech software) = %+v, %v; want 2 careers", careers, err)
+	}
+	keywords.PublishedAfter = sql.NullInt64{Int64: *first.PublishedSeq, Valid: true}
+	keywords.Jobtype = sql.NullString{String: "full-TIME", Valid: true}
+	careers, err = db.Queries.ListCareers(ctx, keywords)
+	if err != nil || len(careers) != 1 || careers[0].Jobid != second.Jobid {
+		t.Errorf("ListCareers after career %d = %+v, %v; want career %d", first.Jobid, careers, err, second.Jobid)
+	}
+	if last, err := db.Queries.GetLastPublishedSeq(ctx); err != nil || last <= *second.PublishedSeq {
+		t.Errorf("GetLastPublishedSeq = %d, %v", last, err)
+	}
+
+	user := testdb.CreateUser(t, db.Queries)
+	eur := "EUR"
+	search, err := db.Queries.CreateSavedSearch(ctx, database.CreateSavedSearchParams{
+		Userid: user.Userid, Name: "Initech", Keywords: "initech", Currency: &eur, Alerts: true, UnsubscribeToken: "token-1", LastPublishedSeq: *first.PublishedSeq,
+	})
+	if err != nil || search.ID == 0 || search.CreatedAt.IsZero() {
+		t.Fatalf("CreateSavedSearch = %+v, %v", search, err)
+	}
+	alerting, err := db.Queries.ListAlertingSearches(ctx)
+	if err != nil || len(alerting) != 1 || alerting[0].Email != user.Email {
+		t.Errorf("ListAlertingSearches = %+v, %v", alerting, err)
+	}
+	if err := db.Queries.AdvanceSavedSearch(ctx, database.AdvanceSavedSearchParams{ID: search.ID, LastPublishedSeq: *second.PublishedSeq}); err != nil {
+		t.Fatalf("AdvanceSavedSearch: %v", err)
+	}
+	// the cursor never moves back
+	if err := db.Queries.AdvanceSavedSearch(ctx, database.AdvanceSavedSearchParams{ID: search.ID, LastPublishedSeq: *first.PublishedSeq}); err != nil {
+		t.Fatalf("AdvanceSavedSearch: %v", err)
+	}
+	byToken, err := db.Queries.GetSavedSearchByTokenForUpdate(ctx, "token-1")
+	if err != nil || byToken.LastPublishedSeq != *second.PublishedSeq {
+		t.Errorf("GetSavedSearchByTokenForUpdate = %+v, %v; want last_published_seq %d", byToken, err, *second.PublishedSeq)
+	}
+	if byToken, err := db.Queries.GetSavedSearchByToken(ctx, "token-1"); err != nil || byToken.ID != search.ID {
+		t.Errorf("GetSavedSearchByToken = %+v, %v", byToken, err)
+	}
+	if disabled, err := db.Queries.DisableSavedSearchAlerts(ctx, search.ID); err != nil || disabled.Alerts {
+		t.Errorf("DisableSavedSearchAlerts = %+v, %v", disabled, err)
+	}
+	if alerting, err := db.Queries.ListAlertingSearches(ctx); err != nil || len(alerting) != 0 {
+		t.Errorf("ListAlertingSearches after disabling = %+v, %v", alerting, err)
+	}
+	if _, err := db.Queries.DeleteSavedSearch(ctx, database.DeleteSavedSearchParams{ID: search.ID, Userid: user.Userid + 1}); !errors.Is(err, pgx.ErrNoRows) {
+		t.Errorf("deleting another user's search: error = %v, want no rows", err)
+	}
+
+	// names are unique per user ignoring case; this aborts the transaction,
+	// so it goes last
+	_, err = db.Queries.CreateSavedSearch(ctx, database.CreateSavedSearchParams{Userid: user.Userid, Name: "INITECH", UnsubscribeToken: "token-2"})
+	if !apperror.IsKind(apperror.FromDB(err, "saved search"), apperror.KindConflict) {
+		t.Errorf("a duplicate name: error = %v, want a conflict", err)
+	}
+}
+
+func TestCareerExpiryQueries(t *testing.T) {
+	db := testdb.New(t)
+	ctx := context.Background()
+
+	today := time.Now().UTC().Truncate(24 * time.Hour)
+	expired := testdb.CreateCareer(t, db.Queries, testdb.WithDates(today.AddDate(-1, 0, 0), today.AddDate(0, 0, -1)))
+	expiring := testdb.CreateCareer(t, db.Queries, testdb.WithDates(today.AddDate(0, 0, -30), today.AddDate(0, 0, 3)))
+	later := testdb.CreateCareer(t, db.Queries, testdb.WithDates(today, today.AddDate(0, 2, 0)))
+
+	params := database.ListCareersParams{Currency: "USD", Keywords: []string{}, SkillIds: []int64{}}
+	if careers, err := db.Queries.ListCareers(ctx, params); err != nil || len(careers) != 2 {
+		t.Errorf("ListCareers = %+v, %v; want the 2 open careers", careers, err)
+	}
+	params.IncludeExpired = true
+	if careers, err := db.Queries.ListCareers(ctx, params); err != nil || len(careers) != 3 {
+		t.Errorf("ListCareers(include_expired) = %+v, %v; want 3 careers", careers, err)
+	}
+
+	closed, err := db.Queries.CloseExpiredCareers(ctx)
+	if err != nil || len(closed) != 1 || closed[0].Jobid != expired.Jobid || closed[0].ClosedAt == nil || closed[0].Version != expired.Version+1 {
+		t.Fatalf("CloseExpiredCareers = %+v, %v; want career %d closed", closed, err, expired.Jobid)
+	}
+	if closed, err := db.Queries.CloseExpiredCareers(ctx); err != nil || len(closed) != 0 {
+		t.Errorf("CloseExpiredCareers again = %+v, %v; want none", closed, err)
+	}
+	extended, err := db.Queries.ExtendCareer(ctx, database.ExtendCareerParams{Jobid: expired.Jobid, Enddate: today.AddDate(0, 1, 0)})
+	if err != nil || extended.ClosedAt != nil || !extended.Enddate.Equal(today.AddDate(0, 1, 0)) {
+		t.Errorf("ExtendCareer = %+v, %v; want it open until next month", extended, err)
+	}
+
+	admin := testdb.CreateUser(t, db.Queries, testdb.WithRole("admin"))
+	other := testdb.CreateUser(t, db.Queries, testdb.WithRole("admin"))
+	testdb.CreateUser(t, db.Queries)
+	if admins, err := db.Queries.ListAdmins(ctx); err != nil || len(admins) != 2 || admins[0].Userid != admin.Userid || admins[0].Email != admin.Email {
+		t.Errorf("ListAdmins = %v, %v; want %s and %s", admins, err, admin.Email, other.Email)
+	}
+
+	within := func(userid int64, until time.Time) []database.Career {
+		t.Helper()
+		careers, err := db.Queries.ListExpiringCareers(ctx, database.ListExpiringCareersParams{Userid: userid, Until: until})
+		if err != nil {
+			t.Fatalf("ListExpiringCareers: %v", err)
+		}
+		return careers
+	}
+	if careers := within(admin.Userid, today.AddDate(0, 0, 7)); len(careers) != 1 || careers[0].Jobid != expiring.Jobid {
+		t.Fatalf("ListExpiringCareers = %+v; want career %d", careers, expiring.Jobid)
+	}
+	if err := db.Queries.SaveExpiryReminder(ctx, database.SaveExpiryReminderParams{Jobid: expiring.Jobid, Userid: admin.Userid, Enddate: expiring.Enddate}); err != nil {
+		t.Fatalf("SaveExpiryReminder: %v", err)
+	}
+	if careers := within(admin.Userid, today.AddDate(0, 0, 7)); len(careers) != 0 {
+		t.Errorf("ListExpiringCareers after the reminder = %+v; want none", careers)
+	}
+	// reminders are kept per admin
+	if careers := within(other.Userid, today.AddDate(0, 0, 7)); len(careers) != 1 {
+		t.Errorf("ListExpiringCareers for another admin = %+v; want career %d", careers, expiring.Jobid)
+	}
+	// moving the enddate arms the reminder again
+	if _, err := db.Queries.ExtendCareer(ctx, database.ExtendCareerParams{Jobid: expiring.Jobid, Enddate: today.AddDate(0, 0, 5)}); err != nil {
+		t.Fatalf("ExtendCareer: %v", err)
+	}
+	if careers := within(admin.Userid, today.AddDate(0, 0, 7)); len(careers) != 1 {
+		t.Errorf("ListExpiringCareers after extending = %+v; want career %d", careers, expiring.Jobid)
+	}
+	if careers := within(admin.Userid, today.AddDate(1, 0, 0)); len(careers) != 3 || careers[2].Jobid != later.Jobid {
+		t.Errorf("ListExpiringCareers within a year = %+v; want 3 careers, latest last", careers)
+	}
+}
+
+func TestScheduledPublishingQueries(t *testing.T) {
+	db := testdb.New(t)
+	ctx := context.Background()
+
+	live := testdb.CreateCareer(t, db.Queries)
+	if live.PublishedAt == nil || live.PublishedSeq == nil {
+		t.Fatalf("CreateCareer = %+v, want it published", live)
+	}
+	scheduled := testdb.CreateCareer(t, db.Queries, testdb.WithPublishAt(time.Now().Add(time.Hour)))
+	if scheduled.PublishedAt != nil || scheduled.PublishedSeq != nil {
+		t.Fatalf("CreateCareer = %+v, want it scheduled", scheduled)
+	}
+
+	params := database.ListCareersParams{Currency: "USD", Keywords: []string{}, SkillIds: []int64{}}
+	if careers, err := db.Queries.ListCareers(ctx, params); err != nil || len(careers) != 1 || careers[0].Jobid != live.Jobid {
+		t.Errorf("ListCareers = %+v, %v; want career %d", careers, err, live.Jobid)
+	}
+	params.IncludeUnpublished = true
+	if careers, err := db.Queries.ListCareers(ctx, params); err != nil || len(careers) != 2 {
+		t.Errorf("ListCareers(include_unpublished) = %+v, %v; want 2 careers", careers, err)
+	}
+	if published, err := db.Queries.PublishDueCareers(ctx); err != nil || len(published) != 0 {
+		t.Errorf("PublishDueCareers = %+v, %v; want nothing due", published, err)
+	}
+
+	if _, err := db.Tx.Exec(ctx, "UPDATE career SET publish_at = now() - interval '1 minute' WHERE jobid = $1", scheduled.Jobid); err != nil {
+		t.Fatal(err)
+	}
+	published, err := db.Queries.PublishDueCareers(ctx)
+	if err != nil || len(published) != 1 || published[0].PublishedAt == nil || published[0].Version != scheduled.Version+1 {
+		t.Fatalf("PublishDueCareers = %+v, %v; want career %d published", published, err, scheduled.Jobid)
+	}
+	if *published[0].PublishedSeq <= *live.PublishedSeq {
+		t.Errorf("published_seq = %d, want after %d", *published[0].PublishedSeq, *live.PublishedSeq)
+	}
+	if last, err := db.Queries.GetLastPublishedSeq(ctx); err != nil || last != *published[0].PublishedSeq {
+		t.Errorf("GetLastPublishedSeq = %d, %v; want %d", last, err, *published[0].PublishedSeq)
+	}
+}
+
+func TestCareerImportQueries(t *testing.T) {
+	db := testdb.New(t)
+	ctx := context.Background()
+
+	job, err := db.Queries.CreateCareerImport(ctx, database.CreateCareerImportParams{
+		Format: "csv", Mode: "best_effort", TotalRows: 2, IgnoredColumns: []string{"Team"}, CreatedBy: "admin@example.com",
+	})
+	if err != nil || job.Status != "running" || len(job.Jobids) != 0 || string(job.Errors) != "[]" || job.FinishedAt != nil {
+		t.Fatalf("CreateCareerImport = %+v, %v", job, err)
+	}
+	if err := db.Queries.UpdateCareerImportProgress(ctx, database.UpdateCareerImportProgressParams{ID: job.ID, ProcessedRows: 1}); err != nil {
+		t.Fatal(err)
+	}
+	if got, err := db.Queries.GetCareerImport(ctx, job.ID); err != nil || got.ProcessedRows != 1 {
+		t.Errorf("GetCareerImport = %+v, %v; want 1 row processed", got, err)
+	}
+
+	career := testdb.CreateCareer(t, db.Queries)
+	finished, err := db.Queries.FinishCareerImport(ctx, database.FinishCareerImportParams{
+		ID: job.ID, Status: "partial", ProcessedRows: 2, CreatedRows: 1, FailedRows: 1,
+		Jobids: []int64{career.Jobid}, Errors: []byte(`[{"line": 3, "detail": "invalid"}]`),
+	})
+	if err != nil || finished.Status != "partial" || len(finished.Jobids) != 1 || finished.Jobids[0] != career.Jobid || finished.FinishedAt == nil {
+		t.Errorf("FinishCareerImport = %+v, %v", finished, err)
+	}
+
+	_, err = db.Queries.FinishCareerImport(ctx, database.FinishCareerImportParams{ID: job.ID, Status: "done", Jobids: []int64{}, Errors: []byte("[]")})
+	if !apperror.IsKind(apperror.FromDB(err, "career import"), apperror.KindValidation) {
+		t.Errorf("FinishCareerImport(status done) error = %v, want a check violation", err)
+	}
+}
+
+// txBeginner runs the transactions of a Store as savepoints of the test's
+// transaction, so they are rolled back with it
+type txBeginner struct{ pgx.Tx }
+
+func (b txBeginner) BeginTx(ctx context.Context, _ pgx.TxOptions) (pgx.Tx, error) {
+	return b.Tx.Begin(ctx)
+}
+
+func TestSavepointRollsBackOnlyItsChanges(t *testing.T) {
+	db := testdb.New(t)
+	ctx := context.Background()
+	store := database.NewStore(txBeginner{db.Tx}, 0)
+	job, err := db.Queries.CreateCareerImport(ctx, database.CreateCareerImportParams{Format: "csv", Mode: "atomic", IgnoredColumns: []string{}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var rolledBack, kept database.Career
+	err = store.ExecTx(ctx, func(q database.TxQuerier) error {
+		err := database.Savepoint(ctx, q, func(q database.TxQuerier) error {
+			rolledBack = testdb.CreateCareer(t, q)
+			// violates the status check, which aborts the statement
+			_, err := q.FinishCareerImport(ctx, database.FinishCareerImportParams{ID: job.ID, Status: "done", Jobids: []int64{}, Errors: []byte("[]")})
+			return err
+		})
+		if err == nil {
+			t.Error("Savepoint succeeded, want the check violation")
+		}
+		// the transaction goes on after the savepoint is rolled back
+		kept = testdb.CreateCareer(t, q)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("ExecTx: %v", err)
+	}
+	if _, err := db.Queries.GetCareerByJobId(ctx, rolledBack.Jobid); !errors.Is(err, pgx.ErrNoRows) {
+		t.Errorf("career created in the failed savepoint: %v, want it rolled back", err)
+	}
+	if _, err := db.Queries.GetCareerByJobId(ctx, kept.Jobid); err != nil {
+		t.Errorf("career created after the savepoint: %v", err)
+	}
+	if err := database.Savepoint(ctx, db.Queries, func(database.TxQuerier) error { return nil }); err == nil {
+		t.Error("Savepoint outside ExecTx succeeded, want an error")
+	}
+}
+
+func TestCareerRevisionQueries(t *testing.T) {
+	db := testdb.New(t)
+	ctx := context.Background()
+
+	career := testdb.CreateCareer(t, db.Queries, testdb.WithCompany("Acme"))
+	for _, revision := range []database.CreateCareerRevisionParams{
+		{Jobid: career.Jobid, Revision: 1, Company: "Acme", Position: career.Position, Jobtype: career.Jobtype, Startdate: career.Startdate, Enddate: career.Enddate, AuthorEmail: "admin@example.com", RemotePolicy: "onsite", RemoteCountries: []string{}},
+		{Jobid: career.Jobid, Revision: 2, Company: "Acme Inc", Position: career.Position, Jobtype: career.Jobtype, Startdate: career.Startdate, Enddate: career.Enddate, AuthorEmail: "admin@example.com", RemotePolicy: "onsite", RemoteCountries: []string{}},
+	} {
+		if err := db.Queries.CreateCareerRevision(ctx, revision); err != nil {
+			t.Fatalf("CreateCareerRevision(%d): %v", revision.Revision, err)
+		}
+	}
+
+	revisions, err := db.Queries.ListCareerRevisions(ctx, database.ListCareerRevisionsParams{Jobid: career.Jobid})
+	if err != nil || len(revisions) != 2 || revisions[0].Revision != 2 {
+		t.Fatalf("ListCareerRevisions = %+v, %v", revisions, err)
+	}
+	past := time.Now().Add(-time.Hour)
+	if revisions, err := db.Queries.ListCareerRevisions(ctx, database.ListCareerRevisionsParams{Jobid: career.Jobid, At: &past}); err != nil || len(revisions) != 0 {
+		t.Errorf("ListCareerRevisions an hour ago = %+v, %v", revisions, err)
+	}
+
+	got, err := db.Queries.GetCareerRevision(ctx, database.GetCareerRevisionParams{Jobid: career.Jobid, Revision: 1})
+	if err != nil || got.Company != "Acme" {
+		t.Fatalf("GetCareerRevision = %+v, %v", got, err)
+	}
+	if _, err := db.Queries.GetCareerRevision(ctx, database.GetCareerRevisionParams{Jobid: career.Jobid, Revision: 3}); !errors.Is(err, pgx.ErrNoRows) {
+		t.Errorf("GetCareerRevision of a missing revision error = %v, want pgx.ErrNoRows", err)
+	}
+}
+
+func TestAuditEventsAreAppendOnly(t *testing.T) {
+	db := testdb.New(t)
+	ctx := context.Background()
+
+	err := db.Queries.CreateAuditEvent(ctx, database.CreateAuditEventParams{
+		ActorEmail: "admin@example.com", ActorRole: "admin", Action: "create", ResourceType: "career",
+		ResourceID: 1, Changes: []byte(`{"company": {"before": null, "after": "Acme"}}`), RequestID: "req-1", IP: "127.0.0.1",
+	})
+	if err != nil {
+		t.Fatalf("CreateAuditEvent: %v", err)
+	}
+
+	events, err := db.Queries.ListAuditEvents(ctx, database.ListAuditEventsParams{
+		Actor:   sql.NullString{String: "admin@example.com", Valid: true},
+		MaxRows: 10,
+	})
+	if err != nil || len(events) != 1 || events[0].RequestID != "req-1" {
+		t.Fatalf("ListAuditEvents = %+v, %v", events, err)
+	}
+
+	// last, as the failed statement aborts the test transaction
+	if _, err := db.Tx.Exec(ctx, "DELETE FROM audit_events"); err == nil {
+		t.Error("deleting audit events succeeded, want the append-only trigger to reject it")
+	}
+}
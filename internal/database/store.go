@@ -0,0 +1,147 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"math/rand"
+	"time"
+
+	"github.com/jackc/pgconn"
+	"github.com/jackc/pgx/v4"
+)
+
+// TxQuerier is every query, generated or hand-written
+type TxQuerier interface {
+	Querier
+	PatchQuerier
+}
+
+// Store runs queries on their own or grouped in a transaction
+type Store interface {
+	TxQuerier
+	// ExecTx runs fn in a serializable transaction. It commits when fn returns
+	// nil and rolls back otherwise. When Postgres aborts the transaction with a
+	// serialization failure or deadlock, fn is run again from the start, so it
+	// must not have side effects outside the transaction.
+	ExecTx(ctx context.Context, fn func(TxQuerier) error) error
+}
+
+// TxBeginner is a connection that can start transactions, e.g. *pgxpool.Pool
+type TxBeginner interface {
+	DBTX
+	BeginTx(ctx context.Context, txOptions pgx.TxOptions) (pgx.Tx, error)
+}
+
+// SQLStore is the Postgres Store
+type SQLStore struct {
+	*Queries
+	db           TxBeginner
+	queryTimeout time.Duration
+}
+
+var _ Store = (*SQLStore)(nil)
+
+// NewStore returns a Store on db. Every statement, inside a transaction or
+// not, is cancelled after queryTimeout; zero disables the timeout.
+func NewStore(db TxBeginner, queryTimeout time.Duration) *SQLStore {
+	return &SQLStore{Queries: New(withQueryTimeout(db, queryTimeout)), db: db, queryTimeout: queryTimeout}
+}
+
+// maxTxAttempts bounds how often a transaction is retried
+const maxTxAttempts = 3
+
+func (s *SQLStore) ExecTx(ctx context.Context, fn func(TxQuerier) error) error {
+	return retry(ctx, maxTxAttempts, func() error {
+		tx, err := s.db.BeginTx(ctx, pgx.TxOptions{IsoLevel: pgx.Serializable})
+		if err != nil {
+			return err
+		}
+		// a no-op once the transaction is committed
+		defer tx.Rollback(ctx)
+
+		if err := fn(s.inTx(tx)); err != nil {
+			return err
+		}
+		return tx.Commit(ctx)
+	})
+}
+
+// Savepointer is a TxQuerier that can run part of its transaction in a
+// savepoint
+type Savepointer interface {
+	TxQuerier
+	// Savepoint runs fn in a savepoint. When fn fails only its changes are
+	// rolled back and the transaction goes on.
+	Savepoint(ctx context.Context, fn func(TxQuerier) error) error
+}
+
+// Savepoint runs fn in a savepoint of q, which must be the TxQuerier of an
+// ExecTx
+func Savepoint(ctx context.Context, q TxQuerier, fn func(TxQuerier) error) error {
+	savepointer, ok := q.(Savepointer)
+	if !ok {
+		return errors.New("savepoints need the querier of a transaction")
+	}
+	return savepointer.Savepoint(ctx, fn)
+}
+
+// txQueries are the queries of a transaction
+type txQueries struct {
+	*Queries
+	tx    pgx.Tx
+	store *SQLStore
+}
+
+var _ Savepointer = txQueries{}
+
+func (s *SQLStore) inTx(tx pgx.Tx) txQueries {
+	return txQueries{Queries: New(withQueryTimeout(tx, s.queryTimeout)), tx: tx, store: s}
+}
+
+func (q txQueries) Savepoint(ctx context.Context, fn func(TxQuerier) error) error {
+	// a transaction begun in a transaction is a savepoint
+	savepoint, err := q.tx.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	// a no-op once the savepoint is released
+	defer savepoint.Rollback(ctx)
+
+	if err := fn(q.store.inTx(savepoint)); err != nil {
+		return err
+	}
+	return savepoint.Commit(ctx)
+}
+
+// retry calls fn up to attempts times while it fails with a retryable error,
+// backing off a little longer with some jitter before each new attempt
+func retry(ctx context.Context, attempts int, fn func() error) error {
+	for attempt := 1; ; attempt++ {
+		err := fn()
+		if err == nil || attempt >= attempts || !Retryable(err) {
+			return err
+		}
+
+		backoff := time.Duration(attempt)*10*time.Millisecond + time.Duration(rand.Int63n(int64(10*time.Millisecond)))
+		timer := time.NewTimer(backoff)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return err
+		case <-timer.C:
+		}
+	}
+}
+
+// Postgres error codes that mean the transaction can safely be run again
+const (
+	pgSerializationFailure = "40001"
+	pgDeadlockDetected     = "40P01"
+)
+
+// Retryable reports whether err aborted a transaction that may succeed when
+// run again
+func Retryable(err error) bool {
+	var pgErr *pgconn.PgError
+	return errors.As(err, &pgErr) && (pgErr.Code == pgSerializationFailure || pgErr.Code == pgDeadlockDetected)
+}
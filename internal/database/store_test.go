@@ -0,0 +1,57 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/jackc/pgconn"
+)
+
+func TestRetry(t *testing.T) {
+	serialization := &pgconn.PgError{Code: pgSerializationFailure}
+	deadlock := &pgconn.PgError{Code: pgDeadlockDetected}
+	unique := &pgconn.PgError{Code: "23505"}
+
+	tests := []struct {
+		name      string
+		errs      []error
+		wantCalls int
+		wantErr   error
+	}{
+		{name: "success", errs: []error{nil}, wantCalls: 1},
+		{name: "serialization failure then success", errs: []error{serialization, nil}, wantCalls: 2},
+		{name: "deadlock then success", errs: []error{deadlock, nil}, wantCalls: 2},
+		{name: "gives up after max attempts", errs: []error{serialization, serialization, serialization, nil}, wantCalls: 3, wantErr: serialization},
+		{name: "other errors are not retried", errs: []error{unique, nil}, wantCalls: 1, wantErr: unique},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			calls := 0
+			err := retry(context.Background(), maxTxAttempts, func() error {
+				calls++
+				return tt.errs[calls-1]
+			})
+			if calls != tt.wantCalls {
+				t.Errorf("calls = %d, want %d", calls, tt.wantCalls)
+			}
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("err = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestRetryStopsWhenContextIsDone(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	calls := 0
+	err := retry(ctx, maxTxAttempts, func() error {
+		calls++
+		return &pgconn.PgError{Code: pgSerializationFailure}
+	})
+	if calls != 1 || !Retryable(err) {
+		t.Errorf("calls = %d, err = %v; want one call returning the serialization failure", calls, err)
+	}
+}
@@ -0,0 +1,109 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"jobApps/metrics"
+
+	"github.com/jackc/pgconn"
+	"github.com/jackc/pgx/v4"
+)
+
+// ErrQueryTimeout reports a single query running longer than the store's
+// query timeout while the request itself still had time left
+var ErrQueryTimeout = errors.New("query timed out")
+
+// timeoutDB bounds every statement run through db by timeout
+type timeoutDB struct {
+	db      DBTX
+	timeout time.Duration
+}
+
+// withQueryTimeout wraps db so each statement gets its own deadline. A zero
+// timeout leaves db unchanged.
+func withQueryTimeout(db DBTX, timeout time.Duration) DBTX {
+	if timeout <= 0 {
+		return db
+	}
+	return timeoutDB{db: db, timeout: timeout}
+}
+
+func (t timeoutDB) Exec(ctx context.Context, sql string, args ...interface{}) (pgconn.CommandTag, error) {
+	queryCtx, cancel := context.WithTimeout(ctx, t.timeout)
+	defer cancel()
+
+	tag, err := t.db.Exec(queryCtx, sql, args...)
+	return tag, queryError(ctx, queryCtx, err)
+}
+
+func (t timeoutDB) Query(ctx context.Context, sql string, args ...interface{}) (pgx.Rows, error) {
+	queryCtx, cancel := context.WithTimeout(ctx, t.timeout)
+
+	rows, err := t.db.Query(queryCtx, sql, args...)
+	if err != nil {
+		cancel()
+		return nil, queryError(ctx, queryCtx, err)
+	}
+	// the rows are read after Query returns, so the deadline is released on Close
+	return &timeoutRows{Rows: rows, ctx: ctx, queryCtx: queryCtx, cancel: cancel}, nil
+}
+
+func (t timeoutDB) QueryRow(ctx context.Context, sql string, args ...interface{}) pgx.Row {
+	queryCtx, cancel := context.WithTimeout(ctx, t.timeout)
+	return &timeoutRow{row: t.db.QueryRow(queryCtx, sql, args...), ctx: ctx, queryCtx: queryCtx, cancel: cancel}
+}
+
+type timeoutRows struct {
+	pgx.Rows
+	ctx, queryCtx context.Context
+	cancel        context.CancelFunc
+}
+
+func (r *timeoutRows) Close() {
+	r.Rows.Close()
+	r.cancel()
+}
+
+func (r *timeoutRows) Next() bool {
+	if r.Rows.Next() {
+		return true
+	}
+	// pgx closes the rows once they are exhausted or fail
+	r.cancel()
+	return false
+}
+
+func (r *timeoutRows) Err() error {
+	return queryError(r.ctx, r.queryCtx, r.Rows.Err())
+}
+
+type timeoutRow struct {
+	row           pgx.Row
+	ctx, queryCtx context.Context
+	cancel        context.CancelFunc
+}
+
+func (r *timeoutRow) Scan(dest ...interface{}) error {
+	defer r.cancel()
+	return queryError(r.ctx, r.queryCtx, r.row.Scan(dest...))
+}
+
+// queryError tells apart a query hitting its own timeout from the caller's
+// context ending. Query timeouts are counted here; requests ending early are
+// counted by the deadline middleware.
+func queryError(ctx, queryCtx context.Context, err error) error {
+	if err == nil {
+		return nil
+	}
+	switch {
+	case ctx.Err() != nil:
+		return fmt.Errorf("%w: %w", ctx.Err(), err)
+	case errors.Is(queryCtx.Err(), context.DeadlineExceeded):
+		metrics.Cancellations.Add(metrics.ReasonQueryTimeout, 1)
+		return fmt.Errorf("%w: %w", ErrQueryTimeout, err)
+	}
+	return err
+}
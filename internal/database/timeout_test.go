@@ -0,0 +1,74 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgconn"
+	"github.com/jackc/pgx/v4"
+)
+
+// slowDB blocks every statement until its context is done
+type slowDB struct{}
+
+func (slowDB) Exec(ctx context.Context, _ string, _ ...interface{}) (pgconn.CommandTag, error) {
+	<-ctx.Done()
+	return nil, ctx.Err()
+}
+
+func (slowDB) Query(ctx context.Context, _ string, _ ...interface{}) (pgx.Rows, error) {
+	<-ctx.Done()
+	return nil, ctx.Err()
+}
+
+func (slowDB) QueryRow(ctx context.Context, _ string, _ ...interface{}) pgx.Row {
+	return slowRow{ctx: ctx}
+}
+
+type slowRow struct{ ctx context.Context }
+
+func (r slowRow) Scan(...interface{}) error {
+	<-r.ctx.Done()
+	return r.ctx.Err()
+}
+
+func TestQueryTimeout(t *testing.T) {
+	db := withQueryTimeout(slowDB{}, 10*time.Millisecond)
+	ctx := context.Background()
+
+	if _, err := db.Exec(ctx, "SELECT pg_sleep(1)"); !errors.Is(err, ErrQueryTimeout) {
+		t.Errorf("Exec error = %v, want ErrQueryTimeout", err)
+	}
+	if _, err := db.Query(ctx, "SELECT pg_sleep(1)"); !errors.Is(err, ErrQueryTimeout) {
+		t.Errorf("Query error = %v, want ErrQueryTimeout", err)
+	}
+	if err := db.QueryRow(ctx, "SELECT pg_sleep(1)").Scan(); !errors.Is(err, ErrQueryTimeout) {
+		t.Errorf("QueryRow error = %v, want ErrQueryTimeout", err)
+	}
+}
+
+func TestQueryTimeoutKeepsCallerCancellation(t *testing.T) {
+	db := withQueryTimeout(slowDB{}, time.Minute)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	_, err := db.Exec(ctx, "SELECT pg_sleep(1)")
+	if !errors.Is(err, context.DeadlineExceeded) || errors.Is(err, ErrQueryTimeout) {
+		t.Errorf("Exec past the request deadline: error = %v, want context.DeadlineExceeded only", err)
+	}
+
+	ctx, cancel = context.WithCancel(context.Background())
+	cancel()
+	_, err = db.Exec(ctx, "SELECT 1")
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("Exec after the client left: error = %v, want context.Canceled", err)
+	}
+}
+
+func TestZeroQueryTimeoutIsDisabled(t *testing.T) {
+	if _, ok := withQueryTimeout(slowDB{}, 0).(slowDB); !ok {
+		t.Error("withQueryTimeout(db, 0) wrapped db")
+	}
+}
@@ -0,0 +1,2209 @@
+// Package memstore is a thread-safe in-memory database.Store for tests.
+// It mirrors the constraints in sql/migrations: unique violations and foreign
+// key violations are reported as *pgconn.PgError and missing rows as
+// pgx.ErrNoRows, so callers see the same errors as with Postgres. Like the
+// queries, it hides soft-deleted rows from everything but the trash.
+package memstore
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"jobApps/geo"
+	"jobApps/internal/database"
+
+	"github.com/jackc/pgconn"
+	"github.com/jackc/pgx/v4"
+)
+
+type Store struct {
+	mu sync.RWMutex
+	// txMu runs transactions one at a time
+	txMu sync.Mutex
+
+	users    map[int64]database.User
+	profiles map[int64]database.Profile
+	careers  map[int64]database.Career
+	// revisions holds each career's revisions in ascending order
+	revisions map[int64][]database.CareerRevision
+	// auditEvents is append-only, in ID order
+	auditEvents []database.AuditEvent
+	companies   map[int64]database.Company
+	recruiters  map[database.CompanyRecruiter]bool
+	// exchangeRates holds units of each currency per US dollar; it is not
+	// changed after New
+	exchangeRates map[string]float64
+	skills        map[int64]database.Skill
+	// skillAliases maps lower-case aliases to skill IDs
+	skillAliases map[string]int64
+	// careerSkills and profileSkills hold the links of each career and
+	// profile in insertion order
+	careerSkills  map[int64][]database.CareerSkill
+	profileSkills map[int64][]database.ProfileSkill
+	savedCareers  map[savedKey]database.SavedCareer
+	savedSearches map[int64]database.SavedSearch
+	// expiryReminders holds the enddate each admin's last reminder about a
+	// career was about
+	expiryReminders map[reminderKey]time.Time
+	// careerImports are only written outside transactions, so ExecTx leaves
+	// them out of its snapshot and a rolled back import keeps its progress,
+	// as with Postgres
+	careerImports map[int64]database.CareerImport
+
+	lastUserID    int64
+	lastProfileID int64
+	lastJobID     int64
+	lastAuditID   int64
+	lastCompanyID int64
+	lastSkillID   int64
+	lastSearchID  int64
+	lastImportID  int64
+	// lastPublishedSeq stands in for career_published_seq
+	lastPublishedSeq int64
+}
+
+var (
+	_ database.Store       = (*Store)(nil)
+	_ database.Savepointer = (*Store)(nil)
+)
+
+func New() *Store {
+	s := &Store{
+		users:    map[int64]database.User{},
+		profiles: map[int64]database.Profile{},
+		careers:  map[int64]database.Career{},
+
+		revisions:  map[int64][]database.CareerRevision{},
+		companies:  map[int64]database.Company{},
+		recruiters: map[database.CompanyRecruiter]bool{},
+
+		exchangeRates: maps.Clone(seedExchangeRates),
+		skills:        map[int64]database.Skill{},
+		skillAliases:  map[string]int64{},
+		careerSkills:  map[int64][]database.CareerSkill{},
+		profileSkills: map[int64][]database.ProfileSkill{},
+		savedCareers:  map[savedKey]database.SavedCareer{},
+		savedSearches: map[int64]database.SavedSearch{},
+
+		expiryReminders: map[reminderKey]time.Time{},
+		careerImports:   map[int64]database.CareerImport{},
+	}
+	for _, name := range seedSkills {
+		s.lastSkillID++
+		s.skills[s.lastSkillID] = database.Skill{ID: s.lastSkillID, Name: name, CreatedAt: time.Now()}
+	}
+	for alias, name := range seedSkillAliases {
+		for id, skill := range s.skills {
+			if skill.Name == name {
+				s.skillAliases[alias] = id
+			}
+		}
+	}
+	return s
+}
+
+// seedSkills and seedSkillAliases are the rows inserted by 0009_skills.sql
+var (
+	seedSkills = []string{
+		"Go", "PostgreSQL", "SQL", "JavaScript", "TypeScript", "Python",
+		"Java", "Rust", "React", "Docker", "Kubernetes", "AWS", "Terraform",
+	}
+	seedSkillAliases = map[string]string{
+		"golang": "Go", "postgres": "PostgreSQL", "psql": "PostgreSQL", "js": "JavaScript",
+		"ts": "TypeScript", "py": "Python", "reactjs": "React", "react.js": "React",
+		"k8s": "Kubernetes", "amazon web services": "AWS",
+	}
+)
+
+// seedExchangeRates are the rates inserted by 0007_compensation.sql
+var seedExchangeRates = map[string]float64{
+	"USD": 1, "EUR": 0.92, "GBP": 0.79, "CHF": 0.88, "CAD": 1.36, "AUD": 1.52,
+	"JPY": 150, "CNY": 7.2, "INR": 83, "SGD": 1.35, "SEK": 10.5, "BRL": 5,
+}
+
+func uniqueViolation(table, constraint string) error {
+	return &pgconn.PgError{Code: "23505", Severity: "ERROR", TableName: table, ConstraintName: constraint,
+		Message: "duplicate key value violates unique constraint \"" + constraint + "\""}
+}
+
+func foreignKeyViolation(table, constraint string) error {
+	return &pgconn.PgError{Code: "23503", Severity: "ERROR", TableName: table, ConstraintName: constraint,
+		Message: "insert or update on table \"" + table + "\" violates foreign key constraint \"" + constraint + "\""}
+}
+
+func notNullViolation(table, column string) error {
+	return &pgconn.PgError{Code: "23502", Severity: "ERROR", TableName: table, ColumnName: column,
+		Message: "null value in column \"" + column + "\" of relation \"" + table + "\" violates not-null constraint"}
+}
+
+func checkViolation(table, constraint string) error {
+	return &pgconn.PgError{Code: "23514", Severity: "ERROR", TableName: table, ConstraintName: constraint,
+		Message: "new row for relation \"" + table + "\" violates check constraint \"" + constraint + "\""}
+}
+
+// ExecTx runs fn with exclusive access to the transaction and restores every
+// table if it fails. Like Postgres sequences, IDs handed out by a failed
+// transaction are not reused.
+func (s *Store) ExecTx(_ context.Context, fn func(database.TxQuerier) error) error {
+	s.txMu.Lock()
+	defer s.txMu.Unlock()
+
+	restore := s.snapshot()
+	if err := fn(s); err != nil {
+		restore()
+		return err
+	}
+	return nil
+}
+
+// Savepoint runs fn in the transaction of an ExecTx, undoing only fn's
+// changes when it fails
+func (s *Store) Savepoint(_ context.Context, fn func(database.TxQuerier) error) error {
+	restore := s.snapshot()
+	if err := fn(s); err != nil {
+		restore()
+		return err
+	}
+	return nil
+}
+
+// snapshot copies the tables a transaction may change and returns a func
+// that puts the copies back
+func (s *Store) snapshot() (restore func()) {
+	s.mu.RLock()
+	users, profiles, careers := maps.Clone(s.users), maps.Clone(s.profiles), maps.Clone(s.careers)
+	// revision slices are only appended to, so a shallow copy keeps their old length
+	revisions := maps.Clone(s.revisions)
+	companies, recruiters := maps.Clone(s.companies), maps.Clone(s.recruiters)
+	skills, skillAliases := maps.Clone(s.skills), maps.Clone(s.skillAliases)
+	// skill links are replaced rather than edited in place
+	careerSkills, profileSkills := maps.Clone(s.careerSkills), maps.Clone(s.profileSkills)
+	savedCareers, savedSearches := maps.Clone(s.savedCareers), maps.Clone(s.savedSearches)
+	expiryReminders := maps.Clone(s.expiryReminders)
+	auditEvents := len(s.auditEvents)
+	s.mu.RUnlock()
+
+	return func() {
+		s.mu.Lock()
+		s.users, s.profiles, s.careers, s.revisions = users, profiles, careers, revisions
+		s.companies, s.recruiters = companies, recruiters
+		s.skills, s.skillAliases, s.careerSkills, s.profileSkills = skills, skillAliases, careerSkills, profileSkills
+		s.savedCareers, s.savedSearches, s.expiryReminders = savedCareers, savedSearches, expiryReminders
+		s.auditEvents = s.auditEvents[:auditEvents]
+		s.mu.Unlock()
+	}
+}
+
+// deletedNow is the deleted_at of a row deleted now
+func deletedNow() *time.Time {
+	now := time.Now()
+	return &now
+}
+
+// byDeletedAt sorts trash rows newest first, then by key
+func byDeletedAt[V any](rows []V, deletedAt func(V) time.Time, key func(V) int64) {
+	sort.SliceStable(rows, func(i, j int) bool {
+		if a, b := deletedAt(rows[i]), deletedAt(rows[j]); !a.Equal(b) {
+			return a.After(b)
+		}
+		return key(rows[i]) < key(rows[j])
+	})
+}
+
+// sortedKeys returns map keys in ascending order, matching the insertion
+// order of BIGSERIAL keys
+func sortedKeys[V any](m map[int64]V) []int64 {
+	keys := make([]int64, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool { return keys[i] < keys[j] })
+	return keys
+}
+
+// sortedIDs returns ids in ascending order without duplicates, like rows
+// matched by = ANY
+func sortedIDs(ids []int64) []int64 {
+	sorted := slices.Clone(ids)
+	slices.Sort(sorted)
+	return slices.Compact(sorted)
+}
+
+// Users
+
+func (s *Store) CreateUser(_ context.Context, arg database.CreateUserParams) (database.User, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, user := range s.users {
+		if user.DeletedAt != nil {
+			continue
+		}
+		if user.Email == arg.Email {
+			return database.User{}, uniqueViolation("users", "users_email_key")
+		}
+		if user.Phonenumber == arg.Phonenumber {
+			return database.User{}, uniqueViolation("users", "users_phonenumber_key")
+		}
+	}
+
+	s.lastUserID++
+	now := sql.NullTime{Time: time.Now(), Valid: true}
+	user := database.User{
+		Userid:      s.lastUserID,
+		Username:    arg.Username,
+		Email:       arg.Email,
+		Phonenumber: arg.Phonenumber,
+		Password:    arg.Password,
+		Role:        arg.Role,
+		Createdat:   now,
+		Updatedat:   now,
+	}
+	s.users[user.Userid] = user
+	return user, nil
+}
+
+func (s *Store) GetUserByEmail(_ context.Context, email string) (database.User, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	for _, id := range sortedKeys(s.users) {
+		if s.users[id].Email == email && s.users[id].DeletedAt == nil {
+			return s.users[id], nil
+		}
+	}
+	return database.User{}, pgx.ErrNoRows
+}
+
+func (s *Store) GetUserByPhoneNumber(_ context.Context, phonenumber string) (database.User, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	for _, id := range sortedKeys(s.users) {
+		if s.users[id].Phonenumber == phonenumber && s.users[id].DeletedAt == nil {
+			return s.users[id], nil
+		}
+	}
+	return database.User{}, pgx.ErrNoRows
+}
+
+// GetUserByIdForUpdate needs no row lock as ExecTx serialises transactions
+func (s *Store) GetUserByIdForUpdate(_ context.Context, userid int64) (database.User, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	user, ok := s.users[userid]
+	if !ok || user.DeletedAt != nil {
+		return database.User{}, pgx.ErrNoRows
+	}
+	return user, nil
+}
+
+func (s *Store) DeleteUserById(_ context.Context, userid int64) (database.User, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	user, ok := s.users[userid]
+	if !ok || user.DeletedAt != nil {
+		return database.User{}, pgx.ErrNoRows
+	}
+	user.DeletedAt = deletedNow()
+	s.users[userid] = user
+	return user, nil
+}
+
+func (s *Store) GetDeletedUserByIdForUpdate(_ context.Context, userid int64) (database.User, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	user, ok := s.users[userid]
+	if !ok || user.DeletedAt == nil {
+		return database.User{}, pgx.ErrNoRows
+	}
+	return user, nil
+}
+
+// RestoreUserById fails like the partial unique indexes when another active
+// user has taken the email or phone number in the meantime
+func (s *Store) RestoreUserById(_ context.Context, userid int64) (database.User, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	user, ok := s.users[userid]
+	if !ok || user.DeletedAt == nil {
+		return database.User{}, pgx.ErrNoRows
+	}
+	for _, other := range s.users {
+		if other.DeletedAt != nil {
+			continue
+		}
+		if other.Email == user.Email {
+			return database.User{}, uniqueViolation("users", "users_email_key")
+		}
+		if other.Phonenumber == user.Phonenumber {
+			return database.User{}, uniqueViolation("users", "users_phonenumber_key")
+		}
+	}
+	user.DeletedAt = nil
+	s.users[userid] = user
+	return user, nil
+}
+
+func (s *Store) ListDeletedUsers(_ context.Context) ([]database.User, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var users []database.User
+	for _, id := range sortedKeys(s.users) {
+		if s.users[id].DeletedAt != nil {
+			users = append(users, s.users[id])
+		}
+	}
+	byDeletedAt(users, func(u database.User) time.Time { return *u.DeletedAt }, func(u database.User) int64 { return u.Userid })
+	return users, nil
+}
+
+// PurgeUsers also removes the profiles, company memberships, saved careers,
+// expiry reminders and saved searches of purged users, like ON DELETE CASCADE
+func (s *Store) PurgeUsers(_ context.Context, before time.Time) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var purged int64
+	for id, user := range s.users {
+		if user.DeletedAt == nil || !user.DeletedAt.Before(before) {
+			continue
+		}
+		for profileID, profile := range s.profiles {
+			if profile.Userid == id {
+				delete(s.profiles, profileID)
+				delete(s.profileSkills, profileID)
+			}
+		}
+		for membership := range s.recruiters {
+			if membership.Userid == id {
+				delete(s.recruiters, membership)
+			}
+		}
+		for key := range s.savedCareers {
+			if key.userid == id {
+				delete(s.savedCareers, key)
+			}
+		}
+		for key := range s.expiryReminders {
+			if key.userid == id {
+				delete(s.expiryReminders, key)
+			}
+		}
+		for searchID, search := range s.savedSearches {
+			if search.Userid == id {
+				delete(s.savedSearches, searchID)
+			}
+		}
+		delete(s.users, id)
+		purged++
+	}
+	return purged, nil
+}
+
+func (s *Store) GetallusersEmail(_ context.Context) ([]string, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var emails []string
+	for _, id := range sortedKeys(s.users) {
+		if s.users[id].Role == "user" && s.users[id].DeletedAt == nil {
+			emails = append(emails, s.users[id].Email)
+		}
+	}
+	return emails, nil
+}
+
+// Careers
+
+func (s *Store) CreateCareer(_ context.Context, arg database.CreateCareerParams) (database.Career, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.companies[arg.CompanyID]; !ok {
+		return database.Career{}, foreignKeyViolation("career", "career_company_id_fkey")
+	}
+	s.lastJobID++
+	career := database.Career{
+		Jobid:       s.lastJobID,
+		Company:     arg.Company,
+		Position:    arg.Position,
+		Jobtype:     arg.Jobtype,
+		Description: arg.Description,
+		Startdate:   arg.Startdate,
+		Enddate:     arg.Enddate,
+		Version:     1,
+		CompanyID:   arg.CompanyID,
+
+		SalaryMin:      arg.SalaryMin,
+		SalaryMax:      arg.SalaryMax,
+		SalaryCurrency: arg.SalaryCurrency,
+		PayPeriod:      arg.PayPeriod,
+		Equity:         arg.Equity,
+		SalaryVisible:  arg.SalaryVisible,
+
+		Locations:       storedLocations(arg.Locations),
+		RemotePolicy:    arg.RemotePolicy,
+		RemoteCountries: arg.RemoteCountries,
+		Seniority:       arg.Seniority,
+
+		PublishAt: arg.PublishAt,
+
+		DescriptionHTML:    arg.DescriptionHTML,
+		DescriptionExcerpt: arg.DescriptionExcerpt,
+	}
+	if !arg.PublishAt.After(time.Now()) {
+		s.publish(&career)
+	}
+	if err := s.checkCareer(career); err != nil {
+		return database.Career{}, err
+	}
+	s.careers[career.Jobid] = career
+	return career, nil
+}
+
+// publish sets published_at and takes the next published_seq
+func (s *Store) publish(career *database.Career) {
+	now := time.Now()
+	s.lastPublishedSeq++
+	seq := s.lastPublishedSeq
+	career.PublishedAt, career.PublishedSeq = &now, &seq
+}
+
+func (s *Store) GetCareerByJobId(_ context.Context, jobid int64) (database.Career, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	career, ok := s.careers[jobid]
+	if !ok || career.DeletedAt != nil {
+		return database.Career{}, pgx.ErrNoRows
+	}
+	return career, nil
+}
+
+// GetCareerByJobIdForUpdate needs no row lock as ExecTx serialises transactions
+func (s *Store) GetCareerByJobIdForUpdate(ctx context.Context, jobid int64) (database.Career, error) {
+	return s.GetCareerByJobId(ctx, jobid)
+}
+
+func (s *Store) GetAllCareerDetails(_ context.Context) ([]database.Career, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var careers []database.Career
+	for _, id := range sortedKeys(s.careers) {
+		if s.careers[id].DeletedAt == nil {
+			careers = append(careers, s.careers[id])
+		}
+	}
+	return careers, nil
+}
+
+// ListCareers mirrors the yearly_salary and haversine_km comparisons of the
+// query
+func (s *Store) ListCareers(_ context.Context, arg database.ListCareersParams) ([]database.Career, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	today := time.Now().UTC().Truncate(24 * time.Hour)
+	var careers []database.Career
+	for _, id := range sortedKeys(s.careers) {
+		career := s.careers[id]
+		if career.DeletedAt != nil {
+			continue
+		}
+		if !arg.IncludeExpired && career.Enddate.Before(today) {
+			continue
+		}
+		if arg.SalaryMin.Valid {
+			yearly, ok := s.yearlySalary(career.SalaryMax, career, arg.Currency)
+			if !career.SalaryVisible || !ok || yearly < float64(arg.SalaryMin.Int64) {
+				continue
+			}
+		}
+		if arg.SalaryMax.Valid {
+			yearly, ok := s.yearlySalary(career.SalaryMin, career, arg.Currency)
+			if !career.SalaryVisible || !ok || yearly > float64(arg.SalaryMax.Int64) {
+				continue
+			}
+		}
+		if arg.NearLat.Valid && !within(career.Locations, geo.Point{Lat: arg.NearLat.Float64, Lon: arg.NearLon.Float64}, arg.RadiusKm) {
+			continue
+		}
+		if len(arg.SkillIds) > 0 && !s.hasSkills(id, arg.SkillIds, arg.AllSkills) {
+			continue
+		}
+		if !hasKeywords(career, arg.Keywords) {
+			continue
+		}
+		if arg.Jobtype.Valid && !strings.EqualFold(career.Jobtype, arg.Jobtype.String) {
+			continue
+		}
+		if arg.PublishedAfter.Valid && (career.PublishedSeq == nil || *career.PublishedSeq <= arg.PublishedAfter.Int64) {
+			continue
+		}
+		if !arg.IncludeUnpublished && career.PublishedAt == nil {
+			continue
+		}
+		careers = append(careers, career)
+	}
+	return careers, nil
+}
+
+// hasKeywords reports whether every keyword appears in the career's position,
+// company or description, ignoring case
+func hasKeywords(career database.Career, keywords []string) bool {
+	text := strings.ToLower(career.Position + " " + career.Company + " " + career.Description)
+	for _, keyword := range keywords {
+		if !strings.Contains(text, strings.ToLower(keyword)) {
+			return false
+		}
+	}
+	return true
+}
+
+// yearlySalary converts amount, paid per the career's pay period in its
+// currency, to a yearly amount in base
+func (s *Store) yearlySalary(amount *int64, career database.Career, base string) (float64, bool) {
+	if amount == nil || career.SalaryCurrency == nil || career.PayPeriod == nil {
+		return 0, false
+	}
+	from, ok := s.exchangeRates[*career.SalaryCurrency]
+	to, baseOK := s.exchangeRates[base]
+	if !ok || !baseOK {
+		return 0, false
+	}
+	yearly := float64(*amount)
+	switch *career.PayPeriod {
+	case "hourly":
+		yearly *= 2080
+	case "monthly":
+		yearly *= 12
+	}
+	return yearly / from * to, true
+}
+
+// checkCareer enforces the salary constraints of the career table
+// within reports whether any of locations is at most radiusKm from near
+func within(locations geo.Locations, near geo.Point, radiusKm float64) bool {
+	for _, location := range locations {
+		if point, ok := location.Point(); ok && geo.DistanceKm(point, near) <= radiusKm {
+			return true
+		}
+	}
+	return false
+}
+
+// storedLocations mirrors the encoding of the locations column, where nil is
+// stored as an empty array
+func storedLocations(locations geo.Locations) geo.Locations {
+	if locations == nil {
+		return geo.Locations{}
+	}
+	return locations
+}
+
+func (s *Store) checkCareer(career database.Career) error {
+	switch career.RemotePolicy {
+	case "onsite", "hybrid", "remote":
+	default:
+		return checkViolation("career", "career_remote_policy_check")
+	}
+	if career.RemoteCountries == nil {
+		return notNullViolation("career", "remote_countries")
+	}
+	if career.RemotePolicy == "onsite" && len(career.RemoteCountries) > 0 {
+		return checkViolation("career", "career_remote_countries_check")
+	}
+	if career.Seniority != nil {
+		switch *career.Seniority {
+		case "intern", "junior", "mid", "senior", "lead":
+		default:
+			return checkViolation("career", "career_seniority_check")
+		}
+	}
+
+	set := career.SalaryMin != nil && career.SalaryMax != nil && career.SalaryCurrency != nil && career.PayPeriod != nil
+	unset := career.SalaryMin == nil && career.SalaryMax == nil && career.SalaryCurrency == nil && career.PayPeriod == nil
+	if unset {
+		return nil
+	}
+	if !set || *career.SalaryMin < 0 || *career.SalaryMin > *career.SalaryMax {
+		return checkViolation("career", "career_salary_check")
+	}
+	switch *career.PayPeriod {
+	case "hourly", "monthly", "yearly":
+	default:
+		return checkViolation("career", "career_pay_period_check")
+	}
+	if _, ok := s.exchangeRates[*career.SalaryCurrency]; !ok {
+		return foreignKeyViolation("career", "career_salary_currency_fkey")
+	}
+	return nil
+}
+
+func (s *Store) HasExchangeRate(_ context.Context, currency string) (bool, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	_, ok := s.exchangeRates[currency]
+	return ok, nil
+}
+
+func (s *Store) ListExchangeRates(_ context.Context) ([]database.ListExchangeRatesRow, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	currencies := make([]string, 0, len(s.exchangeRates))
+	for currency := range s.exchangeRates {
+		currencies = append(currencies, currency)
+	}
+	slices.Sort(currencies)
+	var rates []database.ListExchangeRatesRow
+	for _, currency := range currencies {
+		rates = append(rates, database.ListExchangeRatesRow{Currency: currency, UnitsPerUsd: s.exchangeRates[currency]})
+	}
+	return rates, nil
+}
+
+func (s *Store) UpdateCareerByJobId(_ context.Context, arg database.UpdateCareerByJobIdParams) (database.Career, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	career, ok := s.careers[arg.Jobid]
+	if !ok || career.DeletedAt != nil {
+		return database.Career{}, pgx.ErrNoRows
+	}
+	if _, ok := s.companies[arg.CompanyID]; !ok {
+		return database.Career{}, foreignKeyViolation("career", "career_company_id_fkey")
+	}
+	career.Company = arg.Company
+	career.Position = arg.Position
+	career.Jobtype = arg.Jobtype
+	career.Description = arg.Description
+	career.DescriptionHTML = arg.DescriptionHTML
+	career.DescriptionExcerpt = arg.DescriptionExcerpt
+	career.CompanyID = arg.CompanyID
+	career.Version++
+	s.careers[career.Jobid] = career
+	return career, nil
+}
+
+func (s *Store) DeleteCareerByJobId(_ context.Context, jobid int64) (database.Career, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	career, ok := s.careers[jobid]
+	if !ok || career.DeletedAt != nil {
+		return database.Career{}, pgx.ErrNoRows
+	}
+	career.DeletedAt = deletedNow()
+	s.careers[jobid] = career
+	return career, nil
+}
+
+func (s *Store) GetDeletedCareerByJobIdForUpdate(_ context.Context, jobid int64) (database.Career, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	career, ok := s.careers[jobid]
+	if !ok || career.DeletedAt == nil {
+		return database.Career{}, pgx.ErrNoRows
+	}
+	return career, nil
+}
+
+func (s *Store) RestoreCareerByJobId(_ context.Context, jobid int64) (database.Career, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	career, ok := s.careers[jobid]
+	if !ok || career.DeletedAt == nil {
+		return database.Career{}, pgx.ErrNoRows
+	}
+	career.DeletedAt = nil
+	career.Version++
+	s.careers[jobid] = career
+	return career, nil
+}
+
+func (s *Store) ListDeletedCareers(_ context.Context) ([]database.Career, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var careers []database.Career
+	for _, id := range sortedKeys(s.careers) {
+		if s.careers[id].DeletedAt != nil {
+			careers = append(careers, s.careers[id])
+		}
+	}
+	byDeletedAt(careers, func(c database.Career) time.Time { return *c.DeletedAt }, func(c database.Career) int64 { return c.Jobid })
+	return careers, nil
+}
+
+func (s *Store) PurgeCareers(_ context.Context, before time.Time) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var purged int64
+	for id, career := range s.careers {
+		if career.DeletedAt != nil && career.DeletedAt.Before(before) {
+			delete(s.careers, id)
+			delete(s.revisions, id)
+			delete(s.careerSkills, id)
+			for key := range s.expiryReminders {
+				if key.jobid == id {
+					delete(s.expiryReminders, key)
+				}
+			}
+			for key := range s.savedCareers {
+				if key.jobid == id {
+					delete(s.savedCareers, key)
+				}
+			}
+			purged++
+		}
+	}
+	return purged, nil
+}
+
+// Career revisions
+
+func (s *Store) CreateCareerRevision(_ context.Context, arg database.CreateCareerRevisionParams) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.careers[arg.Jobid]; !ok {
+		return foreignKeyViolation("career_revisions", "career_revisions_jobid_fkey")
+	}
+	for _, revision := range s.revisions[arg.Jobid] {
+		if revision.Revision == arg.Revision {
+			return uniqueViolation("career_revisions", "career_revisions_pkey")
+		}
+	}
+	s.revisions[arg.Jobid] = append(s.revisions[arg.Jobid], database.CareerRevision{
+		Jobid:       arg.Jobid,
+		Revision:    arg.Revision,
+		Company:     arg.Company,
+		Position:    arg.Position,
+		Jobtype:     arg.Jobtype,
+		Description: arg.Description,
+		Startdate:   arg.Startdate,
+		Enddate:     arg.Enddate,
+		AuthorEmail: arg.AuthorEmail,
+		CreatedAt:   time.Now(),
+
+		SalaryMin:      arg.SalaryMin,
+		SalaryMax:      arg.SalaryMax,
+		SalaryCurrency: arg.SalaryCurrency,
+		PayPeriod:      arg.PayPeriod,
+		Equity:         arg.Equity,
+		SalaryVisible:  arg.SalaryVisible,
+
+		Locations:       storedLocations(arg.Locations),
+		RemotePolicy:    arg.RemotePolicy,
+		RemoteCountries: arg.RemoteCountries,
+		Seniority:       arg.Seniority,
+	})
+	return nil
+}
+
+// ListCareerRevisions returns the newest revision first
+func (s *Store) ListCareerRevisions(_ context.Context, arg database.ListCareerRevisionsParams) ([]database.CareerRevision, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var revisions []database.CareerRevision
+	stored := s.revisions[arg.Jobid]
+	for i := len(stored) - 1; i >= 0; i-- {
+		if arg.At == nil || !stored[i].CreatedAt.After(*arg.At) {
+			revisions = append(revisions, stored[i])
+		}
+	}
+	return revisions, nil
+}
+
+func (s *Store) GetCareerRevision(_ context.Context, arg database.GetCareerRevisionParams) (database.CareerRevision, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	for _, revision := range s.revisions[arg.Jobid] {
+		if revision.Revision == arg.Revision {
+			return revision, nil
+		}
+	}
+	return database.CareerRevision{}, pgx.ErrNoRows
+}
+
+// Companies
+
+func (s *Store) CreateCompany(_ context.Context, arg database.CreateCompanyParams) (database.Company, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, company := range s.companies {
+		if company.Slug == arg.Slug {
+			return database.Company{}, uniqueViolation("companies", "companies_slug_key")
+		}
+	}
+	s.lastCompanyID++
+	company := database.Company{
+		ID:          s.lastCompanyID,
+		Name:        arg.Name,
+		Slug:        arg.Slug,
+		Website:     arg.Website,
+		LogoURL:     arg.LogoURL,
+		Description: arg.Description,
+		CreatedAt:   time.Now(),
+	}
+	s.companies[company.ID] = company
+	return company, nil
+}
+
+func (s *Store) GetCompanyBySlug(_ context.Context, slug string) (database.Company, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	for _, company := range s.companies {
+		if company.Slug == slug {
+			return company, nil
+		}
+	}
+	return database.Company{}, pgx.ErrNoRows
+}
+
+func (s *Store) ListCompanies(_ context.Context) ([]database.Company, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var companies []database.Company
+	for _, id := range sortedKeys(s.companies) {
+		companies = append(companies, s.companies[id])
+	}
+	sort.SliceStable(companies, func(i, j int) bool { return companies[i].Name < companies[j].Name })
+	return companies, nil
+}
+
+// ListOpenCareers returns the careers that have not ended yet
+func (s *Store) ListOpenCareers(_ context.Context) ([]database.Career, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	today := time.Now().UTC().Truncate(24 * time.Hour)
+	var careers []database.Career
+	for _, id := range sortedKeys(s.careers) {
+		career := s.careers[id]
+		if career.DeletedAt == nil && !career.Enddate.Before(today) {
+			careers = append(careers, career)
+		}
+	}
+	return careers, nil
+}
+
+// ListOpenCareersByCompany returns the careers that have not ended yet,
+// earliest start first
+func (s *Store) ListOpenCareersByCompany(_ context.Context, companyID int64) ([]database.Career, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	today := time.Now().UTC().Truncate(24 * time.Hour)
+	var careers []database.Career
+	for _, id := range sortedKeys(s.careers) {
+		career := s.careers[id]
+		if career.CompanyID == companyID && career.DeletedAt == nil && !career.Enddate.Before(today) {
+			careers = append(careers, career)
+		}
+	}
+	sort.SliceStable(careers, func(i, j int) bool { return careers[i].Startdate.Before(careers[j].Startdate) })
+	return careers, nil
+}
+
+func (s *Store) AddCompanyRecruiter(_ context.Context, arg database.AddCompanyRecruiterParams) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.companies[arg.CompanyID]; !ok {
+		return foreignKeyViolation("company_recruiters", "company_recruiters_company_id_fkey")
+	}
+	if _, ok := s.users[arg.Userid]; !ok {
+		return foreignKeyViolation("company_recruiters", "company_recruiters_userid_fkey")
+	}
+	membership := database.CompanyRecruiter{CompanyID: arg.CompanyID, Userid: arg.Userid}
+	if s.recruiters[membership] {
+		return uniqueViolation("company_recruiters", "company_recruiters_pkey")
+	}
+	s.recruiters[membership] = true
+	return nil
+}
+
+func (s *Store) RemoveCompanyRecruiter(_ context.Context, arg database.RemoveCompanyRecruiterParams) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	membership := database.CompanyRecruiter{CompanyID: arg.CompanyID, Userid: arg.Userid}
+	if !s.recruiters[membership] {
+		return 0, nil
+	}
+	delete(s.recruiters, membership)
+	return 1, nil
+}
+
+func (s *Store) ListCompanyRecruiters(_ context.Context, companyID int64) ([]database.User, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var users []database.User
+	for _, id := range sortedKeys(s.users) {
+		membership := database.CompanyRecruiter{CompanyID: companyID, Userid: id}
+		if s.recruiters[membership] && s.users[id].DeletedAt == nil {
+			users = append(users, s.users[id])
+		}
+	}
+	return users, nil
+}
+
+func (s *Store) IsCompanyRecruiter(_ context.Context, arg database.IsCompanyRecruiterParams) (bool, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	for id, user := range s.users {
+		if user.Email == arg.Email && user.DeletedAt == nil && s.recruiters[database.CompanyRecruiter{CompanyID: arg.CompanyID, Userid: id}] {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// Profiles
+
+func (s *Store) CreateProfile(_ context.Context, arg database.CreateProfileParams) (database.Profile, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.users[arg.Userid]; !ok {
+		return database.Profile{}, foreignKeyViolation("profile", "profile_userid_fkey")
+	}
+
+	s.lastProfileID++
+	profile := database.Profile{
+		Profileid:   s.lastProfileID,
+		Userid:      arg.Userid,
+		Fullname:    arg.Fullname,
+		Age:         arg.Age,
+		Gender:      arg.Gender,
+		Address:     arg.Address,
+		Phonenumber: arg.Phonenumber,
+		Version:     1,
+
+		Locations:        storedLocations(arg.Locations),
+		RemotePreference: arg.RemotePreference,
+		ExpectedSalary:   arg.ExpectedSalary,
+		SalaryCurrency:   arg.SalaryCurrency,
+		YearsExperience:  arg.YearsExperience,
+	}
+	if err := s.checkProfile(profile); err != nil {
+		return database.Profile{}, err
+	}
+	s.profiles[profile.Profileid] = profile
+	return profile, nil
+}
+
+func (s *Store) checkProfile(profile database.Profile) error {
+	switch profile.RemotePreference {
+	case "onsite", "hybrid", "remote", "any":
+	default:
+		return checkViolation("profile", "profile_remote_preference_check")
+	}
+	if (profile.ExpectedSalary == nil) != (profile.SalaryCurrency == nil) ||
+		profile.ExpectedSalary != nil && *profile.ExpectedSalary < 0 {
+		return checkViolation("profile", "profile_expected_salary_check")
+	}
+	if profile.YearsExperience != nil && (*profile.YearsExperience < 0 || *profile.YearsExperience > 60) {
+		return checkViolation("profile", "profile_years_experience_check")
+	}
+	if profile.SalaryCurrency != nil {
+		if _, ok := s.exchangeRates[*profile.SalaryCurrency]; !ok {
+			return foreignKeyViolation("profile", "profile_salary_currency_fkey")
+		}
+	}
+	return nil
+}
+
+// profileByUserID returns the first profile of a user, like LIMIT 1 without ORDER BY
+func (s *Store) profileByUserID(userid int64) (database.Profile, bool) {
+	for _, id := range sortedKeys(s.profiles) {
+		if s.profiles[id].Userid == userid && s.profiles[id].DeletedAt == nil {
+			return s.profiles[id], true
+		}
+	}
+	return database.Profile{}, false
+}
+
+func (s *Store) GetProfileByuserId(_ context.Context, userid int64) (database.Profile, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	profile, ok := s.profileByUserID(userid)
+	if !ok {
+		return database.Profile{}, pgx.ErrNoRows
+	}
+	return profile, nil
+}
+
+// GetProfileByuserIdForUpdate needs no row lock as ExecTx serialises transactions
+func (s *Store) GetProfileByuserIdForUpdate(ctx context.Context, userid int64) (database.Profile, error) {
+	return s.GetProfileByuserId(ctx, userid)
+}
+
+func (s *Store) GetAllProfileDetails(_ context.Context) ([]database.Profile, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var profiles []database.Profile
+	for _, id := range sortedKeys(s.profiles) {
+		if s.profiles[id].DeletedAt == nil {
+			profiles = append(profiles, s.profiles[id])
+		}
+	}
+	return profiles, nil
+}
+
+// UpdateProfileByuserId returns the first updated row, like a :one UPDATE
+func (s *Store) UpdateProfileByuserId(_ context.Context, arg database.UpdateProfileByuserIdParams) (database.Profile, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var updated []database.Profile
+	for _, id := range sortedKeys(s.profiles) {
+		profile := s.profiles[id]
+		if profile.Userid != arg.Userid || profile.DeletedAt != nil {
+			continue
+		}
+		profile.Fullname = arg.Fullname
+		profile.Age = arg.Age
+		profile.Gender = arg.Gender
+		profile.Address = arg.Address
+		profile.Version++
+		s.profiles[id] = profile
+		updated = append(updated, profile)
+	}
+	if len(updated) == 0 {
+		return database.Profile{}, pgx.ErrNoRows
+	}
+	return updated[0], nil
+}
+
+// DeleteProfileByUserId moves every profile of the user to the trash and
+// returns the first
+func (s *Store) DeleteProfileByUserId(_ context.Context, userid int64) (database.Profile, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	deleted := s.setProfilesDeletedAt(userid, func(p database.Profile) bool { return p.DeletedAt == nil }, deletedNow())
+	if len(deleted) == 0 {
+		return database.Profile{}, pgx.ErrNoRows
+	}
+	return deleted[0], nil
+}
+
+// GetLatestDeletedProfileByUserIdForUpdate returns the user's most recently
+// deleted profile
+func (s *Store) GetLatestDeletedProfileByUserIdForUpdate(_ context.Context, userid int64) (database.Profile, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var latest *database.Profile
+	for _, id := range sortedKeys(s.profiles) {
+		profile := s.profiles[id]
+		if profile.Userid != userid || profile.DeletedAt == nil {
+			continue
+		}
+		if latest == nil || !profile.DeletedAt.Before(*latest.DeletedAt) {
+			latest = &profile
+		}
+	}
+	if latest == nil {
+		return database.Profile{}, pgx.ErrNoRows
+	}
+	return *latest, nil
+}
+
+func (s *Store) RestoreProfileById(_ context.Context, profileid int64) (database.Profile, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	profile, ok := s.profiles[profileid]
+	if !ok || profile.DeletedAt == nil {
+		return database.Profile{}, pgx.ErrNoRows
+	}
+	profile.DeletedAt = nil
+	profile.Version++
+	s.profiles[profileid] = profile
+	return profile, nil
+}
+
+func (s *Store) DeleteProfilesOfUser(_ context.Context, arg database.DeleteProfilesOfUserParams) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.setProfilesDeletedAt(arg.Userid, func(p database.Profile) bool { return p.DeletedAt == nil }, arg.DeletedAt)
+	return nil
+}
+
+// RestoreProfilesOfUser restores the profiles deleted together with the user
+func (s *Store) RestoreProfilesOfUser(_ context.Context, userid int64) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	userDeletedAt := s.users[userid].DeletedAt
+	if userDeletedAt == nil {
+		return nil
+	}
+	s.setProfilesDeletedAt(userid, func(p database.Profile) bool {
+		return p.DeletedAt != nil && p.DeletedAt.Equal(*userDeletedAt)
+	}, nil)
+	return nil
+}
+
+// setProfilesDeletedAt sets deleted_at on the user's profiles matching match,
+// bumping their version when they are restored
+func (s *Store) setProfilesDeletedAt(userid int64, match func(database.Profile) bool, deletedAt *time.Time) []database.Profile {
+	var changed []database.Profile
+	for _, id := range sortedKeys(s.profiles) {
+		profile := s.profiles[id]
+		if profile.Userid != userid || !match(profile) {
+			continue
+		}
+		profile.DeletedAt = deletedAt
+		if deletedAt == nil {
+			profile.Version++
+		}
+		s.profiles[id] = profile
+		changed = append(changed, profile)
+	}
+	return changed
+}
+
+func (s *Store) ListDeletedProfiles(_ context.Context) ([]database.Profile, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var profiles []database.Profile
+	for _, id := range sortedKeys(s.profiles) {
+		if s.profiles[id].DeletedAt != nil {
+			profiles = append(profiles, s.profiles[id])
+		}
+	}
+	byDeletedAt(profiles, func(p database.Profile) time.Time { return *p.DeletedAt }, func(p database.Profile) int64 { return p.Profileid })
+	return profiles, nil
+}
+
+func (s *Store) PurgeProfiles(_ context.Context, before time.Time) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var purged int64
+	for id, profile := range s.profiles {
+		if profile.DeletedAt != nil && profile.DeletedAt.Before(before) {
+			delete(s.profiles, id)
+			delete(s.profileSkills, id)
+			purged++
+		}
+	}
+	return purged, nil
+}
+
+// Skills
+
+func (s *Store) CreateSkill(_ context.Context, name string) (database.Skill, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, skill := range s.skills {
+		if strings.EqualFold(skill.Name, name) {
+			return database.Skill{}, uniqueViolation("skills", "skills_name_key")
+		}
+	}
+	s.lastSkillID++
+	skill := database.Skill{ID: s.lastSkillID, Name: name, CreatedAt: time.Now()}
+	s.skills[skill.ID] = skill
+	return skill, nil
+}
+
+func (s *Store) AddSkillAlias(_ context.Context, arg database.AddSkillAliasParams) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	alias := strings.ToLower(arg.Alias)
+	if _, ok := s.skills[arg.SkillID]; !ok {
+		return foreignKeyViolation("skill_aliases", "skill_aliases_skill_id_fkey")
+	}
+	if _, taken := s.skillAliases[alias]; taken {
+		return uniqueViolation("skill_aliases", "skill_aliases_pkey")
+	}
+	s.skillAliases[alias] = arg.SkillID
+	return nil
+}
+
+// FindSkill matches the name or an alias, ignoring case
+func (s *Store) FindSkill(_ context.Context, name string) (database.Skill, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	for _, id := range sortedKeys(s.skills) {
+		if strings.EqualFold(s.skills[id].Name, name) {
+			return s.skills[id], nil
+		}
+	}
+	if id, ok := s.skillAliases[strings.ToLower(name)]; ok {
+		return s.skills[id], nil
+	}
+	return database.Skill{}, pgx.ErrNoRows
+}
+
+func (s *Store) GetSkill(_ context.Context, id int64) (database.Skill, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	skill, ok := s.skills[id]
+	if !ok {
+		return database.Skill{}, pgx.ErrNoRows
+	}
+	return skill, nil
+}
+
+func (s *Store) ListSkillAliases(_ context.Context, skillID int64) ([]string, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var aliases []string
+	for alias, id := range s.skillAliases {
+		if id == skillID {
+			aliases = append(aliases, alias)
+		}
+	}
+	sort.Strings(aliases)
+	return aliases, nil
+}
+
+// ListSkills orders by lower-case name, then ID, like the query
+func (s *Store) ListSkills(_ context.Context, arg database.ListSkillsParams) ([]database.ListSkillsRow, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	prefix := strings.ToLower(arg.Prefix)
+	var rows []database.ListSkillsRow
+	for _, id := range sortedKeys(s.skills) {
+		row := database.ListSkillsRow{ID: id, Name: s.skills[id].Name, Aliases: []string{}}
+		match := strings.HasPrefix(strings.ToLower(row.Name), prefix)
+		for alias, skillID := range s.skillAliases {
+			if skillID == id {
+				row.Aliases = append(row.Aliases, alias)
+				match = match || strings.HasPrefix(alias, prefix)
+			}
+		}
+		if match {
+			sort.Strings(row.Aliases)
+			rows = append(rows, row)
+		}
+	}
+	sort.SliceStable(rows, func(i, j int) bool {
+		return strings.ToLower(rows[i].Name) < strings.ToLower(rows[j].Name)
+	})
+	if len(rows) > int(arg.MaxRows) {
+		rows = rows[:arg.MaxRows]
+	}
+	return rows, nil
+}
+
+func (s *Store) DeleteCareerSkills(_ context.Context, jobid int64) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.careerSkills, jobid)
+	return nil
+}
+
+func (s *Store) AddCareerSkill(_ context.Context, arg database.AddCareerSkillParams) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.careers[arg.Jobid]; !ok {
+		return foreignKeyViolation("career_skills", "career_skills_jobid_fkey")
+	}
+	if _, ok := s.skills[arg.SkillID]; !ok {
+		return foreignKeyViolation("career_skills", "career_skills_skill_id_fkey")
+	}
+	if arg.MinYears != nil && (*arg.MinYears < 0 || *arg.MinYears > 50) {
+		return checkViolation("career_skills", "career_skills_min_years_check")
+	}
+	for _, link := range s.careerSkills[arg.Jobid] {
+		if link.SkillID == arg.SkillID {
+			return uniqueViolation("career_skills", "career_skills_pkey")
+		}
+	}
+	s.careerSkills[arg.Jobid] = append(s.careerSkills[arg.Jobid], database.CareerSkill{
+		Jobid:    arg.Jobid,
+		SkillID:  arg.SkillID,
+		Required: arg.Required,
+		MinYears: arg.MinYears,
+	})
+	return nil
+}
+
+// ListCareerSkills lists required skills first, then by lower-case name
+func (s *Store) ListCareerSkills(_ context.Context, jobid int64) ([]database.ListCareerSkillsRow, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var rows []database.ListCareerSkillsRow
+	for _, link := range s.careerSkills[jobid] {
+		rows = append(rows, database.ListCareerSkillsRow{
+			SkillID:  link.SkillID,
+			Name:     s.skills[link.SkillID].Name,
+			Required: link.Required,
+			MinYears: link.MinYears,
+		})
+	}
+	sort.SliceStable(rows, func(i, j int) bool {
+		if rows[i].Required != rows[j].Required {
+			return rows[i].Required
+		}
+		return strings.ToLower(rows[i].Name) < strings.ToLower(rows[j].Name)
+	})
+	return rows, nil
+}
+
+// ListSkillsOfCareers orders like ListCareerSkills within each career
+func (s *Store) ListSkillsOfCareers(ctx context.Context, jobids []int64) ([]database.ListSkillsOfCareersRow, error) {
+	var rows []database.ListSkillsOfCareersRow
+	for _, jobid := range sortedIDs(jobids) {
+		skills, err := s.ListCareerSkills(ctx, jobid)
+		if err != nil {
+			return nil, err
+		}
+		for _, skill := range skills {
+			rows = append(rows, database.ListSkillsOfCareersRow{
+				Jobid:    jobid,
+				SkillID:  skill.SkillID,
+				Name:     skill.Name,
+				Required: skill.Required,
+				MinYears: skill.MinYears,
+			})
+		}
+	}
+	return rows, nil
+}
+
+// hasSkills reports whether the career is tagged with every skill in ids
+// when all is set, or with any of them otherwise
+func (s *Store) hasSkills(jobid int64, ids []int64, all bool) bool {
+	matched := 0
+	for _, link := range s.careerSkills[jobid] {
+		if slices.Contains(ids, link.SkillID) {
+			matched++
+		}
+	}
+	if all {
+		return matched >= len(ids)
+	}
+	return matched > 0
+}
+
+func (s *Store) DeleteProfileSkills(_ context.Context, profileid int64) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.profileSkills, profileid)
+	return nil
+}
+
+func (s *Store) AddProfileSkill(_ context.Context, arg database.AddProfileSkillParams) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.profiles[arg.Profileid]; !ok {
+		return foreignKeyViolation("profile_skills", "profile_skills_profileid_fkey")
+	}
+	if _, ok := s.skills[arg.SkillID]; !ok {
+		return foreignKeyViolation("profile_skills", "profile_skills_skill_id_fkey")
+	}
+	switch arg.Proficiency {
+	case "beginner", "intermediate", "advanced", "expert":
+	default:
+		return checkViolation("profile_skills", "profile_skills_proficiency_check")
+	}
+	for _, link := range s.profileSkills[arg.Profileid] {
+		if link.SkillID == arg.SkillID {
+			return uniqueViolation("profile_skills", "profile_skills_pkey")
+		}
+	}
+	s.profileSkills[arg.Profileid] = append(s.profileSkills[arg.Profileid], database.ProfileSkill(arg))
+	return nil
+}
+
+// ListProfileSkills orders by lower-case name
+func (s *Store) ListProfileSkills(_ context.Context, profileid int64) ([]database.ListProfileSkillsRow, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var rows []database.ListProfileSkillsRow
+	for _, link := range s.profileSkills[profileid] {
+		rows = append(rows, database.ListProfileSkillsRow{
+			SkillID:     link.SkillID,
+			Name:        s.skills[link.SkillID].Name,
+			Proficiency: link.Proficiency,
+		})
+	}
+	sort.SliceStable(rows, func(i, j int) bool {
+		return strings.ToLower(rows[i].Name) < strings.ToLower(rows[j].Name)
+	})
+	return rows, nil
+}
+
+// ListSkillsOfProfiles orders like ListProfileSkills within each profile
+func (s *Store) ListSkillsOfProfiles(ctx context.Context, profileids []int64) ([]database.ListSkillsOfProfilesRow, error) {
+	var rows []database.ListSkillsOfProfilesRow
+	for _, profileid := range sortedIDs(profileids) {
+		skills, err := s.ListProfileSkills(ctx, profileid)
+		if err != nil {
+			return nil, err
+		}
+		for _, skill := range skills {
+			rows = append(rows, database.ListSkillsOfProfilesRow{
+				Profileid:   profileid,
+				SkillID:     skill.SkillID,
+				Name:        skill.Name,
+				Proficiency: skill.Proficiency,
+			})
+		}
+	}
+	return rows, nil
+}
+
+func (s *Store) TouchCareer(_ context.Context, jobid int64) (database.Career, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	career, ok := s.careers[jobid]
+	if !ok || career.DeletedAt != nil {
+		return database.Career{}, pgx.ErrNoRows
+	}
+	career.Version++
+	s.careers[jobid] = career
+	return career, nil
+}
+
+func (s *Store) TouchProfile(_ context.Context, profileid int64) (database.Profile, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	profile, ok := s.profiles[profileid]
+	if !ok || profile.DeletedAt != nil {
+		return database.Profile{}, pgx.ErrNoRows
+	}
+	profile.Version++
+	s.profiles[profileid] = profile
+	return profile, nil
+}
+
+// Saved careers
+
+// savedKey is the primary key of saved_careers
+type savedKey struct {
+	userid, jobid int64
+}
+
+func (s *Store) GetSavedCareerForUpdate(_ context.Context, arg database.GetSavedCareerForUpdateParams) (database.SavedCareer, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	saved, ok := s.savedCareers[savedKey{arg.Userid, arg.Jobid}]
+	if !ok {
+		return database.SavedCareer{}, pgx.ErrNoRows
+	}
+	return saved, nil
+}
+
+func (s *Store) CreateSavedCareer(_ context.Context, arg database.CreateSavedCareerParams) (database.SavedCareer, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.users[arg.Userid]; !ok {
+		return database.SavedCareer{}, foreignKeyViolation("saved_careers", "saved_careers_userid_fkey")
+	}
+	if _, ok := s.careers[arg.Jobid]; !ok {
+		return database.SavedCareer{}, foreignKeyViolation("saved_careers", "saved_careers_jobid_fkey")
+	}
+	key := savedKey{arg.Userid, arg.Jobid}
+	if _, ok := s.savedCareers[key]; ok {
+		return database.SavedCareer{}, uniqueViolation("saved_careers", "saved_careers_pkey")
+	}
+	saved := database.SavedCareer{
+		Userid:   arg.Userid,
+		Jobid:    arg.Jobid,
+		Note:     arg.Note,
+		ListName: arg.ListName,
+		SavedAt:  time.Now(),
+	}
+	if err := checkSavedCareer(saved); err != nil {
+		return database.SavedCareer{}, err
+	}
+	s.savedCareers[key] = saved
+	return saved, nil
+}
+
+func (s *Store) UpdateSavedCareer(_ context.Context, arg database.UpdateSavedCareerParams) (database.SavedCareer, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	key := savedKey{arg.Userid, arg.Jobid}
+	saved, ok := s.savedCareers[key]
+	if !ok {
+		return database.SavedCareer{}, pgx.ErrNoRows
+	}
+	saved.Note, saved.ListName = arg.Note, arg.ListName
+	if err := checkSavedCareer(saved); err != nil {
+		return database.SavedCareer{}, err
+	}
+	s.savedCareers[key] = saved
+	return saved, nil
+}
+
+func checkSavedCareer(saved database.SavedCareer) error {
+	if saved.ListName != nil && *saved.ListName == "" {
+		return checkViolation("saved_careers", "saved_careers_list_name_check")
+	}
+	return nil
+}
+
+func (s *Store) DeleteSavedCareer(_ context.Context, arg database.DeleteSavedCareerParams) (database.SavedCareer, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	key := savedKey{arg.Userid, arg.Jobid}
+	saved, ok := s.savedCareers[key]
+	if !ok {
+		return database.SavedCareer{}, pgx.ErrNoRows
+	}
+	delete(s.savedCareers, key)
+	return saved, nil
+}
+
+// ListSavedCareers returns the newest save first
+func (s *Store) ListSavedCareers(_ context.Context, arg database.ListSavedCareersParams) ([]database.ListSavedCareersRow, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var rows []database.ListSavedCareersRow
+	for key, saved := range s.savedCareers {
+		if key.userid != arg.Userid {
+			continue
+		}
+		if arg.ListName.Valid && (saved.ListName == nil || !strings.EqualFold(*saved.ListName, arg.ListName.String)) {
+			continue
+		}
+		rows = append(rows, database.ListSavedCareersRow{SavedCareer: saved, Career: s.careers[key.jobid]})
+	}
+	sort.Slice(rows, func(i, j int) bool {
+		if a, b := rows[i].SavedCareer.SavedAt, rows[j].SavedCareer.SavedAt; !a.Equal(b) {
+			return a.After(b)
+		}
+		return rows[i].SavedCareer.Jobid < rows[j].SavedCareer.Jobid
+	})
+	return rows, nil
+}
+
+// ListSavedCareerLists orders by lower-case name
+func (s *Store) ListSavedCareerLists(_ context.Context, userid int64) ([]database.ListSavedCareerListsRow, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	counts := map[string]int64{}
+	for key, saved := range s.savedCareers {
+		if key.userid == userid && saved.ListName != nil {
+			counts[*saved.ListName]++
+		}
+	}
+	var rows []database.ListSavedCareerListsRow
+	for name, careers := range counts {
+		rows = append(rows, database.ListSavedCareerListsRow{Name: name, Careers: careers})
+	}
+	sort.Slice(rows, func(i, j int) bool {
+		if a, b := strings.ToLower(rows[i].Name), strings.ToLower(rows[j].Name); a != b {
+			return a < b
+		}
+		return rows[i].Name < rows[j].Name
+	})
+	return rows, nil
+}
+
+func (s *Store) CountCareerSaves(_ context.Context, jobid int64) (int64, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var saves int64
+	for key := range s.savedCareers {
+		if key.jobid == jobid {
+			saves++
+		}
+	}
+	return saves, nil
+}
+
+// Saved searches
+
+func (s *Store) CreateSavedSearch(_ context.Context, arg database.CreateSavedSearchParams) (database.SavedSearch, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.users[arg.Userid]; !ok {
+		return database.SavedSearch{}, foreignKeyViolation("saved_searches", "saved_searches_userid_fkey")
+	}
+	for _, search := range s.savedSearches {
+		if search.UnsubscribeToken == arg.UnsubscribeToken {
+			return database.SavedSearch{}, uniqueViolation("saved_searches", "saved_searches_unsubscribe_token_key")
+		}
+	}
+	search := database.SavedSearch{
+		Userid:           arg.Userid,
+		Name:             arg.Name,
+		Keywords:         arg.Keywords,
+		Jobtype:          arg.Jobtype,
+		Near:             arg.Near,
+		RadiusKm:         arg.RadiusKm,
+		SalaryMin:        arg.SalaryMin,
+		Currency:         arg.Currency,
+		Alerts:           arg.Alerts,
+		UnsubscribeToken: arg.UnsubscribeToken,
+		LastPublishedSeq: arg.LastPublishedSeq,
+		CreatedAt:        time.Now(),
+	}
+	if err := s.checkSavedSearch(search); err != nil {
+		return database.SavedSearch{}, err
+	}
+	s.lastSearchID++
+	search.ID = s.lastSearchID
+	s.savedSearches[search.ID] = search
+	return search, nil
+}
+
+func (s *Store) GetSavedSearchForUpdate(_ context.Context, arg database.GetSavedSearchForUpdateParams) (database.SavedSearch, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	search, ok := s.savedSearches[arg.ID]
+	if !ok || search.Userid != arg.Userid {
+		return database.SavedSearch{}, pgx.ErrNoRows
+	}
+	return search, nil
+}
+
+func (s *Store) UpdateSavedSearch(_ context.Context, arg database.UpdateSavedSearchParams) (database.SavedSearch, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	search, ok := s.savedSearches[arg.ID]
+	if !ok || search.Userid != arg.Userid {
+		return database.SavedSearch{}, pgx.ErrNoRows
+	}
+	search.Name, search.Keywords, search.Jobtype, search.Near = arg.Name, arg.Keywords, arg.Jobtype, arg.Near
+	search.RadiusKm, search.SalaryMin, search.Currency, search.Alerts = arg.RadiusKm, arg.SalaryMin, arg.Currency, arg.Alerts
+	if err := s.checkSavedSearch(search); err != nil {
+		return database.SavedSearch{}, err
+	}
+	s.savedSearches[search.ID] = search
+	return search, nil
+}
+
+// checkSavedSearch enforces the constraints of saved_searches other than the
+// user and the unsubscribe token
+func (s *Store) checkSavedSearch(search database.SavedSearch) error {
+	switch {
+	case search.Name == "":
+		return checkViolation("saved_searches", "saved_searches_name_check")
+	case search.RadiusKm != nil && *search.RadiusKm <= 0:
+		return checkViolation("saved_searches", "saved_searches_radius_km_check")
+	case search.SalaryMin != nil && *search.SalaryMin < 0:
+		return checkViolation("saved_searches", "saved_searches_salary_min_check")
+	}
+	if search.Currency != nil {
+		if _, ok := s.exchangeRates[*search.Currency]; !ok {
+			return foreignKeyViolation("saved_searches", "saved_searches_currency_fkey")
+		}
+	}
+	for _, other := range s.savedSearches {
+		if other.ID != search.ID && other.Userid == search.Userid && strings.EqualFold(other.Name, search.Name) {
+			return uniqueViolation("saved_searches", "saved_searches_name_key")
+		}
+	}
+	return nil
+}
+
+func (s *Store) DeleteSavedSearch(_ context.Context, arg database.DeleteSavedSearchParams) (database.SavedSearch, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	search, ok := s.savedSearches[arg.ID]
+	if !ok || search.Userid != arg.Userid {
+		return database.SavedSearch{}, pgx.ErrNoRows
+	}
+	delete(s.savedSearches, arg.ID)
+	return search, nil
+}
+
+func (s *Store) ListSavedSearches(_ context.Context, userid int64) ([]database.SavedSearch, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var searches []database.SavedSearch
+	for _, id := range sortedKeys(s.savedSearches) {
+		if search := s.savedSearches[id]; search.Userid == userid {
+			searches = append(searches, search)
+		}
+	}
+	return searches, nil
+}
+
+// ListAlertingSearches orders by user, then search
+func (s *Store) ListAlertingSearches(_ context.Context) ([]database.ListAlertingSearchesRow, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var rows []database.ListAlertingSearchesRow
+	for _, id := range sortedKeys(s.savedSearches) {
+		search := s.savedSearches[id]
+		user, ok := s.users[search.Userid]
+		if !search.Alerts || !ok || user.DeletedAt != nil {
+			continue
+		}
+		rows = append(rows, database.ListAlertingSearchesRow{SavedSearch: search, Username: user.Username, Email: user.Email})
+	}
+	sort.SliceStable(rows, func(i, j int) bool { return rows[i].SavedSearch.Userid < rows[j].SavedSearch.Userid })
+	return rows, nil
+}
+
+func (s *Store) AdvanceSavedSearch(_ context.Context, arg database.AdvanceSavedSearchParams) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if search, ok := s.savedSearches[arg.ID]; ok && search.LastPublishedSeq < arg.LastPublishedSeq {
+		search.LastPublishedSeq = arg.LastPublishedSeq
+		s.savedSearches[arg.ID] = search
+	}
+	return nil
+}
+
+func (s *Store) GetSavedSearchByToken(_ context.Context, token string) (database.SavedSearch, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	for _, search := range s.savedSearches {
+		if search.UnsubscribeToken == token {
+			return search, nil
+		}
+	}
+	return database.SavedSearch{}, pgx.ErrNoRows
+}
+
+func (s *Store) GetSavedSearchByTokenForUpdate(_ context.Context, token string) (database.SavedSearch, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	for _, search := range s.savedSearches {
+		if search.UnsubscribeToken == token {
+			return search, nil
+		}
+	}
+	return database.SavedSearch{}, pgx.ErrNoRows
+}
+
+func (s *Store) DisableSavedSearchAlerts(_ context.Context, id int64) (database.SavedSearch, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	search, ok := s.savedSearches[id]
+	if !ok {
+		return database.SavedSearch{}, pgx.ErrNoRows
+	}
+	search.Alerts = false
+	s.savedSearches[id] = search
+	return search, nil
+}
+
+// GetLastPublishedSeq includes deleted careers, like max(published_seq)
+func (s *Store) GetLastPublishedSeq(_ context.Context) (int64, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var last int64
+	for _, career := range s.careers {
+		if career.PublishedSeq != nil {
+			last = max(last, *career.PublishedSeq)
+		}
+	}
+	return last, nil
+}
+
+// Career expiry
+
+func (s *Store) CloseExpiredCareers(_ context.Context) ([]database.Career, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	today := time.Now().UTC().Truncate(24 * time.Hour)
+	var closed []database.Career
+	for _, id := range sortedKeys(s.careers) {
+		career := s.careers[id]
+		if career.DeletedAt != nil || career.ClosedAt != nil || !career.Enddate.Before(today) {
+			continue
+		}
+		now := time.Now()
+		career.ClosedAt = &now
+		career.Version++
+		s.careers[id] = career
+		closed = append(closed, career)
+	}
+	return closed, nil
+}
+
+func (s *Store) ExtendCareer(_ context.Context, arg database.ExtendCareerParams) (database.Career, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	career, ok := s.careers[arg.Jobid]
+	if !ok || career.DeletedAt != nil {
+		return database.Career{}, pgx.ErrNoRows
+	}
+	career.Enddate = arg.Enddate
+	career.ClosedAt = nil
+	if err := s.checkCareer(career); err != nil {
+		return database.Career{}, err
+	}
+	career.Version++
+	s.careers[arg.Jobid] = career
+	return career, nil
+}
+
+// reminderKey is the primary key of career_expiry_reminders
+type reminderKey struct {
+	jobid, userid int64
+}
+
+// ListExpiringCareers returns the open careers ending by until that the admin
+// has not been reminded of for their current enddate, soonest first
+func (s *Store) ListExpiringCareers(_ context.Context, arg database.ListExpiringCareersParams) ([]database.Career, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	today := time.Now().UTC().Truncate(24 * time.Hour)
+	var careers []database.Career
+	for _, id := range sortedKeys(s.careers) {
+		career := s.careers[id]
+		if career.DeletedAt != nil || career.Enddate.Before(today) || career.Enddate.After(arg.Until) {
+			continue
+		}
+		if reminded, ok := s.expiryReminders[reminderKey{id, arg.Userid}]; ok && reminded.Equal(career.Enddate) {
+			continue
+		}
+		careers = append(careers, career)
+	}
+	sort.SliceStable(careers, func(i, j int) bool { return careers[i].Enddate.Before(careers[j].Enddate) })
+	return careers, nil
+}
+
+func (s *Store) SaveExpiryReminder(_ context.Context, arg database.SaveExpiryReminderParams) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.careers[arg.Jobid]; !ok {
+		return foreignKeyViolation("career_expiry_reminders", "career_expiry_reminders_jobid_fkey")
+	}
+	if _, ok := s.users[arg.Userid]; !ok {
+		return foreignKeyViolation("career_expiry_reminders", "career_expiry_reminders_userid_fkey")
+	}
+	s.expiryReminders[reminderKey{arg.Jobid, arg.Userid}] = arg.Enddate
+	return nil
+}
+
+func (s *Store) ListAdmins(_ context.Context) ([]database.ListAdminsRow, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var admins []database.ListAdminsRow
+	for _, id := range sortedKeys(s.users) {
+		user := s.users[id]
+		if user.Role == "admin" && user.DeletedAt == nil {
+			admins = append(admins, database.ListAdminsRow{Userid: user.Userid, Email: user.Email})
+		}
+	}
+	return admins, nil
+}
+
+// Scheduled publishing
+
+func (s *Store) PublishDueCareers(_ context.Context) ([]database.Career, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	var published []database.Career
+	for _, id := range sortedKeys(s.careers) {
+		career := s.careers[id]
+		if career.DeletedAt != nil || career.PublishedAt != nil || career.PublishAt.After(now) {
+			continue
+		}
+		s.publish(&career)
+		career.Version++
+		s.careers[id] = career
+		published = append(published, career)
+	}
+	return published, nil
+}
+
+// Partial updates
+
+func (s *Store) PatchCareer(_ context.Context, jobid int64, set []database.Assignment) (database.Career, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	career, ok := s.careers[jobid]
+	if !ok || career.DeletedAt != nil {
+		return database.Career{}, pgx.ErrNoRows
+	}
+	for _, assignment := range set {
+		var ok bool
+		switch assignment.Column {
+		case "company":
+			career.Company, ok = assignment.Value.(string)
+		case "position":
+			career.Position, ok = assignment.Value.(string)
+		case "jobtype":
+			career.Jobtype, ok = assignment.Value.(string)
+		case "description":
+			career.Description, ok = assignment.Value.(string)
+		case "description_html":
+			career.DescriptionHTML, ok = assignment.Value.(string)
+		case "description_excerpt":
+			career.DescriptionExcerpt, ok = assignment.Value.(string)
+		case "startdate":
+			career.Startdate, ok = assignment.Value.(time.Time)
+		case "enddate":
+			career.Enddate, ok = assignment.Value.(time.Time)
+		case "company_id":
+			career.CompanyID, ok = assignment.Value.(int64)
+		case "salary_min":
+			career.SalaryMin, ok = assignment.Value.(*int64)
+		case "salary_max":
+			career.SalaryMax, ok = assignment.Value.(*int64)
+		case "salary_currency":
+			career.SalaryCurrency, ok = assignment.Value.(*string)
+		case "pay_period":
+			career.PayPeriod, ok = assignment.Value.(*string)
+		case "equity":
+			career.Equity, ok = assignment.Value.(bool)
+		case "salary_visible":
+			career.SalaryVisible, ok = assignment.Value.(bool)
+		case "locations":
+			var locations geo.Locations
+			locations, ok = assignment.Value.(geo.Locations)
+			career.Locations = storedLocations(locations)
+		case "remote_policy":
+			career.RemotePolicy, ok = assignment.Value.(string)
+		case "remote_countries":
+			career.RemoteCountries, ok = assignment.Value.([]string)
+		case "seniority":
+			career.Seniority, ok = assignment.Value.(*string)
+		case "closed_at":
+			career.ClosedAt, ok = assignment.Value.(*time.Time)
+		}
+		if !ok {
+			return database.Career{}, fmt.Errorf("updating career: cannot set %q to %T", assignment.Column, assignment.Value)
+		}
+	}
+	if _, exists := s.companies[career.CompanyID]; !exists {
+		return database.Career{}, foreignKeyViolation("career", "career_company_id_fkey")
+	}
+	if err := s.checkCareer(career); err != nil {
+		return database.Career{}, err
+	}
+	career.Version++
+	s.careers[jobid] = career
+	return career, nil
+}
+
+// PatchProfileByUserId updates every profile of the user and returns the first
+func (s *Store) PatchProfileByUserId(_ context.Context, userid int64, set []database.Assignment) (database.Profile, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var updated []database.Profile
+	for _, id := range sortedKeys(s.profiles) {
+		profile := s.profiles[id]
+		if profile.Userid != userid || profile.DeletedAt != nil {
+			continue
+		}
+		for _, assignment := range set {
+			var ok bool
+			switch assignment.Column {
+			case "fullname":
+				profile.Fullname, ok = assignment.Value.(string)
+			case "age":
+				profile.Age, ok = assignment.Value.(int32)
+			case "gender":
+				profile.Gender, ok = assignment.Value.(string)
+			case "address":
+				profile.Address, ok = assignment.Value.(string)
+			case "locations":
+				var locations geo.Locations
+				locations, ok = assignment.Value.(geo.Locations)
+				profile.Locations = storedLocations(locations)
+			case "remote_preference":
+				profile.RemotePreference, ok = assignment.Value.(string)
+			case "expected_salary":
+				profile.ExpectedSalary, ok = assignment.Value.(*int64)
+			case "salary_currency":
+				profile.SalaryCurrency, ok = assignment.Value.(*string)
+			case "years_experience":
+				profile.YearsExperience, ok = assignment.Value.(*int16)
+			}
+			if !ok {
+				return database.Profile{}, fmt.Errorf("updating profile: cannot set %q to %T", assignment.Column, assignment.Value)
+			}
+		}
+		if err := s.checkProfile(profile); err != nil {
+			return database.Profile{}, err
+		}
+		profile.Version++
+		s.profiles[id] = profile
+		updated = append(updated, profile)
+	}
+	if len(updated) == 0 {
+		return database.Profile{}, pgx.ErrNoRows
+	}
+	return updated[0], nil
+}
+
+// Audit events
+
+func (s *Store) CreateAuditEvent(_ context.Context, arg database.CreateAuditEventParams) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.lastAuditID++
+	s.auditEvents = append(s.auditEvents, database.AuditEvent{
+		ID:           s.lastAuditID,
+		OccurredAt:   time.Now(),
+		ActorEmail:   arg.ActorEmail,
+		ActorRole:    arg.ActorRole,
+		Action:       arg.Action,
+		ResourceType: arg.ResourceType,
+		ResourceID:   arg.ResourceID,
+		Changes:      arg.Changes,
+		RequestID:    arg.RequestID,
+		IP:           arg.IP,
+	})
+	return nil
+}
+
+// ListAuditEvents returns the newest matching events first
+func (s *Store) ListAuditEvents(_ context.Context, arg database.ListAuditEventsParams) ([]database.AuditEvent, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var events []database.AuditEvent
+	for i := len(s.auditEvents) - 1; i >= 0 && len(events) < int(arg.MaxRows); i-- {
+		event := s.auditEvents[i]
+		switch {
+		case arg.Actor.Valid && event.ActorEmail != arg.Actor.String,
+			arg.ResourceType.Valid && event.ResourceType != arg.ResourceType.String,
+			arg.ResourceID.Valid && event.ResourceID != arg.ResourceID.Int64,
+			arg.Since != nil && event.OccurredAt.Before(*arg.Since),
+			arg.Until != nil && !event.OccurredAt.Before(*arg.Until),
+			arg.BeforeID.Valid && event.ID >= arg.BeforeID.Int64:
+			continue
+		}
+		events = append(events, event)
+	}
+	return events, nil
+}
+
+// Career imports
+
+func (s *Store) CreateCareerImport(_ context.Context, arg database.CreateCareerImportParams) (database.CareerImport, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.lastImportID++
+	job := database.CareerImport{
+		ID:             s.lastImportID,
+		Format:         arg.Format,
+		Mode:           arg.Mode,
+		DryRun:         arg.DryRun,
+		Status:         "running",
+		TotalRows:      arg.TotalRows,
+		Jobids:         []int64{},
+		Errors:         json.RawMessage("[]"),
+		IgnoredColumns: arg.IgnoredColumns,
+		CreatedBy:      arg.CreatedBy,
+		CreatedAt:      time.Now(),
+	}
+	if job.IgnoredColumns == nil {
+		return database.CareerImport{}, notNullViolation("career_imports", "ignored_columns")
+	}
+	s.careerImports[job.ID] = job
+	return job, nil
+}
+
+func (s *Store) GetCareerImport(_ context.Context, id int64) (database.CareerImport, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	job, ok := s.careerImports[id]
+	if !ok {
+		return database.CareerImport{}, pgx.ErrNoRows
+	}
+	return job, nil
+}
+
+func (s *Store) UpdateCareerImportProgress(_ context.Context, arg database.UpdateCareerImportProgressParams) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if job, ok := s.careerImports[arg.ID]; ok {
+		job.ProcessedRows = arg.ProcessedRows
+		s.careerImports[arg.ID] = job
+	}
+	return nil
+}
+
+func (s *Store) FinishCareerImport(_ context.Context, arg database.FinishCareerImportParams) (database.CareerImport, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	job, ok := s.careerImports[arg.ID]
+	if !ok {
+		return database.CareerImport{}, pgx.ErrNoRows
+	}
+	if arg.Jobids == nil {
+		return database.CareerImport{}, notNullViolation("career_imports", "jobids")
+	}
+	now := time.Now()
+	job.Status, job.ProcessedRows, job.CreatedRows, job.FailedRows = arg.Status, arg.ProcessedRows, arg.CreatedRows, arg.FailedRows
+	job.Jobids, job.Errors, job.FinishedAt = arg.Jobids, arg.Errors, &now
+	s.careerImports[arg.ID] = job
+	return job, nil
+}
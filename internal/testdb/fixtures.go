@@ -0,0 +1,212 @@
+package testdb
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"jobApps/geo"
+	"jobApps/internal/database"
+	"jobApps/markdown"
+	"jobApps/slug"
+
+	"github.com/jackc/pgx/v4"
+)
+
+// sequence keeps generated emails and phone numbers unique across fixtures
+var sequence atomic.Int64
+
+func next() int64 {
+	return sequence.Add(1)
+}
+
+// UserOption customises a user fixture
+type UserOption func(*database.CreateUserParams)
+
+// WithRole sets the user's role
+func WithRole(role string) UserOption {
+	return func(p *database.CreateUserParams) { p.Role = role }
+}
+
+// WithEmail sets the user's email
+func WithEmail(email string) UserOption {
+	return func(p *database.CreateUserParams) { p.Email = email }
+}
+
+// CreateUser inserts a user with unique defaults. The password is stored as
+// given; it is not hashed.
+func CreateUser(t testing.TB, q database.Querier, opts ...UserOption) database.User {
+	t.Helper()
+	n := next()
+	params := database.CreateUserParams{
+		Username:    fmt.Sprintf("user%d", n),
+		Email:       fmt.Sprintf("user%d@example.com", n),
+		Phonenumber: fmt.Sprintf("+1415555%04d", n%10000),
+		Password:    "not-a-bcrypt-hash",
+		Role:        "user",
+	}
+	for _, opt := range opts {
+		opt(&params)
+	}
+
+	user, err := q.CreateUser(context.Background(), params)
+	if err != nil {
+		t.Fatalf("creating user fixture: %v", err)
+	}
+	return user
+}
+
+// ProfileOption customises a profile fixture
+type ProfileOption func(*database.CreateProfileParams)
+
+// WithAge sets the profile's age
+func WithAge(age int32) ProfileOption {
+	return func(p *database.CreateProfileParams) { p.Age = age }
+}
+
+// WithPreferences sets where the candidate is willing to work and how
+// remotely
+func WithPreferences(remote string, locations ...geo.Location) ProfileOption {
+	return func(p *database.CreateProfileParams) {
+		p.RemotePreference, p.Locations = remote, locations
+	}
+}
+
+// WithExpectedSalary sets the yearly salary the candidate expects
+func WithExpectedSalary(amount int64, currency string) ProfileOption {
+	return func(p *database.CreateProfileParams) {
+		p.ExpectedSalary, p.SalaryCurrency = &amount, &currency
+	}
+}
+
+// WithExperience sets the candidate's years of experience
+func WithExperience(years int16) ProfileOption {
+	return func(p *database.CreateProfileParams) { p.YearsExperience = &years }
+}
+
+// CreateProfile inserts a profile owned by owner
+func CreateProfile(t testing.TB, q database.Querier, owner database.User, opts ...ProfileOption) database.Profile {
+	t.Helper()
+	params := database.CreateProfileParams{
+		Userid:           owner.Userid,
+		Fullname:         "Test " + owner.Username,
+		Age:              30,
+		Gender:           "other",
+		Address:          "1 Test Street",
+		Phonenumber:      owner.Phonenumber,
+		Locations:        geo.Locations{},
+		RemotePreference: "any",
+	}
+	for _, opt := range opts {
+		opt(&params)
+	}
+
+	profile, err := q.CreateProfile(context.Background(), params)
+	if err != nil {
+		t.Fatalf("creating profile fixture: %v", err)
+	}
+	return profile
+}
+
+// CreateCompany inserts a company with the given name, or returns the one
+// already using its slug
+func CreateCompany(t testing.TB, q database.Querier, name string) database.Company {
+	t.Helper()
+	ctx := context.Background()
+	company, err := q.GetCompanyBySlug(ctx, slug.Company(name))
+	if errors.Is(err, pgx.ErrNoRows) {
+		company, err = q.CreateCompany(ctx, database.CreateCompanyParams{Name: name, Slug: slug.Company(name)})
+	}
+	if err != nil {
+		t.Fatalf("creating company fixture: %v", err)
+	}
+	return company
+}
+
+// CareerOption customises a career fixture
+type CareerOption func(*database.CreateCareerParams)
+
+// WithCompany sets the career's company
+func WithCompany(company string) CareerOption {
+	return func(p *database.CreateCareerParams) { p.Company = company }
+}
+
+// WithDates sets the career's start and end dates
+func WithDates(start, end time.Time) CareerOption {
+	return func(p *database.CreateCareerParams) {
+		p.Startdate = start
+		p.Enddate = end
+	}
+}
+
+// WithDescription sets the career's Markdown description
+func WithDescription(description string) CareerOption {
+	return func(p *database.CreateCareerParams) { p.Description = description }
+}
+
+// WithSalary sets the career's salary range
+func WithSalary(min, max int64, currency, payPeriod string) CareerOption {
+	return func(p *database.CreateCareerParams) {
+		p.SalaryMin, p.SalaryMax = &min, &max
+		p.SalaryCurrency, p.PayPeriod = &currency, &payPeriod
+	}
+}
+
+// WithLocations sets where the career is based
+func WithLocations(locations ...geo.Location) CareerOption {
+	return func(p *database.CreateCareerParams) {
+		p.Locations = locations
+	}
+}
+
+// WithRemote sets the career's remote policy and allowed countries
+func WithRemote(policy string, countries ...string) CareerOption {
+	return func(p *database.CreateCareerParams) {
+		p.RemotePolicy, p.RemoteCountries = policy, append([]string{}, countries...)
+	}
+}
+
+// WithSeniority sets the level the career is hired at
+func WithSeniority(seniority string) CareerOption {
+	return func(p *database.CreateCareerParams) { p.Seniority = &seniority }
+}
+
+// WithPublishAt schedules the career to be published at publishAt
+func WithPublishAt(publishAt time.Time) CareerOption {
+	return func(p *database.CreateCareerParams) { p.PublishAt = publishAt }
+}
+
+// CreateCareer inserts a published career post running for the next 90
+// days, at the company named by its company field
+func CreateCareer(t testing.TB, q database.Querier, opts ...CareerOption) database.Career {
+	t.Helper()
+	start := time.Now().UTC().Truncate(24 * time.Hour)
+	params := database.CreateCareerParams{
+		Company:     fmt.Sprintf("Company %d", next()),
+		Position:    "Software Engineer",
+		Jobtype:     "Full-time",
+		Description: "Build and run services",
+		Startdate:   start,
+		Enddate:     start.AddDate(0, 0, 90),
+
+		SalaryVisible:   true,
+		RemotePolicy:    "onsite",
+		RemoteCountries: []string{},
+
+		PublishAt: time.Now(),
+	}
+	for _, opt := range opts {
+		opt(&params)
+	}
+	params.CompanyID = CreateCompany(t, q, params.Company).ID
+	params.DescriptionHTML, params.DescriptionExcerpt = markdown.Render(params.Description)
+
+	career, err := q.CreateCareer(context.Background(), params)
+	if err != nil {
+		t.Fatalf("creating career fixture: %v", err)
+	}
+	return career
+}
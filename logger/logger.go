@@ -0,0 +1,113 @@
+package logger
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"os"
+	"strings"
+)
+
+type ctxKey struct{}
+
+// RequestIDKey is the attribute key used for request IDs on every log line
+const RequestIDKey = "request_id"
+
+const redacted = "[REDACTED]"
+
+// sensitiveKeys are attribute and payload keys whose values never reach the logs
+var sensitiveKeys = map[string]bool{
+	"password":      true,
+	"token":         true,
+	"authorization": true,
+	"email":         true,
+}
+
+// Init installs a JSON slog logger writing to stdout as the default logger
+func Init(level string) {
+	slog.SetDefault(New(os.Stdout, ParseLevel(level)))
+}
+
+// New returns a JSON logger that redacts sensitive attributes
+func New(w io.Writer, level slog.Level) *slog.Logger {
+	return slog.New(slog.NewJSONHandler(w, &slog.HandlerOptions{
+		Level:       level,
+		ReplaceAttr: replaceAttr,
+	}))
+}
+
+// ParseLevel converts a LOG_LEVEL value into a slog level, defaulting to info
+func ParseLevel(level string) slog.Level {
+	switch strings.ToLower(strings.TrimSpace(level)) {
+	case "debug":
+		return slog.LevelDebug
+	case "warn", "warning":
+		return slog.LevelWarn
+	case "error":
+		return slog.LevelError
+	default:
+		return slog.LevelInfo
+	}
+}
+
+// WithContext stores a logger in the context
+func WithContext(ctx context.Context, l *slog.Logger) context.Context {
+	return context.WithValue(ctx, ctxKey{}, l)
+}
+
+// FromContext returns the request scoped logger, or the default logger
+func FromContext(ctx context.Context) *slog.Logger {
+	if l, ok := ctx.Value(ctxKey{}).(*slog.Logger); ok {
+		return l
+	}
+	return slog.Default()
+}
+
+// Redact returns a copy of a JSON-serialisable payload with sensitive fields masked
+func Redact(v any) any {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return v
+	}
+	var generic any
+	if err := json.Unmarshal(data, &generic); err != nil {
+		return v
+	}
+	return redactValue(generic)
+}
+
+func redactValue(v any) any {
+	switch val := v.(type) {
+	case map[string]any:
+		for key, inner := range val {
+			if sensitiveKeys[strings.ToLower(key)] {
+				val[key] = redacted
+				continue
+			}
+			val[key] = redactValue(inner)
+		}
+		return val
+	case []any:
+		for i, inner := range val {
+			val[i] = redactValue(inner)
+		}
+		return val
+	default:
+		return v
+	}
+}
+
+func replaceAttr(_ []string, a slog.Attr) slog.Attr {
+	if sensitiveKeys[strings.ToLower(a.Key)] {
+		return slog.String(a.Key, redacted)
+	}
+	if a.Value.Kind() == slog.KindAny {
+		switch a.Value.Any().(type) {
+		case error, string:
+			return a
+		}
+		return slog.Any(a.Key, Redact(a.Value.Any()))
+	}
+	return a
+}
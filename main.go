@@ -2,27 +2,169 @@ package main
 
 import (
 	"context"
-	_ "embed"
+	"errors"
 	"fmt"
 	"jobApps/drivers"
+	"jobApps/helper"
+	"jobApps/internal/database"
+	"jobApps/logger"
+	"jobApps/notify"
 	router "jobApps/routers"
+	"jobApps/scheduler"
+	"jobApps/service"
+	"jobApps/sql/migrations"
+	"log/slog"
+	"os"
+	"strings"
+	"time"
 )
 
-//go:embed sql/schema.sql
-var schemaSql string
-
 func main() {
-	conn, err := drivers.DataBaseConnection()
-	if err != nil {
-		fmt.Println(err)
-		return
+	if err := helper.Configure(".env"); err != nil {
+		os.Exit(1)
 	}
+	logger.Init(os.Getenv("LOG_LEVEL"))
 
-	_, err = conn.Exec(context.Background(), schemaSql)
+	pool, err := drivers.DataBaseConnection()
 	if err != nil {
-		fmt.Println("table creation failed:", err)
-		return
+		slog.Error("database connection failed", slog.Any("error", err))
+		os.Exit(1)
+	}
+
+	defer pool.Close()
+
+	if err := migrations.Apply(context.Background(), pool); err != nil {
+		slog.Error("table creation failed", slog.Any("error", err))
+		os.Exit(1)
+	}
+
+	svc := service.New(database.NewStore(pool, helper.DurationEnv("DB_QUERY_TIMEOUT", 5*time.Second)))
+	if currency := os.Getenv("BASE_CURRENCY"); currency != "" {
+		svc.BaseCurrency = strings.ToUpper(currency)
+	}
+	if publicURL := os.Getenv("PUBLIC_URL"); publicURL != "" {
+		svc.PublicURL = strings.TrimSuffix(publicURL, "/")
 	}
+	svc.CareerWebhookURL = careerWebhookURL()
+	svc.ImportSyncRows = helper.IntEnv("IMPORT_SYNC_ROWS", 1000)
+	if svc.Notifier, err = alertNotifier(); err != nil {
+		slog.Error("invalid notifier configuration", slog.Any("error", err))
+		os.Exit(1)
+	}
+
+	scheduler.Start(context.Background(), purgeTrashJob(svc), jobAlertsJob(svc), careerExpiryJob(svc), publishCareersJob(svc))
+
+	router.Router(svc)
+}
 
-	router.Router(conn)
+// purgeTrashJob permanently deletes rows that have been in the trash for
+// longer than TRASH_RETENTION, checking every TRASH_PURGE_INTERVAL
+func purgeTrashJob(svc *service.Service) scheduler.Job {
+	retention := helper.DurationEnv("TRASH_RETENTION", 30*24*time.Hour)
+	return scheduler.Job{
+		Name:     "purge_trash",
+		Interval: helper.DurationEnv("TRASH_PURGE_INTERVAL", time.Hour),
+		Run: func(ctx context.Context) error {
+			purged, err := svc.PurgeTrash(ctx, time.Now().Add(-retention))
+			if err != nil {
+				return err
+			}
+			slog.Info("trash purged", slog.Int64("careers", purged.Careers), slog.Int64("profiles", purged.Profiles), slog.Int64("users", purged.Users))
+			return nil
+		},
+	}
+}
+
+// jobAlertsJob sends the job alert digests every JOB_ALERT_INTERVAL
+func jobAlertsJob(svc *service.Service) scheduler.Job {
+	return scheduler.Job{
+		Name:     "job_alerts",
+		Interval: helper.DurationEnv("JOB_ALERT_INTERVAL", 24*time.Hour),
+		Run: func(ctx context.Context) error {
+			sent, err := svc.SendJobAlerts(ctx)
+			if err != nil {
+				return err
+			}
+			slog.Info("job alerts sent", slog.Int("digests", sent.Digests), slog.Int("careers", sent.Careers))
+			return nil
+		},
+	}
+}
+
+// careerExpiryJob closes the careers whose enddate has passed and reminds
+// admins of those ending within EXPIRY_REMINDER_DAYS, every
+// CAREER_EXPIRY_INTERVAL
+func careerExpiryJob(svc *service.Service) scheduler.Job {
+	days := helper.IntEnv("EXPIRY_REMINDER_DAYS", 7)
+	return scheduler.Job{
+		Name:     "career_expiry",
+		Interval: helper.DurationEnv("CAREER_EXPIRY_INTERVAL", time.Hour),
+		Run: func(ctx context.Context) error {
+			closed, err := svc.CloseExpiredCareers(ctx)
+			if err != nil {
+				return err
+			}
+			sent, err := svc.SendExpiryReminders(ctx, days)
+			if err != nil {
+				return err
+			}
+			slog.Info("careers expired", slog.Int("closed", len(closed)), slog.Int("reminded", sent.Careers), slog.Int("admins", sent.Admins))
+			return nil
+		},
+	}
+}
+
+// publishCareersJob publishes the careers whose publish_at has come, every
+// PUBLISH_INTERVAL
+func publishCareersJob(svc *service.Service) scheduler.Job {
+	return scheduler.Job{
+		Name:     "publish_careers",
+		Interval: helper.DurationEnv("PUBLISH_INTERVAL", time.Minute),
+		Run: func(ctx context.Context) error {
+			published, err := svc.PublishDueCareers(ctx)
+			if len(published) > 0 {
+				slog.Info("careers published", slog.Int("careers", len(published)))
+			}
+			return err
+		},
+	}
+}
+
+// careerWebhookURL reads CAREER_WEBHOOK_URL, defaulting to the local webhook
+// server; "off" disables the notification
+func careerWebhookURL() string {
+	switch value := os.Getenv("CAREER_WEBHOOK_URL"); value {
+	case "":
+		return "http://localhost:9000/webhook"
+	case "off":
+		return ""
+	default:
+		return value
+	}
+}
+
+// alertNotifier picks how digests are delivered from ALERT_NOTIFIER: log (the
+// default), smtp or webhook
+func alertNotifier() (notify.Notifier, error) {
+	switch kind := os.Getenv("ALERT_NOTIFIER"); kind {
+	case "", "log":
+		return notify.Log{}, nil
+	case "smtp":
+		return notify.Mail{Addr: envOr("SMTP_ADDR", "localhost:1025"), From: envOr("ALERT_FROM", "alerts@localhost")}, nil
+	case "webhook":
+		url := os.Getenv("ALERT_WEBHOOK_URL")
+		if url == "" {
+			return nil, errors.New("ALERT_NOTIFIER=webhook needs ALERT_WEBHOOK_URL")
+		}
+		return notify.Webhook{URL: url}, nil
+	default:
+		return nil, fmt.Errorf("unknown ALERT_NOTIFIER %q; use log, smtp or webhook", kind)
+	}
+}
+
+func envOr(name, fallback string) string {
+	if value := os.Getenv(name); value != "" {
+		return value
+	}
+	return fallback
 }
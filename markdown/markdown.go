@@ -0,0 +1,76 @@
+// Package markdown renders career descriptions, which are written in
+// Markdown, to HTML that is safe to embed in a page and to a plain-text
+// excerpt for lists of careers.
+package markdown
+
+import (
+	"bytes"
+	"html"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/microcosm-cc/bluemonday"
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/extension"
+)
+
+// ExcerptLength is the most characters an excerpt has, its ellipsis included
+const ExcerptLength = 200
+
+var (
+	// renderer leaves raw HTML out of the output; sanitizer is the line of
+	// defence all the same
+	renderer = goldmark.New(goldmark.WithExtensions(
+		extension.NewTable(extension.WithTableCellAlignMethod(extension.TableCellAlignAttribute)),
+		extension.Strikethrough, extension.Linkify))
+
+	// sanitizer keeps the tags Markdown produces for text, lists, code,
+	// quotes and tables, and links to http, https and mailto URLs only
+	sanitizer = func() *bluemonday.Policy {
+		policy := bluemonday.NewPolicy()
+		policy.AllowElements("p", "br", "hr", "h1", "h2", "h3", "h4", "h5", "h6",
+			"strong", "em", "del", "code", "pre", "blockquote", "ul", "ol", "li",
+			"table", "thead", "tbody", "tr", "th", "td")
+		policy.AllowAttrs("start").Matching(bluemonday.Integer).OnElements("ol")
+		policy.AllowAttrs("align").Matching(regexp.MustCompile(`^(left|center|right)$`)).OnElements("th", "td")
+		policy.AllowAttrs("href").OnElements("a")
+		policy.AllowURLSchemes("http", "https", "mailto")
+		policy.AllowRelativeURLs(false)
+		policy.RequireParseableURLs(true)
+		policy.RequireNoFollowOnLinks(true)
+		return policy
+	}()
+
+	// text strips every tag; the renderer ends each block with a newline, so
+	// the words of adjacent blocks stay apart
+	text = bluemonday.StrictPolicy()
+)
+
+// Render returns the sanitized HTML of a Markdown source and its plain-text
+// excerpt: the words of the text, cut after ExcerptLength characters at the
+// last whole word with an ellipsis.
+func Render(source string) (htmlText, excerpt string) {
+	var rendered bytes.Buffer
+	if err := renderer.Convert([]byte(source), &rendered); err != nil {
+		// goldmark only fails on a failing writer, and a buffer does not
+		rendered.Reset()
+		rendered.WriteString(html.EscapeString(source))
+	}
+	htmlText = sanitizer.Sanitize(rendered.String())
+	words := strings.Fields(html.UnescapeString(text.Sanitize(htmlText)))
+	return htmlText, cut(strings.Join(words, " "))
+}
+
+// cut shortens text to ExcerptLength characters
+func cut(text string) string {
+	if utf8.RuneCountInString(text) <= ExcerptLength {
+		return text
+	}
+	runes := []rune(text)[:ExcerptLength-1]
+	short := string(runes)
+	if i := strings.LastIndexByte(short, ' '); i > 0 {
+		short = short[:i]
+	}
+	return strings.TrimRight(short, " ,.;:") + "…"
+}
@@ -0,0 +1,45 @@
+package markdown
+
+import (
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+func TestRender(t *testing.T) {
+	tests := []struct {
+		source, html, excerpt string
+	}{
+		{"We are **hiring**.", "<p>We are <strong>hiring</strong>.</p>\n", "We are hiring."},
+		{"## Role\n\n- Go\n- SQL", "<h2>Role</h2>\n<ul>\n<li>Go</li>\n<li>SQL</li>\n</ul>\n", "Role Go SQL"},
+		{"Apply at [our site](https://example.com/jobs)", `<p>Apply at <a href="https://example.com/jobs" rel="nofollow">our site</a></p>` + "\n", "Apply at our site"},
+		{"[click](javascript:alert(1))", "<p>click</p>\n", "click"},
+		{"[click](JaVaScRiPt:alert(1))", "<p>click</p>\n", "click"},
+		{"<script>alert(1)</script>", "\n", ""},
+		{"Hi <img src=x onerror=alert(1)> there", "<p>Hi  there</p>\n", "Hi there"},
+		{`<a href="javascript:alert(1)">x</a>`, "<p>x</p>\n", "x"},
+		{"Fish & chips < 5", "<p>Fish &amp; chips &lt; 5</p>\n", "Fish & chips < 5"},
+		{"| a | b |\n|---|:-:|\n| 1 | 2 |", "<table>\n<thead>\n<tr>\n<th>a</th>\n<th align=\"center\">b</th>\n</tr>\n</thead>\n<tbody>\n<tr>\n<td>1</td>\n<td align=\"center\">2</td>\n</tr>\n</tbody>\n</table>\n", "a b 1 2"},
+		{"", "", ""},
+	}
+	for _, test := range tests {
+		html, excerpt := Render(test.source)
+		if html != test.html {
+			t.Errorf("Render(%q) HTML = %q, want %q", test.source, html, test.html)
+		}
+		if excerpt != test.excerpt {
+			t.Errorf("Render(%q) excerpt = %q, want %q", test.source, excerpt, test.excerpt)
+		}
+	}
+}
+
+func TestExcerptIsCut(t *testing.T) {
+	source := "# Backend Engineer\n\n" + strings.Repeat("Build *reliable* services. ", 20)
+	_, excerpt := Render(source)
+	if n := utf8.RuneCountInString(excerpt); n > ExcerptLength {
+		t.Errorf("excerpt has %d characters, want at most %d", n, ExcerptLength)
+	}
+	if !strings.HasPrefix(excerpt, "Backend Engineer Build reliable services.") || !strings.HasSuffix(excerpt, " Build…") {
+		t.Errorf("excerpt = %q", excerpt)
+	}
+}
@@ -0,0 +1,36 @@
+package metrics
+
+import (
+	"expvar"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Counters are published through expvar and served as JSON by Handler
+
+// LegacyRouteRequests counts requests per deprecated route, keyed by "METHOD /path"
+var LegacyRouteRequests = expvar.NewMap("legacy_route_requests")
+
+// Cancellations counts work cut short, keyed by one of the Reason constants
+var Cancellations = expvar.NewMap("cancellations")
+
+const (
+	// ReasonRequestDeadline is a request running past its deadline
+	ReasonRequestDeadline = "request_deadline"
+	// ReasonClientGone is a client disconnecting before the response
+	ReasonClientGone = "client_gone"
+	// ReasonQueryTimeout is a single query running past its timeout
+	ReasonQueryTimeout = "query_timeout"
+)
+
+// JobRuns counts background job runs per job name, and JobFailures the runs
+// that returned an error or panicked
+var (
+	JobRuns     = expvar.NewMap("job_runs")
+	JobFailures = expvar.NewMap("job_failures")
+)
+
+// Handler serves every published metric as JSON
+func Handler() gin.HandlerFunc {
+	return gin.WrapH(expvar.Handler())
+}
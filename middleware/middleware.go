@@ -0,0 +1,142 @@
+package middleware
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"regexp"
+	"runtime/debug"
+	"time"
+
+	"jobApps/apperror"
+	"jobApps/audit"
+	"jobApps/logger"
+	"jobApps/metrics"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RequestIDHeader is the header used to receive and propagate request IDs
+const RequestIDHeader = "X-Request-ID"
+
+// incoming IDs are only trusted when they are short and log-safe
+var requestIDPattern = regexp.MustCompile(`^[A-Za-z0-9._\-]{1,64}$`)
+
+// RequestID reuses the caller's X-Request-ID or generates a new one, echoes it
+// in the response and attaches a request scoped logger and the audit actor to
+// the request context
+func RequestID() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		requestID := c.GetHeader(RequestIDHeader)
+		if !requestIDPattern.MatchString(requestID) {
+			requestID = newRequestID()
+		}
+
+		c.Set(logger.RequestIDKey, requestID)
+		c.Header(RequestIDHeader, requestID)
+
+		log := slog.Default().With(slog.String(logger.RequestIDKey, requestID))
+		ctx := logger.WithContext(c.Request.Context(), log)
+		ctx = audit.WithActor(ctx, audit.Actor{RequestID: requestID, IP: c.ClientIP()})
+		c.Request = c.Request.WithContext(ctx)
+
+		c.Next()
+	}
+}
+
+// Logger writes one structured access log line per request
+func Logger() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		start := time.Now()
+		c.Next()
+
+		status := c.Writer.Status()
+		level := slog.LevelInfo
+		switch {
+		case status >= 500:
+			level = slog.LevelError
+		case status >= 400:
+			level = slog.LevelWarn
+		}
+
+		logger.FromContext(c.Request.Context()).LogAttrs(c.Request.Context(), level, "request completed",
+			slog.String("method", c.Request.Method),
+			slog.String("path", c.Request.URL.Path),
+			slog.String("route", c.FullPath()),
+			slog.Int("status", status),
+			slog.Duration("latency", time.Since(start)),
+			slog.String("client_ip", c.ClientIP()),
+			slog.Int("size", c.Writer.Size()),
+		)
+	}
+}
+
+// Recovery turns panics into a logged 500 problem response. The stack trace
+// goes to the request logger only, never into the response.
+func Recovery() gin.HandlerFunc {
+	return gin.CustomRecoveryWithWriter(nil, func(c *gin.Context, recovered any) {
+		logger.FromContext(c.Request.Context()).Error("panic recovered",
+			slog.Any("panic", recovered),
+			slog.String("stack", string(debug.Stack())),
+		)
+		apperror.Write(c, apperror.Internal(fmt.Errorf("panic: %v", recovered)))
+	})
+}
+
+// Deadline bounds the request context by timeout so database calls made with
+// it are cancelled, and counts requests that ran out of time or were abandoned
+// by the client. A zero timeout only counts abandoned requests.
+func Deadline(timeout time.Duration) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx := c.Request.Context()
+		if timeout > 0 {
+			var cancel context.CancelFunc
+			ctx, cancel = context.WithTimeout(ctx, timeout)
+			defer cancel()
+			c.Request = c.Request.WithContext(ctx)
+		}
+
+		c.Next()
+
+		switch err := ctx.Err(); {
+		case errors.Is(err, context.DeadlineExceeded):
+			metrics.Cancellations.Add(metrics.ReasonRequestDeadline, 1)
+		case errors.Is(err, context.Canceled):
+			metrics.Cancellations.Add(metrics.ReasonClientGone, 1)
+		}
+	}
+}
+
+func newRequestID() string {
+	buf := make([]byte, 16)
+	if _, err := rand.Read(buf); err != nil {
+		return time.Now().UTC().Format("20060102150405.000000000")
+	}
+	return hex.EncodeToString(buf)
+}
+
+// Deprecated marks a legacy route: it advertises the successor route and the
+// sunset date (RFC 8594, RFC 9745) and counts usage of the old path
+func Deprecated(successor string, deprecatedAt, sunset time.Time) gin.HandlerFunc {
+	deprecation := fmt.Sprintf("@%d", deprecatedAt.Unix())
+	sunsetDate := sunset.UTC().Format(http.TimeFormat)
+	link := fmt.Sprintf(`<%s>; rel="successor-version"`, successor)
+
+	return func(c *gin.Context) {
+		metrics.LegacyRouteRequests.Add(c.Request.Method+" "+c.FullPath(), 1)
+
+		c.Header("Deprecation", deprecation)
+		c.Header("Sunset", sunsetDate)
+		c.Header("Link", link)
+
+		logger.FromContext(c.Request.Context()).Debug("legacy route used",
+			slog.String("route", c.FullPath()),
+			slog.String("successor", successor),
+		)
+		c.Next()
+	}
+}
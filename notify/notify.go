@@ -0,0 +1,105 @@
+// Package notify delivers messages to users. The Notifier is chosen at
+// startup: Mail sends them through an SMTP server, Webhook posts them as JSON
+// and Log only notes them in the log, which suits development.
+package notify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"mime"
+	"net/http"
+	"net/smtp"
+	"strings"
+	"time"
+
+	"jobApps/logger"
+)
+
+// Message is a notification to one recipient. Text is the plain text body;
+// Data is the structured content Webhook sends along.
+type Message struct {
+	To      string `json:"to"`
+	Subject string `json:"subject"`
+	Text    string `json:"text"`
+	Data    any    `json:"data,omitempty"`
+}
+
+type Notifier interface {
+	Notify(ctx context.Context, message Message) error
+}
+
+// Log notes messages in the log instead of sending them. The recipient's
+// address and the text stay out of the log: a digest's text holds its
+// unsubscribe links, which work without logging in.
+type Log struct{}
+
+func (Log) Notify(ctx context.Context, message Message) error {
+	_, domain, _ := strings.Cut(message.To, "@")
+	logger.FromContext(ctx).Info("notification",
+		slog.String("to_domain", domain), slog.String("subject", message.Subject), slog.Int("text_bytes", len(message.Text)))
+	return nil
+}
+
+// Webhook posts each message as JSON to URL and expects a 2xx response
+type Webhook struct {
+	URL    string
+	Client *http.Client
+}
+
+func (w Webhook) Notify(ctx context.Context, message Message) error {
+	body, err := json.Marshal(message)
+	if err != nil {
+		return fmt.Errorf("encoding notification: %w", err)
+	}
+	request, err := http.NewRequestWithContext(ctx, http.MethodPost, w.URL, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("building notification request: %w", err)
+	}
+	request.Header.Set("Content-Type", "application/json")
+
+	client := w.Client
+	if client == nil {
+		client = &http.Client{Timeout: 10 * time.Second}
+	}
+	response, err := client.Do(request)
+	if err != nil {
+		return fmt.Errorf("posting notification: %w", err)
+	}
+	defer response.Body.Close()
+	if response.StatusCode/100 != 2 {
+		return fmt.Errorf("posting notification: %s answered %s", w.URL, response.Status)
+	}
+	return nil
+}
+
+// Mail sends each message as a plain text email from From through the SMTP
+// server at Addr, without authentication. In development Addr is a stand-in
+// such as MailHog that catches the mail.
+type Mail struct {
+	Addr string
+	From string
+}
+
+func (m Mail) Notify(_ context.Context, message Message) error {
+	if err := smtp.SendMail(m.Addr, nil, m.From, []string{message.To}, m.email(message)); err != nil {
+		return fmt.Errorf("sending notification email: %w", err)
+	}
+	return nil
+}
+
+// email formats message as an RFC 5322 email
+func (m Mail) email(message Message) []byte {
+	var b bytes.Buffer
+	fmt.Fprintf(&b, "From: %s\r\n", m.From)
+	fmt.Fprintf(&b, "To: %s\r\n", message.To)
+	fmt.Fprintf(&b, "Subject: %s\r\n", mime.QEncoding.Encode("utf-8", message.Subject))
+	fmt.Fprintf(&b, "Date: %s\r\n", time.Now().Format(time.RFC1123Z))
+	b.WriteString("MIME-Version: 1.0\r\n")
+	b.WriteString("Content-Type: text/plain; charset=utf-8\r\n")
+	b.WriteString("\r\n")
+	b.WriteString(strings.ReplaceAll(strings.ReplaceAll(message.Text, "\r\n", "\n"), "\n", "\r\n"))
+	return b.Bytes()
+}
@@ -0,0 +1,81 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"jobApps/logger"
+)
+
+func TestLogKeepsRecipientsAndTextOut(t *testing.T) {
+	var out bytes.Buffer
+	ctx := logger.WithContext(context.Background(), logger.New(&out, slog.LevelInfo))
+	message := Message{To: "jane@example.com", Subject: "New careers", Text: "Unsubscribe: https://jobs.example.com/api/v1/alerts/unsubscribe?token=secret"}
+	if err := (Log{}).Notify(ctx, message); err != nil {
+		t.Fatalf("Notify: %v", err)
+	}
+	logged := out.String()
+	if strings.Contains(logged, "jane") || strings.Contains(logged, "secret") {
+		t.Errorf("log = %s, want no recipient or text", logged)
+	}
+	if !strings.Contains(logged, `"subject":"New careers"`) || !strings.Contains(logged, `"to_domain":"example.com"`) {
+		t.Errorf("log = %s, want the subject and the recipient's domain", logged)
+	}
+}
+
+func TestWebhookPostsTheMessage(t *testing.T) {
+	var got Message
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Content-Type") != "application/json" {
+			t.Errorf("Content-Type = %q", r.Header.Get("Content-Type"))
+		}
+		if err := json.NewDecoder(r.Body).Decode(&got); err != nil {
+			t.Errorf("decoding body: %v", err)
+		}
+	}))
+	defer server.Close()
+
+	message := Message{To: "jane@example.com", Subject: "New careers", Text: "Backend Engineer at Acme"}
+	if err := (Webhook{URL: server.URL}).Notify(context.Background(), message); err != nil {
+		t.Fatalf("Notify: %v", err)
+	}
+	if got.To != message.To || got.Subject != message.Subject || got.Text != message.Text {
+		t.Errorf("posted %+v, want %+v", got, message)
+	}
+}
+
+func TestWebhookFailsOnErrorStatus(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	defer server.Close()
+
+	err := (Webhook{URL: server.URL}).Notify(context.Background(), Message{To: "jane@example.com"})
+	if err == nil || !strings.Contains(err.Error(), "502") {
+		t.Errorf("error = %v, want the 502 reported", err)
+	}
+}
+
+func TestMailFormatsAnEmail(t *testing.T) {
+	email := string(Mail{From: "alerts@example.com"}.email(Message{
+		To:      "jane@example.com",
+		Subject: "Neue Stellen für dich",
+		Text:    "line one\nline two",
+	}))
+	for _, want := range []string{
+		"From: alerts@example.com\r\n",
+		"To: jane@example.com\r\n",
+		"Subject: =?utf-8?q?Neue_Stellen_f=C3=BCr_dich?=\r\n",
+		"Content-Type: text/plain; charset=utf-8\r\n\r\nline one\r\nline two",
+	} {
+		if !strings.Contains(email, want) {
+			t.Errorf("email %q lacks %q", email, want)
+		}
+	}
+}
@@ -0,0 +1,289 @@
+package openapi
+
+import (
+	_ "embed"
+	"net/http"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+
+	"jobApps/apperror"
+	"jobApps/patch"
+
+	"github.com/gin-gonic/gin"
+)
+
+//go:embed swagger.html
+var swaggerHTML []byte
+
+// Document is an OpenAPI 3.1 document
+type Document struct {
+	OpenAPI    string                           `json:"openapi"`
+	Info       Info                             `json:"info"`
+	Paths      map[string]map[string]*Operation `json:"paths"`
+	Components Components                       `json:"components"`
+}
+
+type Info struct {
+	Title       string `json:"title"`
+	Version     string `json:"version"`
+	Description string `json:"description,omitempty"`
+}
+
+type Components struct {
+	Schemas         map[string]*Schema         `json:"schemas"`
+	SecuritySchemes map[string]*SecurityScheme `json:"securitySchemes"`
+}
+
+type SecurityScheme struct {
+	Type         string `json:"type"`
+	Scheme       string `json:"scheme"`
+	BearerFormat string `json:"bearerFormat,omitempty"`
+	Description  string `json:"description,omitempty"`
+}
+
+type Operation struct {
+	Summary     string                `json:"summary"`
+	Tags        []string              `json:"tags,omitempty"`
+	OperationID string                `json:"operationId,omitempty"`
+	Deprecated  bool                  `json:"deprecated,omitempty"`
+	Parameters  []Parameter           `json:"parameters,omitempty"`
+	RequestBody *RequestBody          `json:"requestBody,omitempty"`
+	Responses   map[string]*Response  `json:"responses"`
+	Security    []map[string][]string `json:"security,omitempty"`
+}
+
+type Parameter struct {
+	Name        string  `json:"name"`
+	In          string  `json:"in"`
+	Required    bool    `json:"required,omitempty"`
+	Description string  `json:"description,omitempty"`
+	Schema      *Schema `json:"schema"`
+}
+
+type RequestBody struct {
+	Required bool                  `json:"required"`
+	Content  map[string]*MediaType `json:"content"`
+}
+
+type Response struct {
+	Description string                `json:"description"`
+	Headers     map[string]*Header    `json:"headers,omitempty"`
+	Content     map[string]*MediaType `json:"content,omitempty"`
+}
+
+type Header struct {
+	Description string  `json:"description,omitempty"`
+	Schema      *Schema `json:"schema"`
+}
+
+type MediaType struct {
+	Schema *Schema `json:"schema"`
+}
+
+// Route describes one API route for the document
+type Route struct {
+	Method      string
+	Path        string // gin syntax, e.g. /careers/:id
+	Summary     string
+	Tag         string
+	Auth        bool
+	Deprecated  bool
+	Query       []Parameter
+	Request     any    // request body type, nil when the route takes no body
+	Response    any    // type of the data field of the success envelope
+	DataKey     string // envelope key holding Response, defaults to "data"
+	Body        any    // whole success body, for routes without the envelope
+	Status      int    // success status, defaults to 200
+	ContentType string
+	// ETag marks a versioned resource: the success response carries an ETag,
+	// GET honours If-None-Match and PUT, PATCH and DELETE take If-Match
+	ETag bool
+	// CSV marks a response that can also be downloaded as text/csv
+	CSV bool
+}
+
+var pathParam = regexp.MustCompile(`:([A-Za-z0-9_]+)`)
+
+// OpenAPIPath converts a gin path into an OpenAPI path template
+func OpenAPIPath(ginPath string) string {
+	return pathParam.ReplaceAllString(ginPath, "{$1}")
+}
+
+// New builds a document describing routes
+func New(routes []Route) *Document {
+	doc := &Document{
+		OpenAPI: "3.1.0",
+		Info: Info{
+			Title:       "jobApps API",
+			Version:     "1.0.0",
+			Description: "Career postings and candidate profiles backed by PostgreSQL and sqlc.",
+		},
+		Paths: map[string]map[string]*Operation{},
+		Components: Components{
+			Schemas: map[string]*Schema{},
+			SecuritySchemes: map[string]*SecurityScheme{
+				"bearerAuth": {
+					Type:         "http",
+					Scheme:       "bearer",
+					BearerFormat: "JWT",
+					Description:  "Token returned by the login endpoint",
+				},
+			},
+		},
+	}
+	problem := doc.SchemaOf(apperror.Problem{})
+
+	for _, route := range routes {
+		path := OpenAPIPath(route.Path)
+		if doc.Paths[path] == nil {
+			doc.Paths[path] = map[string]*Operation{}
+		}
+		doc.Paths[path][strings.ToLower(route.Method)] = doc.operation(route, problem)
+	}
+	return doc
+}
+
+func (d *Document) operation(route Route, problem *Schema) *Operation {
+	op := &Operation{
+		Summary:     route.Summary,
+		OperationID: operationID(route),
+		Deprecated:  route.Deprecated,
+		Responses:   map[string]*Response{},
+	}
+	if route.Tag != "" {
+		op.Tags = []string{route.Tag}
+	}
+
+	for _, match := range pathParam.FindAllStringSubmatch(route.Path, -1) {
+		op.Parameters = append(op.Parameters, Parameter{
+			Name:     match[1],
+			In:       "path",
+			Required: true,
+			Schema:   &Schema{Type: "integer", Format: "int64"},
+		})
+	}
+	op.Parameters = append(op.Parameters, route.Query...)
+
+	switch {
+	case route.Request != nil && route.Method == http.MethodPatch:
+		op.RequestBody = &RequestBody{Required: true, Content: d.patchContent(route.Request)}
+	case route.Request != nil:
+		contentType := route.ContentType
+		if contentType == "" {
+			contentType = "application/json"
+		}
+		op.RequestBody = &RequestBody{
+			Required: true,
+			Content:  map[string]*MediaType{contentType: {Schema: d.SchemaOf(route.Request)}},
+		}
+	}
+
+	status := route.Status
+	if status == 0 {
+		status = http.StatusOK
+	}
+	success := &Response{Description: http.StatusText(status)}
+	switch {
+	case route.Body != nil:
+		success.Content = map[string]*MediaType{"application/json": {Schema: d.SchemaOf(route.Body)}}
+	case route.Response != nil:
+		success.Content = map[string]*MediaType{"application/json": {Schema: envelope(route.DataKey, d.SchemaOf(route.Response))}}
+	}
+	if route.CSV {
+		success.Content["text/csv"] = &MediaType{Schema: &Schema{Type: "string", Description: "One row per item, with a header row"}}
+	}
+	op.Responses[strconv.Itoa(status)] = success
+
+	problemContent := map[string]*MediaType{apperror.ContentType: {Schema: problem}}
+	if route.ETag {
+		success.Headers = map[string]*Header{"ETag": {Description: "Version of the resource", Schema: &Schema{Type: "string"}}}
+		switch route.Method {
+		case http.MethodGet:
+			op.Parameters = append(op.Parameters, Parameter{
+				Name: "If-None-Match", In: "header", Description: "Answer 304 if the resource still has one of these ETags", Schema: &Schema{Type: "string"},
+			})
+			op.Responses["304"] = &Response{Description: "The resource still has the given ETag"}
+		case http.MethodPut, http.MethodPatch, http.MethodDelete:
+			op.Parameters = append(op.Parameters, Parameter{
+				Name: "If-Match", In: "header", Description: "ETag of the version being changed, or *; required unless IF_MATCH_REQUIRED is false", Schema: &Schema{Type: "string"},
+			})
+			op.Responses["412"] = &Response{Description: "The resource was modified since the given ETag", Content: problemContent}
+			op.Responses["428"] = &Response{Description: "The If-Match header is missing", Content: problemContent}
+		}
+	}
+	op.Responses["default"] = &Response{Description: "Error", Content: problemContent}
+	if route.Auth {
+		op.Security = []map[string][]string{{"bearerAuth": {}}}
+		op.Responses["401"] = &Response{Description: "Missing, invalid or expired token", Content: problemContent}
+		op.Responses["403"] = &Response{Description: "Role not allowed", Content: problemContent}
+	}
+	return op
+}
+
+// patchContent describes the two PATCH formats for a resource document:
+// a merge patch holding any subset of its fields, and a JSON Patch
+func (d *Document) patchContent(document any) map[string]*MediaType {
+	merge := d.structSchema(reflect.TypeOf(document))
+	merge.Required = nil
+	merge.Description = "JSON Merge Patch (RFC 7396): fields to change; null resets a field to its zero value"
+
+	operation := &Schema{
+		Type:        "array",
+		Description: "JSON Patch (RFC 6902) operations applied to the fields of the merge patch schema",
+		Items: &Schema{
+			Type: "object",
+			Properties: map[string]*Schema{
+				"op":    {Type: "string", Enum: []any{"add", "remove", "replace", "move", "copy", "test"}},
+				"path":  {Type: "string", Description: "JSON Pointer, e.g. /company"},
+				"from":  {Type: "string"},
+				"value": {},
+			},
+			Required: []string{"op", "path"},
+		},
+	}
+	return map[string]*MediaType{
+		patch.MergePatchType: {Schema: merge},
+		patch.JSONPatchType:  {Schema: operation},
+	}
+}
+
+// envelope wraps a payload schema in the {status, message, data} response body
+func envelope(key string, data *Schema) *Schema {
+	if key == "" {
+		key = "data"
+	}
+	return &Schema{
+		Type: "object",
+		Properties: map[string]*Schema{
+			"status":  {Type: "integer"},
+			"message": {Type: "string"},
+			key:       data,
+		},
+	}
+}
+
+func operationID(route Route) string {
+	id := strings.ToLower(route.Method)
+	for _, part := range strings.FieldsFunc(route.Path, func(r rune) bool { return r == '/' || r == '-' || r == ':' }) {
+		id += strings.ToUpper(part[:1]) + part[1:]
+	}
+	return id
+}
+
+// Register serves the document at /openapi.json and Swagger UI at /docs
+func Register(router gin.IRoutes, routes []Route) {
+	var (
+		once sync.Once
+		doc  *Document
+	)
+	router.GET("/openapi.json", func(c *gin.Context) {
+		once.Do(func() { doc = New(routes) })
+		c.JSON(http.StatusOK, doc)
+	})
+	router.GET("/docs", func(c *gin.Context) {
+		c.Data(http.StatusOK, "text/html; charset=utf-8", swaggerHTML)
+	})
+}
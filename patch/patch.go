@@ -0,0 +1,87 @@
+// Package patch applies PATCH request bodies: RFC 7396 JSON Merge Patch and
+// RFC 6902 JSON Patch. A patch is applied to the JSON form of a resource
+// document and the result is decoded and validated by the caller.
+package patch
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"mime"
+
+	"jobApps/apperror"
+
+	jsonpatch "github.com/evanphx/json-patch/v5"
+)
+
+const (
+	MergePatchType = "application/merge-patch+json"
+	JSONPatchType  = "application/json-patch+json"
+)
+
+// Patch is a decoded PATCH body
+type Patch struct {
+	merge []byte
+	// ops is used instead of merge when jsonPatch is set
+	ops       jsonpatch.Patch
+	jsonPatch bool
+}
+
+// Parse decodes body according to contentType. Plain application/json is read
+// as a merge patch.
+func Parse(contentType string, body []byte) (Patch, error) {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		mediaType = contentType
+	}
+
+	switch mediaType {
+	case MergePatchType, "application/json":
+		body = bytes.TrimSpace(body)
+		if !json.Valid(body) {
+			return Patch{}, apperror.Validation("request body is not valid JSON")
+		}
+		// a merge patch that is not an object would replace the whole resource
+		if len(body) == 0 || body[0] != '{' {
+			return Patch{}, apperror.Validation("a merge patch must be a JSON object")
+		}
+		return Patch{merge: body}, nil
+	case JSONPatchType:
+		ops, err := jsonpatch.DecodePatch(body)
+		if err != nil {
+			return Patch{}, apperror.Validation("request body is not a valid JSON Patch: " + err.Error())
+		}
+		for _, op := range ops {
+			if _, err := op.Path(); err != nil {
+				return Patch{}, apperror.Validation("every JSON Patch operation needs a path")
+			}
+		}
+		return Patch{ops: ops, jsonPatch: true}, nil
+	default:
+		return Patch{}, apperror.UnsupportedMediaType(fmt.Sprintf("PATCH accepts %s or %s", MergePatchType, JSONPatchType))
+	}
+}
+
+// Apply patches the JSON form of document and returns the patched JSON
+func (p Patch) Apply(document any) ([]byte, error) {
+	original, err := json.Marshal(document)
+	if err != nil {
+		return nil, apperror.Internal(fmt.Errorf("encoding document: %w", err))
+	}
+
+	var patched []byte
+	if p.jsonPatch {
+		patched, err = p.ops.Apply(original)
+	} else {
+		patched, err = jsonpatch.MergePatch(original, p.merge)
+	}
+	switch {
+	case err == nil:
+		return patched, nil
+	case errors.Is(err, jsonpatch.ErrTestFailed):
+		return nil, apperror.Conflict("a JSON Patch test operation failed: " + err.Error())
+	default:
+		return nil, apperror.Validation("the patch cannot be applied: " + err.Error())
+	}
+}
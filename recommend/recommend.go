@@ -0,0 +1,319 @@
+// Package recommend scores how well a candidate fits a career. The score is
+// the weighted sum of four factors: skill overlap, location and remote fit,
+// salary expectations and seniority. Each factor is scored from 0 to 1 with a
+// reason, so a ranking can be explained to the people in it. A factor that
+// cannot be judged because either side leaves it unset scores Neutral.
+package recommend
+
+import (
+	"fmt"
+	"math"
+	"slices"
+	"strings"
+
+	"jobApps/geo"
+)
+
+// Factor names
+const (
+	FactorSkills    = "skills"
+	FactorLocation  = "location"
+	FactorSalary    = "salary"
+	FactorSeniority = "seniority"
+)
+
+// Weights of the factors in the score; they add up to 1
+const (
+	SkillsWeight    = 0.4
+	LocationWeight  = 0.25
+	SalaryWeight    = 0.2
+	SeniorityWeight = 0.15
+)
+
+// Neutral is the score of a factor that cannot be judged
+const Neutral = 0.5
+
+// Distances within CommuteKm of a career location score 1, falling to 0 at
+// MaxCommuteKm
+const (
+	CommuteKm    = 50
+	MaxCommuteKm = 500
+)
+
+// SkillRequirement is a skill a career asks for
+type SkillRequirement struct {
+	SkillID  int64
+	Name     string
+	Required bool
+	MinYears *int16
+}
+
+// SkillLevel is a skill a candidate has
+type SkillLevel struct {
+	SkillID     int64
+	Proficiency string
+}
+
+// Job is the side of a match a career provides. Salaries are yearly amounts
+// in one currency shared with Candidate; nil when the salary is not known.
+type Job struct {
+	Skills          []SkillRequirement
+	Locations       geo.Locations
+	RemotePolicy    string
+	RemoteCountries []string
+	SalaryMin       *float64
+	SalaryMax       *float64
+	Seniority       string
+}
+
+// Candidate is the side of a match a profile provides. The first location is
+// where the candidate lives.
+type Candidate struct {
+	Skills           []SkillLevel
+	Locations        geo.Locations
+	RemotePreference string
+	ExpectedSalary   *float64
+	YearsExperience  *int16
+}
+
+// Factor is one part of a match
+type Factor struct {
+	Name   string  `json:"name"`
+	Weight float64 `json:"weight"`
+	Score  float64 `json:"score"`
+	Reason string  `json:"reason"`
+}
+
+// Match is how well a candidate fits a job, from 0 to 1, with the factors
+// making up the score
+type Match struct {
+	Score   float64
+	Factors []Factor
+}
+
+// Score matches candidate against job
+func Score(job Job, candidate Candidate) Match {
+	factors := []Factor{
+		skills(job, candidate),
+		location(job, candidate),
+		salary(job, candidate),
+		seniority(job, candidate),
+	}
+	var total float64
+	for i := range factors {
+		factors[i].Score = round(factors[i].Score)
+		total += factors[i].Weight * factors[i].Score
+	}
+	return Match{Score: round(total), Factors: factors}
+}
+
+// ProficiencyYears is the experience each proficiency stands for when a
+// career asks for a minimum number of years with a skill
+var ProficiencyYears = map[string]int16{
+	"beginner":     0,
+	"intermediate": 2,
+	"advanced":     5,
+	"expert":       8,
+}
+
+// skills credits each skill the career asks for that the candidate has, a
+// required skill counting twice as much as a nice to have one. A skill held
+// at a proficiency below the years asked for earns half the credit.
+func skills(job Job, candidate Candidate) Factor {
+	factor := Factor{Name: FactorSkills, Weight: SkillsWeight}
+	if len(job.Skills) == 0 {
+		factor.Score, factor.Reason = Neutral, "the career lists no skills"
+		return factor
+	}
+
+	held := make(map[int64]string, len(candidate.Skills))
+	for _, skill := range candidate.Skills {
+		held[skill.SkillID] = skill.Proficiency
+	}
+	var credit, total float64
+	var matched, short, missing []string
+	for _, requirement := range job.Skills {
+		weight := 1.0
+		if requirement.Required {
+			weight = 2
+		}
+		total += weight
+
+		proficiency, ok := held[requirement.SkillID]
+		switch {
+		case !ok:
+			missing = append(missing, requirement.Name)
+		case requirement.MinYears != nil && ProficiencyYears[proficiency] < *requirement.MinYears:
+			credit += weight / 2
+			short = append(short, requirement.Name)
+		default:
+			credit += weight
+			matched = append(matched, requirement.Name)
+		}
+	}
+	factor.Score = credit / total
+
+	var reasons []string
+	if len(matched) > 0 {
+		reasons = append(reasons, "has "+strings.Join(matched, ", "))
+	}
+	if len(short) > 0 {
+		reasons = append(reasons, "has less experience than asked for in "+strings.Join(short, ", "))
+	}
+	if len(missing) > 0 {
+		reasons = append(reasons, "lacks "+strings.Join(missing, ", "))
+	}
+	factor.Reason = strings.Join(reasons, "; ")
+	return factor
+}
+
+// RemoteFit is how well each remote policy suits each remote preference
+var RemoteFit = map[string]map[string]float64{
+	"onsite": {"onsite": 1, "hybrid": 0.75, "remote": 0.5},
+	"hybrid": {"onsite": 0.75, "hybrid": 1, "remote": 0.75},
+	"remote": {"onsite": 0.25, "hybrid": 0.5, "remote": 1},
+	"any":    {"onsite": 1, "hybrid": 1, "remote": 1},
+}
+
+// location scores whether the candidate can work where the career is, scaled
+// by how well its remote policy suits the candidate. Remote careers only ask
+// that the candidate lives in an allowed country; others need a commute from
+// one of the candidate's locations to one of the career's.
+func location(job Job, candidate Candidate) Factor {
+	factor := Factor{Name: FactorLocation, Weight: LocationWeight}
+
+	var place float64
+	var reason string
+	if job.RemotePolicy == "remote" {
+		place, reason = remotePlace(job, candidate)
+	} else {
+		place, reason = commute(job, candidate)
+	}
+
+	fit, ok := RemoteFit[candidate.RemotePreference][job.RemotePolicy]
+	if !ok {
+		fit = 1
+	}
+	factor.Score = place * fit
+	factor.Reason = reason
+	if fit < 1 {
+		factor.Reason += fmt.Sprintf("; prefers %s work, the career is %s", candidate.RemotePreference, job.RemotePolicy)
+	}
+	return factor
+}
+
+func remotePlace(job Job, candidate Candidate) (float64, string) {
+	if len(job.RemoteCountries) == 0 {
+		return 1, "remote from anywhere"
+	}
+	if len(candidate.Locations) == 0 {
+		return Neutral, "remote from " + strings.Join(job.RemoteCountries, ", ") + "; where the candidate lives is not known"
+	}
+	home := candidate.Locations[0].Country
+	if slices.Contains(job.RemoteCountries, home) {
+		return 1, "remote from " + home + " is allowed"
+	}
+	return 0, "remote only from " + strings.Join(job.RemoteCountries, ", ") + ", the candidate lives in " + home
+}
+
+func commute(job Job, candidate Candidate) (float64, string) {
+	nearest := math.Inf(1)
+	for _, from := range candidate.Locations {
+		a, ok := from.Point()
+		if !ok {
+			continue
+		}
+		for _, to := range job.Locations {
+			if b, ok := to.Point(); ok {
+				nearest = min(nearest, geo.DistanceKm(a, b))
+			}
+		}
+	}
+	if math.IsInf(nearest, 1) {
+		return Neutral, "the career's or the candidate's locations are not known"
+	}
+
+	reason := fmt.Sprintf("the nearest career location is %.0f km away", nearest)
+	switch {
+	case nearest <= CommuteKm:
+		return 1, reason
+	case nearest >= MaxCommuteKm:
+		return 0, reason
+	default:
+		return (MaxCommuteKm - nearest) / (MaxCommuteKm - CommuteKm), reason
+	}
+}
+
+// salary scores 1 when the candidate expects no more than the top of the
+// range, falling to 0 when they expect half as much again
+func salary(job Job, candidate Candidate) Factor {
+	factor := Factor{Name: FactorSalary, Weight: SalaryWeight}
+	switch {
+	case job.SalaryMax == nil:
+		factor.Score, factor.Reason = Neutral, "the career's salary is not known"
+	case candidate.ExpectedSalary == nil:
+		factor.Score, factor.Reason = Neutral, "the candidate's expected salary is not known"
+	case *candidate.ExpectedSalary <= *job.SalaryMax:
+		factor.Score = 1
+		if job.SalaryMin != nil && *candidate.ExpectedSalary < *job.SalaryMin {
+			factor.Reason = "expects less than the salary range"
+		} else {
+			factor.Reason = "expects a salary within the range"
+		}
+	default:
+		over := math.Inf(1)
+		if *job.SalaryMax > 0 {
+			over = (*candidate.ExpectedSalary - *job.SalaryMax) / *job.SalaryMax
+		}
+		factor.Score = max(0, 1-2*over)
+		if math.IsInf(over, 1) {
+			factor.Reason = "expects a salary and the career pays none"
+		} else {
+			factor.Reason = fmt.Sprintf("expects %.0f%% more than the top of the range", over*100)
+		}
+	}
+	return factor
+}
+
+// SeniorityYears is the range of years of experience each seniority expects
+var SeniorityYears = map[string][2]int16{
+	"intern": {0, 1},
+	"junior": {1, 3},
+	"mid":    {3, 6},
+	"senior": {5, 10},
+	"lead":   {8, 60},
+}
+
+// seniority scores 1 when the candidate's experience is in the range the
+// career's seniority expects, losing a quarter for every year outside it
+func seniority(job Job, candidate Candidate) Factor {
+	factor := Factor{Name: FactorSeniority, Weight: SeniorityWeight}
+	expected, ok := SeniorityYears[job.Seniority]
+	switch {
+	case !ok:
+		factor.Score, factor.Reason = Neutral, "the career's seniority is not known"
+		return factor
+	case candidate.YearsExperience == nil:
+		factor.Score, factor.Reason = Neutral, "the candidate's experience is not known"
+		return factor
+	}
+
+	years := *candidate.YearsExperience
+	role := fmt.Sprintf("a %s role (%d-%d years)", job.Seniority, expected[0], expected[1])
+	switch {
+	case years < expected[0]:
+		factor.Score = max(0, 1-float64(expected[0]-years)/4)
+		factor.Reason = fmt.Sprintf("%d years of experience is short of %s", years, role)
+	case years > expected[1]:
+		factor.Score = max(0, 1-float64(years-expected[1])/4)
+		factor.Reason = fmt.Sprintf("%d years of experience is more than %s asks for", years, role)
+	default:
+		factor.Score = 1
+		factor.Reason = fmt.Sprintf("%d years of experience fits %s", years, role)
+	}
+	return factor
+}
+
+func round(score float64) float64 {
+	return math.Round(score*1000) / 1000
+}
@@ -0,0 +1,173 @@
+package recommend
+
+import (
+	"math"
+	"strings"
+	"testing"
+
+	"jobApps/geo"
+)
+
+func ptr[T any](v T) *T { return &v }
+
+func place(city, country string, lat, lon float64) geo.Location {
+	return geo.Location{City: city, Country: country, Lat: &lat, Lon: &lon}
+}
+
+var (
+	berlin  = place("Berlin", "DE", 52.52, 13.405)
+	potsdam = place("Potsdam", "DE", 52.3906, 13.0645)
+	munich  = place("Munich", "DE", 48.1351, 11.582)
+	paris   = place("Paris", "FR", 48.8566, 2.3522)
+)
+
+func factor(match Match, name string) Factor {
+	for _, f := range match.Factors {
+		if f.Name == name {
+			return f
+		}
+	}
+	return Factor{}
+}
+
+func TestSkills(t *testing.T) {
+	job := Job{Skills: []SkillRequirement{
+		{SkillID: 1, Name: "Go", Required: true, MinYears: ptr[int16](3)},
+		{SkillID: 2, Name: "PostgreSQL", Required: true},
+		{SkillID: 3, Name: "Docker"},
+	}}
+	tests := []struct {
+		name   string
+		skills []SkillLevel
+		want   float64
+		reason string
+	}{
+		{"all", []SkillLevel{{1, "advanced"}, {2, "beginner"}, {3, "beginner"}}, 1, "has Go, PostgreSQL, Docker"},
+		{"none", nil, 0, "lacks Go, PostgreSQL, Docker"},
+		{"short of the years asked for", []SkillLevel{{1, "intermediate"}, {2, "expert"}}, 0.6, "has PostgreSQL; has less experience than asked for in Go; lacks Docker"},
+		{"only the nice to have", []SkillLevel{{3, "expert"}}, 0.2, "has Docker; lacks Go, PostgreSQL"},
+	}
+	for _, tt := range tests {
+		got := skills(job, Candidate{Skills: tt.skills})
+		if math.Abs(got.Score-tt.want) > 1e-9 || got.Reason != tt.reason {
+			t.Errorf("%s: skills = %.3f %q, want %.3f %q", tt.name, got.Score, got.Reason, tt.want, tt.reason)
+		}
+	}
+	if got := skills(Job{}, Candidate{}); got.Score != Neutral {
+		t.Errorf("skills of a career listing none = %.3f, want %v", got.Score, Neutral)
+	}
+}
+
+func TestLocation(t *testing.T) {
+	tests := []struct {
+		name      string
+		job       Job
+		candidate Candidate
+		want      float64
+	}{
+		{"same city", Job{Locations: geo.Locations{berlin}, RemotePolicy: "onsite"}, Candidate{Locations: geo.Locations{berlin}, RemotePreference: "any"}, 1},
+		{"commutable", Job{Locations: geo.Locations{berlin}, RemotePolicy: "hybrid"}, Candidate{Locations: geo.Locations{potsdam}, RemotePreference: "hybrid"}, 1},
+		{"too far", Job{Locations: geo.Locations{berlin}, RemotePolicy: "onsite"}, Candidate{Locations: geo.Locations{paris}, RemotePreference: "any"}, 0},
+		{"willing to move", Job{Locations: geo.Locations{berlin}, RemotePolicy: "onsite"}, Candidate{Locations: geo.Locations{paris, berlin}, RemotePreference: "onsite"}, 1},
+		{"between", Job{Locations: geo.Locations{munich}, RemotePolicy: "onsite"}, Candidate{Locations: geo.Locations{place("Augsburg", "DE", 48.3705, 10.8978)}, RemotePreference: "any"}, 0.984},
+		{"unknown", Job{RemotePolicy: "onsite"}, Candidate{Locations: geo.Locations{berlin}, RemotePreference: "any"}, Neutral},
+		{"remote from anywhere", Job{RemotePolicy: "remote"}, Candidate{RemotePreference: "remote"}, 1},
+		{"remote from an allowed country", Job{RemotePolicy: "remote", RemoteCountries: []string{"FR", "DE"}}, Candidate{Locations: geo.Locations{munich}, RemotePreference: "any"}, 1},
+		{"remote from elsewhere", Job{RemotePolicy: "remote", RemoteCountries: []string{"FR"}}, Candidate{Locations: geo.Locations{munich}, RemotePreference: "any"}, 0},
+		{"remote but prefers the office", Job{RemotePolicy: "remote"}, Candidate{RemotePreference: "onsite"}, 0.5},
+		{"onsite but prefers remote", Job{Locations: geo.Locations{berlin}, RemotePolicy: "onsite"}, Candidate{Locations: geo.Locations{berlin}, RemotePreference: "remote"}, 0.25},
+	}
+	for _, tt := range tests {
+		got := location(tt.job, tt.candidate)
+		if math.Abs(got.Score-tt.want) > 0.01 {
+			t.Errorf("%s: location = %.3f (%s), want %.3f", tt.name, got.Score, got.Reason, tt.want)
+		}
+	}
+}
+
+func TestSalary(t *testing.T) {
+	job := Job{SalaryMin: ptr(80_000.0), SalaryMax: ptr(100_000.0)}
+	tests := map[float64]float64{
+		60_000:  1,
+		90_000:  1,
+		100_000: 1,
+		125_000: 0.5,
+		150_000: 0,
+		400_000: 0,
+	}
+	for expected, want := range tests {
+		got := salary(job, Candidate{ExpectedSalary: ptr(expected)})
+		if math.Abs(got.Score-want) > 1e-9 {
+			t.Errorf("salary expecting %.0f = %.3f (%s), want %.3f", expected, got.Score, got.Reason, want)
+		}
+	}
+	if got := salary(Job{}, Candidate{ExpectedSalary: ptr(90_000.0)}); got.Score != Neutral {
+		t.Errorf("salary of a career without one = %.3f, want %v", got.Score, Neutral)
+	}
+	if got := salary(job, Candidate{}); got.Score != Neutral {
+		t.Errorf("salary of a candidate without an expectation = %.3f, want %v", got.Score, Neutral)
+	}
+}
+
+func TestSeniority(t *testing.T) {
+	tests := []struct {
+		seniority string
+		years     int16
+		want      float64
+	}{
+		{"senior", 7, 1},
+		{"senior", 5, 1},
+		{"senior", 3, 0.5},
+		{"senior", 12, 0.5},
+		{"intern", 9, 0},
+		{"junior", 0, 0.75},
+	}
+	for _, tt := range tests {
+		got := seniority(Job{Seniority: tt.seniority}, Candidate{YearsExperience: ptr(tt.years)})
+		if math.Abs(got.Score-tt.want) > 1e-9 {
+			t.Errorf("seniority %s with %d years = %.3f (%s), want %.3f", tt.seniority, tt.years, got.Score, got.Reason, tt.want)
+		}
+	}
+	if got := seniority(Job{}, Candidate{YearsExperience: ptr[int16](4)}); got.Score != Neutral {
+		t.Errorf("seniority of a career without one = %.3f, want %v", got.Score, Neutral)
+	}
+}
+
+func TestScore(t *testing.T) {
+	job := Job{
+		Skills:       []SkillRequirement{{SkillID: 1, Name: "Go", Required: true}},
+		Locations:    geo.Locations{berlin},
+		RemotePolicy: "hybrid",
+		SalaryMin:    ptr(70_000.0),
+		SalaryMax:    ptr(90_000.0),
+		Seniority:    "mid",
+	}
+	perfect := Score(job, Candidate{
+		Skills:           []SkillLevel{{1, "advanced"}},
+		Locations:        geo.Locations{berlin},
+		RemotePreference: "hybrid",
+		ExpectedSalary:   ptr(80_000.0),
+		YearsExperience:  ptr[int16](4),
+	})
+	if perfect.Score != 1 {
+		t.Errorf("Score of a perfect fit = %v, want 1; factors %+v", perfect.Score, perfect.Factors)
+	}
+
+	unknown := Score(job, Candidate{RemotePreference: "any"})
+	// everything but the skills is neutral and the only skill is missing
+	want := LocationWeight*Neutral + SalaryWeight*Neutral + SeniorityWeight*Neutral
+	if math.Abs(unknown.Score-want) > 1e-9 {
+		t.Errorf("Score of an empty profile = %v, want %v", unknown.Score, want)
+	}
+	if f := factor(unknown, FactorSkills); f.Score != 0 || !strings.Contains(f.Reason, "lacks Go") {
+		t.Errorf("skills factor = %+v, want 0 lacking Go", f)
+	}
+
+	var weights float64
+	for _, f := range perfect.Factors {
+		weights += f.Weight
+	}
+	if math.Abs(weights-1) > 1e-9 {
+		t.Errorf("weights add up to %v, want 1", weights)
+	}
+}
@@ -0,0 +1,2216 @@
+package router
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"maps"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"jobApps/audit"
+	"jobApps/dto"
+	"jobApps/internal/memstore"
+	"jobApps/logger"
+	"jobApps/notify"
+	"jobApps/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+// apiClient drives the engine over httptest and remembers which routes were hit
+type apiClient struct {
+	t       *testing.T
+	service *service.Service
+	engine  *gin.Engine
+	hits    map[string]bool
+}
+
+type apiResponse struct {
+	Code   int
+	Header http.Header
+	Body   map[string]any
+	// Text is the raw body
+	Text string
+}
+
+func newAPIClient(t *testing.T) *apiClient {
+	t.Helper()
+	svc := service.New(memstore.New())
+	return &apiClient{t: t, service: svc, engine: New(svc), hits: map[string]bool{}}
+}
+
+func (c *apiClient) do(method, path, token string, body any) apiResponse {
+	c.t.Helper()
+	return c.doWith(method, path, token, nil, body)
+}
+
+// doWith is do with extra request headers
+func (c *apiClient) doWith(method, path, token string, header http.Header, body any) apiResponse {
+	c.t.Helper()
+
+	var reader io.Reader
+	switch body := body.(type) {
+	case nil:
+	case string:
+		reader = strings.NewReader(body)
+	default:
+		data, err := json.Marshal(body)
+		if err != nil {
+			c.t.Fatalf("encoding body: %v", err)
+		}
+		reader = bytes.NewReader(data)
+	}
+	request := httptest.NewRequest(method, path, reader)
+	request.Header.Set("Content-Type", "application/json")
+	if token != "" {
+		request.Header.Set("Authorization", "Bearer "+token)
+	}
+	for key, values := range header {
+		request.Header[key] = values
+	}
+
+	recorder := httptest.NewRecorder()
+	c.engine.ServeHTTP(recorder, request)
+	c.record(method, request.URL.Path)
+
+	response := apiResponse{Code: recorder.Code, Header: recorder.Header(), Text: recorder.Body.String()}
+	if recorder.Body.Len() > 0 && strings.Contains(recorder.Header().Get("Content-Type"), "json") {
+		if err := json.Unmarshal(recorder.Body.Bytes(), &response.Body); err != nil {
+			c.t.Fatalf("%s %s: decoding body %q: %v", method, path, recorder.Body.String(), err)
+		}
+	}
+	return response
+}
+
+// expect fails the test unless the response has the wanted status
+func (c *apiClient) expect(response apiResponse, status int) apiResponse {
+	c.t.Helper()
+	if response.Code != status {
+		c.t.Fatalf("status = %d, want %d; body = %v", response.Code, status, response.Body)
+	}
+	return response
+}
+
+func (c *apiClient) record(method, path string) {
+	for _, route := range c.engine.Routes() {
+		if route.Method == method && routeRegexp(route.Path).MatchString(path) {
+			c.hits[method+" "+route.Path] = true
+			return
+		}
+	}
+}
+
+var routeParam = regexp.MustCompile(`:[^/]+`)
+
+// routeRegexp matches request paths against a gin route pattern
+func routeRegexp(pattern string) *regexp.Regexp {
+	return regexp.MustCompile("^" + routeParam.ReplaceAllString(regexp.QuoteMeta(pattern), `[^/]+`) + "$")
+}
+
+// signUpAndLogin registers an account and returns its token
+func (c *apiClient) signUpAndLogin(username, email, phone, role string) string {
+	c.t.Helper()
+	c.expect(c.do(http.MethodPost, "/api/v1/auth/signup", "", map[string]any{
+		"username":    username,
+		"email":       email,
+		"phonenumber": phone,
+		"password":    "Passw0rd!",
+		"role":        role,
+	}), http.StatusOK)
+
+	login := c.expect(c.do(http.MethodPost, "/api/v1/auth/login", "", map[string]any{
+		"email":    email,
+		"password": "Passw0rd!",
+	}), http.StatusOK)
+	token, _ := login.Body["token"].(string)
+	if token == "" {
+		c.t.Fatalf("login returned no token: %v", login.Body)
+	}
+	return token
+}
+
+func ifMatch(tag string) http.Header {
+	return http.Header{"If-Match": {tag}}
+}
+
+// patchHeader sends a PATCH body of the given media type against any version
+func patchHeader(contentType string) http.Header {
+	return http.Header{"Content-Type": {contentType}, "If-Match": {"*"}}
+}
+
+// tagVersion is the resource version an ETag was made from
+func tagVersion(tag string) string {
+	version, _, _ := strings.Cut(strings.Trim(tag, `"`), "-")
+	return version
+}
+
+func data(response apiResponse) map[string]any {
+	value, _ := response.Body["data"].(map[string]any)
+	return value
+}
+
+func problemCode(response apiResponse) string {
+	code, _ := response.Body["code"].(string)
+	return code
+}
+
+var careerBody = map[string]any{
+	"company":     "Acme",
+	"position":    "Backend Engineer",
+	"jobtype":     "Full-time",
+	"description": "Build APIs",
+	"startdate":   "2026-01-01T00:00:00Z",
+	"enddate":     "2026-12-31T00:00:00Z",
+}
+
+var profileBody = map[string]any{
+	"fullname": "Jane Doe",
+	"age":      30,
+	"gender":   "female",
+	"address":  "1 Main Street",
+}
+
+func TestAuthRoutes(t *testing.T) {
+	c := newAPIClient(t)
+	c.signUpAndLogin("jane", "jane@example.com", "+14155550100", "user")
+
+	t.Run("duplicate email is a conflict", func(t *testing.T) {
+		response := c.do(http.MethodPost, "/api/v1/auth/signup", "", map[string]any{
+			"username": "jane2", "email": "JANE@example.com", "phonenumber": "+14155550101",
+			"password": "Passw0rd!", "role": "user",
+		})
+		c.expect(response, http.StatusConflict)
+		if problemCode(response) != "conflict" {
+			t.Errorf("code = %q, want conflict", problemCode(response))
+		}
+	})
+
+	t.Run("invalid signup reports every field", func(t *testing.T) {
+		response := c.expect(c.do(http.MethodPost, "/api/v1/auth/signup", "", map[string]any{
+			"username": "", "email": "nope", "phonenumber": "123", "password": "short", "role": "root",
+		}), http.StatusBadRequest)
+		if errs, _ := response.Body["errors"].([]any); len(errs) != 5 {
+			t.Errorf("errors = %v, want 5 field errors", response.Body["errors"])
+		}
+	})
+
+	t.Run("wrong password is unauthorized", func(t *testing.T) {
+		c.expect(c.do(http.MethodPost, "/api/v1/auth/login", "", map[string]any{
+			"email": "jane@example.com", "password": "Wrong0ne!",
+		}), http.StatusUnauthorized)
+	})
+
+	t.Run("missing token is unauthorized", func(t *testing.T) {
+		c.expect(c.do(http.MethodGet, "/api/v1/careers", "", nil), http.StatusUnauthorized)
+	})
+}
+
+func TestCareerRoutes(t *testing.T) {
+	c := newAPIClient(t)
+	admin := c.signUpAndLogin("admin", "admin@example.com", "+14155550100", "admin")
+	user := c.signUpAndLogin("jane", "jane@example.com", "+14155550101", "user")
+
+	c.expect(c.do(http.MethodPost, "/api/v1/careers", user, careerBody), http.StatusForbidden)
+	c.expect(c.do(http.MethodPost, "/api/v1/careers", admin, careerBody), http.StatusOK)
+
+	invalid := map[string]any{}
+	for key, value := range careerBody {
+		invalid[key] = value
+	}
+	invalid["enddate"] = "2025-01-01T00:00:00Z"
+	c.expect(c.do(http.MethodPost, "/api/v1/careers", admin, invalid), http.StatusBadRequest)
+
+	list := c.expect(c.do(http.MethodGet, "/api/v1/careers", user, nil), http.StatusOK)
+	if careers, _ := list.Body["data"].([]any); len(careers) != 1 {
+		t.Fatalf("careers = %v, want 1", list.Body["data"])
+	}
+
+	got := c.expect(c.do(http.MethodGet, "/api/v1/careers/1", user, nil), http.StatusOK)
+	if data(got)["company"] != "Acme" {
+		t.Errorf("company = %v", data(got)["company"])
+	}
+	c.expect(c.do(http.MethodGet, "/api/v1/careers/99", user, nil), http.StatusNotFound)
+	c.expect(c.do(http.MethodGet, "/api/v1/careers/abc", user, nil), http.StatusBadRequest)
+
+	updated := c.expect(c.doWith(http.MethodPut, "/api/v1/careers/1", admin, ifMatch(got.Header.Get("ETag")), map[string]any{"position": "Staff Engineer"}), http.StatusOK)
+	if data(updated)["position"] != "Staff Engineer" || data(updated)["company"] != "Acme" {
+		t.Errorf("updated career = %v", data(updated))
+	}
+	c.expect(c.doWith(http.MethodPut, "/api/v1/careers/99", admin, ifMatch("*"), map[string]any{"position": "x"}), http.StatusNotFound)
+
+	c.expect(c.doWith(http.MethodDelete, "/api/v1/careers/1", user, ifMatch("*"), nil), http.StatusForbidden)
+	c.expect(c.doWith(http.MethodDelete, "/api/v1/careers/1", admin, ifMatch(updated.Header.Get("ETag")), nil), http.StatusOK)
+	c.expect(c.doWith(http.MethodDelete, "/api/v1/careers/1", admin, ifMatch("*"), nil), http.StatusNotFound)
+}
+
+func TestProfileRoutes(t *testing.T) {
+	c := newAPIClient(t)
+	admin := c.signUpAndLogin("admin", "admin@example.com", "+14155550100", "admin")
+	user := c.signUpAndLogin("jane", "jane@example.com", "+14155550101", "user")
+
+	c.expect(c.do(http.MethodPost, "/api/v1/profiles", admin, profileBody), http.StatusForbidden)
+	created := c.expect(c.do(http.MethodPost, "/api/v1/profiles", user, profileBody), http.StatusOK)
+	userID := int(data(created)["userid"].(float64))
+	if data(created)["phonenumber"] != "+14155550101" {
+		t.Errorf("profile phone = %v", data(created)["phonenumber"])
+	}
+	path := "/api/v1/profiles/" + strconv.Itoa(userID)
+
+	list := c.expect(c.do(http.MethodGet, "/api/v1/profiles", admin, nil), http.StatusOK)
+	if profiles, _ := list.Body["data"].([]any); len(profiles) != 1 {
+		t.Fatalf("profiles = %v, want 1", list.Body["data"])
+	}
+	got := c.expect(c.do(http.MethodGet, path, admin, nil), http.StatusOK)
+	c.expect(c.do(http.MethodGet, "/api/v1/profiles/99", admin, nil), http.StatusNotFound)
+
+	updated := c.expect(c.doWith(http.MethodPut, path, user, ifMatch(got.Header.Get("ETag")), map[string]any{"age": 31}), http.StatusOK)
+	if data(updated)["age"] != float64(31) || data(updated)["fullname"] != "Jane Doe" {
+		t.Errorf("updated profile = %v", data(updated))
+	}
+	c.expect(c.doWith(http.MethodPut, path, user, ifMatch("*"), map[string]any{"age": -1}), http.StatusBadRequest)
+
+	c.expect(c.doWith(http.MethodDelete, path, admin, ifMatch("*"), nil), http.StatusForbidden)
+	c.expect(c.doWith(http.MethodDelete, path, user, ifMatch(updated.Header.Get("ETag")), nil), http.StatusOK)
+	c.expect(c.do(http.MethodGet, path, admin, nil), http.StatusNotFound)
+}
+
+func TestUserRoutes(t *testing.T) {
+	c := newAPIClient(t)
+	admin := c.signUpAndLogin("admin", "admin@example.com", "+14155550100", "admin")
+	user := c.signUpAndLogin("jane", "jane@example.com", "+14155550101", "user")
+
+	c.expect(c.do(http.MethodGet, "/api/v1/users/emails", user, nil), http.StatusForbidden)
+	emails := c.expect(c.do(http.MethodGet, "/api/v1/users/emails", admin, nil), http.StatusOK)
+	if list, _ := emails.Body["data"].([]any); len(list) != 1 || list[0] != "jane@example.com" {
+		t.Errorf("emails = %v", emails.Body["data"])
+	}
+}
+
+func TestConditionalRequests(t *testing.T) {
+	c := newAPIClient(t)
+	admin := c.signUpAndLogin("admin", "admin@example.com", "+14155550100", "admin")
+
+	created := c.expect(c.do(http.MethodPost, "/api/v1/careers", admin, careerBody), http.StatusOK)
+	if tag := created.Header.Get("ETag"); tagVersion(tag) != "1" {
+		t.Fatalf("ETag of a new career = %q, want \"1\"", tag)
+	}
+
+	t.Run("reads honour If-None-Match", func(t *testing.T) {
+		tag := c.expect(c.do(http.MethodGet, "/api/v1/careers/1", admin, nil), http.StatusOK).Header.Get("ETag")
+		c.expect(c.doWith(http.MethodGet, "/api/v1/careers/1", admin, http.Header{"If-None-Match": {tag}}, nil), http.StatusNotModified)
+		c.expect(c.doWith(http.MethodGet, "/api/v1/careers/1", admin, http.Header{"If-None-Match": {`"0"`}}, nil), http.StatusOK)
+
+		list := c.expect(c.do(http.MethodGet, "/api/v1/careers", admin, nil), http.StatusOK)
+		c.expect(c.doWith(http.MethodGet, "/api/v1/careers", admin, http.Header{"If-None-Match": {list.Header.Get("ETag")}}, nil), http.StatusNotModified)
+	})
+
+	t.Run("callers who see different fields get different tags", func(t *testing.T) {
+		user := c.signUpAndLogin("jane", "jane@example.com", "+14155550101", "user")
+		body := map[string]any{"salary_min": 100000, "salary_max": 120000, "salary_currency": "USD", "pay_period": "yearly", "salary_visible": false}
+		for key, value := range careerBody {
+			body[key] = value
+		}
+		created := data(c.expect(c.do(http.MethodPost, "/api/v1/careers", admin, body), http.StatusOK))
+		path := fmt.Sprintf("/api/v1/careers/%v", created["jobid"])
+
+		adminTag := c.expect(c.do(http.MethodGet, path, admin, nil), http.StatusOK).Header.Get("ETag")
+		userTag := c.expect(c.do(http.MethodGet, path, user, nil), http.StatusOK).Header.Get("ETag")
+		if adminTag == userTag || tagVersion(adminTag) != tagVersion(userTag) {
+			t.Errorf("ETags = %s for the admin and %s for a user, want the same version with different representations", adminTag, userTag)
+		}
+		c.expect(c.doWith(http.MethodGet, path, user, http.Header{"If-None-Match": {adminTag}}, nil), http.StatusOK)
+		c.expect(c.doWith(http.MethodPut, path, admin, ifMatch(userTag), map[string]any{"position": "Lead"}), http.StatusOK)
+
+		adminList := c.expect(c.do(http.MethodGet, "/api/v1/careers", admin, nil), http.StatusOK).Header.Get("ETag")
+		userList := c.expect(c.do(http.MethodGet, "/api/v1/careers", user, nil), http.StatusOK).Header.Get("ETag")
+		if adminList == userList {
+			t.Errorf("list ETag = %s for both the admin and a user, want different tags", adminList)
+		}
+		c.expect(c.doWith(http.MethodGet, "/api/v1/careers", user, http.Header{"If-None-Match": {adminList}}, nil), http.StatusOK)
+	})
+
+	t.Run("writes need If-Match", func(t *testing.T) {
+		response := c.expect(c.do(http.MethodPut, "/api/v1/careers/1", admin, map[string]any{"position": "x"}), http.StatusPreconditionRequired)
+		if problemCode(response) != "precondition_required" {
+			t.Errorf("code = %q, want precondition_required", problemCode(response))
+		}
+		c.expect(c.do(http.MethodDelete, "/api/v1/careers/1", admin, nil), http.StatusPreconditionRequired)
+	})
+
+	t.Run("stale If-Match is rejected", func(t *testing.T) {
+		updated := c.expect(c.doWith(http.MethodPut, "/api/v1/careers/1", admin, ifMatch(`"1"`), map[string]any{"position": "Lead"}), http.StatusOK)
+		if tag := updated.Header.Get("ETag"); tagVersion(tag) != "2" {
+			t.Errorf("ETag after update = %q, want \"2\"", tag)
+		}
+
+		response := c.expect(c.doWith(http.MethodPut, "/api/v1/careers/1", admin, ifMatch(`"1"`), map[string]any{"position": "Lost update"}), http.StatusPreconditionFailed)
+		if problemCode(response) != "precondition_failed" {
+			t.Errorf("code = %q, want precondition_failed", problemCode(response))
+		}
+		c.expect(c.doWith(http.MethodDelete, "/api/v1/careers/1", admin, ifMatch(`W/"2"`), nil), http.StatusPreconditionFailed)
+
+		got := c.expect(c.do(http.MethodGet, "/api/v1/careers/1", admin, nil), http.StatusOK)
+		if data(got)["position"] != "Lead" {
+			t.Errorf("position = %v, want the first update to win", data(got)["position"])
+		}
+	})
+
+	t.Run("legacy routes keep If-Match optional", func(t *testing.T) {
+		c.expect(c.do(http.MethodPut, "/updatecareer/1", admin, map[string]any{"jobtype": "Contract"}), http.StatusOK)
+	})
+}
+
+func TestPatchRoutes(t *testing.T) {
+	c := newAPIClient(t)
+	admin := c.signUpAndLogin("admin", "admin@example.com", "+14155550100", "admin")
+	user := c.signUpAndLogin("jane", "jane@example.com", "+14155550101", "user")
+	c.expect(c.do(http.MethodPost, "/api/v1/careers", admin, careerBody), http.StatusOK)
+	created := c.expect(c.do(http.MethodPost, "/api/v1/profiles", user, profileBody), http.StatusOK)
+	profilePath := "/api/v1/profiles/" + strconv.Itoa(int(data(created)["userid"].(float64)))
+
+	t.Run("merge patch clears fields and moves dates", func(t *testing.T) {
+		response := c.expect(c.doWith(http.MethodPatch, "/api/v1/careers/1", admin, patchHeader("application/merge-patch+json"),
+			`{"description": null, "enddate": "2027-06-30T00:00:00Z"}`), http.StatusOK)
+		career := data(response)
+		if career["description"] != "" || career["enddate"] != "2027-06-30T00:00:00Z" || career["company"] != "Acme" {
+			t.Errorf("patched career = %v", career)
+		}
+		if tag := response.Header.Get("ETag"); tagVersion(tag) != "2" {
+			t.Errorf("ETag = %q, want \"2\"", tag)
+		}
+	})
+
+	t.Run("JSON Patch with a passing test", func(t *testing.T) {
+		response := c.expect(c.doWith(http.MethodPatch, "/api/v1/careers/1", admin, patchHeader("application/json-patch+json"),
+			`[{"op": "test", "path": "/company", "value": "Acme"}, {"op": "replace", "path": "/position", "value": "Principal"}]`), http.StatusOK)
+		if data(response)["position"] != "Principal" {
+			t.Errorf("position = %v", data(response)["position"])
+		}
+	})
+
+	t.Run("JSON Patch with a failing test is a conflict", func(t *testing.T) {
+		c.expect(c.doWith(http.MethodPatch, "/api/v1/careers/1", admin, patchHeader("application/json-patch+json"),
+			`[{"op": "test", "path": "/company", "value": "Other"}, {"op": "replace", "path": "/position", "value": "x"}]`), http.StatusConflict)
+	})
+
+	t.Run("patched document is validated", func(t *testing.T) {
+		response := c.expect(c.doWith(http.MethodPatch, "/api/v1/careers/1", admin, patchHeader("application/merge-patch+json"),
+			`{"company": "", "enddate": "2020-01-01T00:00:00Z"}`), http.StatusBadRequest)
+		if errs, _ := response.Body["errors"].([]any); len(errs) != 2 {
+			t.Errorf("errors = %v, want company and enddate", response.Body["errors"])
+		}
+	})
+
+	t.Run("read-only fields cannot be patched", func(t *testing.T) {
+		c.expect(c.doWith(http.MethodPatch, "/api/v1/careers/1", admin, patchHeader("application/merge-patch+json"), `{"jobid": 7}`), http.StatusBadRequest)
+		c.expect(c.doWith(http.MethodPatch, "/api/v1/careers/1", admin, patchHeader("application/json-patch+json"),
+			`[{"op": "add", "path": "/version", "value": 1}]`), http.StatusBadRequest)
+	})
+
+	t.Run("unknown media type", func(t *testing.T) {
+		c.expect(c.doWith(http.MethodPatch, "/api/v1/careers/1", admin, patchHeader("text/plain"), `company=x`), http.StatusUnsupportedMediaType)
+	})
+
+	t.Run("empty patch keeps the version", func(t *testing.T) {
+		response := c.expect(c.doWith(http.MethodPatch, "/api/v1/careers/1", admin, patchHeader("application/merge-patch+json"), `{}`), http.StatusOK)
+		if tag := response.Header.Get("ETag"); tagVersion(tag) != "3" {
+			t.Errorf("ETag = %q, want \"3\"", tag)
+		}
+	})
+
+	t.Run("profile age can be set to zero", func(t *testing.T) {
+		response := c.expect(c.doWith(http.MethodPatch, profilePath, user, patchHeader("application/merge-patch+json"), `{"age": 0, "address": ""}`), http.StatusOK)
+		if data(response)["age"] != float64(0) || data(response)["address"] != "" {
+			t.Errorf("patched profile = %v", data(response))
+		}
+		c.expect(c.doWith(http.MethodPatch, profilePath, admin, patchHeader("application/merge-patch+json"), `{"age": 1}`), http.StatusForbidden)
+	})
+}
+
+func TestTrashRoutes(t *testing.T) {
+	c := newAPIClient(t)
+	admin := c.signUpAndLogin("admin", "admin@example.com", "+14155550100", "admin")
+	user := c.signUpAndLogin("jane", "jane@example.com", "+14155550101", "user")
+	c.expect(c.do(http.MethodPost, "/api/v1/careers", admin, careerBody), http.StatusOK)
+	created := c.expect(c.do(http.MethodPost, "/api/v1/profiles", user, profileBody), http.StatusOK)
+	userID := strconv.Itoa(int(data(created)["userid"].(float64)))
+
+	trash := func() map[string]any {
+		c.t.Helper()
+		return data(c.expect(c.do(http.MethodGet, "/api/v1/trash", admin, nil), http.StatusOK))
+	}
+	count := func(trash map[string]any, kind string) int {
+		items, _ := trash[kind].([]any)
+		return len(items)
+	}
+
+	t.Run("deleted careers move to the trash and back", func(t *testing.T) {
+		c.expect(c.doWith(http.MethodDelete, "/api/v1/careers/1", admin, ifMatch("*"), nil), http.StatusOK)
+		c.expect(c.do(http.MethodGet, "/api/v1/careers/1", user, nil), http.StatusNotFound)
+		if list := c.expect(c.do(http.MethodGet, "/api/v1/careers", user, nil), http.StatusOK); list.Body["data"] != nil {
+			t.Errorf("careers = %v, want none", list.Body["data"])
+		}
+		c.expect(c.doWith(http.MethodPatch, "/api/v1/careers/1", admin, patchHeader("application/merge-patch+json"), `{"position": "x"}`), http.StatusNotFound)
+		if got := trash(); count(got, "careers") != 1 {
+			t.Fatalf("trash = %v, want the career", got)
+		}
+
+		restored := c.expect(c.do(http.MethodPost, "/api/v1/careers/1/restore", admin, nil), http.StatusOK)
+		if tagVersion(restored.Header.Get("ETag")) != "2" || data(restored)["deleted_at"] != nil {
+			t.Errorf("restored career = %v, ETag %s", data(restored), restored.Header.Get("ETag"))
+		}
+		c.expect(c.do(http.MethodGet, "/api/v1/careers/1", user, nil), http.StatusOK)
+		c.expect(c.do(http.MethodPost, "/api/v1/careers/1/restore", admin, nil), http.StatusNotFound)
+		c.expect(c.do(http.MethodPost, "/api/v1/careers/1/restore", user, nil), http.StatusForbidden)
+	})
+
+	t.Run("deleting a user takes their profile along", func(t *testing.T) {
+		c.expect(c.do(http.MethodGet, "/api/v1/trash", user, nil), http.StatusForbidden)
+		deleted := c.expect(c.do(http.MethodDelete, "/api/v1/users/"+userID, admin, nil), http.StatusOK)
+		if account, _ := deleted.Body["deleted data"].(map[string]any); account["email"] != "jane@example.com" || account["password"] != nil {
+			t.Errorf("deleted user = %v", deleted.Body["deleted data"])
+		}
+		c.expect(c.do(http.MethodGet, "/api/v1/profiles/"+userID, admin, nil), http.StatusNotFound)
+		c.expect(c.do(http.MethodPost, "/api/v1/auth/login", "", map[string]any{"email": "jane@example.com", "password": "Passw0rd!"}), http.StatusUnauthorized)
+		if got := trash(); count(got, "users") != 1 || count(got, "profiles") != 1 {
+			t.Fatalf("trash = %v, want the user and profile", got)
+		}
+
+		response := c.expect(c.do(http.MethodPost, "/api/v1/profiles/"+userID+"/restore", admin, nil), http.StatusConflict)
+		if !strings.Contains(response.Body["detail"].(string), "restore the user first") {
+			t.Errorf("detail = %v", response.Body["detail"])
+		}
+
+		c.expect(c.do(http.MethodPost, "/api/v1/users/"+userID+"/restore", admin, nil), http.StatusOK)
+		c.expect(c.do(http.MethodGet, "/api/v1/profiles/"+userID, admin, nil), http.StatusOK)
+		c.expect(c.do(http.MethodPost, "/api/v1/profiles/"+userID+"/restore", admin, nil), http.StatusConflict)
+		c.expect(c.do(http.MethodDelete, "/api/v1/users/99", admin, nil), http.StatusNotFound)
+	})
+
+	t.Run("a deleted user's email can be reused", func(t *testing.T) {
+		c.expect(c.do(http.MethodDelete, "/api/v1/users/"+userID, admin, nil), http.StatusOK)
+		c.signUpAndLogin("jane2", "jane@example.com", "+14155550102", "user")
+		c.expect(c.do(http.MethodPost, "/api/v1/users/"+userID+"/restore", admin, nil), http.StatusConflict)
+	})
+
+	t.Run("purge removes what is older than the cutoff", func(t *testing.T) {
+		c.expect(c.doWith(http.MethodDelete, "/api/v1/careers/1", admin, ifMatch("*"), nil), http.StatusOK)
+
+		purged, err := c.service.PurgeTrash(context.Background(), time.Now().Add(-time.Hour))
+		if err != nil || purged != (service.Purged{}) {
+			t.Fatalf("PurgeTrash an hour ago = %+v, %v; want nothing purged", purged, err)
+		}
+		purged, err = c.service.PurgeTrash(context.Background(), time.Now().Add(time.Second))
+		if err != nil || purged != (service.Purged{Careers: 1, Profiles: 1, Users: 1}) {
+			t.Fatalf("PurgeTrash = %+v, %v", purged, err)
+		}
+		if got := trash(); count(got, "careers")+count(got, "profiles")+count(got, "users") != 0 {
+			t.Errorf("trash after purge = %v", got)
+		}
+		c.expect(c.do(http.MethodPost, "/api/v1/careers/1/restore", admin, nil), http.StatusNotFound)
+	})
+}
+
+func TestAuditRoutes(t *testing.T) {
+	c := newAPIClient(t)
+	admin := c.signUpAndLogin("admin", "admin@example.com", "+14155550100", "admin")
+	user := c.signUpAndLogin("jane", "jane@example.com", "+14155550101", "user")
+
+	c.expect(c.do(http.MethodPost, "/api/v1/careers", admin, careerBody), http.StatusOK)
+	update := c.expect(c.doWith(http.MethodPut, "/api/v1/careers/1", admin, ifMatch(`"1"`), map[string]any{"position": "Lead"}), http.StatusOK)
+	// a rejected write leaves no trace
+	c.expect(c.doWith(http.MethodPut, "/api/v1/careers/1", admin, ifMatch(`"1"`), map[string]any{"position": "Lost"}), http.StatusPreconditionFailed)
+	c.expect(c.doWith(http.MethodDelete, "/api/v1/careers/1", admin, ifMatch("*"), nil), http.StatusOK)
+	c.expect(c.do(http.MethodPost, "/api/v1/profiles", user, profileBody), http.StatusOK)
+
+	events := func(query string) []map[string]any {
+		t.Helper()
+		response := c.expect(c.do(http.MethodGet, "/api/v1/audit"+query, admin, nil), http.StatusOK)
+		list, _ := response.Body["data"].([]any)
+		events := make([]map[string]any, len(list))
+		for i, item := range list {
+			events[i], _ = item.(map[string]any)
+		}
+		return events
+	}
+
+	t.Run("career changes are recorded newest first", func(t *testing.T) {
+		careers := events("?resource_type=career&resource_id=1")
+		if len(careers) != 3 {
+			t.Fatalf("career events = %v, want 3", careers)
+		}
+		for i, action := range []string{"delete", "update", "create"} {
+			if careers[i]["action"] != action || careers[i]["actor_email"] != "admin@example.com" || careers[i]["actor_role"] != "admin" {
+				t.Errorf("event %d = %v, want an admin %s", i, careers[i], action)
+			}
+		}
+
+		updated := careers[1]
+		if updated["request_id"] != update.Header.Get("X-Request-ID") || updated["ip"] == "" {
+			t.Errorf("update event = %v, want request ID %s and an IP", updated, update.Header.Get("X-Request-ID"))
+		}
+		changes, _ := updated["changes"].(map[string]any)
+		if position, _ := changes["position"].(map[string]any); position["before"] != "Backend Engineer" || position["after"] != "Lead" {
+			t.Errorf("update changes = %v", changes)
+		}
+		if _, ok := changes["company"]; ok {
+			t.Errorf("unchanged company is in the diff: %v", changes)
+		}
+	})
+
+	t.Run("filter by actor and time", func(t *testing.T) {
+		jane := events("?actor=jane@example.com")
+		if len(jane) != 2 || jane[0]["resource_type"] != "profile" || jane[1]["resource_type"] != "user" {
+			t.Errorf("jane's events = %v, want the profile and the signup", jane)
+		}
+		if changes, _ := jane[1]["changes"].(map[string]any); changes["password"] != nil {
+			t.Errorf("signup event exposes the password: %v", changes)
+		}
+		if future := events("?since=" + time.Now().Add(time.Hour).UTC().Format(time.RFC3339)); len(future) != 0 {
+			t.Errorf("events in the future = %v", future)
+		}
+	})
+
+	t.Run("paging", func(t *testing.T) {
+		first := events("?limit=2")
+		if len(first) != 2 {
+			t.Fatalf("first page = %v", first)
+		}
+		rest := events("?before_id=" + strconv.Itoa(int(first[1]["id"].(float64))))
+		// seven events: two signups, the company created with the career,
+		// three career changes and the profile
+		if len(rest) != 5 {
+			t.Errorf("second page = %d events, want 5", len(rest))
+		}
+	})
+
+	t.Run("CSV export", func(t *testing.T) {
+		response := c.expect(c.do(http.MethodGet, "/api/v1/audit?format=csv&resource_type=career", admin, nil), http.StatusOK)
+		if !strings.HasPrefix(response.Header.Get("Content-Type"), "text/csv") {
+			t.Errorf("Content-Type = %q", response.Header.Get("Content-Type"))
+		}
+		records, err := csv.NewReader(strings.NewReader(response.Text)).ReadAll()
+		if err != nil || len(records) != 4 || records[0][0] != "id" || records[1][4] != "delete" {
+			t.Errorf("CSV = %q, %v", records, err)
+		}
+	})
+
+	t.Run("invalid filters and other roles", func(t *testing.T) {
+		c.expect(c.do(http.MethodGet, "/api/v1/audit?resource_type=bogus", admin, nil), http.StatusBadRequest)
+		c.expect(c.do(http.MethodGet, "/api/v1/audit?since=yesterday", admin, nil), http.StatusBadRequest)
+		c.expect(c.do(http.MethodGet, "/api/v1/audit?limit=5000", admin, nil), http.StatusBadRequest)
+		c.expect(c.do(http.MethodGet, "/api/v1/audit", user, nil), http.StatusForbidden)
+	})
+}
+
+func TestCareerRevisionRoutes(t *testing.T) {
+	c := newAPIClient(t)
+	admin := c.signUpAndLogin("admin", "admin@example.com", "+14155550100", "admin")
+	user := c.signUpAndLogin("jane", "jane@example.com", "+14155550101", "user")
+
+	c.expect(c.do(http.MethodPost, "/api/v1/careers", admin, careerBody), http.StatusOK)
+	c.expect(c.doWith(http.MethodPut, "/api/v1/careers/1", admin, ifMatch(`"1"`), map[string]any{"position": "Lead"}), http.StatusOK)
+	c.expect(c.doWith(http.MethodPatch, "/api/v1/careers/1", admin, patchHeader("application/merge-patch+json"), `{"jobtype": "Contract"}`), http.StatusOK)
+	// a patch changing nothing saves no revision
+	c.expect(c.doWith(http.MethodPatch, "/api/v1/careers/1", admin, patchHeader("application/merge-patch+json"), `{"jobtype": "Contract"}`), http.StatusOK)
+
+	revisions := func(query string) []map[string]any {
+		t.Helper()
+		response := c.expect(c.do(http.MethodGet, "/api/v1/careers/1/revisions"+query, admin, nil), http.StatusOK)
+		list, _ := response.Body["data"].([]any)
+		revisions := make([]map[string]any, len(list))
+		for i, item := range list {
+			revisions[i], _ = item.(map[string]any)
+		}
+		return revisions
+	}
+
+	t.Run("every change saves a revision", func(t *testing.T) {
+		list := revisions("")
+		if len(list) != 3 {
+			t.Fatalf("revisions = %v, want 3", list)
+		}
+		for i, want := range []struct {
+			revision float64
+			position string
+			jobtype  string
+		}{{3, "Lead", "Contract"}, {2, "Lead", "Full-time"}, {1, "Backend Engineer", "Full-time"}} {
+			got := list[i]
+			if got["revision"] != want.revision || got["position"] != want.position || got["jobtype"] != want.jobtype || got["author_email"] != "admin@example.com" {
+				t.Errorf("revision %d = %v, want %+v", i, got, want)
+			}
+		}
+
+		first := data(c.expect(c.do(http.MethodGet, "/api/v1/careers/1/revisions/1", admin, nil), http.StatusOK))
+		if first["position"] != "Backend Engineer" {
+			t.Errorf("revision 1 = %v", first)
+		}
+		c.expect(c.do(http.MethodGet, "/api/v1/careers/1/revisions/9", admin, nil), http.StatusNotFound)
+	})
+
+	t.Run("point in time", func(t *testing.T) {
+		if past := revisions("?at=" + time.Now().Add(-time.Hour).UTC().Format(time.RFC3339)); len(past) != 0 {
+			t.Errorf("revisions an hour ago = %v, want none", past)
+		}
+		if now := revisions("?at=" + time.Now().Add(time.Minute).UTC().Format(time.RFC3339)); len(now) != 3 || now[0]["revision"] != float64(3) {
+			t.Errorf("revisions now = %v, want 3 starting with revision 3", now)
+		}
+	})
+
+	t.Run("diff", func(t *testing.T) {
+		previous := data(c.expect(c.do(http.MethodGet, "/api/v1/careers/1/revisions/3/diff", admin, nil), http.StatusOK))
+		changes, _ := previous["changes"].(map[string]any)
+		if previous["from"] != float64(2) || len(changes) != 1 {
+			t.Fatalf("diff of revision 3 = %v, want only jobtype from revision 2", previous)
+		}
+		if jobtype, _ := changes["jobtype"].(map[string]any); jobtype["before"] != "Full-time" || jobtype["after"] != "Contract" {
+			t.Errorf("jobtype change = %v", changes["jobtype"])
+		}
+
+		across := data(c.expect(c.do(http.MethodGet, "/api/v1/careers/1/revisions/3/diff?from=1", admin, nil), http.StatusOK))
+		if changes, _ := across["changes"].(map[string]any); len(changes) != 2 {
+			t.Errorf("diff 1..3 = %v, want position and jobtype", across)
+		}
+		c.expect(c.do(http.MethodGet, "/api/v1/careers/1/revisions/3/diff?from=0", admin, nil), http.StatusBadRequest)
+	})
+
+	t.Run("revert", func(t *testing.T) {
+		c.expect(c.doWith(http.MethodPost, "/api/v1/careers/1/revisions/1/revert", admin, ifMatch(`"2"`), nil), http.StatusPreconditionFailed)
+		reverted := c.expect(c.doWith(http.MethodPost, "/api/v1/careers/1/revisions/1/revert", admin, ifMatch(`"3"`), nil), http.StatusOK)
+		if career := data(reverted); career["position"] != "Backend Engineer" || career["jobtype"] != "Full-time" || career["version"] != float64(4) {
+			t.Errorf("reverted career = %v", career)
+		}
+		if got := reverted.Header.Get("ETag"); tagVersion(got) != "4" {
+			t.Errorf("ETag = %q, want \"4\"", got)
+		}
+		if list := revisions(""); len(list) != 4 || list[0]["position"] != "Backend Engineer" {
+			t.Errorf("revisions after revert = %v", list)
+		}
+
+		response := c.expect(c.do(http.MethodGet, "/api/v1/audit?resource_type=career&resource_id=1&limit=1", admin, nil), http.StatusOK)
+		if events, _ := response.Body["data"].([]any); len(events) != 1 || events[0].(map[string]any)["action"] != "revert" {
+			t.Errorf("latest audit event = %v, want a revert", response.Body["data"])
+		}
+	})
+
+	t.Run("missing careers and other roles", func(t *testing.T) {
+		c.expect(c.do(http.MethodGet, "/api/v1/careers/9/revisions", admin, nil), http.StatusNotFound)
+		c.expect(c.do(http.MethodGet, "/api/v1/careers/1/revisions/x", admin, nil), http.StatusBadRequest)
+		c.expect(c.do(http.MethodGet, "/api/v1/careers/1/revisions", user, nil), http.StatusForbidden)
+		c.expect(c.do(http.MethodPost, "/api/v1/careers/1/revisions/1/revert", user, nil), http.StatusForbidden)
+	})
+
+	t.Run("company recruiters read the revisions of their careers", func(t *testing.T) {
+		recruiter := c.signUpAndLogin("rita", "rita@example.com", "+14155550102", "recruiter")
+		outsider := c.signUpAndLogin("otto", "otto@example.com", "+14155550103", "recruiter")
+		const ritaID = 3
+		c.expect(c.do(http.MethodPost, "/api/v1/companies/acme/recruiters", admin, map[string]any{"userid": ritaID}), http.StatusOK)
+
+		c.expect(c.do(http.MethodGet, "/api/v1/careers/1/revisions", recruiter, nil), http.StatusOK)
+		c.expect(c.do(http.MethodGet, "/api/v1/careers/1/revisions/1", recruiter, nil), http.StatusOK)
+		c.expect(c.do(http.MethodGet, "/api/v1/careers/1/revisions/3/diff", recruiter, nil), http.StatusOK)
+		c.expect(c.doWith(http.MethodPost, "/api/v1/careers/1/revisions/1/revert", recruiter, ifMatch("*"), nil), http.StatusForbidden)
+
+		c.expect(c.do(http.MethodGet, "/api/v1/careers/1/revisions", outsider, nil), http.StatusForbidden)
+		c.expect(c.do(http.MethodGet, "/api/v1/careers/1/revisions/1", outsider, nil), http.StatusForbidden)
+		c.expect(c.do(http.MethodGet, "/api/v1/careers/1/revisions/3/diff", outsider, nil), http.StatusForbidden)
+	})
+}
+
+func TestCompanyRoutes(t *testing.T) {
+	c := newAPIClient(t)
+	admin := c.signUpAndLogin("admin", "admin@example.com", "+14155550100", "admin")
+	recruiter := c.signUpAndLogin("rita", "rita@example.com", "+14155550101", "recruiter")
+	outsider := c.signUpAndLogin("otto", "otto@example.com", "+14155550102", "recruiter")
+	user := c.signUpAndLogin("jane", "jane@example.com", "+14155550103", "user")
+	const ritaID, janeID = 2, 4
+
+	career := func(company, enddate string) map[string]any {
+		return map[string]any{
+			"company": company, "position": "Backend Engineer", "jobtype": "Full-time", "description": "Build APIs",
+			"startdate": "2020-01-01T00:00:00Z", "enddate": enddate,
+		}
+	}
+
+	t.Run("spellings of a company share one company", func(t *testing.T) {
+		first := data(c.expect(c.do(http.MethodPost, "/api/v1/careers", admin, career("Acme", "2099-12-31T00:00:00Z")), http.StatusOK))
+		second := data(c.expect(c.do(http.MethodPost, "/api/v1/careers", admin, career(" ACME, Inc. ", "2099-12-31T00:00:00Z")), http.StatusOK))
+		if first["company_id"] != second["company_id"] || second["company"] != "Acme" {
+			t.Errorf("careers = %v and %v, want the same company named Acme", first, second)
+		}
+		// ended careers are not listed on the company page
+		c.expect(c.do(http.MethodPost, "/api/v1/careers", admin, career("acme", "2021-01-01T00:00:00Z")), http.StatusOK)
+
+		page := data(c.expect(c.do(http.MethodGet, "/api/v1/companies/acme", user, nil), http.StatusOK))
+		company, _ := page["company"].(map[string]any)
+		careers, _ := page["careers"].([]any)
+		if company["name"] != "Acme" || len(careers) != 2 {
+			t.Errorf("company page = %v, want Acme with 2 open careers", page)
+		}
+		c.expect(c.do(http.MethodGet, "/api/v1/companies/initech", user, nil), http.StatusNotFound)
+	})
+
+	t.Run("admins create companies", func(t *testing.T) {
+		created := data(c.expect(c.do(http.MethodPost, "/api/v1/companies", admin, map[string]any{
+			"name": "Globex Corporation", "website": "https://globex.example.com", "logo_url": "https://globex.example.com/logo.png",
+		}), http.StatusOK))
+		if created["slug"] != "globex" || created["website"] != "https://globex.example.com" {
+			t.Errorf("created company = %v", created)
+		}
+		c.expect(c.do(http.MethodPost, "/api/v1/companies", admin, map[string]any{"name": "GLOBEX"}), http.StatusConflict)
+		c.expect(c.do(http.MethodPost, "/api/v1/companies", admin, map[string]any{"name": "Initech", "website": "not a url"}), http.StatusBadRequest)
+		c.expect(c.do(http.MethodPost, "/api/v1/companies", user, map[string]any{"name": "Initech"}), http.StatusForbidden)
+
+		list, _ := c.expect(c.do(http.MethodGet, "/api/v1/companies", user, nil), http.StatusOK).Body["data"].([]any)
+		if len(list) != 2 {
+			t.Errorf("companies = %v, want Acme and Globex", list)
+		}
+	})
+
+	t.Run("recruiters manage their own company's careers", func(t *testing.T) {
+		c.expect(c.do(http.MethodPost, "/api/v1/careers", recruiter, career("Acme", "2099-12-31T00:00:00Z")), http.StatusForbidden)
+
+		c.expect(c.do(http.MethodPost, "/api/v1/companies/acme/recruiters", admin, map[string]any{"userid": janeID}), http.StatusConflict)
+		c.expect(c.do(http.MethodPost, "/api/v1/companies/acme/recruiters", admin, map[string]any{"userid": ritaID}), http.StatusOK)
+		c.expect(c.do(http.MethodPost, "/api/v1/companies/acme/recruiters", admin, map[string]any{"userid": ritaID}), http.StatusConflict)
+		recruiters, _ := c.expect(c.do(http.MethodGet, "/api/v1/companies/acme/recruiters", admin, nil), http.StatusOK).Body["data"].([]any)
+		if len(recruiters) != 1 || recruiters[0].(map[string]any)["email"] != "rita@example.com" {
+			t.Errorf("recruiters = %v, want rita", recruiters)
+		}
+
+		created := data(c.expect(c.do(http.MethodPost, "/api/v1/careers", recruiter, career("acme inc", "2099-12-31T00:00:00Z")), http.StatusOK))
+		if created["company"] != "Acme" {
+			t.Errorf("recruiter's career = %v", created)
+		}
+		c.expect(c.doWith(http.MethodPut, "/api/v1/careers/1", recruiter, ifMatch("*"), map[string]any{"position": "Lead"}), http.StatusOK)
+		c.expect(c.doWith(http.MethodPut, "/api/v1/careers/1", recruiter, ifMatch("*"), map[string]any{"company": "Globex"}), http.StatusForbidden)
+		c.expect(c.doWith(http.MethodPatch, "/api/v1/careers/1", recruiter, patchHeader("application/merge-patch+json"), `{"company": "globex"}`), http.StatusForbidden)
+		c.expect(c.doWith(http.MethodDelete, "/api/v1/careers/1", outsider, ifMatch("*"), nil), http.StatusForbidden)
+
+		// a company a recruiter does not belong to is not created for them
+		c.expect(c.do(http.MethodPost, "/api/v1/careers", recruiter, career("Initech", "2099-12-31T00:00:00Z")), http.StatusForbidden)
+		c.expect(c.do(http.MethodGet, "/api/v1/companies/initech", user, nil), http.StatusNotFound)
+
+		moved := data(c.expect(c.doWith(http.MethodPatch, "/api/v1/careers/2", admin, patchHeader("application/merge-patch+json"), `{"company": "Globex Corp."}`), http.StatusOK))
+		if moved["company"] != "Globex Corporation" {
+			t.Errorf("career moved to Globex = %v", moved)
+		}
+		c.expect(c.do(http.MethodGet, "/api/v1/careers/2", recruiter, nil), http.StatusOK)
+		c.expect(c.doWith(http.MethodDelete, "/api/v1/careers/2", recruiter, ifMatch("*"), nil), http.StatusForbidden)
+	})
+
+	t.Run("removed recruiters lose access", func(t *testing.T) {
+		c.expect(c.do(http.MethodDelete, "/api/v1/companies/acme/recruiters/2", admin, nil), http.StatusOK)
+		c.expect(c.do(http.MethodDelete, "/api/v1/companies/acme/recruiters/2", admin, nil), http.StatusNotFound)
+		c.expect(c.doWith(http.MethodPut, "/api/v1/careers/1", recruiter, ifMatch("*"), map[string]any{"position": "Staff"}), http.StatusForbidden)
+	})
+
+	t.Run("non-Latin names are different companies", func(t *testing.T) {
+		yandex := data(c.expect(c.do(http.MethodPost, "/api/v1/careers", admin, career("Яндекс", "2099-12-31T00:00:00Z")), http.StatusOK))
+		gazprom := data(c.expect(c.do(http.MethodPost, "/api/v1/careers", admin, career("Газпром", "2099-12-31T00:00:00Z")), http.StatusOK))
+		if yandex["company_id"] == gazprom["company_id"] || gazprom["company"] != "Газпром" {
+			t.Errorf("careers = %v and %v, want two companies", yandex, gazprom)
+		}
+		bangs := data(c.expect(c.do(http.MethodPost, "/api/v1/careers", admin, career("!!!", "2099-12-31T00:00:00Z")), http.StatusOK))
+		marks := data(c.expect(c.do(http.MethodPost, "/api/v1/careers", admin, career("???", "2099-12-31T00:00:00Z")), http.StatusOK))
+		if bangs["company_id"] == marks["company_id"] {
+			t.Errorf("careers = %v and %v, want two companies", bangs, marks)
+		}
+
+		page := data(c.expect(c.do(http.MethodGet, "/api/v1/companies/"+url.PathEscape("яндекс"), user, nil), http.StatusOK))
+		if company, _ := page["company"].(map[string]any); company["name"] != "Яндекс" {
+			t.Errorf("company page = %v, want Яндекс", page)
+		}
+
+		c.expect(c.do(http.MethodPost, "/api/v1/companies/"+url.PathEscape("яндекс")+"/recruiters", admin, map[string]any{"userid": ritaID}), http.StatusOK)
+		path := fmt.Sprintf("/api/v1/careers/%v", gazprom["jobid"])
+		c.expect(c.doWith(http.MethodPut, path, recruiter, ifMatch("*"), map[string]any{"position": "Lead"}), http.StatusForbidden)
+	})
+}
+
+func TestCareerCompensation(t *testing.T) {
+	c := newAPIClient(t)
+	admin := c.signUpAndLogin("admin", "admin@example.com", "+14155550100", "admin")
+	user := c.signUpAndLogin("jane", "jane@example.com", "+14155550101", "user")
+
+	withSalary := func(fields map[string]any) map[string]any {
+		body := map[string]any{}
+		for key, value := range careerBody {
+			body[key] = value
+		}
+		for key, value := range fields {
+			body[key] = value
+		}
+		return body
+	}
+	for _, fields := range []map[string]any{
+		{"salary_min": 100000, "salary_max": 150000, "salary_currency": "USD", "pay_period": "yearly", "equity": true},
+		// 60,000-72,000 EUR a year
+		{"salary_min": 5000, "salary_max": 6000, "salary_currency": "EUR", "pay_period": "monthly"},
+		// 104,000-145,600 USD a year
+		{"salary_min": 50, "salary_max": 70, "salary_currency": "USD", "pay_period": "hourly"},
+		{},
+		{"salary_min": 80000, "salary_max": 90000, "salary_currency": "GBP", "pay_period": "yearly", "equity": true, "salary_visible": false},
+	} {
+		c.expect(c.do(http.MethodPost, "/api/v1/careers", admin, withSalary(fields)), http.StatusOK)
+	}
+
+	t.Run("validation", func(t *testing.T) {
+		invalid := map[string]map[string]any{
+			"salary_max":      {"salary_min": 10, "salary_max": 5, "salary_currency": "USD", "pay_period": "yearly"},
+			"salary_min":      {"salary_currency": "USD"},
+			"pay_period":      {"salary_min": 1, "salary_max": 2, "salary_currency": "USD", "pay_period": "weekly"},
+			"salary_currency": {"salary_min": 1, "salary_max": 2, "salary_currency": "usd", "pay_period": "yearly"},
+		}
+		for field, fields := range invalid {
+			response := c.expect(c.do(http.MethodPost, "/api/v1/careers", admin, withSalary(fields)), http.StatusBadRequest)
+			if !strings.Contains(response.Text, `"field":"`+field+`"`) {
+				t.Errorf("%v: errors = %s, want one for %s", fields, response.Text, field)
+			}
+		}
+		// a real currency without a stored rate
+		c.expect(c.do(http.MethodPost, "/api/v1/careers", admin, withSalary(map[string]any{
+			"salary_min": 1, "salary_max": 2, "salary_currency": "NOK", "pay_period": "yearly",
+		})), http.StatusBadRequest)
+		c.expect(c.doWith(http.MethodPatch, "/api/v1/careers/1", admin, patchHeader("application/merge-patch+json"), `{"salary_min": 200000}`), http.StatusBadRequest)
+	})
+
+	jobIDs := func(query string) []float64 {
+		t.Helper()
+		response := c.expect(c.do(http.MethodGet, "/api/v1/careers"+query, user, nil), http.StatusOK)
+		list, _ := response.Body["data"].([]any)
+		ids := []float64{}
+		for _, item := range list {
+			ids = append(ids, item.(map[string]any)["jobid"].(float64))
+		}
+		return ids
+	}
+
+	t.Run("salary filters compare yearly amounts", func(t *testing.T) {
+		tests := map[string][]float64{
+			"":                                    {1, 2, 3, 4, 5},
+			"?salary_min=100000":                  {1, 3},
+			"?salary_max=80000":                   {2},
+			"?salary_min=70000&salary_max=100000": {1, 2},
+			"?salary_min=60000&currency=EUR":      {1, 2, 3},
+			"?salary_min=200000":                  {},
+		}
+		for query, want := range tests {
+			if got := jobIDs(query); fmt.Sprint(got) != fmt.Sprint(want) {
+				t.Errorf("careers%s = %v, want %v", query, got, want)
+			}
+		}
+		c.expect(c.do(http.MethodGet, "/api/v1/careers?salary_min=1&currency=NOK", user, nil), http.StatusBadRequest)
+		c.expect(c.do(http.MethodGet, "/api/v1/careers?salary_min=-1", user, nil), http.StatusBadRequest)
+	})
+
+	t.Run("hidden salaries", func(t *testing.T) {
+		hidden := data(c.expect(c.do(http.MethodGet, "/api/v1/careers/5", user, nil), http.StatusOK))
+		if hidden["salary_min"] != nil || hidden["salary_currency"] != nil || hidden["equity"] != true {
+			t.Errorf("hidden salary shown to a user: %v", hidden)
+		}
+		shown := data(c.expect(c.do(http.MethodGet, "/api/v1/careers/5", admin, nil), http.StatusOK))
+		if shown["salary_min"] != float64(80000) || shown["salary_currency"] != "GBP" {
+			t.Errorf("hidden salary not shown to an admin: %v", shown)
+		}
+	})
+
+	t.Run("patching and reverting salaries", func(t *testing.T) {
+		cleared := data(c.expect(c.doWith(http.MethodPatch, "/api/v1/careers/1", admin, patchHeader("application/merge-patch+json"),
+			`{"salary_min": null, "salary_max": null, "salary_currency": null, "pay_period": null}`), http.StatusOK))
+		if cleared["salary_min"] != nil || cleared["pay_period"] != nil {
+			t.Errorf("cleared salary = %v", cleared)
+		}
+		reverted := data(c.expect(c.doWith(http.MethodPost, "/api/v1/careers/1/revisions/1/revert", admin, ifMatch("*"), nil), http.StatusOK))
+		if reverted["salary_max"] != float64(150000) || reverted["salary_currency"] != "USD" {
+			t.Errorf("reverted salary = %v", reverted)
+		}
+	})
+}
+
+func TestCareerLocations(t *testing.T) {
+	c := newAPIClient(t)
+	admin := c.signUpAndLogin("admin", "admin@example.com", "+14155550100", "admin")
+	user := c.signUpAndLogin("jane", "jane@example.com", "+14155550101", "user")
+
+	withLocation := func(fields map[string]any) map[string]any {
+		body := map[string]any{}
+		for key, value := range careerBody {
+			body[key] = value
+		}
+		for key, value := range fields {
+			body[key] = value
+		}
+		return body
+	}
+	for _, fields := range []map[string]any{
+		{"locations": []any{map[string]any{"city": "Berlin"}}},
+		{"locations": []any{map[string]any{"city": "Potsdam", "country": "DE", "lat": 52.3906, "lon": 13.0645}}, "remote_policy": "hybrid"},
+		{"locations": []any{map[string]any{"city": "Paris"}, map[string]any{"city": "London"}}},
+		{"remote_policy": "remote", "remote_countries": []string{"DE", "FR"}},
+	} {
+		c.expect(c.do(http.MethodPost, "/api/v1/careers", admin, withLocation(fields)), http.StatusOK)
+	}
+
+	t.Run("city names are geocoded", func(t *testing.T) {
+		berlin := data(c.expect(c.do(http.MethodGet, "/api/v1/careers/1", user, nil), http.StatusOK))
+		locations, _ := berlin["locations"].([]any)
+		if len(locations) != 1 {
+			t.Fatalf("locations = %v", berlin["locations"])
+		}
+		location := locations[0].(map[string]any)
+		if location["country"] != "DE" || location["region"] != "Berlin" || location["lat"] != 52.52 || location["lon"] != 13.405 {
+			t.Errorf("geocoded location = %v", location)
+		}
+		if berlin["remote_policy"] != "onsite" {
+			t.Errorf("default remote_policy = %v, want onsite", berlin["remote_policy"])
+		}
+	})
+
+	t.Run("validation", func(t *testing.T) {
+		invalid := map[string]map[string]any{
+			"locations[0].city":    {"locations": []any{map[string]any{"city": "Atlantis"}}},
+			"locations[0].lat":     {"locations": []any{map[string]any{"city": "Nowhere", "lat": 91, "lon": 0}}},
+			"locations[0].lon":     {"locations": []any{map[string]any{"city": "Nowhere", "lat": 10}}},
+			"locations[0].country": {"locations": []any{map[string]any{"city": "Berlin", "country": "Germany"}}},
+			"remote_policy":        {"remote_policy": "sometimes"},
+			"remote_countries":     {"remote_countries": []string{"DE"}},
+			"remote_countries[0]":  {"remote_policy": "remote", "remote_countries": []string{"XX"}},
+		}
+		for field, fields := range invalid {
+			response := c.expect(c.do(http.MethodPost, "/api/v1/careers", admin, withLocation(fields)), http.StatusBadRequest)
+			if !strings.Contains(response.Text, `"field":"`+field+`"`) {
+				t.Errorf("%v: errors = %s, want one for %s", fields, response.Text, field)
+			}
+		}
+	})
+
+	jobIDs := func(query string) []float64 {
+		t.Helper()
+		response := c.expect(c.do(http.MethodGet, "/api/v1/careers"+query, user, nil), http.StatusOK)
+		list, _ := response.Body["data"].([]any)
+		ids := []float64{}
+		for _, item := range list {
+			ids = append(ids, item.(map[string]any)["jobid"].(float64))
+		}
+		return ids
+	}
+
+	t.Run("radius search", func(t *testing.T) {
+		tests := map[string][]float64{
+			"":                                  {1, 2, 3, 4},
+			"?near=52.52,13.405":                {1, 2},
+			"?near=52.52,13.405&radius_km=10":   {1},
+			"?near=Berlin&radius_km=1000":       {1, 2, 3},
+			"?near=London,GB":                   {3},
+			"?near=Portland,US&radius_km=20000": {1, 2, 3},
+			"?near=0,0&radius_km=1":             {},
+		}
+		for query, want := range tests {
+			if got := jobIDs(query); fmt.Sprint(got) != fmt.Sprint(want) {
+				t.Errorf("careers%s = %v, want %v", query, got, want)
+			}
+		}
+		for _, query := range []string{"?near=Atlantis", "?near=100,0", "?radius_km=10", "?near=Berlin&radius_km=0"} {
+			c.expect(c.do(http.MethodGet, "/api/v1/careers"+query, user, nil), http.StatusBadRequest)
+		}
+	})
+
+	t.Run("patching locations", func(t *testing.T) {
+		patched := data(c.expect(c.doWith(http.MethodPatch, "/api/v1/careers/4", admin, patchHeader("application/merge-patch+json"),
+			`{"remote_policy": "hybrid", "locations": [{"city": "Munich"}]}`), http.StatusOK))
+		locations, _ := patched["locations"].([]any)
+		if len(locations) != 1 || locations[0].(map[string]any)["lat"] != 48.1351 || patched["remote_policy"] != "hybrid" {
+			t.Errorf("patched career = %v", patched)
+		}
+		c.expect(c.doWith(http.MethodPatch, "/api/v1/careers/4", admin, patchHeader("application/merge-patch+json"),
+			`{"remote_policy": "onsite"}`), http.StatusBadRequest)
+		if got := jobIDs("?near=Munich"); fmt.Sprint(got) != "[4]" {
+			t.Errorf("careers near Munich = %v, want [4]", got)
+		}
+	})
+}
+
+func TestSkillRoutes(t *testing.T) {
+	c := newAPIClient(t)
+	admin := c.signUpAndLogin("admin", "admin@example.com", "+14155550100", "admin")
+	user := c.signUpAndLogin("jane", "jane@example.com", "+14155550101", "user")
+	outsider := c.signUpAndLogin("otto", "otto@example.com", "+14155550102", "recruiter")
+
+	names := func(response apiResponse) []string {
+		list, _ := response.Body["data"].([]any)
+		names := []string{}
+		for _, item := range list {
+			names = append(names, item.(map[string]any)["name"].(string))
+		}
+		return names
+	}
+
+	t.Run("autocomplete matches names and aliases", func(t *testing.T) {
+		tests := map[string][]string{
+			"?prefix=go":        {"Go"},
+			"?prefix=GOL":       {"Go"},
+			"?prefix=post":      {"PostgreSQL"},
+			"?prefix=k8":        {"Kubernetes"},
+			"?prefix=t":         {"Terraform", "TypeScript"},
+			"?prefix=j&limit=1": {"Java"},
+			"?prefix=cobol":     {},
+		}
+		for query, want := range tests {
+			got := names(c.expect(c.do(http.MethodGet, "/api/v1/skills"+query, user, nil), http.StatusOK))
+			if fmt.Sprint(got) != fmt.Sprint(want) {
+				t.Errorf("skills%s = %v, want %v", query, got, want)
+			}
+		}
+		c.expect(c.do(http.MethodGet, "/api/v1/skills?limit=0", user, nil), http.StatusOK)
+		c.expect(c.do(http.MethodGet, "/api/v1/skills?limit=101", user, nil), http.StatusBadRequest)
+	})
+
+	t.Run("admins curate the taxonomy", func(t *testing.T) {
+		created := data(c.expect(c.do(http.MethodPost, "/api/v1/skills", admin, map[string]any{"name": "Elixir", "aliases": []string{"EX", "elixir-lang"}}), http.StatusOK))
+		if created["name"] != "Elixir" || fmt.Sprint(created["aliases"]) != "[elixir-lang ex]" {
+			t.Errorf("created skill = %v", created)
+		}
+		id := strconv.Itoa(int(created["id"].(float64)))
+		updated := data(c.expect(c.do(http.MethodPost, "/api/v1/skills/"+id+"/aliases", admin, map[string]any{"alias": "Phoenix"}), http.StatusOK))
+		if fmt.Sprint(updated["aliases"]) != "[elixir-lang ex phoenix]" {
+			t.Errorf("aliases = %v", updated["aliases"])
+		}
+
+		c.expect(c.do(http.MethodPost, "/api/v1/skills", admin, map[string]any{"name": "elixir"}), http.StatusConflict)
+		c.expect(c.do(http.MethodPost, "/api/v1/skills", admin, map[string]any{"name": "Golang"}), http.StatusConflict)
+		c.expect(c.do(http.MethodPost, "/api/v1/skills/"+id+"/aliases", admin, map[string]any{"alias": "go"}), http.StatusConflict)
+		c.expect(c.do(http.MethodPost, "/api/v1/skills/999/aliases", admin, map[string]any{"alias": "nothing"}), http.StatusNotFound)
+		c.expect(c.do(http.MethodPost, "/api/v1/skills", user, map[string]any{"name": "Haskell"}), http.StatusForbidden)
+	})
+
+	for i := 0; i < 3; i++ {
+		c.expect(c.do(http.MethodPost, "/api/v1/careers", admin, careerBody), http.StatusOK)
+	}
+	setSkills := func(jobID, tag string, skills ...map[string]any) apiResponse {
+		return c.doWith(http.MethodPut, "/api/v1/careers/"+jobID+"/skills", admin, ifMatch(tag), map[string]any{"skills": skills})
+	}
+
+	t.Run("careers are tagged by name or alias", func(t *testing.T) {
+		response := c.expect(setSkills("1", `"1"`,
+			map[string]any{"skill": "golang", "min_years": 3},
+			map[string]any{"skill": "Postgres", "required": false},
+		), http.StatusOK)
+		if tagVersion(response.Header.Get("ETag")) != "2" {
+			t.Errorf("ETag = %q, want \"2\"", response.Header.Get("ETag"))
+		}
+		tagged := data(c.expect(c.do(http.MethodGet, "/api/v1/careers/1/skills", user, nil), http.StatusOK))
+		skills, _ := tagged["skills"].([]any)
+		if len(skills) != 2 {
+			t.Fatalf("career skills = %v", tagged)
+		}
+		first, second := skills[0].(map[string]any), skills[1].(map[string]any)
+		if first["name"] != "Go" || first["required"] != true || first["min_years"] != float64(3) ||
+			second["name"] != "PostgreSQL" || second["required"] != false || second["min_years"] != nil {
+			t.Errorf("career skills = %v", skills)
+		}
+		if career := data(c.expect(c.do(http.MethodGet, "/api/v1/careers/1", user, nil), http.StatusOK)); career["version"] != float64(2) {
+			t.Errorf("career version = %v, want 2", career["version"])
+		}
+
+		c.expect(setSkills("2", "*", map[string]any{"skill": "Go"}), http.StatusOK)
+		c.expect(setSkills("3", "*", map[string]any{"skill": "PostgreSQL"}, map[string]any{"skill": "Docker"}), http.StatusOK)
+	})
+
+	t.Run("career skill validation", func(t *testing.T) {
+		invalid := map[string][]map[string]any{
+			"skills[1].skill":     {{"skill": "Go"}, {"skill": "COBOL"}},
+			"skills[0].min_years": {{"skill": "Go", "min_years": 51}},
+		}
+		for field, skills := range invalid {
+			response := c.expect(setSkills("1", "*", skills...), http.StatusBadRequest)
+			if !strings.Contains(response.Text, `"field":"`+field+`"`) {
+				t.Errorf("%v: errors = %s, want one for %s", skills, response.Text, field)
+			}
+		}
+		response := c.expect(setSkills("1", "*", map[string]any{"skill": "Go"}, map[string]any{"skill": "golang"}), http.StatusBadRequest)
+		if !strings.Contains(response.Text, "listed twice") {
+			t.Errorf("duplicate skills = %s", response.Text)
+		}
+		c.expect(c.doWith(http.MethodPut, "/api/v1/careers/1/skills", admin, ifMatch("*"), map[string]any{}), http.StatusBadRequest)
+		c.expect(setSkills("1", `"1"`, map[string]any{"skill": "Go"}), http.StatusPreconditionFailed)
+		c.expect(c.doWith(http.MethodPut, "/api/v1/careers/1/skills", outsider, ifMatch("*"), map[string]any{"skills": []any{}}), http.StatusForbidden)
+		c.expect(setSkills("99", "*", map[string]any{"skill": "Go"}), http.StatusNotFound)
+	})
+
+	t.Run("careers filter by skills", func(t *testing.T) {
+		jobIDs := func(query string) []float64 {
+			t.Helper()
+			response := c.expect(c.do(http.MethodGet, "/api/v1/careers"+query, user, nil), http.StatusOK)
+			list, _ := response.Body["data"].([]any)
+			ids := []float64{}
+			for _, item := range list {
+				ids = append(ids, item.(map[string]any)["jobid"].(float64))
+			}
+			return ids
+		}
+		tests := map[string][]float64{
+			"?skills=go":              {1, 2},
+			"?skills=golang,postgres": {1},
+			"?skills=Go,%20PostgreSQL&skill_match=any": {1, 2, 3},
+			"?skills=go,golang":                        {1, 2},
+			"?skills=docker&skill_match=all":           {3},
+		}
+		for query, want := range tests {
+			if got := jobIDs(query); fmt.Sprint(got) != fmt.Sprint(want) {
+				t.Errorf("careers%s = %v, want %v", query, got, want)
+			}
+		}
+		c.expect(c.do(http.MethodGet, "/api/v1/careers?skills=cobol", user, nil), http.StatusBadRequest)
+		c.expect(c.do(http.MethodGet, "/api/v1/careers?skills=go&skill_match=most", user, nil), http.StatusBadRequest)
+	})
+
+	t.Run("profiles list skills with a proficiency", func(t *testing.T) {
+		created := c.expect(c.do(http.MethodPost, "/api/v1/profiles", user, profileBody), http.StatusOK)
+		userID := strconv.Itoa(int(data(created)["userid"].(float64)))
+		path := "/api/v1/profiles/" + userID + "/skills"
+
+		c.expect(c.doWith(http.MethodPut, path, user, ifMatch("*"), map[string]any{"skills": []any{
+			map[string]any{"skill": "Go", "proficiency": "guru"},
+		}}), http.StatusBadRequest)
+		response := c.expect(c.doWith(http.MethodPut, path, user, ifMatch(`"1"`), map[string]any{"skills": []any{
+			map[string]any{"skill": "py", "proficiency": "beginner"},
+			map[string]any{"skill": "golang", "proficiency": "expert"},
+		}}), http.StatusOK)
+		tagged := data(response)
+		if fmt.Sprint(tagged["skills"]) != "[map[name:Go proficiency:expert skill_id:1] map[name:Python proficiency:beginner skill_id:6]]" || tagged["version"] != float64(2) {
+			t.Errorf("profile skills = %v", tagged)
+		}
+		c.expect(c.doWith(http.MethodGet, path, user, http.Header{"If-None-Match": {response.Header.Get("ETag")}}, nil), http.StatusNotModified)
+	})
+}
+
+// TestEveryRouteIsExercised walks each registered route once, including the
+// legacy aliases, and fails if a route is added without being covered here
+func TestRecommendations(t *testing.T) {
+	c := newAPIClient(t)
+	admin := c.signUpAndLogin("admin", "admin@example.com", "+14155550100", "admin")
+	user := c.signUpAndLogin("jane", "jane@example.com", "+14155550101", "user")
+	other := c.signUpAndLogin("joe", "joe@example.com", "+14155550102", "user")
+
+	career := func(fields map[string]any) map[string]any {
+		body := map[string]any{"enddate": "2099-12-31T00:00:00Z"}
+		for key, value := range careerBody {
+			if key != "enddate" {
+				body[key] = value
+			}
+		}
+		for key, value := range fields {
+			body[key] = value
+		}
+		return body
+	}
+	for _, fields := range []map[string]any{
+		{"locations": []any{map[string]any{"city": "Berlin"}}, "remote_policy": "hybrid", "seniority": "senior",
+			"salary_min": 70000, "salary_max": 90000, "salary_currency": "EUR", "pay_period": "yearly"},
+		{"locations": []any{map[string]any{"city": "Paris"}}, "seniority": "junior",
+			"salary_min": 2500, "salary_max": 3000, "salary_currency": "EUR", "pay_period": "monthly", "salary_visible": false},
+		{"remote_policy": "remote", "remote_countries": []string{"US"}},
+		{"startdate": "2020-01-01T00:00:00Z", "enddate": "2021-01-01T00:00:00Z"},
+	} {
+		c.expect(c.do(http.MethodPost, "/api/v1/careers", admin, career(fields)), http.StatusOK)
+	}
+	c.expect(c.doWith(http.MethodPut, "/api/v1/careers/1/skills", admin, ifMatch("*"), map[string]any{"skills": []any{
+		map[string]any{"skill": "Go", "min_years": 5},
+		map[string]any{"skill": "PostgreSQL"},
+		map[string]any{"skill": "Docker", "required": false},
+	}}), http.StatusOK)
+	c.expect(c.doWith(http.MethodPut, "/api/v1/careers/2/skills", admin, ifMatch("*"), map[string]any{"skills": []any{
+		map[string]any{"skill": "Java"},
+	}}), http.StatusOK)
+
+	c.expect(c.do(http.MethodGet, "/api/v1/me/recommended-careers", user, nil), http.StatusNotFound)
+
+	profile := map[string]any{
+		"locations": []any{map[string]any{"city": "Potsdam", "country": "DE", "lat": 52.3906, "lon": 13.0645}}, "remote_preference": "hybrid",
+		"expected_salary": 85000, "salary_currency": "EUR", "years_experience": 6,
+	}
+	for key, value := range profileBody {
+		profile[key] = value
+	}
+	created := data(c.expect(c.do(http.MethodPost, "/api/v1/profiles", user, profile), http.StatusOK))
+	userID := strconv.Itoa(int(created["userid"].(float64)))
+	c.expect(c.doWith(http.MethodPut, "/api/v1/profiles/"+userID+"/skills", user, ifMatch("*"), map[string]any{"skills": []any{
+		map[string]any{"skill": "golang", "proficiency": "expert"},
+		map[string]any{"skill": "postgres", "proficiency": "advanced"},
+	}}), http.StatusOK)
+	c.expect(c.do(http.MethodPost, "/api/v1/profiles", other, profileBody), http.StatusOK)
+
+	recommendations := func(path, token string) []map[string]any {
+		t.Helper()
+		list, _ := c.expect(c.do(http.MethodGet, path, token, nil), http.StatusOK).Body["data"].([]any)
+		items := make([]map[string]any, len(list))
+		for i, item := range list {
+			items[i] = item.(map[string]any)
+		}
+		return items
+	}
+	factors := func(recommendation map[string]any) map[string]map[string]any {
+		byName := map[string]map[string]any{}
+		for _, factor := range recommendation["factors"].([]any) {
+			factor := factor.(map[string]any)
+			byName[factor["name"].(string)] = factor
+		}
+		return byName
+	}
+
+	t.Run("careers are ranked against the caller's profile", func(t *testing.T) {
+		ranked := recommendations("/api/v1/me/recommended-careers", user)
+		if len(ranked) != 3 {
+			t.Fatalf("recommended careers = %v, want the 3 open ones", ranked)
+		}
+		best := ranked[0]
+		if best["career"].(map[string]any)["jobid"] != float64(1) || best["score"] != 0.92 {
+			t.Errorf("best recommendation = %v, want career 1 scoring 0.92", best)
+		}
+		byName := factors(best)
+		if len(byName) != 4 || byName["skills"]["score"] != 0.8 || byName["skills"]["reason"] != "has Go, PostgreSQL; lacks Docker" ||
+			byName["location"]["score"] != 1.0 || byName["salary"]["score"] != 1.0 || byName["seniority"]["score"] != 1.0 {
+			t.Errorf("factors = %v", byName)
+		}
+		for _, recommendation := range ranked[1:] {
+			if recommendation["career"].(map[string]any)["jobid"] == float64(2) {
+				if salary := factors(recommendation)["salary"]; salary["reason"] != "the career's salary is not known" {
+					t.Errorf("a hidden salary is scored: %v", salary)
+				}
+			}
+		}
+
+		if got := recommendations("/api/v1/me/recommended-careers?limit=1", user); len(got) != 1 {
+			t.Errorf("limit=1 returned %d", len(got))
+		}
+		if got := recommendations("/api/v1/me/recommended-careers?min_score=0.85", user); len(got) != 1 {
+			t.Errorf("min_score=0.85 returned %d", len(got))
+		}
+		c.expect(c.do(http.MethodGet, "/api/v1/me/recommended-careers?limit=101", user, nil), http.StatusBadRequest)
+		c.expect(c.do(http.MethodGet, "/api/v1/me/recommended-careers?min_score=2", user, nil), http.StatusBadRequest)
+		c.expect(c.do(http.MethodGet, "/api/v1/me/recommended-careers", admin, nil), http.StatusForbidden)
+	})
+
+	t.Run("updates invalidate cached rankings", func(t *testing.T) {
+		c.expect(c.doWith(http.MethodPatch, "/api/v1/profiles/"+userID, user, patchHeader("application/merge-patch+json"), `{"years_experience": 1}`), http.StatusOK)
+		best := recommendations("/api/v1/me/recommended-careers", user)[0]
+		if seniority := factors(best)["seniority"]; seniority["score"] != 0.0 {
+			t.Errorf("seniority after the patch = %v, want 0", seniority)
+		}
+
+		c.expect(c.doWith(http.MethodPut, "/api/v1/careers/1/skills", admin, ifMatch("*"), map[string]any{"skills": []any{
+			map[string]any{"skill": "Go"},
+		}}), http.StatusOK)
+		best = recommendations("/api/v1/me/recommended-careers", user)[0]
+		if skills := factors(best)["skills"]; skills["score"] != 1.0 {
+			t.Errorf("skills after retagging = %v, want 1", skills)
+		}
+	})
+
+	t.Run("candidates are ranked against a career", func(t *testing.T) {
+		ranked := recommendations("/api/v1/careers/1/recommended-candidates", admin)
+		if len(ranked) != 2 || ranked[0]["profile"].(map[string]any)["userid"] != created["userid"] {
+			t.Fatalf("recommended candidates = %v, want jane first of 2", ranked)
+		}
+		if ranked[0]["score"].(float64) <= ranked[1]["score"].(float64) {
+			t.Errorf("scores are not descending: %v, %v", ranked[0]["score"], ranked[1]["score"])
+		}
+		if salary := factors(ranked[1])["salary"]; salary["score"] != 0.5 {
+			t.Errorf("salary factor of a profile without an expectation = %v, want neutral", salary)
+		}
+		c.expect(c.do(http.MethodGet, "/api/v1/careers/1/recommended-candidates", user, nil), http.StatusForbidden)
+		c.expect(c.do(http.MethodGet, "/api/v1/careers/99/recommended-candidates", admin, nil), http.StatusNotFound)
+	})
+
+	t.Run("profile preferences are validated", func(t *testing.T) {
+		patch := func(body string) apiResponse {
+			return c.doWith(http.MethodPatch, "/api/v1/profiles/"+userID, user, patchHeader("application/merge-patch+json"), body)
+		}
+		c.expect(patch(`{"salary_currency": "ZAR"}`), http.StatusBadRequest)
+		c.expect(patch(`{"expected_salary": null}`), http.StatusBadRequest)
+		c.expect(patch(`{"remote_preference": "never"}`), http.StatusBadRequest)
+		c.expect(patch(`{"years_experience": 61}`), http.StatusBadRequest)
+		c.expect(patch(`{"locations": [{"city": "Atlantis"}]}`), http.StatusBadRequest)
+		c.expect(patch(`{"expected_salary": null, "salary_currency": null}`), http.StatusOK)
+		c.expect(c.do(http.MethodPost, "/api/v1/careers", admin, career(map[string]any{"seniority": "guru"})), http.StatusBadRequest)
+	})
+}
+
+func TestSavedCareers(t *testing.T) {
+	c := newAPIClient(t)
+	admin := c.signUpAndLogin("admin", "admin@example.com", "+14155550100", "admin")
+	user := c.signUpAndLogin("jane", "jane@example.com", "+14155550101", "user")
+	other := c.signUpAndLogin("joe", "joe@example.com", "+14155550102", "user")
+
+	for _, enddate := range []string{"2099-12-31T00:00:00Z", "2021-01-01T00:00:00Z", "2099-12-31T00:00:00Z"} {
+		body := map[string]any{}
+		for key, value := range careerBody {
+			body[key] = value
+		}
+		body["startdate"], body["enddate"] = "2020-01-01T00:00:00Z", enddate
+		c.expect(c.do(http.MethodPost, "/api/v1/careers", admin, body), http.StatusOK)
+	}
+
+	saved := func(path string) []map[string]any {
+		t.Helper()
+		list, _ := c.expect(c.do(http.MethodGet, path, user, nil), http.StatusOK).Body["data"].([]any)
+		items := make([]map[string]any, len(list))
+		for i, item := range list {
+			items[i] = item.(map[string]any)
+		}
+		return items
+	}
+	jobids := func(items []map[string]any) []float64 {
+		ids := make([]float64, len(items))
+		for i, item := range items {
+			ids[i] = item["career"].(map[string]any)["jobid"].(float64)
+		}
+		return ids
+	}
+
+	t.Run("careers are saved to lists", func(t *testing.T) {
+		first := data(c.expect(c.do(http.MethodPost, "/api/v1/me/saved-careers/1", user, map[string]any{"note": " ask about visas ", "list": "To apply"}), http.StatusOK))
+		if first["note"] != "ask about visas" || first["list"] != "To apply" || first["status"] != "open" {
+			t.Errorf("saved career = %v", first)
+		}
+		second := data(c.expect(c.do(http.MethodPost, "/api/v1/me/saved-careers/2", user, map[string]any{"list": "to APPLY"}), http.StatusOK))
+		if second["list"] != "To apply" {
+			t.Errorf("list = %v, want the existing spelling To apply", second["list"])
+		}
+		c.expect(c.do(http.MethodPost, "/api/v1/me/saved-careers/3", user, map[string]any{}), http.StatusOK)
+
+		resaved := data(c.expect(c.do(http.MethodPost, "/api/v1/me/saved-careers/1", user, map[string]any{"note": "call on Monday"}), http.StatusOK))
+		if resaved["note"] != "call on Monday" || resaved["list"] != "To apply" {
+			t.Errorf("saving again = %v, want the new note in the same list", resaved)
+		}
+
+		if got := jobids(saved("/api/v1/me/saved-careers")); !slices.Equal(got, []float64{3, 2, 1}) {
+			t.Errorf("saved careers = %v, want newest first", got)
+		}
+		if got := jobids(saved("/api/v1/me/saved-careers?list=to%20apply")); !slices.Equal(got, []float64{2, 1}) {
+			t.Errorf("saved careers in to apply = %v", got)
+		}
+		lists := c.expect(c.do(http.MethodGet, "/api/v1/me/saved-lists", user, nil), http.StatusOK).Body["data"]
+		if want := []any{map[string]any{"name": "To apply", "careers": float64(2)}}; fmt.Sprint(lists) != fmt.Sprint(want) {
+			t.Errorf("lists = %v, want %v", lists, want)
+		}
+	})
+
+	t.Run("closed and deleted careers are marked", func(t *testing.T) {
+		c.expect(c.doWith(http.MethodDelete, "/api/v1/careers/3", admin, ifMatch("*"), nil), http.StatusOK)
+		status := map[float64]any{}
+		for _, item := range saved("/api/v1/me/saved-careers") {
+			status[item["career"].(map[string]any)["jobid"].(float64)] = item["status"]
+		}
+		if want := map[float64]any{1: "open", 2: "closed", 3: "deleted"}; !maps.Equal(status, want) {
+			t.Errorf("statuses = %v, want %v", status, want)
+		}
+		c.expect(c.do(http.MethodPost, "/api/v1/me/saved-careers/3", other, map[string]any{}), http.StatusNotFound)
+		c.expect(c.do(http.MethodPost, "/api/v1/me/saved-careers/99", user, map[string]any{}), http.StatusNotFound)
+	})
+
+	t.Run("admins see how often a career is saved", func(t *testing.T) {
+		c.expect(c.do(http.MethodPost, "/api/v1/me/saved-careers/1", other, map[string]any{"list": "dream jobs"}), http.StatusOK)
+		saves := data(c.expect(c.do(http.MethodGet, "/api/v1/careers/1/saves", admin, nil), http.StatusOK))
+		if saves["saves"] != float64(2) {
+			t.Errorf("saves = %v, want 2", saves)
+		}
+		c.expect(c.do(http.MethodGet, "/api/v1/careers/1/saves", user, nil), http.StatusForbidden)
+		c.expect(c.do(http.MethodGet, "/api/v1/careers/99/saves", admin, nil), http.StatusNotFound)
+	})
+
+	t.Run("careers are unsaved", func(t *testing.T) {
+		c.expect(c.do(http.MethodPost, "/api/v1/me/saved-careers/2", user, map[string]any{"list": ""}), http.StatusOK)
+		c.expect(c.do(http.MethodDelete, "/api/v1/me/saved-careers/1", user, nil), http.StatusOK)
+		c.expect(c.do(http.MethodDelete, "/api/v1/me/saved-careers/1", user, nil), http.StatusNotFound)
+		if got := jobids(saved("/api/v1/me/saved-careers")); !slices.Equal(got, []float64{3, 2}) {
+			t.Errorf("saved careers = %v", got)
+		}
+		if got := saved("/api/v1/me/saved-careers?list=to%20apply"); len(got) != 0 {
+			t.Errorf("to apply still holds %v", jobids(got))
+		}
+	})
+
+	t.Run("saves are validated", func(t *testing.T) {
+		c.expect(c.do(http.MethodPost, "/api/v1/me/saved-careers/1", user, map[string]any{"note": strings.Repeat("x", 1001)}), http.StatusBadRequest)
+		c.expect(c.do(http.MethodPost, "/api/v1/me/saved-careers/1", user, map[string]any{"list": strings.Repeat("x", 101)}), http.StatusBadRequest)
+		c.expect(c.do(http.MethodPost, "/api/v1/me/saved-careers/1", admin, map[string]any{}), http.StatusForbidden)
+		c.expect(c.do(http.MethodGet, "/api/v1/me/saved-careers", admin, nil), http.StatusForbidden)
+	})
+}
+
+// recordingNotifier keeps the messages it is given, failing with err when set
+type recordingNotifier struct {
+	messages []notify.Message
+	err      error
+	// failTo is an address every message to fails
+	failTo string
+}
+
+func (n *recordingNotifier) Notify(_ context.Context, message notify.Message) error {
+	if n.err != nil {
+		return n.err
+	}
+	if n.failTo != "" && message.To == n.failTo {
+		return errors.New("mailbox unavailable")
+	}
+	n.messages = append(n.messages, message)
+	return nil
+}
+
+func TestJobAlerts(t *testing.T) {
+	c := newAPIClient(t)
+	notifier := &recordingNotifier{}
+	c.service.Notifier = notifier
+	c.service.PublicURL = "https://jobs.example.com"
+	admin := c.signUpAndLogin("admin", "admin@example.com", "+14155550100", "admin")
+	user := c.signUpAndLogin("jane", "jane@example.com", "+14155550101", "user")
+	other := c.signUpAndLogin("joe", "joe@example.com", "+14155550102", "user")
+
+	post := func(fields map[string]any) {
+		t.Helper()
+		body := map[string]any{"startdate": "2020-01-01T00:00:00Z", "enddate": "2099-12-31T00:00:00Z"}
+		for key, value := range careerBody {
+			if body[key] == nil {
+				body[key] = value
+			}
+		}
+		for key, value := range fields {
+			body[key] = value
+		}
+		c.expect(c.do(http.MethodPost, "/api/v1/careers", admin, body), http.StatusOK)
+	}
+	berlin := []any{map[string]any{"city": "Berlin"}}
+	send := func() []notify.Message {
+		t.Helper()
+		notifier.messages = nil
+		if _, err := c.service.SendJobAlerts(context.Background()); err != nil {
+			t.Fatalf("SendJobAlerts: %v", err)
+		}
+		return notifier.messages
+	}
+
+	// careers posted before a search is saved are not alerted about
+	post(map[string]any{"locations": berlin})
+
+	backend := data(c.expect(c.do(http.MethodPost, "/api/v1/me/saved-searches", user, map[string]any{
+		"name": "Backend in Berlin", "keywords": "backend", "jobtype": "full-time", "near": "Berlin",
+	}), http.StatusOK))
+	c.expect(c.do(http.MethodPost, "/api/v1/me/saved-searches", user, map[string]any{
+		"name": "Well paid", "salary_min": 80000, "currency": "EUR",
+	}), http.StatusOK)
+	c.expect(c.do(http.MethodPost, "/api/v1/me/saved-searches", other, map[string]any{"name": "Anything", "alerts": false}), http.StatusOK)
+	if _, ok := backend["unsubscribe_token"]; ok || backend["alerts"] != true {
+		t.Errorf("saved search = %v, want alerts on and no token", backend)
+	}
+	backendID := strconv.Itoa(int(backend["id"].(float64)))
+
+	t.Run("searches are validated", func(t *testing.T) {
+		c.expect(c.do(http.MethodPost, "/api/v1/me/saved-searches", user, map[string]any{"name": "WELL PAID"}), http.StatusConflict)
+		c.expect(c.do(http.MethodPost, "/api/v1/me/saved-searches", user, map[string]any{"name": "Atlantis", "near": "Atlantis"}), http.StatusBadRequest)
+		c.expect(c.do(http.MethodPost, "/api/v1/me/saved-searches", user, map[string]any{"name": "Radius", "radius_km": 10}), http.StatusBadRequest)
+		c.expect(c.do(http.MethodPost, "/api/v1/me/saved-searches", user, map[string]any{"name": "Rand", "salary_min": 1, "currency": "ZAR"}), http.StatusBadRequest)
+		c.expect(c.do(http.MethodPost, "/api/v1/me/saved-searches", user, map[string]any{"name": " "}), http.StatusBadRequest)
+		c.expect(c.do(http.MethodPost, "/api/v1/me/saved-searches", admin, map[string]any{"name": "Admin"}), http.StatusForbidden)
+		c.expect(c.doWith(http.MethodPut, "/api/v1/me/saved-searches/"+backendID, other, nil, map[string]any{"name": "Mine"}), http.StatusNotFound)
+	})
+
+	t.Run("the search runs on GET /careers", func(t *testing.T) {
+		careers, _ := c.expect(c.do(http.MethodGet, "/api/v1/careers?q=BACKEND%20apis&jobtype=full-time&near=Berlin", user, nil), http.StatusOK).Body["data"].([]any)
+		if len(careers) != 1 {
+			t.Errorf("careers = %v, want career 1", careers)
+		}
+		careers, _ = c.expect(c.do(http.MethodGet, "/api/v1/careers?q=frontend", user, nil), http.StatusOK).Body["data"].([]any)
+		if len(careers) != 0 {
+			t.Errorf("careers matching frontend = %v, want none", careers)
+		}
+	})
+
+	t.Run("new matching careers are sent in one digest", func(t *testing.T) {
+		post(map[string]any{"locations": berlin, "salary_min": 90000, "salary_max": 100000, "salary_currency": "EUR", "pay_period": "yearly"})
+		post(map[string]any{"position": "Designer", "locations": []any{map[string]any{"city": "Paris"}}})
+		post(map[string]any{"locations": berlin, "enddate": "2021-01-01T00:00:00Z"})
+
+		messages := send()
+		if len(messages) != 1 {
+			t.Fatalf("sent %d digests, want 1: %+v", len(messages), messages)
+		}
+		message := messages[0]
+		if message.To != "jane@example.com" || message.Subject != "1 new career matches your saved searches" {
+			t.Errorf("digest to %q about %q", message.To, message.Subject)
+		}
+		for _, want := range []string{"Hello jane", "Backend in Berlin", "Well paid", "Backend Engineer at Acme", "https://jobs.example.com/api/v1/careers/2\n"} {
+			if !strings.Contains(message.Text, want) {
+				t.Errorf("digest text %q lacks %q", message.Text, want)
+			}
+		}
+		digest := message.Data.(service.JobAlertDigest)
+		if len(digest.Alerts) != 2 || len(digest.Alerts[0].Careers) != 1 || digest.Alerts[0].Careers[0].Jobid != 2 {
+			t.Errorf("digest = %+v", digest)
+		}
+
+		if messages := send(); len(messages) != 0 {
+			t.Errorf("a second run sent %+v, want nothing new", messages)
+		}
+	})
+
+	t.Run("digests that fail are sent at the next run", func(t *testing.T) {
+		post(map[string]any{"locations": berlin, "description": "Build backend APIs"})
+		notifier.err = errors.New("smtp down")
+		if _, err := c.service.SendJobAlerts(context.Background()); err == nil || !strings.Contains(err.Error(), "smtp down") {
+			t.Errorf("SendJobAlerts error = %v, want the notifier's", err)
+		}
+		notifier.err = nil
+		messages := send()
+		if len(messages) != 1 || !strings.Contains(messages[0].Text, "/api/v1/careers/5\n") {
+			t.Errorf("retried digests = %+v, want career 5", messages)
+		}
+	})
+
+	t.Run("the link in a digest unsubscribes", func(t *testing.T) {
+		post(map[string]any{"locations": berlin})
+		messages := send()
+		if len(messages) != 1 {
+			t.Fatalf("sent %d digests, want 1", len(messages))
+		}
+		link := messages[0].Data.(service.JobAlertDigest).Alerts[0].UnsubscribeURL
+		path := strings.TrimPrefix(link, "https://jobs.example.com")
+		// mail scanners follow the link, so opening it changes nothing
+		shown := data(c.expect(c.do(http.MethodGet, path, "", nil), http.StatusOK))
+		if shown["name"] != "Backend in Berlin" || shown["alerts"] != true {
+			t.Errorf("unsubscribe page = %v", shown)
+		}
+		for i := 0; i < 2; i++ {
+			unsubscribed := data(c.expect(c.doWith(http.MethodPost, path, "", http.Header{"Content-Type": {"application/x-www-form-urlencoded"}}, "List-Unsubscribe=One-Click"), http.StatusOK))
+			if unsubscribed["name"] != "Backend in Berlin" || unsubscribed["alerts"] != false {
+				t.Errorf("unsubscribed = %v", unsubscribed)
+			}
+		}
+		c.expect(c.do(http.MethodGet, "/api/v1/alerts/unsubscribe?token=nope", "", nil), http.StatusNotFound)
+		c.expect(c.do(http.MethodPost, "/api/v1/alerts/unsubscribe?token=nope", "", nil), http.StatusNotFound)
+		c.expect(c.do(http.MethodGet, "/api/v1/alerts/unsubscribe", "", nil), http.StatusBadRequest)
+
+		post(map[string]any{"locations": berlin})
+		if messages := send(); len(messages) != 0 {
+			t.Errorf("sent %+v after unsubscribing", messages)
+		}
+
+		// turning alerts back on skips what was posted meanwhile
+		c.expect(c.doWith(http.MethodPut, "/api/v1/me/saved-searches/"+backendID, user, nil, map[string]any{
+			"name": "Backend in Berlin", "keywords": "backend", "near": "Berlin", "alerts": true,
+		}), http.StatusOK)
+		if messages := send(); len(messages) != 0 {
+			t.Errorf("sent %+v after resubscribing", messages)
+		}
+	})
+
+	t.Run("searches are listed and deleted", func(t *testing.T) {
+		searches, _ := c.expect(c.do(http.MethodGet, "/api/v1/me/saved-searches", user, nil), http.StatusOK).Body["data"].([]any)
+		if len(searches) != 2 || searches[0].(map[string]any)["jobtype"] != "" {
+			t.Errorf("saved searches = %v", searches)
+		}
+		c.expect(c.do(http.MethodDelete, "/api/v1/me/saved-searches/"+backendID, other, nil), http.StatusNotFound)
+		c.expect(c.do(http.MethodDelete, "/api/v1/me/saved-searches/"+backendID, user, nil), http.StatusOK)
+		c.expect(c.do(http.MethodDelete, "/api/v1/me/saved-searches/"+backendID, user, nil), http.StatusNotFound)
+	})
+}
+
+func TestCareerExpiry(t *testing.T) {
+	c := newAPIClient(t)
+	notifier := &recordingNotifier{}
+	c.service.Notifier = notifier
+	c.service.PublicURL = "https://jobs.example.com"
+	admin := c.signUpAndLogin("admin", "admin@example.com", "+14155550100", "admin")
+	user := c.signUpAndLogin("jane", "jane@example.com", "+14155550101", "user")
+	recruiter := c.signUpAndLogin("rita", "rita@example.com", "+14155550102", "recruiter")
+
+	inDays := func(days int) string {
+		return time.Now().UTC().AddDate(0, 0, days).Format("2006-01-02") + "T00:00:00Z"
+	}
+	for _, enddate := range []string{"2099-12-31T00:00:00Z", "2021-01-01T00:00:00Z", inDays(3), "2021-06-30T00:00:00Z"} {
+		body := map[string]any{}
+		for key, value := range careerBody {
+			body[key] = value
+		}
+		body["startdate"], body["enddate"] = "2020-01-01T00:00:00Z", enddate
+		c.expect(c.do(http.MethodPost, "/api/v1/careers", admin, body), http.StatusOK)
+	}
+	listed := func(path string) []float64 {
+		t.Helper()
+		careers, _ := c.expect(c.do(http.MethodGet, path, user, nil), http.StatusOK).Body["data"].([]any)
+		jobids := make([]float64, len(careers))
+		for i, career := range careers {
+			jobids[i] = career.(map[string]any)["jobid"].(float64)
+		}
+		return jobids
+	}
+	remind := func() []notify.Message {
+		t.Helper()
+		notifier.messages = nil
+		if _, err := c.service.SendExpiryReminders(context.Background(), 7); err != nil {
+			t.Fatalf("SendExpiryReminders: %v", err)
+		}
+		return notifier.messages
+	}
+
+	t.Run("expired careers are not listed by default", func(t *testing.T) {
+		if jobids := listed("/api/v1/careers"); !slices.Equal(jobids, []float64{1, 3}) {
+			t.Errorf("careers = %v, want 1 and 3", jobids)
+		}
+		if jobids := listed("/api/v1/careers?include_expired=true"); !slices.Equal(jobids, []float64{1, 2, 3, 4}) {
+			t.Errorf("careers with include_expired = %v, want all four", jobids)
+		}
+		c.expect(c.do(http.MethodGet, "/api/v1/careers/2", user, nil), http.StatusOK)
+	})
+
+	t.Run("the expiry job closes expired careers once", func(t *testing.T) {
+		closed, err := c.service.CloseExpiredCareers(context.Background())
+		if err != nil {
+			t.Fatalf("CloseExpiredCareers: %v", err)
+		}
+		if len(closed) != 2 || closed[0].Jobid != 2 || closed[1].Jobid != 4 {
+			t.Errorf("closed = %+v, want careers 2 and 4", closed)
+		}
+		career := data(c.expect(c.do(http.MethodGet, "/api/v1/careers/2", user, nil), http.StatusOK))
+		if career["closed_at"] == nil || career["version"] != float64(2) {
+			t.Errorf("career = %v, want it closed at version 2", career)
+		}
+		if closed, _ := c.service.CloseExpiredCareers(context.Background()); len(closed) != 0 {
+			t.Errorf("a second run closed %+v", closed)
+		}
+
+		events, _ := c.expect(c.do(http.MethodGet, "/api/v1/audit?resource_type=career&resource_id=2", admin, nil), http.StatusOK).Body["data"].([]any)
+		event := events[0].(map[string]any)
+		if event["action"] != "close" || event["actor_role"] != "system" {
+			t.Errorf("latest event = %v, want a close by the system", event)
+		}
+	})
+
+	t.Run("admins are reminded of expiring careers once", func(t *testing.T) {
+		notifier.err = errors.New("smtp down")
+		if _, err := c.service.SendExpiryReminders(context.Background(), 7); err == nil {
+			t.Error("SendExpiryReminders succeeded with the notifier down")
+		}
+		notifier.err = nil
+
+		messages := remind()
+		if len(messages) != 1 {
+			t.Fatalf("sent %d reminders, want 1: %+v", len(messages), messages)
+		}
+		message := messages[0]
+		if message.To != "admin@example.com" || message.Subject != "Backend Engineer at Acme expires in 3 days" {
+			t.Errorf("reminder to %q about %q", message.To, message.Subject)
+		}
+		if !strings.Contains(message.Text, "https://jobs.example.com/api/v1/careers/3\n") {
+			t.Errorf("reminder text %q lacks the career link", message.Text)
+		}
+		if messages := remind(); len(messages) != 0 {
+			t.Errorf("a second run sent %+v", messages)
+		}
+	})
+
+	t.Run("extending a career moves its enddate", func(t *testing.T) {
+		extend := func(token string, header http.Header, enddate string) apiResponse {
+			return c.doWith(http.MethodPost, "/api/v1/careers/2/extend", token, header, map[string]any{"enddate": enddate})
+		}
+		c.expect(extend(user, nil, "2099-12-31T00:00:00Z"), http.StatusForbidden)
+		c.expect(extend(recruiter, ifMatch("*"), "2099-12-31T00:00:00Z"), http.StatusForbidden)
+		c.expect(extend(admin, ifMatch("*"), "2020-12-31T00:00:00Z"), http.StatusBadRequest)
+		c.expect(extend(admin, ifMatch("*"), "2021-03-01T00:00:00Z"), http.StatusBadRequest)
+		c.expect(extend(admin, ifMatch(`"1"`), "2099-12-31T00:00:00Z"), http.StatusPreconditionFailed)
+		c.expect(c.doWith(http.MethodPost, "/api/v1/careers/99/extend", admin, ifMatch("*"), map[string]any{"enddate": "2099-12-31T00:00:00Z"}), http.StatusNotFound)
+
+		response := c.expect(extend(admin, ifMatch(`"2"`), "2099-12-31T00:00:00Z"), http.StatusOK)
+		career := data(response)
+		if career["closed_at"] != nil || career["enddate"] != "2099-12-31T00:00:00Z" || tagVersion(response.Header.Get("ETag")) != "3" {
+			t.Errorf("extended career = %v, ETag %s", career, response.Header.Get("ETag"))
+		}
+		if jobids := listed("/api/v1/careers"); !slices.Equal(jobids, []float64{1, 2, 3}) {
+			t.Errorf("careers = %v, want 1, 2 and 3", jobids)
+		}
+
+		// a new enddate is reminded of again
+		c.expect(c.doWith(http.MethodPost, "/api/v1/careers/3/extend", admin, ifMatch("*"), map[string]any{"enddate": inDays(5)}), http.StatusOK)
+		if messages := remind(); len(messages) != 1 || messages[0].Subject != "Backend Engineer at Acme expires in 5 days" {
+			t.Errorf("reminders after extending = %+v", messages)
+		}
+	})
+
+	t.Run("patching the enddate reopens a closed career", func(t *testing.T) {
+		career := data(c.expect(c.doWith(http.MethodPatch, "/api/v1/careers/4", admin, patchHeader("application/merge-patch+json"), `{"enddate": "2099-12-31T00:00:00Z"}`), http.StatusOK))
+		if career["closed_at"] != nil {
+			t.Errorf("patched career = %v, want it open", career)
+		}
+	})
+
+	t.Run("an admin whose delivery fails does not hold back the others", func(t *testing.T) {
+		c.signUpAndLogin("adam", "adam@example.com", "+14155550103", "admin")
+		body := map[string]any{}
+		for key, value := range careerBody {
+			body[key] = value
+		}
+		body["startdate"], body["enddate"] = "2020-01-01T00:00:00Z", inDays(2)
+		c.expect(c.do(http.MethodPost, "/api/v1/careers", admin, body), http.StatusOK)
+
+		notifier.failTo = "adam@example.com"
+		for run := 0; run < 2; run++ {
+			notifier.messages = nil
+			sent, err := c.service.SendExpiryReminders(context.Background(), 7)
+			if err == nil {
+				t.Errorf("run %d succeeded with adam's mailbox unavailable", run)
+			}
+			if run == 0 && (len(notifier.messages) != 1 || notifier.messages[0].To != "admin@example.com" || sent.Admins != 1 || sent.Careers != 1) {
+				t.Errorf("first run sent %+v (%+v), want the new career to admin", notifier.messages, sent)
+			}
+			if run == 1 && len(notifier.messages) != 0 {
+				t.Errorf("second run sent %+v, want nothing", notifier.messages)
+			}
+		}
+
+		notifier.failTo = ""
+		messages := remind()
+		if len(messages) != 1 || messages[0].To != "adam@example.com" || messages[0].Subject != "2 postings expire within 7 days" {
+			t.Errorf("reminders once adam's mailbox works = %+v, want both careers to adam", messages)
+		}
+	})
+}
+
+func TestCareerDescriptions(t *testing.T) {
+	c := newAPIClient(t)
+	admin := c.signUpAndLogin("admin", "admin@example.com", "+14155550100", "admin")
+	user := c.signUpAndLogin("jane", "jane@example.com", "+14155550101", "user")
+
+	description := "## About the role\n\nYou will **build APIs** in Go. [Apply](https://example.com/apply) " +
+		"or [not](javascript:alert(1)).\n\n<script>alert(1)</script>\n\n" + strings.Repeat("We ship often. ", 40)
+	body := map[string]any{}
+	for key, value := range careerBody {
+		body[key] = value
+	}
+	body["description"] = description
+	career := data(c.expect(c.do(http.MethodPost, "/api/v1/careers", admin, body), http.StatusOK))
+
+	t.Run("descriptions are returned as Markdown, HTML and an excerpt", func(t *testing.T) {
+		if career["description"] != strings.TrimSpace(description) {
+			t.Errorf("description = %q, want the Markdown source", career["description"])
+		}
+		html, _ := career["description_html"].(string)
+		if !strings.HasPrefix(html, "<h2>About the role</h2>\n<p>You will <strong>build APIs</strong> in Go. "+
+			`<a href="https://example.com/apply" rel="nofollow">Apply</a> or not.</p>`) {
+			t.Errorf("description_html = %q", html)
+		}
+		if strings.Contains(html, "script") || strings.Contains(html, "javascript") {
+			t.Errorf("description_html = %q, want it sanitized", html)
+		}
+		excerpt, _ := career["description_excerpt"].(string)
+		if !strings.HasPrefix(excerpt, "About the role You will build APIs in Go. Apply or not. We ship often.") ||
+			!strings.HasSuffix(excerpt, "…") || len([]rune(excerpt)) > 200 {
+			t.Errorf("description_excerpt = %q", excerpt)
+		}
+
+		careers, _ := c.expect(c.do(http.MethodGet, "/api/v1/careers", user, nil), http.StatusOK).Body["data"].([]any)
+		if len(careers) != 1 || careers[0].(map[string]any)["description_excerpt"] != excerpt {
+			t.Errorf("careers = %v, want the excerpt listed", careers)
+		}
+
+		body["description"] = strings.Repeat("x", 100001)
+		c.expect(c.do(http.MethodPost, "/api/v1/careers", admin, body), http.StatusBadRequest)
+	})
+
+	t.Run("changing a description renders it again", func(t *testing.T) {
+		updated := data(c.expect(c.doWith(http.MethodPut, "/api/v1/careers/1", admin, ifMatch("*"), map[string]any{"description": "Build *APIs*"}), http.StatusOK))
+		if updated["description_html"] != "<p>Build <em>APIs</em></p>\n" || updated["description_excerpt"] != "Build APIs" {
+			t.Errorf("updated career = %v", updated)
+		}
+		patched := data(c.expect(c.doWith(http.MethodPatch, "/api/v1/careers/1", admin, patchHeader("application/merge-patch+json"), `{"description": "Run ~~APIs~~ services"}`), http.StatusOK))
+		if patched["description_html"] != "<p>Run <del>APIs</del> services</p>\n" || patched["description_excerpt"] != "Run APIs services" {
+			t.Errorf("patched career = %v", patched)
+		}
+		reverted := data(c.expect(c.doWith(http.MethodPost, "/api/v1/careers/1/revisions/1/revert", admin, ifMatch("*"), nil), http.StatusOK))
+		if reverted["description_html"] != career["description_html"] || reverted["description_excerpt"] != career["description_excerpt"] {
+			t.Errorf("reverted career = %v, want the first rendering", reverted)
+		}
+	})
+}
+
+func TestScheduledPublishing(t *testing.T) {
+	c := newAPIClient(t)
+	var (
+		webhooks []map[string]any
+		failing  bool
+		// received runs before the webhook answers
+		received func()
+	)
+	webhook := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var career map[string]any
+		if err := json.NewDecoder(r.Body).Decode(&career); err != nil {
+			t.Errorf("decoding webhook: %v", err)
+		}
+		webhooks = append(webhooks, career)
+		if received != nil {
+			received()
+		}
+		if failing {
+			w.WriteHeader(http.StatusBadGateway)
+		}
+	}))
+	defer webhook.Close()
+	c.service.CareerWebhookURL = webhook.URL
+	admin := c.signUpAndLogin("admin", "admin@example.com", "+14155550100", "admin")
+	user := c.signUpAndLogin("jane", "jane@example.com", "+14155550101", "user")
+
+	create := func(publishAt any) apiResponse {
+		body := map[string]any{}
+		for key, value := range careerBody {
+			body[key] = value
+		}
+		if publishAt != nil {
+			body["publish_at"] = publishAt
+		}
+		return c.do(http.MethodPost, "/api/v1/careers", admin, body)
+	}
+	listed := func(token string) int {
+		t.Helper()
+		careers, _ := c.expect(c.do(http.MethodGet, "/api/v1/careers", token, nil), http.StatusOK).Body["data"].([]any)
+		return len(careers)
+	}
+
+	t.Run("a career without publish_at is published at once", func(t *testing.T) {
+		career := data(c.expect(create(nil), http.StatusOK))
+		if career["published_at"] == nil || career["published_seq"] != float64(1) {
+			t.Errorf("career = %v, want it published", career)
+		}
+		if len(webhooks) != 1 || webhooks[0]["jobid"] != float64(1) {
+			t.Errorf("webhooks = %v, want career 1", webhooks)
+		}
+	})
+
+	soon := time.Now().Add(300 * time.Millisecond)
+	t.Run("scheduled careers are hidden from users", func(t *testing.T) {
+		c.expect(create("2100-01-01T00:00:00Z"), http.StatusBadRequest)
+
+		for _, publishAt := range []time.Time{time.Now().Add(time.Hour), soon} {
+			career := data(c.expect(create(publishAt.Format(time.RFC3339Nano)), http.StatusOK))
+			if career["published_at"] != nil || career["published_seq"] != nil {
+				t.Errorf("career = %v, want it scheduled", career)
+			}
+		}
+		if len(webhooks) != 1 {
+			t.Errorf("webhooks = %v, want none for the scheduled careers", webhooks)
+		}
+		if n := listed(user); n != 1 {
+			t.Errorf("user sees %d careers, want 1", n)
+		}
+		if n := listed(admin); n != 3 {
+			t.Errorf("admin sees %d careers, want 3", n)
+		}
+		c.expect(c.do(http.MethodGet, "/api/v1/careers/3", user, nil), http.StatusNotFound)
+		c.expect(c.do(http.MethodGet, "/api/v1/careers/3", admin, nil), http.StatusOK)
+	})
+
+	t.Run("the publish job publishes due careers", func(t *testing.T) {
+		time.Sleep(time.Until(soon))
+		published, err := c.service.PublishDueCareers(context.Background())
+		if err != nil || len(published) != 1 || published[0].Jobid != 3 || *published[0].PublishedSeq != 2 {
+			t.Fatalf("PublishDueCareers = %+v, %v; want career 3", published, err)
+		}
+		if len(webhooks) != 2 || webhooks[1]["jobid"] != float64(3) {
+			t.Errorf("webhooks = %v, want career 3 last", webhooks)
+		}
+		c.expect(c.do(http.MethodGet, "/api/v1/careers/3", user, nil), http.StatusOK)
+		if n := listed(user); n != 2 {
+			t.Errorf("user sees %d careers, want 2", n)
+		}
+		if published, _ := c.service.PublishDueCareers(context.Background()); len(published) != 0 {
+			t.Errorf("a second run published %+v", published)
+		}
+
+		events, _ := c.expect(c.do(http.MethodGet, "/api/v1/audit?resource_type=career&resource_id=3", admin, nil), http.StatusOK).Body["data"].([]any)
+		event := events[0].(map[string]any)
+		if event["action"] != "publish" || event["actor_role"] != "system" {
+			t.Errorf("latest event = %v, want a publish by the system", event)
+		}
+	})
+
+	t.Run("careers starting later are published on their startdate", func(t *testing.T) {
+		body := map[string]any{}
+		for key, value := range careerBody {
+			body[key] = value
+		}
+		body["startdate"], body["enddate"] = "2099-01-01T00:00:00Z", "2099-12-31T00:00:00Z"
+		career := data(c.expect(c.do(http.MethodPost, "/api/v1/careers", admin, body), http.StatusOK))
+		if career["publish_at"] != "2099-01-01T00:00:00Z" || career["published_at"] != nil {
+			t.Errorf("career = %v, want it scheduled for its startdate", career)
+		}
+	})
+
+	t.Run("a failing webhook does not fail the write", func(t *testing.T) {
+		failing = true
+		defer func() { failing = false }()
+
+		sent := len(webhooks)
+		career := data(c.expect(create(nil), http.StatusOK))
+		if len(webhooks) != sent+1 {
+			t.Errorf("webhooks = %v, want career %v sent", webhooks, career["jobid"])
+		}
+		c.expect(c.do(http.MethodGet, fmt.Sprintf("/api/v1/careers/%v", career["jobid"]), user, nil), http.StatusOK)
+
+		due := time.Now().Add(50 * time.Millisecond)
+		c.expect(create(due.Format(time.RFC3339Nano)), http.StatusOK)
+		time.Sleep(time.Until(due))
+		published, err := c.service.PublishDueCareers(context.Background())
+		if len(published) != 1 || err == nil || !strings.Contains(err.Error(), "502") {
+			t.Errorf("PublishDueCareers = %+v, %v; want the career published and the 502 reported", published, err)
+		}
+	})
+
+	t.Run("the webhook outlives the request", func(t *testing.T) {
+		var logs bytes.Buffer
+		ctx, cancel := context.WithCancel(logger.WithContext(context.Background(), logger.New(&logs, slog.LevelDebug)))
+		ctx = audit.WithActor(ctx, audit.Actor{Email: "admin@example.com", Role: "admin"})
+		// the client goes away while the webhook is being sent
+		received = func() {
+			cancel()
+			time.Sleep(50 * time.Millisecond)
+		}
+		defer func() { received = nil }()
+
+		career, err := c.service.CreateCareer(ctx, dto.CreateCareerRequest{
+			Company: "Acme", Position: "SRE", Jobtype: "Full-time", Description: "Run it",
+			Startdate: time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC), Enddate: time.Date(2099, 12, 31, 0, 0, 0, 0, time.UTC),
+		})
+		if err != nil || career.PublishedAt == nil {
+			t.Fatalf("CreateCareer = %+v, %v; want it published", career, err)
+		}
+		if strings.Contains(logs.String(), "announcing career") {
+			t.Errorf("the webhook was cut short: %s", logs.String())
+		}
+	})
+}
+
+func TestCareerImports(t *testing.T) {
+	c := newAPIClient(t)
+	admin := c.signUpAndLogin("admin", "admin@example.com", "+14155550100", "admin")
+	user := c.signUpAndLogin("jane", "jane@example.com", "+14155550101", "user")
+	outsider := c.signUpAndLogin("otto", "otto@example.com", "+14155550102", "recruiter")
+	csvHeader := http.Header{"Content-Type": {"text/csv"}}
+
+	file := "Company,Job Title,Job Type,Description,Start Date,End Date,Salary Min,Salary Max,Salary Currency,Pay Period,Team\n" +
+		"Acme,Backend Engineer,Full-time,Build APIs,2026-01-01,2026-12-31,100000,120000,USD,yearly,core\n" +
+		"Acme,Frontend Engineer,Full-time,Build UIs,2026-06-01,2026-01-01,,,,,web\n" +
+		"Acme,Data Engineer,Full-time,,2026-01-01,2026-12-31,lots,,,,data\n"
+	countCareers := func() int {
+		careers, _ := c.expect(c.do(http.MethodGet, "/api/v1/careers", admin, nil), http.StatusOK).Body["data"].([]any)
+		return len(careers)
+	}
+	errorLines := func(job map[string]any) []float64 {
+		errs, _ := job["errors"].([]any)
+		var lines []float64
+		for _, err := range errs {
+			lines = append(lines, err.(map[string]any)["line"].(float64))
+		}
+		return lines
+	}
+
+	t.Run("dry runs report the errors of each row", func(t *testing.T) {
+		job := data(c.expect(c.doWith(http.MethodPost, "/api/v1/careers/import?dry_run=true&map[Job%20Title]=position", admin, csvHeader, file), http.StatusOK))
+		if job["status"] != service.ImportFailed || job["total_rows"] != 3.0 || job["created_rows"] != 1.0 || job["failed_rows"] != 2.0 {
+			t.Errorf("dry run = %v", job)
+		}
+		if lines := errorLines(job); !slices.Equal(lines, []float64{3, 4}) {
+			t.Errorf("error lines = %v, want 3 and 4", lines)
+		}
+		errs := job["errors"].([]any)
+		if fields, _ := errs[0].(map[string]any)["errors"].([]any); len(fields) != 1 || fields[0].(map[string]any)["field"] != "enddate" {
+			t.Errorf("errors of line 3 = %v, want enddate", errs[0])
+		}
+		if ignored, _ := job["ignored_columns"].([]any); len(ignored) != 1 || ignored[0] != "Team" {
+			t.Errorf("ignored_columns = %v", job["ignored_columns"])
+		}
+		if n := countCareers(); n != 0 {
+			t.Errorf("a dry run created %d careers", n)
+		}
+	})
+
+	t.Run("atomic imports create every career or none", func(t *testing.T) {
+		job := data(c.expect(c.doWith(http.MethodPost, "/api/v1/careers/import?map[Job%20Title]=position", admin, csvHeader, file), http.StatusOK))
+		if job["status"] != service.ImportFailed || job["created_rows"] != 0.0 || len(job["jobids"].([]any)) != 0 {
+			t.Errorf("atomic import = %v", job)
+		}
+		if n := countCareers(); n != 0 {
+			t.Errorf("a failed atomic import created %d careers", n)
+		}
+
+		valid := strings.SplitAfter(file, "\n")[0] + strings.SplitAfter(file, "\n")[1]
+		job = data(c.expect(c.doWith(http.MethodPost, "/api/v1/careers/import?map[Job%20Title]=position", admin, csvHeader, valid), http.StatusOK))
+		if job["status"] != service.ImportSucceeded || len(job["jobids"].([]any)) != 1 {
+			t.Errorf("atomic import = %v", job)
+		}
+		career := data(c.expect(c.do(http.MethodGet, fmt.Sprintf("/api/v1/careers/%v", job["jobids"].([]any)[0]), user, nil), http.StatusOK))
+		if career["position"] != "Backend Engineer" || career["salary_min"] != 100000.0 {
+			t.Errorf("imported career = %v", career)
+		}
+	})
+
+	t.Run("best effort imports skip the rows that fail", func(t *testing.T) {
+		job := data(c.expect(c.doWith(http.MethodPost, "/api/v1/careers/import?mode=best_effort&map[Job%20Title]=position", admin, csvHeader, file), http.StatusOK))
+		if job["status"] != service.ImportPartial || job["created_rows"] != 1.0 || job["failed_rows"] != 2.0 || len(job["jobids"].([]any)) != 1 {
+			t.Errorf("best effort import = %v", job)
+		}
+		if n := countCareers(); n != 2 {
+			t.Errorf("careers = %d, want 2", n)
+		}
+	})
+
+	t.Run("JSON Lines files are imported", func(t *testing.T) {
+		lines := `{"company":"Acme","position":"SRE","jobtype":"Contract","description":"Keep it up","startdate":"2026-01-01","enddate":"2026-12-31","locations":[{"city":"Berlin","country":"DE"}]}` + "\n" +
+			`{"company":"Acme","position":"QA","jobtype":"Contract","description":"Test it","startdate":"2026-01-01","enddate":"2026-12-31"}` + "\n"
+		job := data(c.expect(c.doWith(http.MethodPost, "/api/v1/careers/import", admin, http.Header{"Content-Type": {"application/x-ndjson"}}, lines), http.StatusOK))
+		if job["format"] != "jsonl" || job["status"] != service.ImportSucceeded || job["created_rows"] != 2.0 {
+			t.Errorf("JSON Lines import = %v", job)
+		}
+
+		c.expect(c.doWith(http.MethodPost, "/api/v1/careers/import", admin, nil, lines), http.StatusUnsupportedMediaType)
+		c.expect(c.doWith(http.MethodPost, "/api/v1/careers/import?format=jsonl", admin, nil, lines), http.StatusOK)
+		c.expect(c.doWith(http.MethodPost, "/api/v1/careers/import?map[Title]=headline", admin, csvHeader, file), http.StatusBadRequest)
+		c.expect(c.doWith(http.MethodPost, "/api/v1/careers/import?mode=sometimes", admin, csvHeader, file), http.StatusBadRequest)
+	})
+
+	t.Run("large files are imported in the background", func(t *testing.T) {
+		c.service.ImportSyncRows = 1
+		t.Cleanup(func() { c.service.ImportSyncRows = 1000 })
+
+		started := c.expect(c.doWith(http.MethodPost, "/api/v1/careers/import?mode=best_effort&map[Job%20Title]=position", admin, csvHeader, file), http.StatusAccepted)
+		location := started.Header.Get("Location")
+		if location != fmt.Sprintf("/api/v1/careers/import/%v", data(started)["id"]) {
+			t.Fatalf("Location = %q", location)
+		}
+
+		var job map[string]any
+		for deadline := time.Now().Add(5 * time.Second); time.Now().Before(deadline); time.Sleep(10 * time.Millisecond) {
+			if job = data(c.expect(c.do(http.MethodGet, location, admin, nil), http.StatusOK)); job["status"] != "running" {
+				break
+			}
+		}
+		if job["status"] != service.ImportPartial || job["processed_rows"] != 3.0 || job["finished_at"] == nil {
+			t.Errorf("background import = %v", job)
+		}
+	})
+
+	t.Run("imports are only shown to admins and whoever started them", func(t *testing.T) {
+		c.expect(c.do(http.MethodGet, "/api/v1/careers/import/1", outsider, nil), http.StatusNotFound)
+		c.expect(c.do(http.MethodGet, "/api/v1/careers/import/1", user, nil), http.StatusForbidden)
+		c.expect(c.doWith(http.MethodPost, "/api/v1/careers/import", user, csvHeader, file), http.StatusForbidden)
+
+		// a recruiter's rows are checked against the companies they manage
+		job := data(c.expect(c.doWith(http.MethodPost, "/api/v1/careers/import?mode=best_effort&map[Job%20Title]=position", outsider, csvHeader, file), http.StatusOK))
+		if job["status"] != service.ImportFailed || job["failed_rows"] != 3.0 {
+			t.Errorf("outsider's import = %v", job)
+		}
+		c.expect(c.do(http.MethodGet, fmt.Sprintf("/api/v1/careers/import/%v", job["id"]), outsider, nil), http.StatusOK)
+	})
+}
+
+func TestEveryRouteIsExercised(t *testing.T) {
+	c := newAPIClient(t)
+	admin := c.signUpAndLogin("admin", "admin@example.com", "+14155550100", "admin")
+	user := c.signUpAndLogin("jane", "jane@example.com", "+14155550101", "user")
+
+	// legacy aliases
+	c.expect(c.do(http.MethodPost, "/signup", "", map[string]any{
+		"username": "joe", "email": "joe@example.com", "phonenumber": "+14155550102", "password": "Passw0rd!", "role": "user",
+	}), http.StatusOK)
+	c.expect(c.do(http.MethodPost, "/login", "", map[string]any{"email": "joe@example.com", "password": "Passw0rd!"}), http.StatusOK)
+	c.expect(c.do(http.MethodPost, "/createcareer", admin, careerBody), http.StatusOK)
+	c.expect(c.do(http.MethodGet, "/getcareerdetail/1", user, nil), http.StatusOK)
+	c.expect(c.do(http.MethodGet, "/get-all-career-details", user, nil), http.StatusOK)
+	c.expect(c.do(http.MethodPut, "/updatecareer/1", admin, map[string]any{"jobtype": "Contract"}), http.StatusOK)
+	c.expect(c.do(http.MethodDelete, "/deletecareer/1", admin, nil), http.StatusOK)
+	created := c.expect(c.do(http.MethodPost, "/createprofile", user, profileBody), http.StatusOK)
+	userID := strconv.Itoa(int(data(created)["userid"].(float64)))
+	c.expect(c.do(http.MethodGet, "/getprofile/"+userID, user, nil), http.StatusOK)
+	c.expect(c.do(http.MethodGet, "/get-all-profile-details", user, nil), http.StatusOK)
+	c.expect(c.do(http.MethodPut, "/update-profile/"+userID, user, map[string]any{"address": "2 Main Street"}), http.StatusOK)
+	c.expect(c.do(http.MethodDelete, "/delete-profile/"+userID, user, nil), http.StatusOK)
+	c.expect(c.do(http.MethodGet, "/get-all-users-email", admin, nil), http.StatusOK)
+
+	// current routes
+	c.expect(c.do(http.MethodPost, "/api/v1/careers", admin, careerBody), http.StatusOK)
+	c.expect(c.do(http.MethodGet, "/api/v1/careers", user, nil), http.StatusOK)
+	c.expect(c.do(http.MethodGet, "/api/v1/careers/2", user, nil), http.StatusOK)
+	c.expect(c.doWith(http.MethodPut, "/api/v1/careers/2", admin, ifMatch(`"1"`), map[string]any{"jobtype": "Contract"}), http.StatusOK)
+	c.expect(c.doWith(http.MethodPatch, "/api/v1/careers/2", admin, patchHeader("application/merge-patch+json"), `{"jobtype": "Internship"}`), http.StatusOK)
+	c.expect(c.doWith(http.MethodDelete, "/api/v1/careers/2", admin, ifMatch(`"3"`), nil), http.StatusOK)
+	c.expect(c.do(http.MethodGet, "/api/v1/trash", admin, nil), http.StatusOK)
+	c.expect(c.do(http.MethodGet, "/api/v1/audit", admin, nil), http.StatusOK)
+	c.expect(c.do(http.MethodPost, "/api/v1/companies", admin, map[string]any{"name": "Globex"}), http.StatusOK)
+	c.expect(c.do(http.MethodGet, "/api/v1/companies", user, nil), http.StatusOK)
+	c.expect(c.do(http.MethodGet, "/api/v1/companies/globex", user, nil), http.StatusOK)
+	c.expect(c.do(http.MethodPost, "/api/v1/companies/globex/recruiters", admin, map[string]any{"userid": 1}), http.StatusConflict)
+	c.expect(c.do(http.MethodGet, "/api/v1/companies/globex/recruiters", admin, nil), http.StatusOK)
+	c.expect(c.do(http.MethodDelete, "/api/v1/companies/globex/recruiters/1", admin, nil), http.StatusNotFound)
+	c.expect(c.do(http.MethodPost, "/api/v1/careers/2/restore", admin, nil), http.StatusOK)
+	c.expect(c.do(http.MethodGet, "/api/v1/careers/2/revisions", admin, nil), http.StatusOK)
+	c.expect(c.do(http.MethodGet, "/api/v1/careers/2/revisions/1", admin, nil), http.StatusOK)
+	c.expect(c.do(http.MethodGet, "/api/v1/careers/2/revisions/3/diff", admin, nil), http.StatusOK)
+	c.expect(c.doWith(http.MethodPost, "/api/v1/careers/2/revisions/1/revert", admin, ifMatch(`"4"`), nil), http.StatusOK)
+	c.expect(c.do(http.MethodPost, "/api/v1/profiles", user, profileBody), http.StatusOK)
+	c.expect(c.do(http.MethodGet, "/api/v1/profiles", user, nil), http.StatusOK)
+	c.expect(c.do(http.MethodGet, "/api/v1/profiles/"+userID, user, nil), http.StatusOK)
+	c.expect(c.doWith(http.MethodPut, "/api/v1/profiles/"+userID, user, ifMatch(`"1"`), map[string]any{"gender": "other"}), http.StatusOK)
+	c.expect(c.doWith(http.MethodPatch, "/api/v1/profiles/"+userID, user, patchHeader("application/json-patch+json"), `[{"op": "replace", "path": "/age", "value": 40}]`), http.StatusOK)
+	c.expect(c.doWith(http.MethodDelete, "/api/v1/profiles/"+userID, user, ifMatch(`"3"`), nil), http.StatusOK)
+	c.expect(c.do(http.MethodPost, "/api/v1/profiles/"+userID+"/restore", admin, nil), http.StatusOK)
+	c.expect(c.do(http.MethodPost, "/api/v1/skills", admin, map[string]any{"name": "Elixir", "aliases": []string{"ex"}}), http.StatusOK)
+	c.expect(c.do(http.MethodGet, "/api/v1/skills?prefix=el", user, nil), http.StatusOK)
+	c.expect(c.do(http.MethodPost, "/api/v1/skills/1/aliases", admin, map[string]any{"alias": "go-lang"}), http.StatusOK)
+	c.expect(c.doWith(http.MethodPut, "/api/v1/careers/2/skills", admin, ifMatch(`"5"`), map[string]any{"skills": []any{map[string]any{"skill": "golang"}}}), http.StatusOK)
+	c.expect(c.do(http.MethodGet, "/api/v1/careers/2/skills", user, nil), http.StatusOK)
+	c.expect(c.doWith(http.MethodPut, "/api/v1/profiles/"+userID+"/skills", user, ifMatch("*"), map[string]any{"skills": []any{map[string]any{"skill": "Go", "proficiency": "expert"}}}), http.StatusOK)
+	c.expect(c.do(http.MethodGet, "/api/v1/profiles/"+userID+"/skills", user, nil), http.StatusOK)
+	c.expect(c.do(http.MethodGet, "/api/v1/me/recommended-careers", user, nil), http.StatusOK)
+	c.expect(c.do(http.MethodGet, "/api/v1/careers/2/recommended-candidates", admin, nil), http.StatusOK)
+	c.expect(c.do(http.MethodPost, "/api/v1/me/saved-careers/2", user, map[string]any{"list": "to apply"}), http.StatusOK)
+	c.expect(c.do(http.MethodGet, "/api/v1/me/saved-careers", user, nil), http.StatusOK)
+	c.expect(c.do(http.MethodGet, "/api/v1/me/saved-lists", user, nil), http.StatusOK)
+	c.expect(c.do(http.MethodGet, "/api/v1/careers/2/saves", admin, nil), http.StatusOK)
+	c.expect(c.do(http.MethodDelete, "/api/v1/me/saved-careers/2", user, nil), http.StatusOK)
+	c.expect(c.doWith(http.MethodPost, "/api/v1/careers/2/extend", admin, ifMatch("*"), map[string]any{"enddate": "2099-12-31T00:00:00Z"}), http.StatusOK)
+	c.expect(c.doWith(http.MethodPost, "/api/v1/careers/import", admin, http.Header{"Content-Type": {"text/csv"}}, "company,position,jobtype,description,startdate,enddate\nAcme,SRE,Full-time,Run it,2026-01-01,2026-12-31\n"), http.StatusOK)
+	c.expect(c.do(http.MethodGet, "/api/v1/careers/import/1", admin, nil), http.StatusOK)
+	c.expect(c.do(http.MethodPost, "/api/v1/me/saved-searches", user, map[string]any{"name": "Go"}), http.StatusOK)
+	c.expect(c.do(http.MethodGet, "/api/v1/me/saved-searches", user, nil), http.StatusOK)
+	c.expect(c.do(http.MethodPut, "/api/v1/me/saved-searches/1", user, map[string]any{"name": "Go", "alerts": false}), http.StatusOK)
+	c.expect(c.do(http.MethodGet, "/api/v1/alerts/unsubscribe?token=unknown", "", nil), http.StatusNotFound)
+	c.expect(c.do(http.MethodPost, "/api/v1/alerts/unsubscribe?token=unknown", "", nil), http.StatusNotFound)
+	c.expect(c.do(http.MethodDelete, "/api/v1/me/saved-searches/1", user, nil), http.StatusOK)
+	c.expect(c.do(http.MethodGet, "/api/v1/users/emails", admin, nil), http.StatusOK)
+	c.expect(c.do(http.MethodDelete, "/api/v1/users/"+userID, admin, nil), http.StatusOK)
+	c.expect(c.do(http.MethodPost, "/api/v1/users/"+userID+"/restore", admin, nil), http.StatusOK)
+
+	// docs and metrics
+	c.expect(c.do(http.MethodGet, "/openapi.json", "", nil), http.StatusOK)
+	c.do(http.MethodGet, "/docs", "", nil)
+	c.expect(c.do(http.MethodGet, "/debug/vars", "", nil), http.StatusUnauthorized)
+	c.expect(c.do(http.MethodGet, "/debug/vars", user, nil), http.StatusForbidden)
+	c.expect(c.do(http.MethodGet, "/debug/vars", admin, nil), http.StatusOK)
+
+	for _, route := range c.engine.Routes() {
+		if !c.hits[route.Method+" "+route.Path] {
+			t.Errorf("route %s %s is not exercised by the test suite", route.Method, route.Path)
+		}
+	}
+}
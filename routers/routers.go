@@ -3,43 +3,178 @@ package router
 import (
 	"jobApps/authentication"
 	"jobApps/handlers"
-	"jobApps/internal/database"
+	"jobApps/helper"
+	"jobApps/middleware"
+	"jobApps/openapi"
+	"jobApps/service"
+	"log/slog"
+	"os"
+	"time"
 
 	"github.com/gin-gonic/gin"
-	"github.com/jackc/pgx/v4"
 )
 
-func Router(conn *pgx.Conn) {
+// the unversioned routes were deprecated when /api/v1 was introduced
+var legacyDeprecatedAt = time.Date(2026, time.October, 19, 0, 0, 0, 0, time.UTC)
+
+func Router(svc *service.Service) {
+	router := New(svc)
+
+	//router
+	router.Run("localhost:8080")
+}
+
+// New builds the engine with every route registered
+func New(svc *service.Service) *gin.Engine {
 	gin.SetMode(gin.ReleaseMode)
-	router := gin.Default()
+	router := gin.New()
+	router.Use(middleware.RequestID(), middleware.Logger(), middleware.Recovery(), middleware.Deadline(helper.DurationEnv("REQUEST_TIMEOUT", 10*time.Second)))
+
+	handler := handlers.ControllerInstance(svc)
+	handler.RequireIfMatch = helper.BoolEnv("IF_MATCH_REQUIRED", true)
+
+	v1 := router.Group("/api/v1")
+
+	// Auth
+	v1.POST("/auth/signup", handler.SignUp)
+	v1.POST("/auth/login", handler.Login)
+
+	// Job alerts link here from emails, without a login
+	v1.GET("/alerts/unsubscribe", handler.ConfirmUnsubscribe)
+	v1.POST("/alerts/unsubscribe", handler.Unsubscribe)
+
+	authorized := v1.Group("", authentication.AuthMiddleware())
+
+	// Career
+	careers := authorized.Group("/careers")
+	careers.POST("", handler.CreateCareer)
+	careers.GET("", handler.GetAllCareers)
+	careers.POST("/import", handler.ImportCareers)
+	careers.GET("/import/:id", handler.GetCareerImport)
+	careers.GET("/:id", handler.GetCareerByJobId)
+	careers.PUT("/:id", handler.UpdateCareerById)
+	careers.PATCH("/:id", handler.PatchCareerById)
+	careers.DELETE("/:id", handler.DeleteCareerById)
+	careers.POST("/:id/restore", handler.RestoreCareerById)
+	careers.POST("/:id/extend", handler.ExtendCareer)
+	careers.GET("/:id/revisions", handler.GetCareerRevisions)
+	careers.GET("/:id/revisions/:rev", handler.GetCareerRevision)
+	careers.GET("/:id/revisions/:rev/diff", handler.GetCareerRevisionDiff)
+	careers.POST("/:id/revisions/:rev/revert", handler.RevertCareer)
+	careers.GET("/:id/skills", handler.GetCareerSkills)
+	careers.PUT("/:id/skills", handler.SetCareerSkills)
+	careers.GET("/:id/recommended-candidates", handler.GetRecommendedCandidates)
+	careers.GET("/:id/saves", handler.GetCareerSaves)
+
+	// Profile
+	profiles := authorized.Group("/profiles")
+	profiles.POST("", handler.CreateProfile)
+	profiles.GET("", handler.GetAllProfiles)
+	profiles.GET("/:id", handler.GetProfileById)
+	profiles.PUT("/:id", handler.UpdateProfileById)
+	profiles.PATCH("/:id", handler.PatchProfileById)
+	profiles.DELETE("/:id", handler.DeleteProfileById)
+	profiles.POST("/:id/restore", handler.RestoreProfileById)
+	profiles.GET("/:id/skills", handler.GetProfileSkills)
+	profiles.PUT("/:id/skills", handler.SetProfileSkills)
+
+	// Recommendations
+	authorized.GET("/me/recommended-careers", handler.GetRecommendedCareers)
 
-	query := database.New(conn)
+	// Saved careers
+	authorized.GET("/me/saved-careers", handler.GetSavedCareers)
+	authorized.POST("/me/saved-careers/:id", handler.SaveCareer)
+	authorized.DELETE("/me/saved-careers/:id", handler.UnsaveCareer)
+	authorized.GET("/me/saved-lists", handler.GetSavedCareerLists)
 
-	handler := handlers.ControllerInstance(query)
+	// Saved searches
+	authorized.POST("/me/saved-searches", handler.CreateSavedSearch)
+	authorized.GET("/me/saved-searches", handler.GetSavedSearches)
+	authorized.PUT("/me/saved-searches/:id", handler.UpdateSavedSearch)
+	authorized.DELETE("/me/saved-searches/:id", handler.DeleteSavedSearch)
+
+	//User
+	authorized.GET("/users/emails", handler.GetAllUsersEmail)
+	authorized.DELETE("/users/:id", handler.DeleteUserById)
+	authorized.POST("/users/:id/restore", handler.RestoreUserById)
+
+	// Trash
+	authorized.GET("/trash", handler.GetTrash)
+
+	// Audit
+	authorized.GET("/audit", handler.GetAuditEvents)
+
+	// Company
+	companies := authorized.Group("/companies")
+	companies.POST("", handler.CreateCompany)
+	companies.GET("", handler.GetAllCompanies)
+	companies.GET("/:slug", handler.GetCompanyBySlug)
+	companies.GET("/:slug/recruiters", handler.GetCompanyRecruiters)
+	companies.POST("/:slug/recruiters", handler.AddCompanyRecruiter)
+	companies.DELETE("/:slug/recruiters/:id", handler.RemoveCompanyRecruiter)
+
+	// Skill
+	skills := authorized.Group("/skills")
+	skills.POST("", handler.CreateSkill)
+	skills.GET("", handler.GetSkills)
+	skills.POST("/:id/aliases", handler.AddSkillAlias)
+
+	// legacy clients predate ETags, so If-Match stays optional for them
+	legacyHandler := *handler
+	legacyHandler.RequireIfMatch = false
+	registerLegacyRoutes(router, &legacyHandler)
+
+	// API documentation
+	openapi.Register(router, apiRoutes)
+
+	// Metrics (admin)
+	router.GET("/debug/vars", authentication.AuthMiddleware(), handler.GetMetrics)
+
+	return router
+}
+
+// registerLegacyRoutes keeps the pre-/api/v1 paths working until the sunset date
+func registerLegacyRoutes(router *gin.Engine, handler *handlers.DbConnection) {
+	sunset := legacySunset()
+	deprecated := func(successor string) gin.HandlerFunc {
+		return middleware.Deprecated(successor, legacyDeprecatedAt, sunset)
+	}
 
 	//signup
-	router.POST("/signup", handler.SignUp)
+	router.POST("/signup", deprecated("/api/v1/auth/signup"), handler.SignUp)
 
 	//login
-	router.POST("/login", handler.Login)
+	router.POST("/login", deprecated("/api/v1/auth/login"), handler.Login)
 
 	// Career
-	router.POST("/createcareer", authentication.AuthMiddleware(), handler.CreateCareer)
-	router.GET("/getcareerdetail/:id", authentication.AuthMiddleware(), handler.GetCareerByJobId)
-	router.GET("/get-all-career-details", authentication.AuthMiddleware(), handler.GetAllCareers)
-	router.PUT("/updatecareer/:id", authentication.AuthMiddleware(), handler.UpdateCareerById)
-	router.DELETE("/deletecareer/:id", authentication.AuthMiddleware(), handler.DeleteCareerById)
+	router.POST("/createcareer", deprecated("/api/v1/careers"), authentication.AuthMiddleware(), handler.CreateCareer)
+	router.GET("/getcareerdetail/:id", deprecated("/api/v1/careers/{id}"), authentication.AuthMiddleware(), handler.GetCareerByJobId)
+	router.GET("/get-all-career-details", deprecated("/api/v1/careers"), authentication.AuthMiddleware(), handler.GetAllCareers)
+	router.PUT("/updatecareer/:id", deprecated("/api/v1/careers/{id}"), authentication.AuthMiddleware(), handler.UpdateCareerById)
+	router.DELETE("/deletecareer/:id", deprecated("/api/v1/careers/{id}"), authentication.AuthMiddleware(), handler.DeleteCareerById)
 
 	// Profile
-	router.POST("/createprofile", authentication.AuthMiddleware(), handler.CreateProfile)
-	router.GET("/getprofile/:id", authentication.AuthMiddleware(), handler.GetProfileById)
-	router.GET("/get-all-profile-details", authentication.AuthMiddleware(), handler.GetAllProfiles)
-	router.DELETE("/delete-profile/:id", authentication.AuthMiddleware(), handler.DeleteProfileById)
-	router.PUT("/update-profile/:id", authentication.AuthMiddleware(), handler.UpdateProfileById)
+	router.POST("/createprofile", deprecated("/api/v1/profiles"), authentication.AuthMiddleware(), handler.CreateProfile)
+	router.GET("/getprofile/:id", deprecated("/api/v1/profiles/{id}"), authentication.AuthMiddleware(), handler.GetProfileById)
+	router.GET("/get-all-profile-details", deprecated("/api/v1/profiles"), authentication.AuthMiddleware(), handler.GetAllProfiles)
+	router.DELETE("/delete-profile/:id", deprecated("/api/v1/profiles/{id}"), authentication.AuthMiddleware(), handler.DeleteProfileById)
+	router.PUT("/update-profile/:id", deprecated("/api/v1/profiles/{id}"), authentication.AuthMiddleware(), handler.UpdateProfileById)
 
 	//User
-	router.GET("/get-all-users-email", authentication.AuthMiddleware(), handler.GetAllUsersEmail)
+	router.GET("/get-all-users-email", deprecated("/api/v1/users/emails"), authentication.AuthMiddleware(), handler.GetAllUsersEmail)
+}
 
-	//router
-	router.Run("localhost:8080")
+// legacySunset reads LEGACY_ROUTES_SUNSET (YYYY-MM-DD), defaulting to six
+// months after the deprecation
+func legacySunset() time.Time {
+	sunset := legacyDeprecatedAt.AddDate(0, 6, 0)
+	if value := os.Getenv("LEGACY_ROUTES_SUNSET"); value != "" {
+		parsed, err := time.Parse(time.DateOnly, value)
+		if err != nil {
+			slog.Warn("ignoring invalid LEGACY_ROUTES_SUNSET", slog.String("value", value), slog.Any("error", err))
+			return sunset
+		}
+		sunset = parsed
+	}
+	return sunset
 }
@@ -0,0 +1,98 @@
+package router
+
+import (
+	"encoding/json"
+	"expvar"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"jobApps/internal/memstore"
+	"jobApps/metrics"
+	"jobApps/openapi"
+	"jobApps/service"
+)
+
+func TestOpenAPIDocumentsEveryRoute(t *testing.T) {
+	engine := New(service.New(memstore.New()))
+	doc := openapi.New(apiRoutes)
+
+	registered := map[string]bool{}
+	for _, route := range engine.Routes() {
+		path := openapi.OpenAPIPath(route.Path)
+		method := strings.ToLower(route.Method)
+		registered[method+" "+path] = true
+
+		if _, ok := doc.Paths[path][method]; !ok {
+			t.Errorf("route %s %s is missing from the OpenAPI document", route.Method, route.Path)
+		}
+	}
+
+	for path, operations := range doc.Paths {
+		for method := range operations {
+			if !registered[method+" "+path] {
+				t.Errorf("OpenAPI document describes %s %s which is not registered", strings.ToUpper(method), path)
+			}
+		}
+	}
+}
+
+func TestOpenAPIServed(t *testing.T) {
+	engine := New(service.New(memstore.New()))
+
+	recorder := httptest.NewRecorder()
+	engine.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/openapi.json", nil))
+	if recorder.Code != http.StatusOK {
+		t.Fatalf("GET /openapi.json: status %d", recorder.Code)
+	}
+
+	var doc openapi.Document
+	if err := json.Unmarshal(recorder.Body.Bytes(), &doc); err != nil {
+		t.Fatalf("decoding document: %v", err)
+	}
+	if doc.OpenAPI != "3.1.0" {
+		t.Errorf("openapi version = %q, want 3.1.0", doc.OpenAPI)
+	}
+	if _, ok := doc.Components.Schemas["CreateCareerRequest"]; !ok {
+		t.Error("CreateCareerRequest schema is missing")
+	}
+
+	recorder = httptest.NewRecorder()
+	engine.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/docs", nil))
+	if recorder.Code != http.StatusOK || !strings.Contains(recorder.Body.String(), "/openapi.json") {
+		t.Errorf("GET /docs: status %d, body does not load the document", recorder.Code)
+	}
+}
+
+func TestLegacyRoutesAreDeprecated(t *testing.T) {
+	engine := New(service.New(memstore.New()))
+	before := legacyHits("GET /get-all-career-details")
+
+	recorder := httptest.NewRecorder()
+	engine.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/get-all-career-details", nil))
+
+	if recorder.Code != http.StatusUnauthorized {
+		t.Errorf("status = %d, want %d", recorder.Code, http.StatusUnauthorized)
+	}
+	if got := recorder.Header().Get("Deprecation"); got != fmt.Sprintf("@%d", legacyDeprecatedAt.Unix()) {
+		t.Errorf("Deprecation = %q", got)
+	}
+	if recorder.Header().Get("Sunset") == "" {
+		t.Error("Sunset header is missing")
+	}
+	if got := recorder.Header().Get("Link"); got != `</api/v1/careers>; rel="successor-version"` {
+		t.Errorf("Link = %q", got)
+	}
+	if after := legacyHits("GET /get-all-career-details"); after != before+1 {
+		t.Errorf("legacy usage metric = %d, want %d", after, before+1)
+	}
+}
+
+func legacyHits(key string) int64 {
+	if value, ok := metrics.LegacyRouteRequests.Get(key).(*expvar.Int); ok {
+		return value.Value()
+	}
+	return 0
+}
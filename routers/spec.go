@@ -0,0 +1,229 @@
+package router
+
+import (
+	"jobApps/dto"
+	"jobApps/internal/database"
+	"jobApps/openapi"
+	"jobApps/service"
+	"net/http"
+)
+
+// response bodies of the routes that don't use the status/message/data envelope
+
+type signUpResponse struct {
+	InsertedDetails database.User `json:"Inserted details"`
+}
+
+type loginResponse struct {
+	Status   int           `json:"status"`
+	Message  string        `json:"message"`
+	UserData database.User `json:"user_data"`
+	Token    string        `json:"token"`
+}
+
+var (
+	signUpRoute           = openapi.Route{Method: http.MethodPost, Path: "/api/v1/auth/signup", Summary: "Register a user or admin", Tag: "auth", Request: dto.SignUpRequest{}, Body: signUpResponse{}}
+	loginRoute            = openapi.Route{Method: http.MethodPost, Path: "/api/v1/auth/login", Summary: "Exchange credentials for a JWT", Tag: "auth", Request: dto.LoginRequest{}, Body: loginResponse{}}
+	unsubscribePageRoute  = openapi.Route{Method: http.MethodGet, Path: "/api/v1/alerts/unsubscribe", Summary: "Show the saved search behind the unsubscribe link in a digest, without changing it", Tag: "saved searches", Query: unsubscribeQuery, Response: service.SavedSearch{}}
+	unsubscribeRoute      = openapi.Route{Method: http.MethodPost, Path: "/api/v1/alerts/unsubscribe", Summary: "Turn off the job alerts of a saved search, from the link in a digest (also RFC 8058 one-click)", Tag: "saved searches", Query: unsubscribeQuery, Response: service.SavedSearch{}}
+	createCareerRoute     = openapi.Route{Method: http.MethodPost, Path: "/api/v1/careers", Summary: "Create a career post (admin or company recruiter)", Tag: "careers", Auth: true, Request: dto.CreateCareerRequest{}, Response: database.Career{}, ETag: true}
+	listCareersRoute      = openapi.Route{Method: http.MethodGet, Path: "/api/v1/careers", Summary: "List career posts", Tag: "careers", Auth: true, Query: careersQuery, Response: []database.Career{}, ETag: true}
+	importCareersRoute    = openapi.Route{Method: http.MethodPost, Path: "/api/v1/careers/import", Summary: "Create career posts from a CSV or JSON Lines file, checking each like a single post; large or async imports answer 202 with a Location to poll (admin or company recruiter)", Tag: "careers", Auth: true, Query: importQuery, Request: "", ContentType: "text/csv", Response: database.CareerImport{}}
+	careerImportRoute     = openapi.Route{Method: http.MethodGet, Path: "/api/v1/careers/import/:id", Summary: "Get the progress and outcome of a career import (admin or the recruiter who started it)", Tag: "careers", Auth: true, Response: database.CareerImport{}}
+	getCareerRoute        = openapi.Route{Method: http.MethodGet, Path: "/api/v1/careers/:id", Summary: "Get a career post", Tag: "careers", Auth: true, Response: database.Career{}, ETag: true}
+	updateCareerRoute     = openapi.Route{Method: http.MethodPut, Path: "/api/v1/careers/:id", Summary: "Update a career post (admin or company recruiter)", Tag: "careers", Auth: true, Request: dto.UpdateCareerRequest{}, Response: database.Career{}, ETag: true}
+	patchCareerRoute      = openapi.Route{Method: http.MethodPatch, Path: "/api/v1/careers/:id", Summary: "Partially update a career post (admin or company recruiter)", Tag: "careers", Auth: true, Request: dto.CareerDocument{}, Response: database.Career{}, ETag: true}
+	deleteCareerRoute     = openapi.Route{Method: http.MethodDelete, Path: "/api/v1/careers/:id", Summary: "Delete a career post (admin or company recruiter)", Tag: "careers", Auth: true, Response: database.Career{}, DataKey: "deleted data", ETag: true}
+	extendCareerRoute     = openapi.Route{Method: http.MethodPost, Path: "/api/v1/careers/:id/extend", Summary: "Move a career post's end date later, opening it again if it has closed (admin or company recruiter)", Tag: "careers", Auth: true, Request: dto.ExtendCareerRequest{}, Response: database.Career{}, ETag: true}
+	restoreCareerRoute    = openapi.Route{Method: http.MethodPost, Path: "/api/v1/careers/:id/restore", Summary: "Restore a deleted career post (admin)", Tag: "trash", Auth: true, Response: database.Career{}}
+	careerRevisionsRoute  = openapi.Route{Method: http.MethodGet, Path: "/api/v1/careers/:id/revisions", Summary: "List a career post's revisions, newest first (admin or company recruiter)", Tag: "revisions", Auth: true, Query: revisionsQuery, Response: []database.CareerRevision{}}
+	careerRevisionRoute   = openapi.Route{Method: http.MethodGet, Path: "/api/v1/careers/:id/revisions/:rev", Summary: "Get a revision of a career post (admin or company recruiter)", Tag: "revisions", Auth: true, Response: database.CareerRevision{}}
+	revisionDiffRoute     = openapi.Route{Method: http.MethodGet, Path: "/api/v1/careers/:id/revisions/:rev/diff", Summary: "Compare a revision of a career post with an earlier one (admin or company recruiter)", Tag: "revisions", Auth: true, Query: revisionDiffQuery, Response: service.RevisionDiff{}}
+	revertCareerRoute     = openapi.Route{Method: http.MethodPost, Path: "/api/v1/careers/:id/revisions/:rev/revert", Summary: "Revert a career post to a revision (admin)", Tag: "revisions", Auth: true, Response: database.Career{}, ETag: true}
+	careerSkillsRoute     = openapi.Route{Method: http.MethodGet, Path: "/api/v1/careers/:id/skills", Summary: "List the skills a career post asks for", Tag: "skills", Auth: true, Response: service.CareerSkills{}, ETag: true}
+	setCareerSkillsRoute  = openapi.Route{Method: http.MethodPut, Path: "/api/v1/careers/:id/skills", Summary: "Replace the skills a career post asks for (admin or company recruiter)", Tag: "skills", Auth: true, Request: dto.SetCareerSkillsRequest{}, Response: service.CareerSkills{}, ETag: true}
+	candidateMatchesRoute = openapi.Route{Method: http.MethodGet, Path: "/api/v1/careers/:id/recommended-candidates", Summary: "Rank profiles by how well they fit a career post, with the score of each factor (admin)", Tag: "recommendations", Auth: true, Query: recommendationsQuery, Response: []service.CandidateRecommendation{}}
+	careerSavesRoute      = openapi.Route{Method: http.MethodGet, Path: "/api/v1/careers/:id/saves", Summary: "Count the users who saved a career post (admin)", Tag: "saved careers", Auth: true, Response: service.CareerSaves{}}
+	careerMatchesRoute    = openapi.Route{Method: http.MethodGet, Path: "/api/v1/me/recommended-careers", Summary: "Rank open career posts by how well they fit the caller's profile, with the score of each factor (user)", Tag: "recommendations", Auth: true, Query: recommendationsQuery, Response: []service.CareerRecommendation{}}
+	savedCareersRoute     = openapi.Route{Method: http.MethodGet, Path: "/api/v1/me/saved-careers", Summary: "List the caller's saved career posts, newest first, with whether each is open, closed or deleted (user)", Tag: "saved careers", Auth: true, Query: savedCareersQuery, Response: []service.SavedCareer{}}
+	saveCareerRoute       = openapi.Route{Method: http.MethodPost, Path: "/api/v1/me/saved-careers/:id", Summary: "Save a career post, or change the note and list of a saved one (user)", Tag: "saved careers", Auth: true, Request: dto.SaveCareerRequest{}, Response: service.SavedCareer{}}
+	unsaveCareerRoute     = openapi.Route{Method: http.MethodDelete, Path: "/api/v1/me/saved-careers/:id", Summary: "Remove a career post from the caller's saved ones (user)", Tag: "saved careers", Auth: true}
+	savedListsRoute       = openapi.Route{Method: http.MethodGet, Path: "/api/v1/me/saved-lists", Summary: "List the caller's lists of saved career posts with their sizes (user)", Tag: "saved careers", Auth: true, Response: []database.ListSavedCareerListsRow{}}
+	createSearchRoute     = openapi.Route{Method: http.MethodPost, Path: "/api/v1/me/saved-searches", Summary: "Save a career search to be alerted about new matching career posts (user)", Tag: "saved searches", Auth: true, Request: dto.SavedSearchRequest{}, Response: service.SavedSearch{}}
+	listSearchesRoute     = openapi.Route{Method: http.MethodGet, Path: "/api/v1/me/saved-searches", Summary: "List the caller's saved searches (user)", Tag: "saved searches", Auth: true, Response: []service.SavedSearch{}}
+	updateSearchRoute     = openapi.Route{Method: http.MethodPut, Path: "/api/v1/me/saved-searches/:id", Summary: "Replace a saved search (user)", Tag: "saved searches", Auth: true, Request: dto.SavedSearchRequest{}, Response: service.SavedSearch{}}
+	deleteSearchRoute     = openapi.Route{Method: http.MethodDelete, Path: "/api/v1/me/saved-searches/:id", Summary: "Delete a saved search (user)", Tag: "saved searches", Auth: true}
+	createProfileRoute    = openapi.Route{Method: http.MethodPost, Path: "/api/v1/profiles", Summary: "Create a profile (user)", Tag: "profiles", Auth: true, Request: dto.CreateProfileRequest{}, Response: database.Profile{}, ETag: true}
+	listProfilesRoute     = openapi.Route{Method: http.MethodGet, Path: "/api/v1/profiles", Summary: "List profiles", Tag: "profiles", Auth: true, Response: []database.Profile{}, ETag: true}
+	getProfileRoute       = openapi.Route{Method: http.MethodGet, Path: "/api/v1/profiles/:id", Summary: "Get a profile by user ID", Tag: "profiles", Auth: true, Response: database.Profile{}, ETag: true}
+	updateProfileRoute    = openapi.Route{Method: http.MethodPut, Path: "/api/v1/profiles/:id", Summary: "Update a profile (user)", Tag: "profiles", Auth: true, Request: dto.UpdateProfileRequest{}, Response: database.Profile{}, ETag: true}
+	patchProfileRoute     = openapi.Route{Method: http.MethodPatch, Path: "/api/v1/profiles/:id", Summary: "Partially update a profile (user)", Tag: "profiles", Auth: true, Request: dto.ProfileDocument{}, Response: database.Profile{}, ETag: true}
+	deleteProfileRoute    = openapi.Route{Method: http.MethodDelete, Path: "/api/v1/profiles/:id", Summary: "Delete a profile (user)", Tag: "profiles", Auth: true, Response: database.Profile{}, DataKey: "deleted data", ETag: true}
+	restoreProfileRoute   = openapi.Route{Method: http.MethodPost, Path: "/api/v1/profiles/:id/restore", Summary: "Restore a user's deleted profile (admin)", Tag: "trash", Auth: true, Response: database.Profile{}}
+	profileSkillsRoute    = openapi.Route{Method: http.MethodGet, Path: "/api/v1/profiles/:id/skills", Summary: "List the skills of a user's profile", Tag: "skills", Auth: true, Response: service.ProfileSkills{}, ETag: true}
+	setProfileSkillsRoute = openapi.Route{Method: http.MethodPut, Path: "/api/v1/profiles/:id/skills", Summary: "Replace the skills of a user's profile (user)", Tag: "skills", Auth: true, Request: dto.SetProfileSkillsRequest{}, Response: service.ProfileSkills{}, ETag: true}
+	usersEmailRoute       = openapi.Route{Method: http.MethodGet, Path: "/api/v1/users/emails", Summary: "List the emails of all users (admin)", Tag: "users", Auth: true, Response: []string{}}
+	deleteUserRoute       = openapi.Route{Method: http.MethodDelete, Path: "/api/v1/users/:id", Summary: "Delete a user and their profile (admin)", Tag: "users", Auth: true, Response: service.Account{}, DataKey: "deleted data"}
+	restoreUserRoute      = openapi.Route{Method: http.MethodPost, Path: "/api/v1/users/:id/restore", Summary: "Restore a deleted user and their profile (admin)", Tag: "trash", Auth: true, Response: service.Account{}}
+	auditRoute            = openapi.Route{Method: http.MethodGet, Path: "/api/v1/audit", Summary: "List audit events, newest first (admin)", Tag: "audit", Auth: true, Query: auditQuery, Response: []database.AuditEvent{}, CSV: true}
+	createCompanyRoute    = openapi.Route{Method: http.MethodPost, Path: "/api/v1/companies", Summary: "Create a company (admin)", Tag: "companies", Auth: true, Request: dto.CreateCompanyRequest{}, Response: database.Company{}}
+	listCompaniesRoute    = openapi.Route{Method: http.MethodGet, Path: "/api/v1/companies", Summary: "List companies", Tag: "companies", Auth: true, Response: []database.Company{}}
+	getCompanyRoute       = openapi.Route{Method: http.MethodGet, Path: "/api/v1/companies/:slug", Summary: "Get a company with its open career posts", Tag: "companies", Auth: true, Response: service.CompanyPage{}}
+	listRecruitersRoute   = openapi.Route{Method: http.MethodGet, Path: "/api/v1/companies/:slug/recruiters", Summary: "List a company's recruiters (admin)", Tag: "companies", Auth: true, Response: []service.Account{}}
+	addRecruiterRoute     = openapi.Route{Method: http.MethodPost, Path: "/api/v1/companies/:slug/recruiters", Summary: "Add a recruiter to a company (admin)", Tag: "companies", Auth: true, Request: dto.AddRecruiterRequest{}, Response: service.Account{}}
+	removeRecruiterRoute  = openapi.Route{Method: http.MethodDelete, Path: "/api/v1/companies/:slug/recruiters/:id", Summary: "Remove a recruiter from a company (admin)", Tag: "companies", Auth: true}
+	createSkillRoute      = openapi.Route{Method: http.MethodPost, Path: "/api/v1/skills", Summary: "Add a skill with its aliases (admin)", Tag: "skills", Auth: true, Request: dto.CreateSkillRequest{}, Response: database.ListSkillsRow{}}
+	listSkillsRoute       = openapi.Route{Method: http.MethodGet, Path: "/api/v1/skills", Summary: "Autocomplete skills by name or alias", Tag: "skills", Auth: true, Query: skillsQuery, Response: []database.ListSkillsRow{}}
+	addSkillAliasRoute    = openapi.Route{Method: http.MethodPost, Path: "/api/v1/skills/:id/aliases", Summary: "Add an alias to a skill (admin)", Tag: "skills", Auth: true, Request: dto.AddSkillAliasRequest{}, Response: database.ListSkillsRow{}}
+	trashRoute            = openapi.Route{Method: http.MethodGet, Path: "/api/v1/trash", Summary: "List deleted careers, profiles and users (admin)", Tag: "trash", Auth: true, Response: service.Trash{}}
+)
+
+var careersQuery = []openapi.Parameter{
+	{Name: "q", In: "query", Description: "Keywords that must all appear in the position, company or description, ignoring case", Schema: &openapi.Schema{Type: "string"}},
+	{Name: "jobtype", In: "query", Description: "Only careers of this job type, ignoring case", Schema: &openapi.Schema{Type: "string"}},
+	{Name: "salary_min", In: "query", Description: "Only careers whose yearly salary reaches this amount", Schema: &openapi.Schema{Type: "integer", Format: "int64"}},
+	{Name: "salary_max", In: "query", Description: "Only careers whose yearly salary starts at or below this amount", Schema: &openapi.Schema{Type: "integer", Format: "int64"}},
+	{Name: "currency", In: "query", Description: "ISO 4217 currency of the salary bounds (default: the base currency)", Schema: &openapi.Schema{Type: "string"}},
+	{Name: "near", In: "query", Description: "Only careers with a location near this point, given as lat,lon or a city name such as Berlin or Portland,US", Schema: &openapi.Schema{Type: "string"}},
+	{Name: "radius_km", In: "query", Description: "Search radius around near in kilometres (default: 50)", Schema: &openapi.Schema{Type: "number"}},
+	{Name: "skills", In: "query", Description: "Comma-separated skill names or aliases, e.g. go,postgres", Schema: &openapi.Schema{Type: "string"}},
+	{Name: "skill_match", In: "query", Description: "Whether careers need all of the skills or any of them (default: all)", Schema: &openapi.Schema{Type: "string", Enum: []any{"all", "any"}}},
+	{Name: "include_expired", In: "query", Description: "Also list careers whose end date has passed (default: false)", Schema: &openapi.Schema{Type: "boolean"}},
+}
+
+var skillsQuery = []openapi.Parameter{
+	{Name: "prefix", In: "query", Description: "Start of a skill name or alias, ignoring case", Schema: &openapi.Schema{Type: "string"}},
+	{Name: "limit", In: "query", Description: "Number of skills, 1-100 (default 20)", Schema: &openapi.Schema{Type: "integer"}},
+}
+
+var recommendationsQuery = []openapi.Parameter{
+	{Name: "limit", In: "query", Description: "Number of recommendations, 1-100 (default 20)", Schema: &openapi.Schema{Type: "integer"}},
+	{Name: "min_score", In: "query", Description: "Only recommendations scoring at least this, 0-1", Schema: &openapi.Schema{Type: "number"}},
+}
+
+var savedCareersQuery = []openapi.Parameter{
+	{Name: "list", In: "query", Description: "Only the careers in this list, ignoring case", Schema: &openapi.Schema{Type: "string"}},
+}
+
+var importQuery = []openapi.Parameter{
+	{Name: "format", In: "query", Description: "Format of the file (default: from the Content-Type, text/csv or application/jsonl)", Schema: &openapi.Schema{Type: "string", Enum: []any{"csv", "jsonl"}}},
+	{Name: "mode", In: "query", Description: "atomic creates every career or none; best_effort skips the rows that fail (default: atomic)", Schema: &openapi.Schema{Type: "string", Enum: []any{"atomic", "best_effort"}}},
+	{Name: "dry_run", In: "query", Description: "Check every row and report the errors without creating any career", Schema: &openapi.Schema{Type: "boolean"}},
+	{Name: "async", In: "query", Description: "Import in the background even if the file is small", Schema: &openapi.Schema{Type: "boolean"}},
+	{Name: "map[column]", In: "query", Description: "Read a column as a career field, e.g. map[Job Title]=position", Schema: &openapi.Schema{Type: "string"}},
+}
+
+var unsubscribeQuery = []openapi.Parameter{
+	{Name: "token", In: "query", Required: true, Description: "Unsubscribe token from the digest's link", Schema: &openapi.Schema{Type: "string"}},
+}
+
+var revisionsQuery = []openapi.Parameter{
+	{Name: "at", In: "query", Description: "Only revisions saved at or before this RFC 3339 time; the first is the post as it was then", Schema: &openapi.Schema{Type: "string", Format: "date-time"}},
+}
+
+var revisionDiffQuery = []openapi.Parameter{
+	{Name: "from", In: "query", Description: "Revision to compare with (default: the previous revision)", Schema: &openapi.Schema{Type: "integer"}},
+}
+
+var auditQuery = []openapi.Parameter{
+	{Name: "actor", In: "query", Description: "Email of the user who made the change", Schema: &openapi.Schema{Type: "string"}},
+	{Name: "resource_type", In: "query", Schema: &openapi.Schema{Type: "string", Enum: []any{"user", "career", "profile", "company", "skill", "saved_career", "saved_search"}}},
+	{Name: "resource_id", In: "query", Description: "userid, jobid, profileid, company id or skill id; the jobid for saved_career, the search id for saved_search", Schema: &openapi.Schema{Type: "integer", Format: "int64"}},
+	{Name: "since", In: "query", Description: "Only events at or after this RFC 3339 time", Schema: &openapi.Schema{Type: "string", Format: "date-time"}},
+	{Name: "until", In: "query", Description: "Only events before this RFC 3339 time", Schema: &openapi.Schema{Type: "string", Format: "date-time"}},
+	{Name: "before_id", In: "query", Description: "Only events older than this ID, for paging", Schema: &openapi.Schema{Type: "integer", Format: "int64"}},
+	{Name: "limit", In: "query", Description: "Page size, 1-1000 (default 100)", Schema: &openapi.Schema{Type: "integer"}},
+	{Name: "format", In: "query", Description: "csv downloads the page as text/csv", Schema: &openapi.Schema{Type: "string", Enum: []any{"json", "csv"}}},
+}
+
+// apiRoutes documents every route registered by New; routers_test.go fails
+// when the two drift apart
+var apiRoutes = []openapi.Route{
+	signUpRoute,
+	loginRoute,
+	unsubscribePageRoute,
+	unsubscribeRoute,
+
+	createCareerRoute,
+	listCareersRoute,
+	importCareersRoute,
+	careerImportRoute,
+	getCareerRoute,
+	updateCareerRoute,
+	patchCareerRoute,
+	deleteCareerRoute,
+	extendCareerRoute,
+	restoreCareerRoute,
+	careerRevisionsRoute,
+	careerRevisionRoute,
+	revisionDiffRoute,
+	revertCareerRoute,
+	careerSkillsRoute,
+	setCareerSkillsRoute,
+	candidateMatchesRoute,
+	careerSavesRoute,
+
+	createProfileRoute,
+	listProfilesRoute,
+	getProfileRoute,
+	updateProfileRoute,
+	patchProfileRoute,
+	deleteProfileRoute,
+	restoreProfileRoute,
+	profileSkillsRoute,
+	setProfileSkillsRoute,
+	careerMatchesRoute,
+	savedCareersRoute,
+	saveCareerRoute,
+	unsaveCareerRoute,
+	savedListsRoute,
+	createSearchRoute,
+	listSearchesRoute,
+	updateSearchRoute,
+	deleteSearchRoute,
+
+	usersEmailRoute,
+	deleteUserRoute,
+	restoreUserRoute,
+
+	trashRoute,
+	auditRoute,
+
+	createCompanyRoute,
+	listCompaniesRoute,
+	getCompanyRoute,
+	listRecruitersRoute,
+	addRecruiterRoute,
+	removeRecruiterRoute,
+
+	createSkillRoute,
+	listSkillsRoute,
+	addSkillAliasRoute,
+
+	legacy(signUpRoute, "/signup"),
+	legacy(loginRoute, "/login"),
+	legacy(createCareerRoute, "/createcareer"),
+	legacy(getCareerRoute, "/getcareerdetail/:id"),
+	legacy(listCareersRoute, "/get-all-career-details"),
+	legacy(updateCareerRoute, "/updatecareer/:id"),
+	legacy(deleteCareerRoute, "/deletecareer/:id"),
+	legacy(createProfileRoute, "/createprofile"),
+	legacy(getProfileRoute, "/getprofile/:id"),
+	legacy(listProfilesRoute, "/get-all-profile-details"),
+	legacy(deleteProfileRoute, "/delete-profile/:id"),
+	legacy(updateProfileRoute, "/update-profile/:id"),
+	legacy(usersEmailRoute, "/get-all-users-email"),
+
+	{Method: http.MethodGet, Path: "/openapi.json", Summary: "This OpenAPI document", Tag: "docs"},
+	{Method: http.MethodGet, Path: "/docs", Summary: "Swagger UI", Tag: "docs"},
+	{Method: http.MethodGet, Path: "/debug/vars", Summary: "Runtime and usage metrics (expvar) (admin)", Tag: "ops", Auth: true},
+}
+
+// legacy documents a deprecated alias of route
+func legacy(route openapi.Route, path string) openapi.Route {
+	route.Summary = "Deprecated alias of " + route.Method + " " + openapi.OpenAPIPath(route.Path)
+	route.Path = path
+	route.Tag = "legacy"
+	route.Deprecated = true
+	return route
+}
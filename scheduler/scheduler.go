@@ -0,0 +1,74 @@
+// Package scheduler runs background jobs at a fixed interval for as long as
+// the process lives. Runs of one job never overlap; a run that fails is
+// logged and counted, and the job carries on at the next tick.
+package scheduler
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"jobApps/metrics"
+)
+
+type Job struct {
+	// Name identifies the job in logs and in the job metrics
+	Name string
+	// Interval is the time between runs; zero disables the job
+	Interval time.Duration
+	Run      func(ctx context.Context) error
+}
+
+// Start runs each job in its own goroutine, the first time one interval
+// after Start, until ctx is cancelled
+func Start(ctx context.Context, jobs ...Job) {
+	for _, job := range jobs {
+		if job.Interval <= 0 {
+			slog.Info("job disabled", slog.String("job", job.Name))
+			continue
+		}
+		go loop(ctx, job)
+	}
+}
+
+func loop(ctx context.Context, job Job) {
+	ticker := time.NewTicker(job.Interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			run(ctx, job)
+		}
+	}
+}
+
+// run calls the job once, turning a panic into a failed run
+func run(ctx context.Context, job Job) {
+	start := time.Now()
+	logger := slog.With(slog.String("job", job.Name))
+
+	err := func() (err error) {
+		defer func() {
+			if recovered := recover(); recovered != nil {
+				err = panicError{recovered}
+			}
+		}()
+		return job.Run(ctx)
+	}()
+
+	metrics.JobRuns.Add(job.Name, 1)
+	if err != nil {
+		metrics.JobFailures.Add(job.Name, 1)
+		logger.Error("job failed", slog.Duration("duration", time.Since(start)), slog.Any("error", err))
+		return
+	}
+	logger.Debug("job finished", slog.Duration("duration", time.Since(start)))
+}
+
+type panicError struct{ value any }
+
+func (e panicError) Error() string {
+	return "panic: " + slog.AnyValue(e.value).String()
+}
@@ -0,0 +1,40 @@
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"jobApps/metrics"
+)
+
+func TestJobRunsEveryInterval(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	runs := make(chan struct{}, 10)
+	Start(ctx, Job{Name: "test_tick", Interval: 5 * time.Millisecond, Run: func(context.Context) error {
+		runs <- struct{}{}
+		return nil
+	}})
+
+	for i := 0; i < 3; i++ {
+		select {
+		case <-runs:
+		case <-time.After(time.Second):
+			t.Fatalf("job ran %d times, want 3", i)
+		}
+	}
+}
+
+func TestFailedRunsAreCounted(t *testing.T) {
+	run(context.Background(), Job{Name: "test_error", Run: func(context.Context) error { return errors.New("boom") }})
+	run(context.Background(), Job{Name: "test_panic", Run: func(context.Context) error { panic("boom") }})
+
+	for _, name := range []string{"test_error", "test_panic"} {
+		if got := metrics.JobFailures.Get(name); got == nil || got.String() != "1" {
+			t.Errorf("failures of %s = %v, want 1", name, got)
+		}
+	}
+}
@@ -0,0 +1,389 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/url"
+	"strings"
+	"text/template"
+	"time"
+
+	"jobApps/apperror"
+	"jobApps/audit"
+	"jobApps/dto"
+	"jobApps/internal/database"
+	"jobApps/logger"
+	"jobApps/notify"
+)
+
+// Saved searches are GET /careers queries a user keeps. SendJobAlerts, run by
+// the job alert scheduler, sends every user one digest of the careers
+// published since the last run that match their searches with alerts on. A
+// search remembers the last published_seq it was checked up to, so a career
+// is only ever in one digest for it, and a digest that cannot be sent is
+// retried at the next run. Each search in a digest links to
+// GET /alerts/unsubscribe, which shows the search; POSTing to the same URL
+// turns its alerts off without logging in.
+
+// SavedSearch is a saved search without its unsubscribe token
+type SavedSearch struct {
+	ID        int64     `json:"id"`
+	Name      string    `json:"name"`
+	Keywords  string    `json:"keywords"`
+	Jobtype   string    `json:"jobtype"`
+	Near      string    `json:"near"`
+	RadiusKm  *float64  `json:"radius_km"`
+	SalaryMin *int64    `json:"salary_min"`
+	Currency  *string   `json:"currency"`
+	Alerts    bool      `json:"alerts"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+func newSavedSearch(search database.SavedSearch) SavedSearch {
+	return SavedSearch{
+		ID:        search.ID,
+		Name:      search.Name,
+		Keywords:  search.Keywords,
+		Jobtype:   search.Jobtype,
+		Near:      search.Near,
+		RadiusKm:  search.RadiusKm,
+		SalaryMin: search.SalaryMin,
+		Currency:  search.Currency,
+		Alerts:    search.Alerts,
+		CreatedAt: search.CreatedAt,
+	}
+}
+
+// query is the GET /careers query the search runs
+func (search SavedSearch) query() dto.CareersQuery {
+	query := dto.CareersQuery{
+		Q:         search.Keywords,
+		Jobtype:   search.Jobtype,
+		Near:      search.Near,
+		RadiusKm:  search.RadiusKm,
+		SalaryMin: search.SalaryMin,
+	}
+	if search.Currency != nil {
+		query.Currency = *search.Currency
+	}
+	return query
+}
+
+// CreateSavedSearch saves a search for the caller. It alerts about careers
+// published from now on.
+func (s *Service) CreateSavedSearch(ctx context.Context, request dto.SavedSearchRequest) (SavedSearch, error) {
+	if _, err := s.careersParams(ctx, request.Query()); err != nil {
+		return SavedSearch{}, err
+	}
+	token, err := newUnsubscribeToken()
+	if err != nil {
+		return SavedSearch{}, err
+	}
+
+	var created database.SavedSearch
+	err = s.store.ExecTx(ctx, func(q database.TxQuerier) error {
+		user, err := q.GetUserByEmail(ctx, audit.ActorFrom(ctx).Email)
+		if err != nil {
+			return err
+		}
+		lastPublished, err := q.GetLastPublishedSeq(ctx)
+		if err != nil {
+			return err
+		}
+		if created, err = q.CreateSavedSearch(ctx, request.ToCreateParams(user.Userid, lastPublished, token)); err != nil {
+			return err
+		}
+		return record(ctx, q, audit.ActionCreate, audit.ResourceSavedSearch, created.ID, nil, newSavedSearch(created))
+	})
+	if err != nil {
+		return SavedSearch{}, apperror.FromDB(err, "saved search")
+	}
+	return newSavedSearch(created), nil
+}
+
+// UpdateSavedSearch replaces one of the caller's saved searches. Turning
+// alerts back on skips the careers published while they were off.
+func (s *Service) UpdateSavedSearch(ctx context.Context, searchID int64, request dto.SavedSearchRequest) (SavedSearch, error) {
+	if _, err := s.careersParams(ctx, request.Query()); err != nil {
+		return SavedSearch{}, err
+	}
+
+	var updated database.SavedSearch
+	err := s.store.ExecTx(ctx, func(q database.TxQuerier) error {
+		user, err := q.GetUserByEmail(ctx, audit.ActorFrom(ctx).Email)
+		if err != nil {
+			return err
+		}
+		existing, err := q.GetSavedSearchForUpdate(ctx, database.GetSavedSearchForUpdateParams{ID: searchID, Userid: user.Userid})
+		if err != nil {
+			return err
+		}
+		if updated, err = q.UpdateSavedSearch(ctx, request.ToUpdateParams(existing)); err != nil {
+			return err
+		}
+		if updated.Alerts && !existing.Alerts {
+			lastPublished, err := q.GetLastPublishedSeq(ctx)
+			if err != nil {
+				return err
+			}
+			if err := q.AdvanceSavedSearch(ctx, database.AdvanceSavedSearchParams{ID: updated.ID, LastPublishedSeq: lastPublished}); err != nil {
+				return err
+			}
+		}
+		return record(ctx, q, audit.ActionUpdate, audit.ResourceSavedSearch, updated.ID, newSavedSearch(existing), newSavedSearch(updated))
+	})
+	if err != nil {
+		return SavedSearch{}, apperror.FromDB(err, "saved search")
+	}
+	return newSavedSearch(updated), nil
+}
+
+// DeleteSavedSearch removes one of the caller's saved searches
+func (s *Service) DeleteSavedSearch(ctx context.Context, searchID int64) error {
+	err := s.store.ExecTx(ctx, func(q database.TxQuerier) error {
+		user, err := q.GetUserByEmail(ctx, audit.ActorFrom(ctx).Email)
+		if err != nil {
+			return err
+		}
+		deleted, err := q.DeleteSavedSearch(ctx, database.DeleteSavedSearchParams{ID: searchID, Userid: user.Userid})
+		if err != nil {
+			return err
+		}
+		return record(ctx, q, audit.ActionDelete, audit.ResourceSavedSearch, deleted.ID, newSavedSearch(deleted), nil)
+	})
+	return apperror.FromDB(err, "saved search")
+}
+
+// SavedSearches lists the caller's saved searches, oldest first
+func (s *Service) SavedSearches(ctx context.Context) ([]SavedSearch, error) {
+	user, err := s.store.GetUserByEmail(ctx, audit.ActorFrom(ctx).Email)
+	if err != nil {
+		return nil, apperror.FromDB(err, "user")
+	}
+	rows, err := s.store.ListSavedSearches(ctx, user.Userid)
+	if err != nil {
+		return nil, apperror.FromDB(err, "saved search")
+	}
+	searches := make([]SavedSearch, len(rows))
+	for i, row := range rows {
+		searches[i] = newSavedSearch(row)
+	}
+	return searches, nil
+}
+
+// SavedSearchByToken returns the saved search with the unsubscribe token,
+// for the page that asks to confirm unsubscribing. It changes nothing, since
+// mail scanners and link previews follow the link too.
+func (s *Service) SavedSearchByToken(ctx context.Context, token string) (SavedSearch, error) {
+	search, err := s.store.GetSavedSearchByToken(ctx, token)
+	if err != nil {
+		return SavedSearch{}, apperror.FromDB(err, "saved search")
+	}
+	return newSavedSearch(search), nil
+}
+
+// Unsubscribe turns off the alerts of the saved search with the token. It is
+// attributed to the search's owner, who follows the link without logging in.
+// Following the link again changes nothing.
+func (s *Service) Unsubscribe(ctx context.Context, token string) (SavedSearch, error) {
+	var search database.SavedSearch
+	err := s.store.ExecTx(ctx, func(q database.TxQuerier) error {
+		existing, err := q.GetSavedSearchByTokenForUpdate(ctx, token)
+		if err != nil {
+			return err
+		}
+		search = existing
+		if !existing.Alerts {
+			return nil
+		}
+		if search, err = q.DisableSavedSearchAlerts(ctx, existing.ID); err != nil {
+			return err
+		}
+		owner, err := q.GetUserByIdForUpdate(ctx, search.Userid)
+		if err != nil {
+			return err
+		}
+		actor := audit.ActorFrom(ctx)
+		actor.Email, actor.Role = owner.Email, owner.Role
+		return record(audit.WithActor(ctx, actor), q, audit.ActionUpdate, audit.ResourceSavedSearch, search.ID, newSavedSearch(existing), newSavedSearch(search))
+	})
+	if err != nil {
+		return SavedSearch{}, apperror.FromDB(err, "saved search")
+	}
+	return newSavedSearch(search), nil
+}
+
+func newUnsubscribeToken() (string, error) {
+	buf := make([]byte, 24)
+	if _, err := rand.Read(buf); err != nil {
+		return "", apperror.Internal(fmt.Errorf("generating unsubscribe token: %w", err))
+	}
+	return hex.EncodeToString(buf), nil
+}
+
+// JobAlert is a saved search in a digest with the careers newly matching it
+type JobAlert struct {
+	Search         SavedSearch       `json:"search"`
+	Careers        []database.Career `json:"careers"`
+	UnsubscribeURL string            `json:"unsubscribe_url"`
+}
+
+// JobAlertDigest is what one user is sent by a run of SendJobAlerts
+type JobAlertDigest struct {
+	Username string     `json:"username"`
+	Alerts   []JobAlert `json:"alerts"`
+}
+
+// AlertsSent counts what a run of SendJobAlerts sent
+type AlertsSent struct {
+	Digests int `json:"digests"`
+	Careers int `json:"careers"`
+}
+
+// SendJobAlerts sends each user with alerting searches a digest of the open
+// careers published since the previous run that match them. A search that cannot
+// be run, and every search of a user whose digest cannot be sent, is left to
+// the next run; the error returned joins the reasons.
+func (s *Service) SendJobAlerts(ctx context.Context) (AlertsSent, error) {
+	var sent AlertsSent
+	// careers published while the run is going wait for the next one
+	upTo, err := s.store.GetLastPublishedSeq(ctx)
+	if err != nil {
+		return sent, apperror.FromDB(err, "career")
+	}
+	rows, err := s.store.ListAlertingSearches(ctx)
+	if err != nil {
+		return sent, apperror.FromDB(err, "saved search")
+	}
+
+	var errs []error
+	for start := 0; start < len(rows); {
+		end := start + 1
+		for end < len(rows) && rows[end].SavedSearch.Userid == rows[start].SavedSearch.Userid {
+			end++
+		}
+		careers, err := s.sendDigest(ctx, rows[start:end], upTo)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("job alerts for user %d: %w", rows[start].SavedSearch.Userid, err))
+		}
+		if careers > 0 {
+			sent.Digests++
+			sent.Careers += careers
+		}
+		start = end
+	}
+	return sent, errors.Join(errs...)
+}
+
+// sendDigest runs the searches of one user, sends them the careers found and
+// moves the searches up to upTo, returning the number of careers sent; a
+// career matching several searches counts once
+func (s *Service) sendDigest(ctx context.Context, searches []database.ListAlertingSearchesRow, upTo int64) (int, error) {
+	digest := JobAlertDigest{Username: searches[0].Username}
+	var checked []int64
+	sent := map[int64]bool{}
+	var errs []error
+	for _, row := range searches {
+		search := row.SavedSearch
+		if search.LastPublishedSeq >= upTo {
+			continue
+		}
+		found, err := s.newMatches(ctx, search, upTo)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("saved search %d: %w", search.ID, err))
+			continue
+		}
+		checked = append(checked, search.ID)
+		if len(found) > 0 {
+			digest.Alerts = append(digest.Alerts, JobAlert{
+				Search:         newSavedSearch(search),
+				Careers:        found,
+				UnsubscribeURL: s.PublicURL + "/api/v1/alerts/unsubscribe?token=" + url.QueryEscape(search.UnsubscribeToken),
+			})
+			for _, career := range found {
+				sent[career.Jobid] = true
+			}
+		}
+	}
+
+	careers := len(sent)
+	if careers > 0 {
+		message, err := s.digestMessage(searches[0].Email, digest, careers)
+		if err != nil {
+			return 0, err
+		}
+		if err := s.Notifier.Notify(ctx, message); err != nil {
+			return 0, errors.Join(append(errs, err)...)
+		}
+		logger.FromContext(ctx).Info("job alert digest sent", slog.Int64("userid", searches[0].SavedSearch.Userid), slog.Int("careers", careers))
+	}
+
+	err := s.store.ExecTx(ctx, func(q database.TxQuerier) error {
+		for _, id := range checked {
+			if err := q.AdvanceSavedSearch(ctx, database.AdvanceSavedSearchParams{ID: id, LastPublishedSeq: upTo}); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		errs = append(errs, apperror.FromDB(err, "saved search"))
+	}
+	return careers, errors.Join(errs...)
+}
+
+// newMatches lists the open careers matching search published after its last
+// run, up to upTo, as a user sees them
+func (s *Service) newMatches(ctx context.Context, search database.SavedSearch, upTo int64) ([]database.Career, error) {
+	params, err := s.careersParams(ctx, newSavedSearch(search).query())
+	if err != nil {
+		return nil, err
+	}
+	params.PublishedAfter.Int64, params.PublishedAfter.Valid = search.LastPublishedSeq, true
+	careers, err := s.store.ListCareers(ctx, params)
+	if err != nil {
+		return nil, apperror.FromDB(err, "career")
+	}
+
+	var found []database.Career
+	for _, career := range careers {
+		if *career.PublishedSeq <= upTo {
+			found = append(found, career)
+		}
+	}
+	// the run has no actor, so salaries are hidden as from any user
+	return s.hideSalaries(audit.WithActor(ctx, audit.Actor{Role: "user"}), found)
+}
+
+var digestText = template.Must(template.New("digest").Parse(`Hello {{.Digest.Username}},
+
+new careers match your saved searches.
+{{range .Digest.Alerts}}
+{{.Search.Name}}
+{{range .Careers}}
+- {{.Position}} at {{.Company}} ({{.Jobtype}}), until {{.Enddate.Format "2 January 2006"}}
+  {{$.URL}}/api/v1/careers/{{.Jobid}}
+{{- end}}
+
+Stop alerts for {{.Search.Name}}: {{.UnsubscribeURL}}
+{{end}}`))
+
+func (s *Service) digestMessage(to string, digest JobAlertDigest, careers int) (notify.Message, error) {
+	var text strings.Builder
+	err := digestText.Execute(&text, struct {
+		Digest JobAlertDigest
+		URL    string
+	}{digest, s.PublicURL})
+	if err != nil {
+		return notify.Message{}, apperror.Internal(fmt.Errorf("rendering job alert digest: %w", err))
+	}
+	subject := "1 new career matches your saved searches"
+	if careers > 1 {
+		subject = fmt.Sprintf("%d new careers match your saved searches", careers)
+	}
+	return notify.Message{To: to, Subject: subject, Text: text.String(), Data: digest}, nil
+}
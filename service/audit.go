@@ -0,0 +1,37 @@
+package service
+
+import (
+	"context"
+
+	"jobApps/apperror"
+	"jobApps/audit"
+	"jobApps/internal/database"
+)
+
+// record writes an audit event for a change made by the actor in ctx. It
+// takes the transaction's querier so the event commits or rolls back with
+// the change itself. before is nil for creations.
+func record(ctx context.Context, q database.TxQuerier, action, resourceType string, resourceID int64, before, after any) error {
+	changes, err := audit.Diff(before, after)
+	if err != nil {
+		return apperror.Internal(err)
+	}
+
+	actor := audit.ActorFrom(ctx)
+	return q.CreateAuditEvent(ctx, database.CreateAuditEventParams{
+		ActorEmail:   actor.Email,
+		ActorRole:    actor.Role,
+		Action:       action,
+		ResourceType: resourceType,
+		ResourceID:   resourceID,
+		Changes:      changes,
+		RequestID:    actor.RequestID,
+		IP:           actor.IP,
+	})
+}
+
+// AuditEvents lists the matching audit events, newest first
+func (s *Service) AuditEvents(ctx context.Context, filter database.ListAuditEventsParams) ([]database.AuditEvent, error) {
+	events, err := s.store.ListAuditEvents(ctx, filter)
+	return events, apperror.FromDB(err, "audit event")
+}
@@ -0,0 +1,228 @@
+package service
+
+import (
+	"context"
+	"errors"
+
+	"jobApps/apperror"
+	"jobApps/audit"
+	"jobApps/dto"
+	"jobApps/internal/database"
+
+	"github.com/jackc/pgx/v4"
+)
+
+// A career belongs to a company, which is found by the slug of the company
+// name in the career so that spellings such as "ACME Inc" and "acme" share
+// one company. Admins manage every career; recruiters only those of the
+// companies they are members of.
+
+// CompanyPage is a company with its open careers
+type CompanyPage struct {
+	Company database.Company  `json:"company"`
+	Careers []database.Career `json:"careers"`
+}
+
+// companyRecruiters is the audited form of a company's membership
+type companyRecruiters struct {
+	Recruiters []int64 `json:"recruiters"`
+}
+
+func (s *Service) CreateCompany(ctx context.Context, request dto.CreateCompanyRequest) (database.Company, error) {
+	var company database.Company
+	err := s.store.ExecTx(ctx, func(q database.TxQuerier) error {
+		var err error
+		company, err = q.CreateCompany(ctx, request.ToParams())
+		if err != nil {
+			return err
+		}
+		return record(ctx, q, audit.ActionCreate, audit.ResourceCompany, company.ID, nil, company)
+	})
+	if err != nil {
+		return database.Company{}, apperror.FromDB(err, "company")
+	}
+	return company, nil
+}
+
+func (s *Service) Companies(ctx context.Context) ([]database.Company, error) {
+	companies, err := s.store.ListCompanies(ctx)
+	if err != nil {
+		return nil, apperror.FromDB(err, "company")
+	}
+	return append([]database.Company{}, companies...), nil
+}
+
+// CompanyPage returns the company with the careers that have not ended yet
+func (s *Service) CompanyPage(ctx context.Context, companySlug string) (CompanyPage, error) {
+	company, err := s.store.GetCompanyBySlug(ctx, companySlug)
+	if err != nil {
+		return CompanyPage{}, apperror.FromDB(err, "company")
+	}
+	careers, err := s.store.ListOpenCareersByCompany(ctx, company.ID)
+	if err != nil {
+		return CompanyPage{}, apperror.FromDB(err, "career")
+	}
+	careers, err = s.hideSalaries(ctx, careers)
+	if err != nil {
+		return CompanyPage{}, err
+	}
+	return CompanyPage{Company: company, Careers: append([]database.Career{}, careers...)}, nil
+}
+
+// CompanyRecruiters lists the company's recruiters
+func (s *Service) CompanyRecruiters(ctx context.Context, companySlug string) ([]Account, error) {
+	company, err := s.store.GetCompanyBySlug(ctx, companySlug)
+	if err != nil {
+		return nil, apperror.FromDB(err, "company")
+	}
+	users, err := s.store.ListCompanyRecruiters(ctx, company.ID)
+	if err != nil {
+		return nil, apperror.FromDB(err, "recruiter")
+	}
+	accounts := make([]Account, 0, len(users))
+	for _, user := range users {
+		accounts = append(accounts, newAccount(user))
+	}
+	return accounts, nil
+}
+
+// AddRecruiter makes the user, who must have the recruiter role, a member of
+// the company
+func (s *Service) AddRecruiter(ctx context.Context, companySlug string, userID int64) (Account, error) {
+	var user database.User
+	err := s.store.ExecTx(ctx, func(q database.TxQuerier) error {
+		company, err := q.GetCompanyBySlug(ctx, companySlug)
+		if err != nil {
+			return apperror.FromDB(err, "company")
+		}
+		user, err = q.GetUserByIdForUpdate(ctx, userID)
+		if err != nil {
+			return apperror.FromDB(err, "user")
+		}
+		if user.Role != "recruiter" {
+			return apperror.Conflict("only users with the recruiter role can join a company")
+		}
+
+		before, err := recruitersOf(ctx, q, company.ID)
+		if err != nil {
+			return err
+		}
+		if err := q.AddCompanyRecruiter(ctx, database.AddCompanyRecruiterParams{CompanyID: company.ID, Userid: userID}); err != nil {
+			return err
+		}
+		after, err := recruitersOf(ctx, q, company.ID)
+		if err != nil {
+			return err
+		}
+		return record(ctx, q, audit.ActionUpdate, audit.ResourceCompany, company.ID, before, after)
+	})
+	if err != nil {
+		return Account{}, apperror.FromDB(err, "recruiter")
+	}
+	return newAccount(user), nil
+}
+
+// RemoveRecruiter takes the user out of the company
+func (s *Service) RemoveRecruiter(ctx context.Context, companySlug string, userID int64) error {
+	err := s.store.ExecTx(ctx, func(q database.TxQuerier) error {
+		company, err := q.GetCompanyBySlug(ctx, companySlug)
+		if err != nil {
+			return apperror.FromDB(err, "company")
+		}
+		before, err := recruitersOf(ctx, q, company.ID)
+		if err != nil {
+			return err
+		}
+		removed, err := q.RemoveCompanyRecruiter(ctx, database.RemoveCompanyRecruiterParams{CompanyID: company.ID, Userid: userID})
+		if err != nil {
+			return err
+		}
+		if removed == 0 {
+			return apperror.NotFound("the user is not a recruiter of this company")
+		}
+		after, err := recruitersOf(ctx, q, company.ID)
+		if err != nil {
+			return err
+		}
+		return record(ctx, q, audit.ActionUpdate, audit.ResourceCompany, company.ID, before, after)
+	})
+	return apperror.FromDB(err, "recruiter")
+}
+
+func recruitersOf(ctx context.Context, q database.TxQuerier, companyID int64) (companyRecruiters, error) {
+	users, err := q.ListCompanyRecruiters(ctx, companyID)
+	if err != nil {
+		return companyRecruiters{}, err
+	}
+	ids := companyRecruiters{Recruiters: []int64{}}
+	for _, user := range users {
+		ids.Recruiters = append(ids.Recruiters, user.Userid)
+	}
+	return ids, nil
+}
+
+// companyNamed returns the company whose slug matches name, creating it if
+// there is none yet
+func companyNamed(ctx context.Context, q database.TxQuerier, name string) (database.Company, error) {
+	params := dto.CreateCompanyRequest{Name: name}.ToParams()
+	company, err := q.GetCompanyBySlug(ctx, params.Slug)
+	if !errors.Is(err, pgx.ErrNoRows) {
+		return company, err
+	}
+	company, err = q.CreateCompany(ctx, params)
+	if err != nil {
+		return database.Company{}, err
+	}
+	return company, record(ctx, q, audit.ActionCreate, audit.ResourceCompany, company.ID, nil, company)
+}
+
+// canManageCareers checks that the actor in ctx may create and change the
+// careers of the company
+func canManageCareers(ctx context.Context, q database.TxQuerier, companyID int64) error {
+	actor := audit.ActorFrom(ctx)
+	switch actor.Role {
+	case "admin":
+		return nil
+	case "recruiter":
+		member, err := q.IsCompanyRecruiter(ctx, database.IsCompanyRecruiterParams{CompanyID: companyID, Email: actor.Email})
+		if err != nil || member {
+			return err
+		}
+	}
+	return apperror.Forbidden("recruiters can only manage the careers of their own companies")
+}
+
+// placeCareer finds the company named in a career write and checks the
+// actor may post for it
+func placeCareer(ctx context.Context, q database.TxQuerier, companyName string) (database.Company, error) {
+	company, err := companyNamed(ctx, q, companyName)
+	if err != nil {
+		return database.Company{}, err
+	}
+	return company, canManageCareers(ctx, q, company.ID)
+}
+
+// placeAssignments resolves a change of company in a partial update of
+// existing. The company column takes the company's canonical name alongside
+// its ID; a new spelling of the current company changes nothing.
+func placeAssignments(ctx context.Context, q database.TxQuerier, existing database.Career, set []database.Assignment) ([]database.Assignment, error) {
+	placed := make([]database.Assignment, 0, len(set)+1)
+	for _, assignment := range set {
+		if assignment.Column != "company" {
+			placed = append(placed, assignment)
+			continue
+		}
+		name, _ := assignment.Value.(string)
+		company, err := placeCareer(ctx, q, name)
+		if err != nil {
+			return nil, err
+		}
+		if company.ID == existing.CompanyID && company.Name == existing.Company {
+			continue
+		}
+		placed = append(placed,
+			database.Assignment{Column: "company", Value: company.Name},
+			database.Assignment{Column: "company_id", Value: company.ID})
+	}
+	return placed, nil
+}
@@ -0,0 +1,54 @@
+package service
+
+import (
+	"context"
+
+	"jobApps/apperror"
+	"jobApps/audit"
+	"jobApps/internal/database"
+)
+
+// hideSalaries clears the salary of careers whose salary is not visible,
+// unless the actor in ctx is an admin or a recruiter of the career's company.
+// The equity flag stays visible. careers is changed in place.
+func (s *Service) hideSalaries(ctx context.Context, careers []database.Career) ([]database.Career, error) {
+	actor := audit.ActorFrom(ctx)
+	if actor.Role == "admin" {
+		return careers, nil
+	}
+
+	member := map[int64]bool{}
+	for i, career := range careers {
+		if !career.SalaryVisible && actor.Role == "recruiter" {
+			if _, checked := member[career.CompanyID]; !checked {
+				ok, err := s.store.IsCompanyRecruiter(ctx, database.IsCompanyRecruiterParams{CompanyID: career.CompanyID, Email: actor.Email})
+				if err != nil {
+					return nil, apperror.FromDB(err, "recruiter")
+				}
+				member[career.CompanyID] = ok
+			}
+		}
+		if !career.SalaryVisible && !member[career.CompanyID] {
+			careers[i].SalaryMin, careers[i].SalaryMax, careers[i].SalaryCurrency, careers[i].PayPeriod = nil, nil, nil, nil
+		}
+	}
+	return careers, nil
+}
+
+// checkSalaryCurrency rejects a salary in a currency without an exchange
+// rate, which the salary_currency foreign keys of career and profile would
+// report as a conflict
+func checkSalaryCurrency(ctx context.Context, q database.TxQuerier, currency *string) error {
+	if currency == nil {
+		return nil
+	}
+	known, err := q.HasExchangeRate(ctx, *currency)
+	if err != nil {
+		return err
+	}
+	if !known {
+		return apperror.Validation("request validation failed",
+			apperror.FieldError{Field: "salary_currency", Message: "has no exchange rate"})
+	}
+	return nil
+}
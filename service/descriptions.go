@@ -0,0 +1,20 @@
+package service
+
+import (
+	"jobApps/internal/database"
+	"jobApps/markdown"
+)
+
+// renderedDescription adds the HTML and excerpt of the description to the
+// assignments when they change it
+func renderedDescription(set []database.Assignment) []database.Assignment {
+	for _, assignment := range set {
+		if description, ok := assignment.Value.(string); ok && assignment.Column == "description" {
+			html, excerpt := markdown.Render(description)
+			return append(set,
+				database.Assignment{Column: "description_html", Value: html},
+				database.Assignment{Column: "description_excerpt", Value: excerpt})
+		}
+	}
+	return set
+}
@@ -0,0 +1,224 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"text/template"
+	"time"
+
+	"jobApps/apperror"
+	"jobApps/audit"
+	"jobApps/dto"
+	"jobApps/internal/database"
+	"jobApps/logger"
+	"jobApps/notify"
+)
+
+// A career expires once its enddate has passed: GET /careers leaves it out
+// unless include_expired is asked for, and the expiry job stamps its
+// closed_at. Before that, each admin is reminded once per end date of the
+// careers about to expire. Extending a career, or patching its enddate to
+// today or later, opens it again.
+
+// systemActor is who the changes of the expiry and publish jobs are
+// attributed to
+var systemActor = audit.Actor{Role: "system"}
+
+// ExpiringCareer is a career an expiry reminder is about
+type ExpiringCareer struct {
+	Career   database.Career `json:"career"`
+	DaysLeft int             `json:"days_left"`
+}
+
+// RemindersSent counts the careers reminded of and the admins reminded
+type RemindersSent struct {
+	Careers int `json:"careers"`
+	Admins  int `json:"admins"`
+}
+
+// today is the current date, as compared with a career's enddate
+func today() time.Time {
+	return time.Now().UTC().Truncate(24 * time.Hour)
+}
+
+// ExtendCareer moves the career's enddate to a later day if its version is
+// one of ifMatch, opening it again if it has closed
+func (s *Service) ExtendCareer(ctx context.Context, jobID int64, ifMatch Versions, request dto.ExtendCareerRequest) (database.Career, error) {
+	var career database.Career
+	err := s.store.ExecTx(ctx, func(q database.TxQuerier) error {
+		existing, err := q.GetCareerByJobIdForUpdate(ctx, jobID)
+		if err != nil {
+			return err
+		}
+		if err := ifMatch.check(existing.Version); err != nil {
+			return err
+		}
+		if err := canManageCareers(ctx, q, existing.CompanyID); err != nil {
+			return err
+		}
+
+		enddate := request.Enddate.UTC().Truncate(24 * time.Hour)
+		if !enddate.After(existing.Enddate) {
+			return apperror.Validation("the new enddate must be after "+existing.Enddate.Format(time.DateOnly),
+				apperror.FieldError{Field: "enddate", Message: "must be after the current enddate"})
+		}
+		if enddate.Before(today()) {
+			return apperror.Validation("the new enddate has already passed",
+				apperror.FieldError{Field: "enddate", Message: "must not be in the past"})
+		}
+
+		career, err = q.ExtendCareer(ctx, database.ExtendCareerParams{Jobid: jobID, Enddate: enddate})
+		if err != nil {
+			return err
+		}
+		if err := saveRevision(ctx, q, career); err != nil {
+			return err
+		}
+		return record(ctx, q, audit.ActionExtend, audit.ResourceCareer, jobID, existing, career)
+	})
+	if err != nil {
+		return database.Career{}, apperror.FromDB(err, "career")
+	}
+	return career, nil
+}
+
+// reopened adds clearing closed_at to the assignments when they move the
+// enddate of a closed career to today or later
+func reopened(existing database.Career, set []database.Assignment) []database.Assignment {
+	if existing.ClosedAt == nil {
+		return set
+	}
+	for _, assignment := range set {
+		if enddate, ok := assignment.Value.(time.Time); ok && assignment.Column == "enddate" && !enddate.Before(today()) {
+			return append(set, database.Assignment{Column: "closed_at", Value: (*time.Time)(nil)})
+		}
+	}
+	return set
+}
+
+// CloseExpiredCareers stamps closed_at on the careers whose enddate has
+// passed and returns them
+func (s *Service) CloseExpiredCareers(ctx context.Context) ([]database.Career, error) {
+	ctx = audit.WithActor(ctx, systemActor)
+	var closed []database.Career
+	err := s.store.ExecTx(ctx, func(q database.TxQuerier) error {
+		var err error
+		closed, err = q.CloseExpiredCareers(ctx)
+		if err != nil {
+			return err
+		}
+		for _, career := range closed {
+			open := career
+			open.ClosedAt, open.Version = nil, career.Version-1
+			if err := record(ctx, q, audit.ActionClose, audit.ResourceCareer, career.Jobid, open, career); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, apperror.FromDB(err, "career")
+	}
+	return closed, nil
+}
+
+// SendExpiryReminders tells every admin about the careers ending within days
+// that they have not been reminded of for their current enddate. Each
+// admin's reminders are marked as sent once their own delivery succeeds, so
+// a failed delivery is retried at the next run for that admin alone.
+func (s *Service) SendExpiryReminders(ctx context.Context, days int) (RemindersSent, error) {
+	var sent RemindersSent
+	admins, err := s.store.ListAdmins(ctx)
+	if err != nil {
+		return sent, apperror.FromDB(err, "user")
+	}
+	if len(admins) == 0 {
+		logger.FromContext(ctx).Warn("no admin to remind of expiring careers")
+		return sent, nil
+	}
+
+	now := today()
+	reminded := map[int64]bool{}
+	var errs []error
+	for _, admin := range admins {
+		careers, err := s.store.ListExpiringCareers(ctx, database.ListExpiringCareersParams{Userid: admin.Userid, Until: now.AddDate(0, 0, days)})
+		if err != nil {
+			return sent, apperror.FromDB(err, "career")
+		}
+		if len(careers) == 0 {
+			continue
+		}
+
+		expiring := make([]ExpiringCareer, len(careers))
+		for i, career := range careers {
+			expiring[i] = ExpiringCareer{Career: career, DaysLeft: int(career.Enddate.Sub(now).Hours() / 24)}
+		}
+		message, err := s.expiryMessage(admin.Email, expiring, days)
+		if err != nil {
+			return sent, err
+		}
+		if err := s.Notifier.Notify(ctx, message); err != nil {
+			// the error is logged, so the admin is named by id, not email
+			errs = append(errs, fmt.Errorf("expiry reminder to user %d: %w", admin.Userid, err))
+			continue
+		}
+
+		err = s.store.ExecTx(ctx, func(q database.TxQuerier) error {
+			for _, career := range careers {
+				if err := q.SaveExpiryReminder(ctx, database.SaveExpiryReminderParams{Jobid: career.Jobid, Userid: admin.Userid, Enddate: career.Enddate}); err != nil {
+					return err
+				}
+			}
+			return nil
+		})
+		if err != nil {
+			return sent, apperror.FromDB(err, "career")
+		}
+		sent.Admins++
+		for _, career := range careers {
+			reminded[career.Jobid] = true
+		}
+	}
+	sent.Careers = len(reminded)
+	return sent, errors.Join(errs...)
+}
+
+var expiryText = template.Must(template.New("expiry").Funcs(template.FuncMap{"left": daysLeft}).Parse(`Hello,
+
+these postings expire soon. Extend them with POST /api/v1/careers/:id/extend to keep them listed.
+{{range .Careers}}
+- {{.Career.Position}} at {{.Career.Company}} expires {{left .DaysLeft}}, on {{.Career.Enddate.Format "2 January 2006"}}
+  {{$.URL}}/api/v1/careers/{{.Career.Jobid}}
+{{- end}}
+`))
+
+// daysLeft says when a career expires
+func daysLeft(days int) string {
+	switch days {
+	case 0:
+		return "today"
+	case 1:
+		return "tomorrow"
+	default:
+		return fmt.Sprintf("in %d days", days)
+	}
+}
+
+func (s *Service) expiryMessage(to string, careers []ExpiringCareer, days int) (notify.Message, error) {
+	var text strings.Builder
+	err := expiryText.Execute(&text, struct {
+		Careers []ExpiringCareer
+		URL     string
+	}{careers, s.PublicURL})
+	if err != nil {
+		return notify.Message{}, apperror.Internal(fmt.Errorf("rendering expiry reminder: %w", err))
+	}
+	subject := fmt.Sprintf("%d postings expire within %d days", len(careers), days)
+	if len(careers) == 1 {
+		career := careers[0]
+		subject = fmt.Sprintf("%s at %s expires %s", career.Career.Position, career.Career.Company, daysLeft(career.DaysLeft))
+	}
+	return notify.Message{To: to, Subject: subject, Text: text.String(), Data: careers}, nil
+}
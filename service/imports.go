@@ -0,0 +1,278 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"jobApps/apperror"
+	"jobApps/audit"
+	"jobApps/careerimport"
+	"jobApps/dto"
+	"jobApps/internal/database"
+	"jobApps/logger"
+	"jobApps/validation"
+
+	"github.com/jackc/pgx/v4"
+)
+
+// A career import creates the careers of a CSV or JSON Lines file, each
+// checked like a POST /careers. In atomic mode the file is imported in one
+// transaction, which is rolled back if any row fails; each row runs in a
+// savepoint, so a row failing in the database does not keep the rows after
+// it from being checked. In best_effort mode each row is committed on its
+// own and failing rows are skipped. A dry run checks every row like an
+// atomic import that is always rolled back. Files of more than
+// ImportSyncRows rows are imported in the background, and the
+// career_imports row reports their progress.
+
+// Statuses of a career import
+const (
+	ImportSucceeded = "succeeded"
+	ImportPartial   = "partial"
+	ImportFailed    = "failed"
+)
+
+// importProgressInterval is how often the progress of an import is recorded
+const importProgressInterval = time.Second
+
+// errImportRolledBack rolls back the transaction of an atomic import with
+// failing rows, or of a dry run
+var errImportRolledBack = errors.New("import rolled back")
+
+// ImportRowError is why a row of an import file was not, or would not be,
+// imported
+type ImportRowError struct {
+	Line   int                   `json:"line"`
+	Detail string                `json:"detail"`
+	Errors []apperror.FieldError `json:"errors,omitempty"`
+}
+
+// ImportCareers reads the careers of file and imports them. It reports
+// whether the import goes on in the background, in which case the import
+// returned is still running.
+func (s *Service) ImportCareers(ctx context.Context, query dto.ImportCareersQuery, file io.Reader) (database.CareerImport, bool, error) {
+	parsed, err := careerimport.Parse(file, query.Format, query.Mapping)
+	if err != nil {
+		return database.CareerImport{}, false, err
+	}
+	job, err := s.store.CreateCareerImport(ctx, query.ToParams(len(parsed.Rows), parsed.Ignored, audit.ActorFrom(ctx).Email))
+	if err != nil {
+		return database.CareerImport{}, false, apperror.FromDB(err, "career import")
+	}
+
+	// the import outlives the request, and a request that times out still
+	// records how the import ended
+	ctx = context.WithoutCancel(ctx)
+	if query.Async || len(parsed.Rows) > s.ImportSyncRows {
+		go func() {
+			if _, err := s.runImport(ctx, job, parsed.Rows); err != nil {
+				logger.FromContext(ctx).Error("career import failed", slog.Int64("import", job.ID), slog.Any("error", err))
+			}
+		}()
+		return job, true, nil
+	}
+	job, err = s.runImport(ctx, job, parsed.Rows)
+	return job, false, err
+}
+
+// CareerImport returns an import to the admins and to whoever started it
+func (s *Service) CareerImport(ctx context.Context, id int64) (database.CareerImport, error) {
+	job, err := s.store.GetCareerImport(ctx, id)
+	if err != nil {
+		return database.CareerImport{}, apperror.FromDB(err, "career import")
+	}
+	if actor := audit.ActorFrom(ctx); actor.Role != "admin" && actor.Email != job.CreatedBy {
+		return database.CareerImport{}, apperror.FromDB(pgx.ErrNoRows, "career import")
+	}
+	return job, nil
+}
+
+// runImport imports the rows and records the outcome on job
+func (s *Service) runImport(ctx context.Context, job database.CareerImport, rows []careerimport.Row) (database.CareerImport, error) {
+	var processed atomic.Int32
+	stop := s.reportImportProgress(ctx, job.ID, &processed)
+	created, failed := s.importRows(ctx, job, rows, &processed)
+	stop()
+
+	committed := !job.DryRun && (job.Mode == "best_effort" || len(failed) == 0)
+	finished := importOutcome(job, len(rows), created, failed, committed)
+	errorsJSON, err := json.Marshal(failed)
+	if err != nil {
+		return database.CareerImport{}, apperror.Internal(fmt.Errorf("encoding import errors: %w", err))
+	}
+	finished.Errors = errorsJSON
+	job, err = s.store.FinishCareerImport(ctx, finished)
+	if err != nil {
+		return database.CareerImport{}, apperror.FromDB(err, "career import")
+	}
+
+	for _, career := range created {
+		if !committed || career.PublishedAt == nil {
+			continue
+		}
+		if err := s.announce(ctx, career); err != nil {
+			logger.FromContext(ctx).Warn("announcing imported career", slog.Int64("jobid", career.Jobid), slog.Any("error", err))
+		}
+	}
+	return job, nil
+}
+
+// importRows creates the careers of rows, counting the rows done in
+// processed. It returns the careers created, or that would be created in a
+// dry run or an atomic import that failed, and the rows that failed.
+func (s *Service) importRows(ctx context.Context, job database.CareerImport, rows []careerimport.Row, processed *atomic.Int32) ([]database.Career, []ImportRowError) {
+	var (
+		created []database.Career
+		failed  []ImportRowError
+	)
+	if job.Mode == "best_effort" && !job.DryRun {
+		for i, row := range rows {
+			var career database.Career
+			err := s.store.ExecTx(ctx, func(q database.TxQuerier) error {
+				var err error
+				career, err = importCareer(ctx, q, row)
+				return err
+			})
+			if err != nil {
+				failed = append(failed, importRowError(ctx, row.Line, apperror.FromDB(err, "career")))
+			} else {
+				created = append(created, career)
+			}
+			processed.Store(int32(i + 1))
+		}
+		return created, failed
+	}
+
+	err := s.store.ExecTx(ctx, func(q database.TxQuerier) error {
+		// the transaction is run again from the start after a serialization
+		// failure
+		created, failed = nil, nil
+		for i, row := range rows {
+			var career database.Career
+			// a row failing in the database only rolls back to its savepoint,
+			// so the rows after it are still checked
+			err := database.Savepoint(ctx, q, func(q database.TxQuerier) error {
+				var err error
+				career, err = importCareer(ctx, q, row)
+				return err
+			})
+			switch {
+			case err == nil:
+				created = append(created, career)
+			case database.Retryable(err):
+				return err
+			default:
+				failed = append(failed, importRowError(ctx, row.Line, apperror.FromDB(err, "career")))
+			}
+			processed.Store(int32(i + 1))
+		}
+		if job.DryRun || len(failed) > 0 {
+			return errImportRolledBack
+		}
+		return nil
+	})
+	if err != nil && !errors.Is(err, errImportRolledBack) {
+		// line 0 stands for the whole file
+		created, failed = nil, append(failed, importRowError(ctx, 0, apperror.FromDB(err, "career")))
+	}
+	return created, failed
+}
+
+// reportImportProgress writes the count of processed rows to the import every
+// importProgressInterval until stop is called. The count is kept in memory,
+// so a transaction run again leaves no progress writes behind.
+func (s *Service) reportImportProgress(ctx context.Context, id int64, processed *atomic.Int32) (stop func()) {
+	done := make(chan struct{})
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		ticker := time.NewTicker(importProgressInterval)
+		defer ticker.Stop()
+		var written int32
+		for {
+			select {
+			case <-done:
+				return
+			case <-ticker.C:
+			}
+			n := processed.Load()
+			if n == written {
+				continue
+			}
+			err := s.store.UpdateCareerImportProgress(ctx, database.UpdateCareerImportProgressParams{ID: id, ProcessedRows: n})
+			if err != nil {
+				logger.FromContext(ctx).Warn("recording career import progress", slog.Int64("import", id), slog.Any("error", err))
+				continue
+			}
+			written = n
+		}
+	}()
+	return func() {
+		close(done)
+		wg.Wait()
+	}
+}
+
+// importOutcome is how an import of total rows ended, given the careers it
+// created, or would create in a dry run, the rows that failed and whether the
+// careers were committed
+func importOutcome(job database.CareerImport, total int, created []database.Career, failed []ImportRowError, committed bool) database.FinishCareerImportParams {
+	finished := database.FinishCareerImportParams{
+		ID:            job.ID,
+		ProcessedRows: int32(total),
+		CreatedRows:   int32(len(created)),
+		FailedRows:    int32(len(failed)),
+		Jobids:        []int64{},
+	}
+	if committed {
+		for _, career := range created {
+			finished.Jobids = append(finished.Jobids, career.Jobid)
+		}
+	}
+	// an atomic import with a failing row creates nothing
+	atomicFailure := job.Mode != "best_effort" && len(failed) > 0
+	if atomicFailure && !job.DryRun {
+		finished.CreatedRows = 0
+	}
+	switch {
+	case len(failed) == 0:
+		finished.Status = ImportSucceeded
+	case atomicFailure || len(created) == 0:
+		finished.Status = ImportFailed
+	default:
+		finished.Status = ImportPartial
+	}
+	return finished
+}
+
+// importCareer checks a row like a POST /careers body and creates its career
+func importCareer(ctx context.Context, q database.TxQuerier, row careerimport.Row) (database.Career, error) {
+	if row.Err != nil {
+		return database.Career{}, row.Err
+	}
+	if err := validation.Struct(&row.Request); err != nil {
+		return database.Career{}, err
+	}
+	return createCareer(ctx, q, row.Request)
+}
+
+// importRowError describes err for the row on line; the cause of an
+// unexpected error is logged rather than reported
+func importRowError(ctx context.Context, line int, err error) ImportRowError {
+	var appErr *apperror.Error
+	if !errors.As(err, &appErr) {
+		appErr = apperror.Internal(err)
+	}
+	if appErr.Kind == apperror.KindInternal {
+		logger.FromContext(ctx).Error("importing career", slog.Int("line", line), slog.Any("error", err))
+	}
+	return ImportRowError{Line: line, Detail: appErr.Detail, Errors: appErr.Fields}
+}
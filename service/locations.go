@@ -0,0 +1,39 @@
+package service
+
+import (
+	"fmt"
+
+	"jobApps/apperror"
+	"jobApps/geo"
+)
+
+// geocode fills in the coordinates of every location from the gazetteer,
+// rejecting cities it does not know unless lat and lon are given
+func geocode(locations geo.Locations) (geo.Locations, error) {
+	geocoded := make(geo.Locations, len(locations))
+	var fields []apperror.FieldError
+	for i, location := range locations {
+		var ok bool
+		if geocoded[i], ok = geo.Geocode(location); !ok {
+			fields = append(fields, apperror.FieldError{
+				Field:   fmt.Sprintf("locations[%d].city", i),
+				Message: "is not in the gazetteer; give lat and lon",
+			})
+		}
+	}
+	if len(fields) > 0 {
+		return nil, apperror.Validation("request validation failed", fields...)
+	}
+	return geocoded, nil
+}
+
+// checkRemoteCountries rejects allowed countries on an onsite career, which
+// the career_remote_countries_check constraint would report as a bad request
+// without saying which field is wrong
+func checkRemoteCountries(policy string, countries []string) error {
+	if policy == "onsite" && len(countries) > 0 {
+		return apperror.Validation("request validation failed",
+			apperror.FieldError{Field: "remote_countries", Message: "must be empty for an onsite career"})
+	}
+	return nil
+}
@@ -0,0 +1,113 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"jobApps/apperror"
+	"jobApps/audit"
+	"jobApps/internal/database"
+
+	"github.com/jackc/pgx/v4"
+)
+
+// A career is published at its publish_at. One created with publish_at in
+// the past is published right away; the others wait for PublishDueCareers,
+// run by the publish job. Users see a career only once it is published, and
+// the career webhook is sent at that moment, by whichever of the two
+// published it.
+
+// webhookTimeout bounds sending one career webhook
+const webhookTimeout = 10 * time.Second
+
+// webhookClient sends the career webhook
+var webhookClient = &http.Client{Timeout: webhookTimeout}
+
+// seesUnpublished reports whether the actor in ctx may see careers that are
+// not published yet
+func seesUnpublished(ctx context.Context) bool {
+	role := audit.ActorFrom(ctx).Role
+	return role == "admin" || role == "recruiter"
+}
+
+// checkPublished hides a career that is not published yet from users, as if
+// it did not exist
+func checkPublished(ctx context.Context, career database.Career) error {
+	if career.PublishedAt == nil && !seesUnpublished(ctx) {
+		return pgx.ErrNoRows
+	}
+	return nil
+}
+
+// checkPublishAt rejects scheduling a career for after its last day
+func checkPublishAt(params database.CreateCareerParams) error {
+	if !params.PublishAt.After(time.Now()) || params.PublishAt.Before(params.Enddate.AddDate(0, 0, 1)) {
+		return nil
+	}
+	return apperror.Validation("the career would be published after its enddate",
+		apperror.FieldError{Field: "publish_at", Message: "must not be after enddate"})
+}
+
+// PublishDueCareers publishes the careers whose publish_at has come and sends
+// the career webhook for each. The careers stay published when a webhook
+// fails; the error returned joins the failures.
+func (s *Service) PublishDueCareers(ctx context.Context) ([]database.Career, error) {
+	ctx = audit.WithActor(ctx, systemActor)
+	var published []database.Career
+	err := s.store.ExecTx(ctx, func(q database.TxQuerier) error {
+		var err error
+		published, err = q.PublishDueCareers(ctx)
+		if err != nil {
+			return err
+		}
+		for _, career := range published {
+			scheduled := career
+			scheduled.PublishedAt, scheduled.PublishedSeq, scheduled.Version = nil, nil, career.Version-1
+			if err := record(ctx, q, audit.ActionPublish, audit.ResourceCareer, career.Jobid, scheduled, career); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, apperror.FromDB(err, "career")
+	}
+
+	var errs []error
+	for _, career := range published {
+		if err := s.announce(ctx, career); err != nil {
+			errs = append(errs, fmt.Errorf("career %d: %w", career.Jobid, err))
+		}
+	}
+	return published, errors.Join(errs...)
+}
+
+// announce posts the career to CareerWebhookURL and expects a 2xx response
+func (s *Service) announce(ctx context.Context, career database.Career) error {
+	if s.CareerWebhookURL == "" {
+		return nil
+	}
+	body, err := json.Marshal(&career)
+	if err != nil {
+		return fmt.Errorf("encoding webhook notification: %w", err)
+	}
+	request, err := http.NewRequestWithContext(ctx, http.MethodPost, s.CareerWebhookURL, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("building webhook notification: %w", err)
+	}
+	request.Header.Set("Content-Type", "application/json")
+	response, err := webhookClient.Do(request)
+	if err != nil {
+		return fmt.Errorf("sending webhook notification: %w", err)
+	}
+	defer response.Body.Close()
+	if response.StatusCode/100 != 2 {
+		return fmt.Errorf("sending webhook notification: %s answered %s", s.CareerWebhookURL, response.Status)
+	}
+	return nil
+}
@@ -0,0 +1,345 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"jobApps/apperror"
+	"jobApps/audit"
+	"jobApps/dto"
+	"jobApps/internal/database"
+	"jobApps/recommend"
+)
+
+// Recommendations score every candidate of a ranking in Go, which is too slow
+// to repeat on every request. Rankings are cached until the next committed
+// transaction: nearly any write can change one (a career's skills, a
+// profile's expected salary, a deleted user), so rather than tracking which
+// rankings a write affects, every commit drops them all. Entries also expire
+// after recommendationTTL, since careers close as days pass and exchange
+// rates are changed by hand.
+
+// CareerRecommendation is an open career scored against the caller's profile
+type CareerRecommendation struct {
+	Career  database.Career    `json:"career"`
+	Score   float64            `json:"score"`
+	Factors []recommend.Factor `json:"factors"`
+}
+
+// CandidateRecommendation is a profile scored against a career
+type CandidateRecommendation struct {
+	Profile database.Profile   `json:"profile"`
+	Score   float64            `json:"score"`
+	Factors []recommend.Factor `json:"factors"`
+}
+
+// RecommendedCareers ranks the open careers by how well they fit the
+// caller's profile, best first. Hidden salaries are not shown to the caller
+// and so are not scored either.
+func (s *Service) RecommendedCareers(ctx context.Context, query dto.RecommendationsQuery) ([]CareerRecommendation, error) {
+	user, err := s.store.GetUserByEmail(ctx, audit.ActorFrom(ctx).Email)
+	if err != nil {
+		return nil, apperror.FromDB(err, "user")
+	}
+	profile, err := s.store.GetProfileByuserId(ctx, user.Userid)
+	if err != nil {
+		return nil, apperror.FromDB(err, "profile")
+	}
+
+	key := fmt.Sprintf("careers/%d", profile.Profileid)
+	ranking, generation, ok := s.recommendations.get(key)
+	if !ok {
+		ranking, err = s.rankCareers(ctx, profile)
+		if err != nil {
+			return nil, err
+		}
+		s.recommendations.put(key, generation, ranking)
+	}
+	return top(ranking.([]CareerRecommendation), query, func(r CareerRecommendation) float64 { return r.Score }), nil
+}
+
+func (s *Service) rankCareers(ctx context.Context, profile database.Profile) ([]CareerRecommendation, error) {
+	rates, err := s.exchangeRates(ctx)
+	if err != nil {
+		return nil, err
+	}
+	skills, err := s.store.ListProfileSkills(ctx, profile.Profileid)
+	if err != nil {
+		return nil, apperror.FromDB(err, "skill")
+	}
+	levels := make([]recommend.SkillLevel, len(skills))
+	for i, skill := range skills {
+		levels[i] = recommend.SkillLevel{SkillID: skill.SkillID, Proficiency: skill.Proficiency}
+	}
+	candidate := rates.candidate(profile, levels)
+
+	careers, err := s.store.ListOpenCareers(ctx)
+	if err != nil {
+		return nil, apperror.FromDB(err, "career")
+	}
+	if careers, err = s.hideSalaries(ctx, careers); err != nil {
+		return nil, err
+	}
+	requirements, err := s.careerRequirements(ctx, careers)
+	if err != nil {
+		return nil, err
+	}
+
+	ranking := make([]CareerRecommendation, len(careers))
+	for i, career := range careers {
+		match := recommend.Score(rates.job(career, requirements[career.Jobid]), candidate)
+		ranking[i] = CareerRecommendation{Career: career, Score: match.Score, Factors: match.Factors}
+	}
+	sort.SliceStable(ranking, func(i, j int) bool { return ranking[i].Score > ranking[j].Score })
+	return ranking, nil
+}
+
+// RecommendedCandidates ranks the profiles by how well they fit the career,
+// best first
+func (s *Service) RecommendedCandidates(ctx context.Context, jobID int64, query dto.RecommendationsQuery) ([]CandidateRecommendation, error) {
+	career, err := s.store.GetCareerByJobId(ctx, jobID)
+	if err != nil {
+		return nil, apperror.FromDB(err, "career")
+	}
+
+	key := fmt.Sprintf("candidates/%d", jobID)
+	ranking, generation, ok := s.recommendations.get(key)
+	if !ok {
+		ranking, err = s.rankCandidates(ctx, career)
+		if err != nil {
+			return nil, err
+		}
+		s.recommendations.put(key, generation, ranking)
+	}
+	return top(ranking.([]CandidateRecommendation), query, func(r CandidateRecommendation) float64 { return r.Score }), nil
+}
+
+func (s *Service) rankCandidates(ctx context.Context, career database.Career) ([]CandidateRecommendation, error) {
+	rates, err := s.exchangeRates(ctx)
+	if err != nil {
+		return nil, err
+	}
+	requirements, err := s.careerRequirements(ctx, []database.Career{career})
+	if err != nil {
+		return nil, err
+	}
+	job := rates.job(career, requirements[career.Jobid])
+
+	profiles, err := s.store.GetAllProfileDetails(ctx)
+	if err != nil {
+		return nil, apperror.FromDB(err, "profile")
+	}
+	ids := make([]int64, len(profiles))
+	for i, profile := range profiles {
+		ids[i] = profile.Profileid
+	}
+	skills, err := s.store.ListSkillsOfProfiles(ctx, ids)
+	if err != nil {
+		return nil, apperror.FromDB(err, "skill")
+	}
+	levels := map[int64][]recommend.SkillLevel{}
+	for _, skill := range skills {
+		levels[skill.Profileid] = append(levels[skill.Profileid], recommend.SkillLevel{SkillID: skill.SkillID, Proficiency: skill.Proficiency})
+	}
+
+	ranking := make([]CandidateRecommendation, len(profiles))
+	for i, profile := range profiles {
+		match := recommend.Score(job, rates.candidate(profile, levels[profile.Profileid]))
+		ranking[i] = CandidateRecommendation{Profile: profile, Score: match.Score, Factors: match.Factors}
+	}
+	sort.SliceStable(ranking, func(i, j int) bool {
+		if ranking[i].Score != ranking[j].Score {
+			return ranking[i].Score > ranking[j].Score
+		}
+		return ranking[i].Profile.Profileid < ranking[j].Profile.Profileid
+	})
+	return ranking, nil
+}
+
+// careerRequirements looks up the skills of careers, by jobid
+func (s *Service) careerRequirements(ctx context.Context, careers []database.Career) (map[int64][]recommend.SkillRequirement, error) {
+	ids := make([]int64, len(careers))
+	for i, career := range careers {
+		ids[i] = career.Jobid
+	}
+	skills, err := s.store.ListSkillsOfCareers(ctx, ids)
+	if err != nil {
+		return nil, apperror.FromDB(err, "skill")
+	}
+	requirements := map[int64][]recommend.SkillRequirement{}
+	for _, skill := range skills {
+		requirements[skill.Jobid] = append(requirements[skill.Jobid], recommend.SkillRequirement{
+			SkillID:  skill.SkillID,
+			Name:     skill.Name,
+			Required: skill.Required,
+			MinYears: skill.MinYears,
+		})
+	}
+	return requirements, nil
+}
+
+// top returns the first query.Limit recommendations scoring at least
+// query.MinScore; ranking is shared with the cache and is not changed
+func top[T any](ranking []T, query dto.RecommendationsQuery, score func(T) float64) []T {
+	limit := query.Limit
+	if limit == 0 {
+		limit = dto.DefaultRecommendationLimit
+	}
+	recommendations := []T{}
+	for _, recommendation := range ranking {
+		if len(recommendations) == int(limit) || query.MinScore != nil && score(recommendation) < *query.MinScore {
+			break
+		}
+		recommendations = append(recommendations, recommendation)
+	}
+	return recommendations
+}
+
+// rates converts salaries to yearly amounts in base, like the yearly_salary
+// SQL function
+type rates struct {
+	base        string
+	unitsPerUSD map[string]float64
+}
+
+func (s *Service) exchangeRates(ctx context.Context) (rates, error) {
+	rows, err := s.store.ListExchangeRates(ctx)
+	if err != nil {
+		return rates{}, apperror.FromDB(err, "exchange rate")
+	}
+	r := rates{base: s.BaseCurrency, unitsPerUSD: make(map[string]float64, len(rows))}
+	for _, row := range rows {
+		r.unitsPerUSD[row.Currency] = row.UnitsPerUsd
+	}
+	return r, nil
+}
+
+// hoursPerYear is the working hours an hourly salary is paid for in a year
+const hoursPerYear = 2080
+
+// yearly converts amount, paid per payPeriod in currency, to a yearly amount
+// in the base currency. It returns nil when either currency has no rate.
+func (r rates) yearly(amount int64, payPeriod, currency string) *float64 {
+	from, ok := r.unitsPerUSD[currency]
+	to, baseOK := r.unitsPerUSD[r.base]
+	if !ok || !baseOK {
+		return nil
+	}
+	yearly := float64(amount)
+	switch payPeriod {
+	case "hourly":
+		yearly *= hoursPerYear
+	case "monthly":
+		yearly *= 12
+	}
+	yearly = yearly / from * to
+	return &yearly
+}
+
+func (r rates) job(career database.Career, skills []recommend.SkillRequirement) recommend.Job {
+	job := recommend.Job{
+		Skills:          skills,
+		Locations:       career.Locations,
+		RemotePolicy:    career.RemotePolicy,
+		RemoteCountries: career.RemoteCountries,
+	}
+	if career.SalaryMin != nil && career.SalaryMax != nil && career.SalaryCurrency != nil && career.PayPeriod != nil {
+		job.SalaryMin = r.yearly(*career.SalaryMin, *career.PayPeriod, *career.SalaryCurrency)
+		job.SalaryMax = r.yearly(*career.SalaryMax, *career.PayPeriod, *career.SalaryCurrency)
+	}
+	if career.Seniority != nil {
+		job.Seniority = *career.Seniority
+	}
+	return job
+}
+
+func (r rates) candidate(profile database.Profile, skills []recommend.SkillLevel) recommend.Candidate {
+	candidate := recommend.Candidate{
+		Skills:           skills,
+		Locations:        profile.Locations,
+		RemotePreference: profile.RemotePreference,
+		YearsExperience:  profile.YearsExperience,
+	}
+	if profile.ExpectedSalary != nil && profile.SalaryCurrency != nil {
+		candidate.ExpectedSalary = r.yearly(*profile.ExpectedSalary, "yearly", *profile.SalaryCurrency)
+	}
+	return candidate
+}
+
+// recommendationTTL bounds how long a ranking is cached without any write
+const recommendationTTL = 10 * time.Minute
+
+// maxCachedRankings bounds the cache; it is emptied when full
+const maxCachedRankings = 1000
+
+// rankingCache holds rankings by key until invalidate is called or they
+// expire. Each invalidation starts a new generation, and a ranking computed
+// during an older generation is not stored, since it may have read the data
+// from before the write.
+type rankingCache struct {
+	mu         sync.Mutex
+	generation uint64
+	entries    map[string]cachedRanking
+}
+
+type cachedRanking struct {
+	ranking any
+	expires time.Time
+}
+
+func newRankingCache() *rankingCache {
+	return &rankingCache{entries: map[string]cachedRanking{}}
+}
+
+// get returns the ranking cached under key, or the generation to store a
+// newly computed one with
+func (c *rankingCache) get(key string) (ranking any, generation uint64, ok bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	entry, ok := c.entries[key]
+	if ok && time.Now().After(entry.expires) {
+		delete(c.entries, key)
+		ok = false
+	}
+	return entry.ranking, c.generation, ok
+}
+
+func (c *rankingCache) put(key string, generation uint64, ranking any) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if generation != c.generation {
+		return
+	}
+	if len(c.entries) >= maxCachedRankings {
+		c.entries = map[string]cachedRanking{}
+	}
+	c.entries[key] = cachedRanking{ranking: ranking, expires: time.Now().Add(recommendationTTL)}
+}
+
+func (c *rankingCache) invalidate() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.generation++
+	c.entries = map[string]cachedRanking{}
+}
+
+// invalidatingStore drops the cached rankings after every committed
+// transaction
+type invalidatingStore struct {
+	database.Store
+	cache *rankingCache
+}
+
+func (s invalidatingStore) ExecTx(ctx context.Context, fn func(database.TxQuerier) error) error {
+	err := s.Store.ExecTx(ctx, fn)
+	if err == nil {
+		s.cache.invalidate()
+	}
+	return err
+}
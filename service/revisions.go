@@ -0,0 +1,188 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"jobApps/apperror"
+	"jobApps/audit"
+	"jobApps/dto"
+	"jobApps/internal/database"
+)
+
+// Every change to a career's content saves the new content as a revision,
+// numbered by the version the change produced. Deleting and restoring a
+// career leave its content alone and save no revision.
+
+// RevisionDiff lists the fields that differ between two revisions
+type RevisionDiff struct {
+	From    int32           `json:"from"`
+	To      int32           `json:"to"`
+	Changes json.RawMessage `json:"changes"`
+}
+
+// saveRevision stores the career's current content, attributed to the actor
+// in ctx
+func saveRevision(ctx context.Context, q database.TxQuerier, career database.Career) error {
+	return q.CreateCareerRevision(ctx, database.CreateCareerRevisionParams{
+		Jobid:       career.Jobid,
+		Revision:    career.Version,
+		Company:     career.Company,
+		Position:    career.Position,
+		Jobtype:     career.Jobtype,
+		Description: career.Description,
+		Startdate:   career.Startdate,
+		Enddate:     career.Enddate,
+		AuthorEmail: audit.ActorFrom(ctx).Email,
+
+		SalaryMin:      career.SalaryMin,
+		SalaryMax:      career.SalaryMax,
+		SalaryCurrency: career.SalaryCurrency,
+		PayPeriod:      career.PayPeriod,
+		Equity:         career.Equity,
+		SalaryVisible:  career.SalaryVisible,
+
+		Locations:       career.Locations,
+		RemotePolicy:    career.RemotePolicy,
+		RemoteCountries: career.RemoteCountries,
+
+		Seniority: career.Seniority,
+	})
+}
+
+func revisionDocument(revision database.CareerRevision) dto.CareerDocument {
+	return dto.CareerDocument{
+		Company:     revision.Company,
+		Position:    revision.Position,
+		Jobtype:     revision.Jobtype,
+		Description: revision.Description,
+		Startdate:   revision.Startdate,
+		Enddate:     revision.Enddate,
+
+		SalaryMin:      revision.SalaryMin,
+		SalaryMax:      revision.SalaryMax,
+		SalaryCurrency: revision.SalaryCurrency,
+		PayPeriod:      revision.PayPeriod,
+		Equity:         revision.Equity,
+		SalaryVisible:  revision.SalaryVisible,
+
+		Locations:       revision.Locations,
+		RemotePolicy:    revision.RemotePolicy,
+		RemoteCountries: revision.RemoteCountries,
+
+		Seniority: revision.Seniority,
+	}
+}
+
+// canReadRevisions checks the career exists and the actor manages its
+// careers: admins, and the company's recruiters who edit it
+func (s *Service) canReadRevisions(ctx context.Context, jobID int64) error {
+	career, err := s.store.GetCareerByJobId(ctx, jobID)
+	if err != nil {
+		return apperror.FromDB(err, "career")
+	}
+	return canManageCareers(ctx, s.store, career.CompanyID)
+}
+
+// CareerRevisions lists the career's revisions, newest first. With at set
+// only the revisions saved by then are listed, so the first one is the
+// career as it was at that time.
+func (s *Service) CareerRevisions(ctx context.Context, jobID int64, at *time.Time) ([]database.CareerRevision, error) {
+	if err := s.canReadRevisions(ctx, jobID); err != nil {
+		return nil, err
+	}
+	revisions, err := s.store.ListCareerRevisions(ctx, database.ListCareerRevisionsParams{Jobid: jobID, At: at})
+	if err != nil {
+		return nil, apperror.FromDB(err, "career revision")
+	}
+	return append([]database.CareerRevision{}, revisions...), nil
+}
+
+func (s *Service) CareerRevision(ctx context.Context, jobID int64, revision int32) (database.CareerRevision, error) {
+	if err := s.canReadRevisions(ctx, jobID); err != nil {
+		return database.CareerRevision{}, err
+	}
+	found, err := s.store.GetCareerRevision(ctx, database.GetCareerRevisionParams{Jobid: jobID, Revision: revision})
+	return found, apperror.FromDB(err, "career revision")
+}
+
+// CareerRevisionDiff compares revision to with revision from. A nil from
+// means the revision saved before to; the first revision is compared with
+// nothing, so every field shows as added.
+func (s *Service) CareerRevisionDiff(ctx context.Context, jobID int64, from *int32, to int32) (RevisionDiff, error) {
+	target, err := s.CareerRevision(ctx, jobID, to)
+	if err != nil {
+		return RevisionDiff{}, err
+	}
+
+	diff := RevisionDiff{To: to}
+	var before any
+	if from != nil {
+		base, err := s.CareerRevision(ctx, jobID, *from)
+		if err != nil {
+			return RevisionDiff{}, err
+		}
+		diff.From, before = base.Revision, revisionDocument(base)
+	} else {
+		revisions, err := s.store.ListCareerRevisions(ctx, database.ListCareerRevisionsParams{Jobid: jobID})
+		if err != nil {
+			return RevisionDiff{}, apperror.FromDB(err, "career revision")
+		}
+		// newest first, so the first older revision is the previous one
+		for _, revision := range revisions {
+			if revision.Revision < to {
+				diff.From, before = revision.Revision, revisionDocument(revision)
+				break
+			}
+		}
+	}
+
+	diff.Changes, err = audit.Diff(before, revisionDocument(target))
+	if err != nil {
+		return RevisionDiff{}, apperror.Internal(err)
+	}
+	return diff, nil
+}
+
+// RevertCareer sets the career's content back to that of an earlier
+// revision if its version is one of ifMatch. The revert is a change like any
+// other and saves a new revision; reverting to the current content is a no-op.
+func (s *Service) RevertCareer(ctx context.Context, jobID int64, ifMatch Versions, revision int32) (database.Career, error) {
+	var career database.Career
+	err := s.store.ExecTx(ctx, func(q database.TxQuerier) error {
+		existing, err := q.GetCareerByJobIdForUpdate(ctx, jobID)
+		if err != nil {
+			return err
+		}
+		if err := ifMatch.check(existing.Version); err != nil {
+			return err
+		}
+		target, err := q.GetCareerRevision(ctx, database.GetCareerRevisionParams{Jobid: jobID, Revision: revision})
+		if err != nil {
+			return apperror.FromDB(err, "career revision")
+		}
+
+		set, err := placeAssignments(ctx, q, existing, revisionDocument(target).Changes(existing))
+		if err != nil {
+			return err
+		}
+		set = renderedDescription(reopened(existing, set))
+		if len(set) == 0 {
+			career = existing
+			return nil
+		}
+		career, err = q.PatchCareer(ctx, jobID, set)
+		if err != nil {
+			return err
+		}
+		if err := saveRevision(ctx, q, career); err != nil {
+			return err
+		}
+		return record(ctx, q, audit.ActionRevert, audit.ResourceCareer, jobID, existing, career)
+	})
+	if err != nil {
+		return database.Career{}, apperror.FromDB(err, "career")
+	}
+	return career, nil
+}
@@ -0,0 +1,208 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"jobApps/apperror"
+	"jobApps/audit"
+	"jobApps/dto"
+	"jobApps/internal/database"
+
+	"github.com/jackc/pgx/v4"
+)
+
+// Users save careers for themselves, each with a note and optionally in one
+// of their own lists. Lists are named freely and ignore case, so saving to
+// "To apply" and then "to apply" fills one list. A saved career stays saved
+// when it closes or is deleted, and says so, until it is purged.
+
+// Statuses of a saved career
+const (
+	SavedOpen    = "open"
+	SavedClosed  = "closed"
+	SavedDeleted = "deleted"
+)
+
+// SavedCareer is a career the caller saved
+type SavedCareer struct {
+	Career  database.Career `json:"career"`
+	Note    string          `json:"note"`
+	List    *string         `json:"list"`
+	SavedAt time.Time       `json:"saved_at"`
+	Status  string          `json:"status"`
+}
+
+// CareerSaves is how many users saved a career
+type CareerSaves struct {
+	Jobid int64 `json:"jobid"`
+	Saves int64 `json:"saves"`
+}
+
+// SaveCareer saves the career for the caller, or updates the note and list
+// of their save
+func (s *Service) SaveCareer(ctx context.Context, jobID int64, request dto.SaveCareerRequest) (SavedCareer, error) {
+	var saved SavedCareer
+	err := s.store.ExecTx(ctx, func(q database.TxQuerier) error {
+		user, err := q.GetUserByEmail(ctx, audit.ActorFrom(ctx).Email)
+		if err != nil {
+			return err
+		}
+		// trashed careers are not found, so they cannot be saved
+		career, err := q.GetCareerByJobId(ctx, jobID)
+		if err != nil {
+			return err
+		}
+		if err := checkPublished(ctx, career); err != nil {
+			return err
+		}
+
+		key := database.GetSavedCareerForUpdateParams{Userid: user.Userid, Jobid: jobID}
+		existing, err := q.GetSavedCareerForUpdate(ctx, key)
+		found := err == nil
+		if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+			return err
+		}
+		note, list := existing.Note, existing.ListName
+		if request.Note != nil {
+			note = strings.TrimSpace(*request.Note)
+		}
+		if request.List != nil {
+			if list, err = savedList(ctx, q, user.Userid, *request.List); err != nil {
+				return err
+			}
+		}
+
+		var row database.SavedCareer
+		if found {
+			row, err = q.UpdateSavedCareer(ctx, database.UpdateSavedCareerParams{Userid: user.Userid, Jobid: jobID, Note: note, ListName: list})
+			if err != nil {
+				return err
+			}
+			saved = savedCareer(row, career)
+			return record(ctx, q, audit.ActionUpdate, audit.ResourceSavedCareer, jobID, existing, row)
+		}
+		row, err = q.CreateSavedCareer(ctx, database.CreateSavedCareerParams{Userid: user.Userid, Jobid: jobID, Note: note, ListName: list})
+		if err != nil {
+			return err
+		}
+		saved = savedCareer(row, career)
+		return record(ctx, q, audit.ActionCreate, audit.ResourceSavedCareer, jobID, nil, row)
+	})
+	if err != nil {
+		return SavedCareer{}, apperror.FromDB(err, "career")
+	}
+	return s.hideSavedSalary(ctx, saved)
+}
+
+// savedList returns how the user already spells the list named name, or
+// name itself when the user has no such list; nil for a blank name
+func savedList(ctx context.Context, q database.TxQuerier, userID int64, name string) (*string, error) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return nil, nil
+	}
+	lists, err := q.ListSavedCareerLists(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	for _, list := range lists {
+		if strings.EqualFold(list.Name, name) {
+			return &list.Name, nil
+		}
+	}
+	return &name, nil
+}
+
+// UnsaveCareer removes the career from the caller's saved careers
+func (s *Service) UnsaveCareer(ctx context.Context, jobID int64) error {
+	err := s.store.ExecTx(ctx, func(q database.TxQuerier) error {
+		user, err := q.GetUserByEmail(ctx, audit.ActorFrom(ctx).Email)
+		if err != nil {
+			return err
+		}
+		deleted, err := q.DeleteSavedCareer(ctx, database.DeleteSavedCareerParams{Userid: user.Userid, Jobid: jobID})
+		if err != nil {
+			return err
+		}
+		return record(ctx, q, audit.ActionDelete, audit.ResourceSavedCareer, jobID, deleted, nil)
+	})
+	return apperror.FromDB(err, "saved career")
+}
+
+// SavedCareers lists the caller's saved careers, newest first
+func (s *Service) SavedCareers(ctx context.Context, query dto.SavedCareersQuery) ([]SavedCareer, error) {
+	user, err := s.store.GetUserByEmail(ctx, audit.ActorFrom(ctx).Email)
+	if err != nil {
+		return nil, apperror.FromDB(err, "user")
+	}
+	params := database.ListSavedCareersParams{Userid: user.Userid}
+	if list := strings.TrimSpace(query.List); list != "" {
+		params.ListName.String, params.ListName.Valid = list, true
+	}
+	rows, err := s.store.ListSavedCareers(ctx, params)
+	if err != nil {
+		return nil, apperror.FromDB(err, "saved career")
+	}
+
+	careers := make([]database.Career, len(rows))
+	for i, row := range rows {
+		careers[i] = row.Career
+	}
+	if careers, err = s.hideSalaries(ctx, careers); err != nil {
+		return nil, err
+	}
+	saved := make([]SavedCareer, len(rows))
+	for i, row := range rows {
+		saved[i] = savedCareer(row.SavedCareer, careers[i])
+	}
+	return saved, nil
+}
+
+// SavedCareerLists lists the caller's lists with the number of careers in
+// each
+func (s *Service) SavedCareerLists(ctx context.Context) ([]database.ListSavedCareerListsRow, error) {
+	user, err := s.store.GetUserByEmail(ctx, audit.ActorFrom(ctx).Email)
+	if err != nil {
+		return nil, apperror.FromDB(err, "user")
+	}
+	lists, err := s.store.ListSavedCareerLists(ctx, user.Userid)
+	if err != nil {
+		return nil, apperror.FromDB(err, "saved career")
+	}
+	return append([]database.ListSavedCareerListsRow{}, lists...), nil
+}
+
+// CareerSaves counts the users who saved the career
+func (s *Service) CareerSaves(ctx context.Context, jobID int64) (CareerSaves, error) {
+	if _, err := s.store.GetCareerByJobId(ctx, jobID); err != nil {
+		return CareerSaves{}, apperror.FromDB(err, "career")
+	}
+	saves, err := s.store.CountCareerSaves(ctx, jobID)
+	if err != nil {
+		return CareerSaves{}, apperror.FromDB(err, "saved career")
+	}
+	return CareerSaves{Jobid: jobID, Saves: saves}, nil
+}
+
+func (s *Service) hideSavedSalary(ctx context.Context, saved SavedCareer) (SavedCareer, error) {
+	careers, err := s.hideSalaries(ctx, []database.Career{saved.Career})
+	if err != nil {
+		return SavedCareer{}, err
+	}
+	saved.Career = careers[0]
+	return saved, nil
+}
+
+func savedCareer(row database.SavedCareer, career database.Career) SavedCareer {
+	status := SavedOpen
+	switch {
+	case career.DeletedAt != nil:
+		status = SavedDeleted
+	case career.Enddate.Before(time.Now().UTC().Truncate(24 * time.Hour)):
+		status = SavedClosed
+	}
+	return SavedCareer{Career: career, Note: row.Note, List: row.ListName, SavedAt: row.SavedAt, Status: status}
+}
@@ -0,0 +1,505 @@
+// Package service holds the operations behind the HTTP handlers. Operations
+// spanning several queries run in a single transaction through
+// database.Store.ExecTx, and integrity rules such as unique emails or a
+// profile's owner existing are left to the database constraints instead of
+// being checked up front, which would race with concurrent requests.
+//
+// Every change is recorded in audit_events within the transaction making it,
+// attributed to the audit.Actor in the context.
+//
+// Errors returned by the service are already *apperror.Error values.
+package service
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+
+	"jobApps/apperror"
+	"jobApps/audit"
+	"jobApps/dto"
+	"jobApps/geo"
+	"jobApps/internal/database"
+	"jobApps/logger"
+	"jobApps/markdown"
+	"jobApps/notify"
+	"jobApps/patch"
+	"jobApps/validation"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+type Service struct {
+	store database.Store
+	// BaseCurrency is the currency salary filters use unless the request
+	// names another
+	BaseCurrency string
+	// Notifier delivers the job alert digests and expiry reminders
+	Notifier notify.Notifier
+	// PublicURL is the address of the API as users reach it, which links in
+	// notifications start with
+	PublicURL string
+	// CareerWebhookURL is posted each career as it is published; empty
+	// disables the webhook
+	CareerWebhookURL string
+	// ImportSyncRows is the most rows a career import has to be run within
+	// its request; larger ones run in the background
+	ImportSyncRows int
+	// recommendations caches rankings until the next committed transaction
+	recommendations *rankingCache
+}
+
+func New(store database.Store) *Service {
+	cache := newRankingCache()
+	return &Service{
+		store:           invalidatingStore{Store: store, cache: cache},
+		BaseCurrency:    "USD",
+		Notifier:        notify.Log{},
+		PublicURL:       "http://localhost:8080",
+		ImportSyncRows:  1000,
+		recommendations: cache,
+	}
+}
+
+// Versions are the resource versions a write may apply to, taken from the
+// If-Match header. Nil matches any version.
+type Versions []int32
+
+func (v Versions) check(version int32) error {
+	if v == nil {
+		return nil
+	}
+	for _, candidate := range v {
+		if candidate == version {
+			return nil
+		}
+	}
+	return apperror.PreconditionFailed("the resource has been modified; fetch it again and retry with the new ETag")
+}
+
+// Users
+
+// SignUp hashes the password and creates the user. Duplicate emails and phone
+// numbers of active users are rejected by the users_email_key and
+// users_phonenumber_key unique indexes.
+func (s *Service) SignUp(ctx context.Context, request dto.SignUpRequest) (database.User, error) {
+	//passwords are stored in hashing method in the database
+	password, err := bcrypt.GenerateFromPassword([]byte(request.Password), bcrypt.DefaultCost)
+	if err != nil {
+		return database.User{}, apperror.Internal(fmt.Errorf("hashing password: %w", err))
+	}
+
+	var user database.User
+	err = s.store.ExecTx(ctx, func(q database.TxQuerier) error {
+		user, err = q.CreateUser(ctx, request.ToParams(string(password)))
+		if err != nil {
+			return err
+		}
+		// signing up is the new user acting on their own behalf
+		actor := audit.ActorFrom(ctx)
+		actor.Email, actor.Role = user.Email, user.Role
+		return record(audit.WithActor(ctx, actor), q, audit.ActionCreate, audit.ResourceUser, user.Userid, nil, newAccount(user))
+	})
+	if err != nil {
+		return database.User{}, apperror.FromDB(err, "user")
+	}
+	return user, nil
+}
+
+// Login returns the user matching the email and password
+func (s *Service) Login(ctx context.Context, request dto.LoginRequest) (database.User, error) {
+	user, err := s.store.GetUserByEmail(ctx, request.NormalisedEmail())
+	if err != nil {
+		if err = apperror.FromDB(err, "user"); apperror.IsKind(err, apperror.KindNotFound) {
+			err = apperror.Unauthorized("email does not exists")
+		}
+		return database.User{}, err
+	}
+
+	// Compare the stored hashed password with the provided password
+	if err := bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(request.Password)); err != nil {
+		return database.User{}, apperror.Unauthorized("password not matching")
+	}
+	return user, nil
+}
+
+func (s *Service) UserEmails(ctx context.Context) ([]string, error) {
+	emails, err := s.store.GetallusersEmail(ctx)
+	return emails, apperror.FromDB(err, "user")
+}
+
+// Careers
+
+func (s *Service) CreateCareer(ctx context.Context, request dto.CreateCareerRequest) (database.Career, error) {
+	var career database.Career
+	err := s.store.ExecTx(ctx, func(q database.TxQuerier) error {
+		var err error
+		career, err = createCareer(ctx, q, request)
+		return err
+	})
+	if err != nil {
+		return database.Career{}, apperror.FromDB(err, "career")
+	}
+	// the career is created even if the webhook fails; failing the request
+	// would only have the client post it again. Nothing sends the webhook
+	// later, so it is not cut short by the client leaving or the request
+	// deadline.
+	if career.PublishedAt != nil {
+		ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), webhookTimeout)
+		defer cancel()
+		if err := s.announce(ctx, career); err != nil {
+			logger.FromContext(ctx).Warn("announcing career", slog.Int64("jobid", career.Jobid), slog.Any("error", err))
+		}
+	}
+	return career, nil
+}
+
+// createCareer checks the request and inserts the career in the
+// transaction of q
+func createCareer(ctx context.Context, q database.TxQuerier, request dto.CreateCareerRequest) (database.Career, error) {
+	params := request.ToParams()
+	if err := checkPublishAt(params); err != nil {
+		return database.Career{}, err
+	}
+	if err := checkSalaryCurrency(ctx, q, params.SalaryCurrency); err != nil {
+		return database.Career{}, err
+	}
+	if err := checkRemoteCountries(params.RemotePolicy, params.RemoteCountries); err != nil {
+		return database.Career{}, err
+	}
+	locations, err := geocode(params.Locations)
+	if err != nil {
+		return database.Career{}, err
+	}
+	params.Locations = locations
+	params.DescriptionHTML, params.DescriptionExcerpt = markdown.Render(params.Description)
+	company, err := placeCareer(ctx, q, params.Company)
+	if err != nil {
+		return database.Career{}, err
+	}
+	params.Company, params.CompanyID = company.Name, company.ID
+	career, err := q.CreateCareer(ctx, params)
+	if err != nil {
+		return database.Career{}, err
+	}
+	if err := saveRevision(ctx, q, career); err != nil {
+		return database.Career{}, err
+	}
+	return career, record(ctx, q, audit.ActionCreate, audit.ResourceCareer, career.Jobid, nil, career)
+}
+
+func (s *Service) Career(ctx context.Context, jobID int64) (database.Career, error) {
+	career, err := s.store.GetCareerByJobId(ctx, jobID)
+	if err == nil {
+		err = checkPublished(ctx, career)
+	}
+	if err != nil {
+		return database.Career{}, apperror.FromDB(err, "career")
+	}
+	careers, err := s.hideSalaries(ctx, []database.Career{career})
+	if err != nil {
+		return database.Career{}, err
+	}
+	return careers[0], nil
+}
+
+// Careers lists the careers matching the salary, location and skill filters
+func (s *Service) Careers(ctx context.Context, query dto.CareersQuery) ([]database.Career, error) {
+	params, err := s.careersParams(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	careers, err := s.store.ListCareers(ctx, params)
+	if err != nil {
+		return nil, apperror.FromDB(err, "career")
+	}
+	return s.hideSalaries(ctx, careers)
+}
+
+// careersParams checks the filters of query and resolves its place and
+// skills
+func (s *Service) careersParams(ctx context.Context, query dto.CareersQuery) (database.ListCareersParams, error) {
+	var near *geo.Point
+	if query.Near != "" {
+		point, err := geo.ParseNear(query.Near)
+		if err != nil {
+			return database.ListCareersParams{}, apperror.Validation(err.Error(),
+				apperror.FieldError{Field: "near", Message: "must be lat,lon or a city in the gazetteer"})
+		}
+		near = &point
+	}
+	skillIDs, err := skillFilter(ctx, s.store, query.SkillNames())
+	if err != nil {
+		return database.ListCareersParams{}, err
+	}
+	params := query.ToParams(s.BaseCurrency, near, skillIDs)
+	params.IncludeUnpublished = seesUnpublished(ctx)
+	if query.SalaryMin != nil || query.SalaryMax != nil {
+		known, err := s.store.HasExchangeRate(ctx, params.Currency)
+		if err != nil {
+			return database.ListCareersParams{}, apperror.FromDB(err, "exchange rate")
+		}
+		if !known {
+			return database.ListCareersParams{}, apperror.Validation("no exchange rate is stored for "+params.Currency,
+				apperror.FieldError{Field: "currency", Message: "has no exchange rate"})
+		}
+	}
+	return params, nil
+}
+
+// UpdateCareer applies the request to the career if its version is one of
+// ifMatch. The row is locked while it is read and written so concurrent
+// updates cannot overwrite each other.
+func (s *Service) UpdateCareer(ctx context.Context, jobID int64, ifMatch Versions, request dto.UpdateCareerRequest) (database.Career, error) {
+	var career database.Career
+	err := s.store.ExecTx(ctx, func(q database.TxQuerier) error {
+		existing, err := q.GetCareerByJobIdForUpdate(ctx, jobID)
+		if err != nil {
+			return err
+		}
+		if err := ifMatch.check(existing.Version); err != nil {
+			return err
+		}
+		if err := canManageCareers(ctx, q, existing.CompanyID); err != nil {
+			return err
+		}
+		params := request.ToParams(existing)
+		params.DescriptionHTML, params.DescriptionExcerpt = markdown.Render(params.Description)
+		company, err := placeCareer(ctx, q, params.Company)
+		if err != nil {
+			return err
+		}
+		params.Company, params.CompanyID = company.Name, company.ID
+		career, err = q.UpdateCareerByJobId(ctx, params)
+		if err != nil {
+			return err
+		}
+		if err := saveRevision(ctx, q, career); err != nil {
+			return err
+		}
+		return record(ctx, q, audit.ActionUpdate, audit.ResourceCareer, jobID, existing, career)
+	})
+	if err != nil {
+		return database.Career{}, apperror.FromDB(err, "career")
+	}
+	return career, nil
+}
+
+// PatchCareer applies a merge patch or JSON Patch to the career if its
+// version is one of ifMatch. Only the columns that actually change are
+// written; a patch changing nothing leaves the version as it is.
+func (s *Service) PatchCareer(ctx context.Context, jobID int64, ifMatch Versions, p patch.Patch) (database.Career, error) {
+	var career database.Career
+	err := s.store.ExecTx(ctx, func(q database.TxQuerier) error {
+		existing, err := q.GetCareerByJobIdForUpdate(ctx, jobID)
+		if err != nil {
+			return err
+		}
+		if err := ifMatch.check(existing.Version); err != nil {
+			return err
+		}
+		if err := canManageCareers(ctx, q, existing.CompanyID); err != nil {
+			return err
+		}
+
+		patched, err := p.Apply(dto.NewCareerDocument(existing))
+		if err != nil {
+			return err
+		}
+		var document dto.CareerDocument
+		if err := validation.Document(patched, &document); err != nil {
+			return err
+		}
+		if err := checkSalaryCurrency(ctx, q, document.SalaryCurrency); err != nil {
+			return err
+		}
+		if err := checkRemoteCountries(document.RemotePolicy, document.RemoteCountries); err != nil {
+			return err
+		}
+		if document.Locations, err = geocode(document.Locations); err != nil {
+			return err
+		}
+
+		set, err := placeAssignments(ctx, q, existing, document.Changes(existing))
+		if err != nil {
+			return err
+		}
+		set = renderedDescription(reopened(existing, set))
+		if len(set) == 0 {
+			career = existing
+			return nil
+		}
+		career, err = q.PatchCareer(ctx, jobID, set)
+		if err != nil {
+			return err
+		}
+		if err := saveRevision(ctx, q, career); err != nil {
+			return err
+		}
+		return record(ctx, q, audit.ActionUpdate, audit.ResourceCareer, jobID, existing, career)
+	})
+	if err != nil {
+		return database.Career{}, apperror.FromDB(err, "career")
+	}
+	return career, nil
+}
+
+// DeleteCareer moves the career to the trash if its version is one of ifMatch
+func (s *Service) DeleteCareer(ctx context.Context, jobID int64, ifMatch Versions) (database.Career, error) {
+	var career database.Career
+	err := s.store.ExecTx(ctx, func(q database.TxQuerier) error {
+		existing, err := q.GetCareerByJobIdForUpdate(ctx, jobID)
+		if err != nil {
+			return err
+		}
+		if err := ifMatch.check(existing.Version); err != nil {
+			return err
+		}
+		if err := canManageCareers(ctx, q, existing.CompanyID); err != nil {
+			return err
+		}
+		career, err = q.DeleteCareerByJobId(ctx, jobID)
+		if err != nil {
+			return err
+		}
+		return record(ctx, q, audit.ActionDelete, audit.ResourceCareer, jobID, existing, career)
+	})
+	if err != nil {
+		return database.Career{}, apperror.FromDB(err, "career")
+	}
+	return career, nil
+}
+
+// Profiles
+
+// CreateProfile creates a profile for the user with the given email. The
+// lookup and insert share a transaction, and the foreign key on userid
+// rejects the profile if the user is deleted in between.
+func (s *Service) CreateProfile(ctx context.Context, ownerEmail string, request dto.CreateProfileRequest) (database.Profile, error) {
+	var profile database.Profile
+	err := s.store.ExecTx(ctx, func(q database.TxQuerier) error {
+		owner, err := q.GetUserByEmail(ctx, ownerEmail)
+		if err != nil {
+			return apperror.FromDB(err, "user")
+		}
+		params := request.ToParams(owner)
+		if err := checkSalaryCurrency(ctx, q, params.SalaryCurrency); err != nil {
+			return err
+		}
+		if params.Locations, err = geocode(params.Locations); err != nil {
+			return err
+		}
+		profile, err = q.CreateProfile(ctx, params)
+		if err != nil {
+			return err
+		}
+		return record(ctx, q, audit.ActionCreate, audit.ResourceProfile, profile.Profileid, nil, profile)
+	})
+	if err != nil {
+		return database.Profile{}, apperror.FromDB(err, "profile")
+	}
+	return profile, nil
+}
+
+func (s *Service) Profile(ctx context.Context, userID int64) (database.Profile, error) {
+	profile, err := s.store.GetProfileByuserId(ctx, userID)
+	return profile, apperror.FromDB(err, "profile")
+}
+
+func (s *Service) Profiles(ctx context.Context) ([]database.Profile, error) {
+	profiles, err := s.store.GetAllProfileDetails(ctx)
+	return profiles, apperror.FromDB(err, "profile")
+}
+
+// UpdateProfile applies the request to the user's profile if its version is
+// one of ifMatch, with the row locked between the read and the write
+func (s *Service) UpdateProfile(ctx context.Context, userID int64, ifMatch Versions, request dto.UpdateProfileRequest) (database.Profile, error) {
+	var profile database.Profile
+	err := s.store.ExecTx(ctx, func(q database.TxQuerier) error {
+		existing, err := q.GetProfileByuserIdForUpdate(ctx, userID)
+		if err != nil {
+			return err
+		}
+		if err := ifMatch.check(existing.Version); err != nil {
+			return err
+		}
+		profile, err = q.UpdateProfileByuserId(ctx, request.ToParams(existing))
+		if err != nil {
+			return err
+		}
+		return record(ctx, q, audit.ActionUpdate, audit.ResourceProfile, profile.Profileid, existing, profile)
+	})
+	if err != nil {
+		return database.Profile{}, apperror.FromDB(err, "profile")
+	}
+	return profile, nil
+}
+
+// PatchProfile applies a merge patch or JSON Patch to the user's profile if
+// its version is one of ifMatch
+func (s *Service) PatchProfile(ctx context.Context, userID int64, ifMatch Versions, p patch.Patch) (database.Profile, error) {
+	var profile database.Profile
+	err := s.store.ExecTx(ctx, func(q database.TxQuerier) error {
+		existing, err := q.GetProfileByuserIdForUpdate(ctx, userID)
+		if err != nil {
+			return err
+		}
+		if err := ifMatch.check(existing.Version); err != nil {
+			return err
+		}
+
+		patched, err := p.Apply(dto.NewProfileDocument(existing))
+		if err != nil {
+			return err
+		}
+		var document dto.ProfileDocument
+		if err := validation.Document(patched, &document); err != nil {
+			return err
+		}
+		if err := checkSalaryCurrency(ctx, q, document.SalaryCurrency); err != nil {
+			return err
+		}
+		if document.Locations, err = geocode(document.Locations); err != nil {
+			return err
+		}
+
+		set := document.Changes(existing)
+		if len(set) == 0 {
+			profile = existing
+			return nil
+		}
+		profile, err = q.PatchProfileByUserId(ctx, userID, set)
+		if err != nil {
+			return err
+		}
+		return record(ctx, q, audit.ActionUpdate, audit.ResourceProfile, profile.Profileid, existing, profile)
+	})
+	if err != nil {
+		return database.Profile{}, apperror.FromDB(err, "profile")
+	}
+	return profile, nil
+}
+
+// DeleteProfile moves the user's profile to the trash if its version is one
+// of ifMatch
+func (s *Service) DeleteProfile(ctx context.Context, userID int64, ifMatch Versions) (database.Profile, error) {
+	var profile database.Profile
+	err := s.store.ExecTx(ctx, func(q database.TxQuerier) error {
+		existing, err := q.GetProfileByuserIdForUpdate(ctx, userID)
+		if err != nil {
+			return err
+		}
+		if err := ifMatch.check(existing.Version); err != nil {
+			return err
+		}
+		profile, err = q.DeleteProfileByUserId(ctx, userID)
+		if err != nil {
+			return err
+		}
+		return record(ctx, q, audit.ActionDelete, audit.ResourceProfile, profile.Profileid, existing, profile)
+	})
+	if err != nil {
+		return database.Profile{}, apperror.FromDB(err, "profile")
+	}
+	return profile, nil
+}
@@ -0,0 +1,325 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	"jobApps/apperror"
+	"jobApps/audit"
+	"jobApps/dto"
+	"jobApps/internal/database"
+
+	"github.com/jackc/pgx/v4"
+)
+
+// Skills form a shared taxonomy: a skill has one name and any number of
+// aliases, and names and aliases never overlap, so every spelling resolves to
+// at most one skill. Careers and profiles are tagged by replacing their whole
+// skill set, which bumps the version of the career or profile.
+
+// CareerSkills is the skill set of a career at a version of the career
+type CareerSkills struct {
+	Jobid   int64                          `json:"jobid"`
+	Version int32                          `json:"version"`
+	Skills  []database.ListCareerSkillsRow `json:"skills"`
+}
+
+// ProfileSkills is the skill set of a profile at a version of the profile
+type ProfileSkills struct {
+	Profileid int64                           `json:"profileid"`
+	Userid    int64                           `json:"userid"`
+	Version   int32                           `json:"version"`
+	Skills    []database.ListProfileSkillsRow `json:"skills"`
+}
+
+// CreateSkill adds a skill with its aliases to the taxonomy
+func (s *Service) CreateSkill(ctx context.Context, request dto.CreateSkillRequest) (database.ListSkillsRow, error) {
+	var created database.ListSkillsRow
+	err := s.store.ExecTx(ctx, func(q database.TxQuerier) error {
+		name := strings.TrimSpace(request.Name)
+		if err := checkSkillNameFree(ctx, q, name); err != nil {
+			return err
+		}
+		skill, err := q.CreateSkill(ctx, name)
+		if err != nil {
+			return err
+		}
+		for _, alias := range request.Aliases {
+			alias = strings.ToLower(strings.TrimSpace(alias))
+			if err := checkSkillNameFree(ctx, q, alias); err != nil {
+				return err
+			}
+			if err := q.AddSkillAlias(ctx, database.AddSkillAliasParams{Alias: alias, SkillID: skill.ID}); err != nil {
+				return err
+			}
+		}
+		if created, err = skillWithAliases(ctx, q, skill); err != nil {
+			return err
+		}
+		return record(ctx, q, audit.ActionCreate, audit.ResourceSkill, skill.ID, nil, created)
+	})
+	if err != nil {
+		return database.ListSkillsRow{}, apperror.FromDB(err, "skill")
+	}
+	return created, nil
+}
+
+// AddSkillAlias lets the skill also be found as alias
+func (s *Service) AddSkillAlias(ctx context.Context, skillID int64, request dto.AddSkillAliasRequest) (database.ListSkillsRow, error) {
+	var updated database.ListSkillsRow
+	err := s.store.ExecTx(ctx, func(q database.TxQuerier) error {
+		skill, err := q.GetSkill(ctx, skillID)
+		if err != nil {
+			return err
+		}
+		existing, err := skillWithAliases(ctx, q, skill)
+		if err != nil {
+			return err
+		}
+		alias := strings.ToLower(strings.TrimSpace(request.Alias))
+		if err := checkSkillNameFree(ctx, q, alias); err != nil {
+			return err
+		}
+		if err := q.AddSkillAlias(ctx, database.AddSkillAliasParams{Alias: alias, SkillID: skill.ID}); err != nil {
+			return err
+		}
+		if updated, err = skillWithAliases(ctx, q, skill); err != nil {
+			return err
+		}
+		return record(ctx, q, audit.ActionUpdate, audit.ResourceSkill, skill.ID, existing, updated)
+	})
+	if err != nil {
+		return database.ListSkillsRow{}, apperror.FromDB(err, "skill")
+	}
+	return updated, nil
+}
+
+// Skills lists the skills whose name or an alias starts with the prefix
+func (s *Service) Skills(ctx context.Context, query dto.SkillsQuery) ([]database.ListSkillsRow, error) {
+	skills, err := s.store.ListSkills(ctx, query.ToParams())
+	if err != nil {
+		return nil, apperror.FromDB(err, "skill")
+	}
+	return append([]database.ListSkillsRow{}, skills...), nil
+}
+
+func (s *Service) CareerSkills(ctx context.Context, jobID int64) (CareerSkills, error) {
+	career, err := s.store.GetCareerByJobId(ctx, jobID)
+	if err == nil {
+		err = checkPublished(ctx, career)
+	}
+	if err != nil {
+		return CareerSkills{}, apperror.FromDB(err, "career")
+	}
+	skills, err := careerSkills(ctx, s.store, career)
+	return skills, apperror.FromDB(err, "skill")
+}
+
+// SetCareerSkills replaces the skills of the career if its version is one of
+// ifMatch
+func (s *Service) SetCareerSkills(ctx context.Context, jobID int64, ifMatch Versions, request dto.SetCareerSkillsRequest) (CareerSkills, error) {
+	var updated CareerSkills
+	err := s.store.ExecTx(ctx, func(q database.TxQuerier) error {
+		career, err := q.GetCareerByJobIdForUpdate(ctx, jobID)
+		if err != nil {
+			return err
+		}
+		if err := ifMatch.check(career.Version); err != nil {
+			return err
+		}
+		if err := canManageCareers(ctx, q, career.CompanyID); err != nil {
+			return err
+		}
+		existing, err := careerSkills(ctx, q, career)
+		if err != nil {
+			return err
+		}
+
+		names := make([]string, len(request.Skills))
+		for i, skill := range request.Skills {
+			names[i] = skill.Skill
+		}
+		skills, err := resolveSkills(ctx, q, names)
+		if err != nil {
+			return err
+		}
+		if err := q.DeleteCareerSkills(ctx, jobID); err != nil {
+			return err
+		}
+		for i, skill := range request.Skills {
+			err := q.AddCareerSkill(ctx, database.AddCareerSkillParams{
+				Jobid:    jobID,
+				SkillID:  skills[i].ID,
+				Required: skill.Required == nil || *skill.Required,
+				MinYears: skill.MinYears,
+			})
+			if err != nil {
+				return err
+			}
+		}
+
+		if career, err = q.TouchCareer(ctx, jobID); err != nil {
+			return err
+		}
+		if updated, err = careerSkills(ctx, q, career); err != nil {
+			return err
+		}
+		return record(ctx, q, audit.ActionUpdate, audit.ResourceCareer, jobID, existing, updated)
+	})
+	if err != nil {
+		return CareerSkills{}, apperror.FromDB(err, "career")
+	}
+	return updated, nil
+}
+
+func (s *Service) ProfileSkills(ctx context.Context, userID int64) (ProfileSkills, error) {
+	profile, err := s.store.GetProfileByuserId(ctx, userID)
+	if err != nil {
+		return ProfileSkills{}, apperror.FromDB(err, "profile")
+	}
+	skills, err := profileSkills(ctx, s.store, profile)
+	return skills, apperror.FromDB(err, "skill")
+}
+
+// SetProfileSkills replaces the skills of the user's profile if its version
+// is one of ifMatch
+func (s *Service) SetProfileSkills(ctx context.Context, userID int64, ifMatch Versions, request dto.SetProfileSkillsRequest) (ProfileSkills, error) {
+	var updated ProfileSkills
+	err := s.store.ExecTx(ctx, func(q database.TxQuerier) error {
+		profile, err := q.GetProfileByuserIdForUpdate(ctx, userID)
+		if err != nil {
+			return err
+		}
+		if err := ifMatch.check(profile.Version); err != nil {
+			return err
+		}
+		existing, err := profileSkills(ctx, q, profile)
+		if err != nil {
+			return err
+		}
+
+		names := make([]string, len(request.Skills))
+		for i, skill := range request.Skills {
+			names[i] = skill.Skill
+		}
+		skills, err := resolveSkills(ctx, q, names)
+		if err != nil {
+			return err
+		}
+		if err := q.DeleteProfileSkills(ctx, profile.Profileid); err != nil {
+			return err
+		}
+		for i, skill := range request.Skills {
+			err := q.AddProfileSkill(ctx, database.AddProfileSkillParams{
+				Profileid:   profile.Profileid,
+				SkillID:     skills[i].ID,
+				Proficiency: skill.Proficiency,
+			})
+			if err != nil {
+				return err
+			}
+		}
+
+		if profile, err = q.TouchProfile(ctx, profile.Profileid); err != nil {
+			return err
+		}
+		if updated, err = profileSkills(ctx, q, profile); err != nil {
+			return err
+		}
+		return record(ctx, q, audit.ActionUpdate, audit.ResourceProfile, profile.Profileid, existing, updated)
+	})
+	if err != nil {
+		return ProfileSkills{}, apperror.FromDB(err, "profile")
+	}
+	return updated, nil
+}
+
+// resolveSkills finds the skill each name or alias stands for, rejecting
+// unknown skills and skills listed twice
+func resolveSkills(ctx context.Context, q database.Querier, names []string) ([]database.Skill, error) {
+	skills := make([]database.Skill, len(names))
+	seen := map[int64]bool{}
+	var fields []apperror.FieldError
+	for i, name := range names {
+		skill, err := q.FindSkill(ctx, strings.TrimSpace(name))
+		switch {
+		case errors.Is(err, pgx.ErrNoRows):
+			fields = append(fields, apperror.FieldError{Field: fmt.Sprintf("skills[%d].skill", i), Message: "is not a known skill"})
+			continue
+		case err != nil:
+			return nil, err
+		case seen[skill.ID]:
+			fields = append(fields, apperror.FieldError{Field: fmt.Sprintf("skills[%d].skill", i), Message: "is listed twice"})
+		}
+		seen[skill.ID] = true
+		skills[i] = skill
+	}
+	if len(fields) > 0 {
+		return nil, apperror.Validation("request validation failed", fields...)
+	}
+	return skills, nil
+}
+
+// skillFilter resolves the skill names of a careers query to skill IDs,
+// ignoring repeats such as "Go,golang"
+func skillFilter(ctx context.Context, q database.Querier, names []string) ([]int64, error) {
+	var ids []int64
+	for _, name := range names {
+		skill, err := q.FindSkill(ctx, name)
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, apperror.Validation(fmt.Sprintf("%q is not a known skill", name),
+				apperror.FieldError{Field: "skills", Message: "must list known skills"})
+		}
+		if err != nil {
+			return nil, apperror.FromDB(err, "skill")
+		}
+		if !slices.Contains(ids, skill.ID) {
+			ids = append(ids, skill.ID)
+		}
+	}
+	return ids, nil
+}
+
+// checkSkillNameFree rejects a skill name or alias that already names a skill
+func checkSkillNameFree(ctx context.Context, q database.TxQuerier, name string) error {
+	skill, err := q.FindSkill(ctx, name)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	return apperror.Conflict(fmt.Sprintf("%q already names the skill %s", name, skill.Name))
+}
+
+func skillWithAliases(ctx context.Context, q database.Querier, skill database.Skill) (database.ListSkillsRow, error) {
+	aliases, err := q.ListSkillAliases(ctx, skill.ID)
+	if err != nil {
+		return database.ListSkillsRow{}, err
+	}
+	return database.ListSkillsRow{ID: skill.ID, Name: skill.Name, Aliases: append([]string{}, aliases...)}, nil
+}
+
+func careerSkills(ctx context.Context, q database.Querier, career database.Career) (CareerSkills, error) {
+	skills, err := q.ListCareerSkills(ctx, career.Jobid)
+	if err != nil {
+		return CareerSkills{}, err
+	}
+	return CareerSkills{Jobid: career.Jobid, Version: career.Version, Skills: append([]database.ListCareerSkillsRow{}, skills...)}, nil
+}
+
+func profileSkills(ctx context.Context, q database.Querier, profile database.Profile) (ProfileSkills, error) {
+	skills, err := q.ListProfileSkills(ctx, profile.Profileid)
+	if err != nil {
+		return ProfileSkills{}, err
+	}
+	return ProfileSkills{
+		Profileid: profile.Profileid,
+		Userid:    profile.Userid,
+		Version:   profile.Version,
+		Skills:    append([]database.ListProfileSkillsRow{}, skills...),
+	}, nil
+}
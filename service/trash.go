@@ -0,0 +1,202 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"jobApps/apperror"
+	"jobApps/audit"
+	"jobApps/internal/database"
+
+	"github.com/jackc/pgx/v4"
+)
+
+// Deleting a career, profile or user only sets its deleted_at; the row stays
+// in the trash, hidden from every other query, until it is restored or
+// PurgeTrash removes it for good.
+
+// Account is a user without the password hash
+type Account struct {
+	Userid      int64      `json:"userid"`
+	Username    string     `json:"username"`
+	Email       string     `json:"email"`
+	Phonenumber string     `json:"phonenumber"`
+	Role        string     `json:"role"`
+	DeletedAt   *time.Time `json:"deleted_at"`
+}
+
+func newAccount(user database.User) Account {
+	return Account{
+		Userid:      user.Userid,
+		Username:    user.Username,
+		Email:       user.Email,
+		Phonenumber: user.Phonenumber,
+		Role:        user.Role,
+		DeletedAt:   user.DeletedAt,
+	}
+}
+
+// Trash lists the deleted rows, most recently deleted first
+type Trash struct {
+	Careers  []database.Career  `json:"careers"`
+	Profiles []database.Profile `json:"profiles"`
+	Users    []Account          `json:"users"`
+}
+
+// Purged counts the rows removed by PurgeTrash
+type Purged struct {
+	Careers  int64 `json:"careers"`
+	Profiles int64 `json:"profiles"`
+	Users    int64 `json:"users"`
+}
+
+// DeleteUser moves the user and their profiles to the trash. The profiles
+// share the user's deleted_at, which is how RestoreUser finds them again.
+func (s *Service) DeleteUser(ctx context.Context, userID int64) (Account, error) {
+	var user database.User
+	err := s.store.ExecTx(ctx, func(q database.TxQuerier) error {
+		existing, err := q.GetUserByIdForUpdate(ctx, userID)
+		if err != nil {
+			return err
+		}
+		user, err = q.DeleteUserById(ctx, userID)
+		if err != nil {
+			return err
+		}
+		if err := q.DeleteProfilesOfUser(ctx, database.DeleteProfilesOfUserParams{Userid: userID, DeletedAt: user.DeletedAt}); err != nil {
+			return err
+		}
+		return record(ctx, q, audit.ActionDelete, audit.ResourceUser, userID, newAccount(existing), newAccount(user))
+	})
+	if err != nil {
+		return Account{}, apperror.FromDB(err, "user")
+	}
+	return newAccount(user), nil
+}
+
+// RestoreUser takes the user out of the trash together with the profiles
+// that were deleted with them. It is a conflict if another account has taken
+// the email or phone number since.
+func (s *Service) RestoreUser(ctx context.Context, userID int64) (Account, error) {
+	var user database.User
+	err := s.store.ExecTx(ctx, func(q database.TxQuerier) error {
+		existing, err := q.GetDeletedUserByIdForUpdate(ctx, userID)
+		if err != nil {
+			return err
+		}
+		// matches on the user's deleted_at, so it has to run first
+		if err := q.RestoreProfilesOfUser(ctx, userID); err != nil {
+			return err
+		}
+		user, err = q.RestoreUserById(ctx, userID)
+		if err != nil {
+			return err
+		}
+		return record(ctx, q, audit.ActionRestore, audit.ResourceUser, userID, newAccount(existing), newAccount(user))
+	})
+	if err != nil {
+		return Account{}, apperror.FromDB(err, "deleted user")
+	}
+	return newAccount(user), nil
+}
+
+func (s *Service) RestoreCareer(ctx context.Context, jobID int64) (database.Career, error) {
+	var career database.Career
+	err := s.store.ExecTx(ctx, func(q database.TxQuerier) error {
+		existing, err := q.GetDeletedCareerByJobIdForUpdate(ctx, jobID)
+		if err != nil {
+			return err
+		}
+		career, err = q.RestoreCareerByJobId(ctx, jobID)
+		if err != nil {
+			return err
+		}
+		return record(ctx, q, audit.ActionRestore, audit.ResourceCareer, jobID, existing, career)
+	})
+	if err != nil {
+		return database.Career{}, apperror.FromDB(err, "deleted career")
+	}
+	return career, nil
+}
+
+// RestoreProfile takes the user's most recently deleted profile out of the
+// trash. The owner is locked so they cannot be deleted at the same time; a
+// profile of a deleted user has to wait for the user to be restored, and a
+// user who has created a new profile since keeps that one.
+func (s *Service) RestoreProfile(ctx context.Context, userID int64) (database.Profile, error) {
+	var profile database.Profile
+	err := s.store.ExecTx(ctx, func(q database.TxQuerier) error {
+		if _, err := q.GetUserByIdForUpdate(ctx, userID); err != nil {
+			if err = apperror.FromDB(err, "user"); apperror.IsKind(err, apperror.KindNotFound) {
+				err = apperror.Conflict("the profile's user is deleted; restore the user first")
+			}
+			return err
+		}
+		if _, err := q.GetProfileByuserId(ctx, userID); err == nil {
+			return apperror.Conflict("the user already has a profile")
+		} else if !errors.Is(err, pgx.ErrNoRows) {
+			return err
+		}
+		existing, err := q.GetLatestDeletedProfileByUserIdForUpdate(ctx, userID)
+		if err != nil {
+			return err
+		}
+		profile, err = q.RestoreProfileById(ctx, existing.Profileid)
+		if err != nil {
+			return err
+		}
+		return record(ctx, q, audit.ActionRestore, audit.ResourceProfile, profile.Profileid, existing, profile)
+	})
+	if err != nil {
+		return database.Profile{}, apperror.FromDB(err, "deleted profile")
+	}
+	return profile, nil
+}
+
+// Trash lists everything that is deleted but not purged yet
+func (s *Service) Trash(ctx context.Context) (Trash, error) {
+	careers, err := s.store.ListDeletedCareers(ctx)
+	if err != nil {
+		return Trash{}, apperror.FromDB(err, "career")
+	}
+	profiles, err := s.store.ListDeletedProfiles(ctx)
+	if err != nil {
+		return Trash{}, apperror.FromDB(err, "profile")
+	}
+	users, err := s.store.ListDeletedUsers(ctx)
+	if err != nil {
+		return Trash{}, apperror.FromDB(err, "user")
+	}
+
+	trash := Trash{
+		Careers:  append([]database.Career{}, careers...),
+		Profiles: append([]database.Profile{}, profiles...),
+		Users:    make([]Account, 0, len(users)),
+	}
+	for _, user := range users {
+		trash.Users = append(trash.Users, newAccount(user))
+	}
+	return trash, nil
+}
+
+// PurgeTrash permanently deletes everything that went to the trash before
+// the cutoff, in one transaction. Purging a user also removes their profiles.
+func (s *Service) PurgeTrash(ctx context.Context, before time.Time) (Purged, error) {
+	var purged Purged
+	err := s.store.ExecTx(ctx, func(q database.TxQuerier) error {
+		var err error
+		if purged.Profiles, err = q.PurgeProfiles(ctx, before); err != nil {
+			return err
+		}
+		if purged.Careers, err = q.PurgeCareers(ctx, before); err != nil {
+			return err
+		}
+		purged.Users, err = q.PurgeUsers(ctx, before)
+		return err
+	})
+	if err != nil {
+		return Purged{}, apperror.FromDB(err, "trash")
+	}
+	return purged, nil
+}
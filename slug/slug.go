@@ -0,0 +1,33 @@
+// Package slug derives the URL identifiers of companies. Company mirrors the
+// company_slug SQL function in sql/migrations, which deduplicated the
+// free-text company names; the two must stay in step.
+package slug
+
+import (
+	"crypto/md5"
+	"encoding/hex"
+	"regexp"
+	"strings"
+)
+
+var (
+	legalSuffix = regexp.MustCompile(`[\s,]+(inc|incorporated|llc|ltd|limited|corp|corporation|co|gmbh)\.?$`)
+	separators  = regexp.MustCompile(`[^\p{L}\p{N}]+`)
+)
+
+// Company returns the slug of a company name: lower case, without a trailing
+// legal form such as "Inc." and with every run of characters other than
+// letters and digits, in any script, replaced by a hyphen. "Acme", "ACME Inc"
+// and " acme " all give "acme"; "Müller GmbH" gives "müller". A name without
+// any letter or digit gets "company-" and a hash of the name, so such names
+// stay apart.
+func Company(name string) string {
+	name = strings.ToLower(strings.TrimSpace(name))
+	slug := legalSuffix.ReplaceAllString(name, "")
+	slug = strings.Trim(separators.ReplaceAllString(slug, "-"), "-")
+	if slug == "" {
+		sum := md5.Sum([]byte(name))
+		return "company-" + hex.EncodeToString(sum[:])[:12]
+	}
+	return slug
+}
@@ -0,0 +1,43 @@
+package slug
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestCompany(t *testing.T) {
+	tests := map[string]string{
+		"Acme":                  "acme",
+		"ACME Inc":              "acme",
+		" acme ":                "acme",
+		"Acme, Inc.":            "acme",
+		"Acme Co":               "acme",
+		"Acme Corporation":      "acme",
+		"Costco":                "costco",
+		"Initech Software Ltd.": "initech-software",
+		"Müller & Söhne GmbH":   "müller-söhne",
+		"AT&T":                  "at-t",
+		"Inc":                   "inc",
+		"Яндекс":                "яндекс",
+		"Газпром":               "газпром",
+		"ООО Ромашка":           "ооо-ромашка",
+		"腾讯":                    "腾讯",
+		"阿里巴巴":                  "阿里巴巴",
+		"株式会社ソニー 2":             "株式会社ソニー-2",
+	}
+	for name, want := range tests {
+		if got := Company(name); got != want {
+			t.Errorf("Company(%q) = %q, want %q", name, got, want)
+		}
+	}
+}
+
+func TestCompanyWithoutLettersOrDigits(t *testing.T) {
+	first, second := Company("!!!"), Company("???")
+	if !strings.HasPrefix(first, "company-") || !strings.HasPrefix(second, "company-") || first == second {
+		t.Errorf("Company(%q) = %q and Company(%q) = %q, want distinct company- slugs", "!!!", first, "???", second)
+	}
+	if again := Company(" !!! "); again != first {
+		t.Errorf("Company(%q) = %q, want %q", " !!! ", again, first)
+	}
+}
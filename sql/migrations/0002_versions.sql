@@ -0,0 +1,4 @@
+-- version is bumped on every update and exposed as the ETag for optimistic
+-- concurrency control
+ALTER TABLE career ADD COLUMN IF NOT EXISTS version INT NOT NULL DEFAULT 1;
+ALTER TABLE profile ADD COLUMN IF NOT EXISTS version INT NOT NULL DEFAULT 1;
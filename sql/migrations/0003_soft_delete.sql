@@ -0,0 +1,20 @@
+-- rows are soft-deleted by setting deleted_at and purged once it is older than
+-- the trash retention
+ALTER TABLE users ADD COLUMN IF NOT EXISTS deleted_at TIMESTAMPTZ;
+ALTER TABLE profile ADD COLUMN IF NOT EXISTS deleted_at TIMESTAMPTZ;
+ALTER TABLE career ADD COLUMN IF NOT EXISTS deleted_at TIMESTAMPTZ;
+
+-- a deleted account must not block signing up again with its email or phone
+-- number; the indexes keep the constraint names so conflicts read the same
+ALTER TABLE users DROP CONSTRAINT IF EXISTS users_email_key;
+ALTER TABLE users DROP CONSTRAINT IF EXISTS users_phonenumber_key;
+CREATE UNIQUE INDEX IF NOT EXISTS users_email_key ON users (email) WHERE deleted_at IS NULL;
+CREATE UNIQUE INDEX IF NOT EXISTS users_phonenumber_key ON users (phonenumber) WHERE deleted_at IS NULL;
+
+-- purging a user takes their profiles with them
+ALTER TABLE profile DROP CONSTRAINT IF EXISTS profile_userid_fkey;
+ALTER TABLE profile ADD CONSTRAINT profile_userid_fkey FOREIGN KEY (userid) REFERENCES users (userid) ON DELETE CASCADE;
+
+CREATE INDEX IF NOT EXISTS career_deleted_at_idx ON career (deleted_at) WHERE deleted_at IS NOT NULL;
+CREATE INDEX IF NOT EXISTS profile_deleted_at_idx ON profile (deleted_at) WHERE deleted_at IS NOT NULL;
+CREATE INDEX IF NOT EXISTS users_deleted_at_idx ON users (deleted_at) WHERE deleted_at IS NOT NULL;
@@ -0,0 +1,31 @@
+-- audit_events records every change made through the API, written in the same
+-- transaction as the change. changes holds the fields that differ as
+-- {"field": {"before": ..., "after": ...}}.
+CREATE TABLE IF NOT EXISTS audit_events (
+    id BIGSERIAL PRIMARY KEY,
+    occurred_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    actor_email VARCHAR(255) NOT NULL,
+    actor_role VARCHAR(255) NOT NULL,
+    action VARCHAR(32) NOT NULL,
+    resource_type VARCHAR(32) NOT NULL,
+    resource_id BIGINT NOT NULL,
+    changes JSONB NOT NULL,
+    request_id VARCHAR(64) NOT NULL,
+    ip VARCHAR(64) NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS audit_events_occurred_at_idx ON audit_events (occurred_at);
+CREATE INDEX IF NOT EXISTS audit_events_actor_idx ON audit_events (actor_email, id);
+CREATE INDEX IF NOT EXISTS audit_events_resource_idx ON audit_events (resource_type, resource_id, id);
+
+-- the log is append-only
+CREATE OR REPLACE FUNCTION audit_events_append_only() RETURNS trigger AS $$
+BEGIN
+    RAISE EXCEPTION 'audit_events is append-only';
+END;
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS audit_events_append_only ON audit_events;
+CREATE TRIGGER audit_events_append_only
+    BEFORE UPDATE OR DELETE ON audit_events
+    FOR EACH ROW EXECUTE FUNCTION audit_events_append_only();
@@ -0,0 +1,21 @@
+-- career_revisions keeps the content of a career after every change. A
+-- revision is numbered by the career version that introduced it.
+CREATE TABLE IF NOT EXISTS career_revisions (
+    jobid BIGINT NOT NULL REFERENCES career (jobid) ON DELETE CASCADE,
+    revision INT NOT NULL,
+    company VARCHAR(255) NOT NULL,
+    position VARCHAR(255) NOT NULL,
+    jobtype VARCHAR(255) NOT NULL,
+    description VARCHAR(255) NOT NULL,
+    startdate DATE NOT NULL,
+    enddate DATE NOT NULL,
+    author_email VARCHAR(255) NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    PRIMARY KEY (jobid, revision)
+);
+
+-- existing careers start their history with their current content
+INSERT INTO career_revisions (jobid, revision, company, position, jobtype, description, startdate, enddate, author_email)
+SELECT jobid, version, company, position, jobtype, description, startdate, enddate, ''
+FROM career
+ON CONFLICT DO NOTHING;
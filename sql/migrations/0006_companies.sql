@@ -0,0 +1,53 @@
+-- companies replaces the free-text career.company. A company is identified by
+-- its slug, so spellings such as "Acme", "ACME Inc" and "acme" are the same
+-- company; company_slug is the single definition of that rule and is mirrored
+-- by slug.Company in Go. In a database with a UTF-8 locale [:alnum:] matches
+-- the letters and digits of every script, so non-Latin names keep theirs; a
+-- name with none gets a hash of itself instead.
+CREATE OR REPLACE FUNCTION company_slug(name TEXT) RETURNS TEXT AS $$
+    SELECT COALESCE(NULLIF(trim(BOTH '-' FROM regexp_replace(
+        regexp_replace(lower(trim(name)), '[\s,]+(inc|incorporated|llc|ltd|limited|corp|corporation|co|gmbh)\.?$', ''),
+        '[^[:alnum:]]+', '-', 'g')), ''), 'company-' || left(md5(lower(trim(name))), 12))
+$$ LANGUAGE SQL IMMUTABLE;
+
+CREATE TABLE IF NOT EXISTS companies (
+    id BIGSERIAL PRIMARY KEY,
+    name VARCHAR(255) NOT NULL,
+    slug VARCHAR(255) NOT NULL,
+    website VARCHAR(255) NOT NULL DEFAULT '',
+    logo_url VARCHAR(255) NOT NULL DEFAULT '',
+    description VARCHAR(1000) NOT NULL DEFAULT '',
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    CONSTRAINT companies_slug_key UNIQUE (slug)
+);
+
+-- recruiters manage the careers of the companies they belong to
+CREATE TABLE IF NOT EXISTS company_recruiters (
+    company_id BIGINT NOT NULL REFERENCES companies (id) ON DELETE CASCADE,
+    userid BIGINT NOT NULL REFERENCES users (userid) ON DELETE CASCADE,
+    PRIMARY KEY (company_id, userid)
+);
+
+CREATE INDEX IF NOT EXISTS company_recruiters_userid_idx ON company_recruiters (userid);
+
+ALTER TABLE career ADD COLUMN IF NOT EXISTS company_id BIGINT REFERENCES companies (id);
+
+-- one company per slug, named after its most common spelling
+INSERT INTO companies (name, slug)
+SELECT DISTINCT ON (slug) name, slug
+FROM (
+    SELECT company_slug(company) AS slug, trim(company) AS name, count(*) AS uses
+    FROM career
+    GROUP BY 1, 2
+) AS spellings
+ORDER BY slug, uses DESC, name
+ON CONFLICT (slug) DO NOTHING;
+
+UPDATE career
+SET company_id = companies.id, company = companies.name
+FROM companies
+WHERE career.company_id IS NULL AND companies.slug = company_slug(career.company);
+
+ALTER TABLE career ALTER COLUMN company_id SET NOT NULL;
+
+CREATE INDEX IF NOT EXISTS career_company_id_idx ON career (company_id) WHERE deleted_at IS NULL;
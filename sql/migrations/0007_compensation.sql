@@ -0,0 +1,57 @@
+-- exchange_rates converts salaries between currencies for filtering. Each
+-- rate is the number of units of the currency one US dollar buys, so any
+-- stored currency can serve as the base. Rates are maintained by hand.
+CREATE TABLE IF NOT EXISTS exchange_rates (
+    currency CHAR(3) PRIMARY KEY,
+    units_per_usd NUMERIC(18, 6) NOT NULL CHECK (units_per_usd > 0),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+INSERT INTO exchange_rates (currency, units_per_usd) VALUES
+    ('USD', 1),
+    ('EUR', 0.92),
+    ('GBP', 0.79),
+    ('CHF', 0.88),
+    ('CAD', 1.36),
+    ('AUD', 1.52),
+    ('JPY', 150),
+    ('CNY', 7.2),
+    ('INR', 83),
+    ('SGD', 1.35),
+    ('SEK', 10.5),
+    ('BRL', 5)
+ON CONFLICT (currency) DO NOTHING;
+
+-- salaries are whole units of salary_currency per pay_period; all four
+-- columns are set together or not at all
+ALTER TABLE career
+    ADD COLUMN IF NOT EXISTS salary_min BIGINT,
+    ADD COLUMN IF NOT EXISTS salary_max BIGINT,
+    ADD COLUMN IF NOT EXISTS salary_currency CHAR(3) REFERENCES exchange_rates (currency),
+    ADD COLUMN IF NOT EXISTS pay_period VARCHAR(10),
+    ADD COLUMN IF NOT EXISTS equity BOOLEAN NOT NULL DEFAULT false,
+    ADD COLUMN IF NOT EXISTS salary_visible BOOLEAN NOT NULL DEFAULT true,
+    ADD CONSTRAINT career_salary_check CHECK (
+        (salary_min IS NULL AND salary_max IS NULL AND salary_currency IS NULL AND pay_period IS NULL)
+        OR (salary_min IS NOT NULL AND salary_max IS NOT NULL AND salary_currency IS NOT NULL AND pay_period IS NOT NULL
+            AND 0 <= salary_min AND salary_min <= salary_max)
+    ),
+    ADD CONSTRAINT career_pay_period_check CHECK (pay_period IN ('hourly', 'monthly', 'yearly'));
+
+ALTER TABLE career_revisions
+    ADD COLUMN IF NOT EXISTS salary_min BIGINT,
+    ADD COLUMN IF NOT EXISTS salary_max BIGINT,
+    ADD COLUMN IF NOT EXISTS salary_currency CHAR(3),
+    ADD COLUMN IF NOT EXISTS pay_period VARCHAR(10),
+    ADD COLUMN IF NOT EXISTS equity BOOLEAN NOT NULL DEFAULT false,
+    ADD COLUMN IF NOT EXISTS salary_visible BOOLEAN NOT NULL DEFAULT true;
+
+-- yearly_salary converts an amount paid per pay_period in currency to a
+-- yearly amount in base, assuming 2080 working hours a year. It is NULL when
+-- either currency has no rate.
+CREATE OR REPLACE FUNCTION yearly_salary(amount BIGINT, pay_period TEXT, currency TEXT, base TEXT) RETURNS NUMERIC AS $$
+    SELECT amount
+        * CASE pay_period WHEN 'hourly' THEN 2080 WHEN 'monthly' THEN 12 ELSE 1 END
+        / (SELECT units_per_usd FROM exchange_rates WHERE exchange_rates.currency = yearly_salary.currency)
+        * (SELECT units_per_usd FROM exchange_rates WHERE exchange_rates.currency = yearly_salary.base)
+$$ LANGUAGE SQL STABLE;
@@ -0,0 +1,26 @@
+-- locations lists where a career is based as a JSON array of
+-- {city, region, country, lat, lon}; coordinates are filled in from the
+-- gazetteer in geo/cities.csv when they are not given. remote_countries
+-- restricts where remote work is allowed from; empty means anywhere.
+ALTER TABLE career
+    ADD COLUMN IF NOT EXISTS locations JSONB NOT NULL DEFAULT '[]',
+    ADD COLUMN IF NOT EXISTS remote_policy VARCHAR(10) NOT NULL DEFAULT 'onsite',
+    ADD COLUMN IF NOT EXISTS remote_countries TEXT[] NOT NULL DEFAULT '{}',
+    ADD CONSTRAINT career_locations_check CHECK (jsonb_typeof(locations) = 'array'),
+    ADD CONSTRAINT career_remote_policy_check CHECK (remote_policy IN ('onsite', 'hybrid', 'remote')),
+    ADD CONSTRAINT career_remote_countries_check CHECK (remote_policy <> 'onsite' OR cardinality(remote_countries) = 0);
+
+ALTER TABLE career_revisions
+    ADD COLUMN IF NOT EXISTS locations JSONB NOT NULL DEFAULT '[]',
+    ADD COLUMN IF NOT EXISTS remote_policy VARCHAR(10) NOT NULL DEFAULT 'onsite',
+    ADD COLUMN IF NOT EXISTS remote_countries TEXT[] NOT NULL DEFAULT '{}';
+
+-- haversine_km is the great-circle distance in kilometres between two points
+-- given in degrees, on a sphere of the Earth's mean radius. geo.DistanceKm
+-- computes the same in Go.
+CREATE OR REPLACE FUNCTION haversine_km(lat1 FLOAT8, lon1 FLOAT8, lat2 FLOAT8, lon2 FLOAT8) RETURNS FLOAT8 AS $$
+    SELECT 2 * 6371.0088 * asin(sqrt(
+        power(sin(radians(lat2 - lat1) / 2), 2)
+        + cos(radians(lat1)) * cos(radians(lat2)) * power(sin(radians(lon2 - lon1) / 2), 2)
+    ))
+$$ LANGUAGE SQL IMMUTABLE;
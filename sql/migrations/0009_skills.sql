@@ -0,0 +1,53 @@
+-- skills is the taxonomy careers and profiles are tagged with. Names are
+-- unique ignoring case, and aliases map other spellings, stored in lower
+-- case, to a skill, so "golang" finds "Go".
+CREATE TABLE IF NOT EXISTS skills (
+    id BIGSERIAL PRIMARY KEY,
+    name VARCHAR(100) NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS skills_name_key ON skills (lower(name));
+
+CREATE TABLE IF NOT EXISTS skill_aliases (
+    alias VARCHAR(100) PRIMARY KEY CHECK (alias = lower(alias)),
+    skill_id BIGINT NOT NULL REFERENCES skills (id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS skill_aliases_skill_id_idx ON skill_aliases (skill_id);
+
+-- a career requires a skill or lists it as nice to have, optionally with a
+-- minimum number of years of experience
+CREATE TABLE IF NOT EXISTS career_skills (
+    jobid BIGINT NOT NULL REFERENCES career (jobid) ON DELETE CASCADE,
+    skill_id BIGINT NOT NULL REFERENCES skills (id),
+    required BOOLEAN NOT NULL DEFAULT true,
+    min_years SMALLINT CHECK (min_years BETWEEN 0 AND 50),
+    PRIMARY KEY (jobid, skill_id)
+);
+
+CREATE INDEX IF NOT EXISTS career_skills_skill_id_idx ON career_skills (skill_id);
+
+CREATE TABLE IF NOT EXISTS profile_skills (
+    profileid BIGINT NOT NULL REFERENCES profile (profileid) ON DELETE CASCADE,
+    skill_id BIGINT NOT NULL REFERENCES skills (id),
+    proficiency VARCHAR(12) NOT NULL CHECK (proficiency IN ('beginner', 'intermediate', 'advanced', 'expert')),
+    PRIMARY KEY (profileid, skill_id)
+);
+
+CREATE INDEX IF NOT EXISTS profile_skills_skill_id_idx ON profile_skills (skill_id);
+
+INSERT INTO skills (name) VALUES
+    ('Go'), ('PostgreSQL'), ('SQL'), ('JavaScript'), ('TypeScript'), ('Python'),
+    ('Java'), ('Rust'), ('React'), ('Docker'), ('Kubernetes'), ('AWS'), ('Terraform')
+ON CONFLICT DO NOTHING;
+
+INSERT INTO skill_aliases (alias, skill_id)
+SELECT alias, skills.id
+FROM (VALUES
+    ('golang', 'Go'), ('postgres', 'PostgreSQL'), ('psql', 'PostgreSQL'), ('js', 'JavaScript'),
+    ('ts', 'TypeScript'), ('py', 'Python'), ('reactjs', 'React'), ('react.js', 'React'),
+    ('k8s', 'Kubernetes'), ('amazon web services', 'AWS')
+) AS seed (alias, name)
+JOIN skills ON skills.name = seed.name
+ON CONFLICT DO NOTHING;
@@ -0,0 +1,26 @@
+-- seniority is the level a career is hired at. Each level expects a range of
+-- years of experience, which recommend.SeniorityYears lists.
+ALTER TABLE career
+    ADD COLUMN IF NOT EXISTS seniority VARCHAR(10),
+    ADD CONSTRAINT career_seniority_check CHECK (seniority IN ('intern', 'junior', 'mid', 'senior', 'lead'));
+
+ALTER TABLE career_revisions
+    ADD COLUMN IF NOT EXISTS seniority VARCHAR(10);
+
+-- what a candidate is looking for, used to recommend careers. locations lists
+-- where the candidate is willing to work, in the same form as
+-- career.locations; the first one is where they live. expected_salary is a
+-- yearly amount in salary_currency and is set together with it or not at all.
+ALTER TABLE profile
+    ADD COLUMN IF NOT EXISTS locations JSONB NOT NULL DEFAULT '[]',
+    ADD COLUMN IF NOT EXISTS remote_preference VARCHAR(10) NOT NULL DEFAULT 'any',
+    ADD COLUMN IF NOT EXISTS expected_salary BIGINT,
+    ADD COLUMN IF NOT EXISTS salary_currency CHAR(3) REFERENCES exchange_rates (currency),
+    ADD COLUMN IF NOT EXISTS years_experience SMALLINT,
+    ADD CONSTRAINT profile_locations_check CHECK (jsonb_typeof(locations) = 'array'),
+    ADD CONSTRAINT profile_remote_preference_check CHECK (remote_preference IN ('onsite', 'hybrid', 'remote', 'any')),
+    ADD CONSTRAINT profile_expected_salary_check CHECK (
+        (expected_salary IS NULL AND salary_currency IS NULL)
+        OR (expected_salary IS NOT NULL AND salary_currency IS NOT NULL AND expected_salary >= 0)
+    ),
+    ADD CONSTRAINT profile_years_experience_check CHECK (years_experience BETWEEN 0 AND 60);
@@ -0,0 +1,14 @@
+-- saved_careers are the careers a user bookmarked, with a private note and
+-- optionally the name of one of their own lists, such as "to apply". Lists
+-- exist as long as a saved career is in them. Saves stay when a career is
+-- deleted, so the user can see it went, and go when it is purged.
+CREATE TABLE IF NOT EXISTS saved_careers (
+    userid BIGINT NOT NULL REFERENCES users (userid) ON DELETE CASCADE,
+    jobid BIGINT NOT NULL REFERENCES career (jobid) ON DELETE CASCADE,
+    note VARCHAR(1000) NOT NULL DEFAULT '',
+    list_name VARCHAR(100) CHECK (list_name <> ''),
+    saved_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    PRIMARY KEY (userid, jobid)
+);
+
+CREATE INDEX IF NOT EXISTS saved_careers_jobid_idx ON saved_careers (jobid);
@@ -0,0 +1,23 @@
+-- saved_searches are career searches a user keeps to be alerted about. Empty
+-- strings and NULLs leave a filter out, as in GET /careers. Each run of the
+-- job alert digest tells the user about the open careers matching a search
+-- whose jobid is above last_jobid, then moves last_jobid up to the newest
+-- career it looked at. The unsubscribe_token in the digest's links turns
+-- alerts off without logging in.
+CREATE TABLE IF NOT EXISTS saved_searches (
+    id BIGSERIAL PRIMARY KEY,
+    userid BIGINT NOT NULL REFERENCES users (userid) ON DELETE CASCADE,
+    name VARCHAR(100) NOT NULL CHECK (name <> ''),
+    keywords VARCHAR(255) NOT NULL DEFAULT '',
+    jobtype VARCHAR(255) NOT NULL DEFAULT '',
+    near VARCHAR(255) NOT NULL DEFAULT '',
+    radius_km DOUBLE PRECISION CHECK (radius_km > 0),
+    salary_min BIGINT CHECK (salary_min >= 0),
+    currency CHAR(3) REFERENCES exchange_rates (currency),
+    alerts BOOLEAN NOT NULL DEFAULT true,
+    unsubscribe_token TEXT NOT NULL UNIQUE,
+    last_jobid BIGINT NOT NULL DEFAULT 0,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS saved_searches_name_key ON saved_searches (userid, lower(name));
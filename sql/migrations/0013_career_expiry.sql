@@ -0,0 +1,17 @@
+-- a career expires once its enddate has passed. The expiry job stamps
+-- closed_at on expired careers, and extending a career clears it again.
+-- career_expiry_reminders holds, per admin, the enddate the last "expiring
+-- soon" reminder they received was about, so each admin is reminded of each
+-- end date once, an admin whose delivery failed is retried alone, and moving
+-- the end date arms the reminder again.
+ALTER TABLE career ADD COLUMN IF NOT EXISTS closed_at TIMESTAMPTZ;
+
+CREATE INDEX IF NOT EXISTS career_enddate_idx ON career (enddate) WHERE deleted_at IS NULL;
+
+CREATE TABLE IF NOT EXISTS career_expiry_reminders (
+    jobid BIGINT NOT NULL REFERENCES career (jobid) ON DELETE CASCADE,
+    userid BIGINT NOT NULL REFERENCES users (userid) ON DELETE CASCADE,
+    enddate DATE NOT NULL,
+    sent_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    PRIMARY KEY (jobid, userid)
+);
@@ -0,0 +1,18 @@
+-- a career goes live at publish_at. Until the publish job sets published_at
+-- and sends the career webhook, users cannot see it. published_seq numbers
+-- careers in the order they went live, and job alerts follow it instead of
+-- the jobid, so a career scheduled early and published late is still
+-- alerted about.
+CREATE SEQUENCE IF NOT EXISTS career_published_seq;
+
+ALTER TABLE career ADD COLUMN IF NOT EXISTS publish_at TIMESTAMPTZ NOT NULL DEFAULT now();
+ALTER TABLE career ADD COLUMN IF NOT EXISTS published_at TIMESTAMPTZ;
+ALTER TABLE career ADD COLUMN IF NOT EXISTS published_seq BIGINT UNIQUE;
+
+-- careers posted before scheduling existed are live, in jobid order
+UPDATE career SET published_at = now(), published_seq = jobid WHERE published_seq IS NULL;
+SELECT setval('career_published_seq', (SELECT COALESCE(max(jobid), 0) + 1 FROM career), false);
+
+CREATE INDEX IF NOT EXISTS career_publish_at_idx ON career (publish_at) WHERE published_at IS NULL;
+
+ALTER TABLE saved_searches RENAME COLUMN last_jobid TO last_published_seq;
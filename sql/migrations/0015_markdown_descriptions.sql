@@ -0,0 +1,18 @@
+-- career descriptions are Markdown with no practical length limit. The
+-- application stores each with its sanitized HTML and plain-text excerpt,
+-- rendered whenever the description is written.
+ALTER TABLE career ALTER COLUMN description TYPE TEXT;
+ALTER TABLE career_revisions ALTER COLUMN description TYPE TEXT;
+ALTER TABLE career ADD COLUMN IF NOT EXISTS description_html TEXT NOT NULL DEFAULT '';
+ALTER TABLE career ADD COLUMN IF NOT EXISTS description_excerpt TEXT NOT NULL DEFAULT '';
+
+-- the descriptions so far are plain text: a paragraph of the escaped text,
+-- and the text itself with its whitespace collapsed
+UPDATE career
+SET description_html = CASE WHEN btrim(description) = '' THEN '' ELSE
+        '<p>' || replace(replace(replace(replace(replace(btrim(description),
+            '&', '&amp;'), '<', '&lt;'), '>', '&gt;'), '"', '&#34;'), '''', '&#39;') || E'</p>\n' END,
+    description_excerpt = CASE WHEN length(regexp_replace(btrim(description), '\s+', ' ', 'g')) <= 200
+        THEN regexp_replace(btrim(description), '\s+', ' ', 'g')
+        ELSE left(regexp_replace(btrim(description), '\s+', ' ', 'g'), 199) || '…' END
+WHERE description_html = '' AND description <> '';
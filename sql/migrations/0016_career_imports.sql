@@ -0,0 +1,20 @@
+-- a career import is one CSV or JSON Lines file of careers. The row is
+-- written outside the import's own transaction, so its progress can be
+-- followed while a large file is imported in the background.
+CREATE TABLE IF NOT EXISTS career_imports (
+    id BIGSERIAL PRIMARY KEY,
+    format TEXT NOT NULL CHECK (format IN ('csv', 'jsonl')),
+    mode TEXT NOT NULL CHECK (mode IN ('atomic', 'best_effort')),
+    dry_run BOOLEAN NOT NULL,
+    status TEXT NOT NULL DEFAULT 'running' CHECK (status IN ('running', 'succeeded', 'partial', 'failed')),
+    total_rows INTEGER NOT NULL,
+    processed_rows INTEGER NOT NULL DEFAULT 0,
+    created_rows INTEGER NOT NULL DEFAULT 0,
+    failed_rows INTEGER NOT NULL DEFAULT 0,
+    jobids BIGINT[] NOT NULL DEFAULT '{}',
+    errors JSONB NOT NULL DEFAULT '[]',
+    ignored_columns TEXT[] NOT NULL DEFAULT '{}',
+    created_by TEXT NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    finished_at TIMESTAMPTZ
+);
@@ -0,0 +1,105 @@
+// Package migrations applies the numbered SQL files in this directory in
+// order, recording each applied version in schema_migrations. sqlc reads the
+// same files as its schema.
+package migrations
+
+import (
+	"context"
+	"embed"
+	"fmt"
+	"io/fs"
+	"log/slog"
+	"sort"
+	"strings"
+
+	"github.com/jackc/pgconn"
+	"github.com/jackc/pgx/v4"
+)
+
+//go:embed *.sql
+var files embed.FS
+
+// lockID serialises concurrent migrators through pg_advisory_xact_lock
+const lockID = 7_141_832_001
+
+// DB is satisfied by *pgx.Conn, *pgxpool.Pool and pgx.Tx
+type DB interface {
+	Exec(ctx context.Context, sql string, arguments ...interface{}) (pgconn.CommandTag, error)
+	Begin(ctx context.Context) (pgx.Tx, error)
+}
+
+// Versions lists the embedded migrations in the order they are applied
+func Versions() ([]string, error) {
+	names, err := fs.Glob(files, "*.sql")
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(names)
+	versions := make([]string, len(names))
+	for i, name := range names {
+		versions[i] = strings.TrimSuffix(name, ".sql")
+	}
+	return versions, nil
+}
+
+// Apply runs every migration that has not been applied yet, each in its own
+// transaction
+func Apply(ctx context.Context, db DB) error {
+	_, err := db.Exec(ctx, `CREATE TABLE IF NOT EXISTS schema_migrations (
+		version TEXT PRIMARY KEY,
+		applied_at TIMESTAMPTZ NOT NULL DEFAULT now()
+	)`)
+	if err != nil {
+		return fmt.Errorf("creating schema_migrations: %w", err)
+	}
+
+	versions, err := Versions()
+	if err != nil {
+		return err
+	}
+	for _, version := range versions {
+		if err := apply(ctx, db, version); err != nil {
+			return fmt.Errorf("migration %s: %w", version, err)
+		}
+	}
+	return nil
+}
+
+func apply(ctx context.Context, db DB, version string) error {
+	script, err := files.ReadFile(version + ".sql")
+	if err != nil {
+		return err
+	}
+
+	tx, err := db.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	if _, err := tx.Exec(ctx, "SELECT pg_advisory_xact_lock($1)", lockID); err != nil {
+		return err
+	}
+
+	var applied bool
+	err = tx.QueryRow(ctx, "SELECT EXISTS (SELECT 1 FROM schema_migrations WHERE version = $1)", version).Scan(&applied)
+	if err != nil {
+		return err
+	}
+	if applied {
+		return nil
+	}
+
+	if _, err := tx.Exec(ctx, string(script)); err != nil {
+		return err
+	}
+	if _, err := tx.Exec(ctx, "INSERT INTO schema_migrations (version) VALUES ($1)", version); err != nil {
+		return err
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return err
+	}
+
+	slog.Info("migration applied", slog.String("version", version))
+	return nil
+}
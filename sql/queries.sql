@@ -6,60 +6,537 @@ RETURNING *;
 
 -- name: GetUserByEmail :one
 SELECT * FROM users
-WHERE email = $1 LIMIT 1;
+WHERE email = $1 AND deleted_at IS NULL LIMIT 1;
 
 -- name: GetUserByPhoneNumber :one
 SELECT * FROM users
-WHERE phonenumber = $1 LIMIT 1;
+WHERE phonenumber = $1 AND deleted_at IS NULL LIMIT 1;
 
+-- name: GetUserByIdForUpdate :one
+SELECT * FROM users
+WHERE userid = $1 AND deleted_at IS NULL LIMIT 1
+FOR UPDATE;
+
+-- name: DeleteUserById :one
+UPDATE users
+SET deleted_at = now()
+WHERE userid = $1 AND deleted_at IS NULL
+RETURNING *;
+
+-- name: GetDeletedUserByIdForUpdate :one
+SELECT * FROM users
+WHERE userid = $1 AND deleted_at IS NOT NULL LIMIT 1
+FOR UPDATE;
+
+-- name: RestoreUserById :one
+UPDATE users
+SET deleted_at = NULL
+WHERE userid = $1 AND deleted_at IS NOT NULL
+RETURNING *;
+
+-- name: ListDeletedUsers :many
+SELECT * FROM users
+WHERE deleted_at IS NOT NULL
+ORDER BY deleted_at DESC, userid;
+
+-- name: PurgeUsers :execrows
+DELETE FROM users
+WHERE deleted_at < @before::timestamptz;
+
+-- CreateCareer publishes the career right away unless publish_at is still
+-- to come. publish_at is compared with the clock rather than now(), the
+-- start of the transaction, as it defaults to the time of the request.
 -- name: CreateCareer :one
-INSERT INTO career (Company,Position,Jobtype,Description,StartDate,EndDate)
-VALUES ($1, $2,$3,$4,$5,$6)
+INSERT INTO career (Company,Position,Jobtype,Description,StartDate,EndDate,company_id,
+    salary_min,salary_max,salary_currency,pay_period,equity,salary_visible,
+    locations,remote_policy,remote_countries,seniority,
+    publish_at,published_at,published_seq,description_html,description_excerpt)
+VALUES ($1, $2,$3,$4,$5,$6,$7,$8,$9,$10,$11,$12,$13,$14,$15,$16,$17,$18,
+    CASE WHEN $18 <= clock_timestamp() THEN clock_timestamp() END,
+    CASE WHEN $18 <= clock_timestamp() THEN nextval('career_published_seq') END,
+    $19,$20)
 RETURNING *;
 
 -- name: GetCareerByJobId :one
 SELECT * FROM career
-WHERE jobid = $1 LIMIT 1;
+WHERE jobid = $1 AND deleted_at IS NULL LIMIT 1;
+
+-- name: GetCareerByJobIdForUpdate :one
+SELECT * FROM career
+WHERE jobid = $1 AND deleted_at IS NULL LIMIT 1
+FOR UPDATE;
 
 -- name: GetAllCareerDetails :many
-SELECT * FROM career;
+SELECT * FROM career
+WHERE deleted_at IS NULL;
+
+-- ListCareers filters by salary, comparing yearly amounts in the currency
+-- given. Careers without a salary, or whose salary is hidden, never match a
+-- salary filter. Given a point, only careers with a location within
+-- radius_km of it are listed. Given skills, only careers tagged with all of
+-- them when all_skills is set, or with any of them otherwise, are listed.
+-- Every keyword must appear in the position, company or description, and
+-- published_after limits the list to careers published after that
+-- published_seq. Careers whose enddate has passed are left out unless
+-- include_expired is set, and careers not published yet unless
+-- include_unpublished is.
+-- name: ListCareers :many
+SELECT * FROM career
+WHERE deleted_at IS NULL
+  AND (sqlc.narg(salary_min)::bigint IS NULL
+       OR (salary_visible AND yearly_salary(salary_max, pay_period, salary_currency, sqlc.arg(currency)::text) >= sqlc.narg(salary_min)))
+  AND (sqlc.narg(salary_max)::bigint IS NULL
+       OR (salary_visible AND yearly_salary(salary_min, pay_period, salary_currency, sqlc.arg(currency)::text) <= sqlc.narg(salary_max)))
+  AND (sqlc.narg(near_lat)::float8 IS NULL
+       OR EXISTS (SELECT 1 FROM jsonb_to_recordset(career.locations) AS location(lat float8, lon float8)
+                  WHERE haversine_km(location.lat, location.lon, sqlc.narg(near_lat), sqlc.narg(near_lon)::float8) <= sqlc.arg(radius_km)::float8))
+  AND NOT EXISTS (SELECT 1 FROM unnest(sqlc.arg(keywords)::text[]) AS keyword
+                  WHERE strpos(lower(position || ' ' || company || ' ' || description), lower(keyword)) = 0)
+  AND (sqlc.narg(jobtype)::text IS NULL OR lower(jobtype) = lower(sqlc.narg(jobtype)))
+  AND (sqlc.narg(published_after)::bigint IS NULL OR published_seq > sqlc.narg(published_after))
+  AND (sqlc.arg(include_expired)::bool OR enddate >= current_date)
+  AND (sqlc.arg(include_unpublished)::bool OR published_at IS NOT NULL)
+  AND (COALESCE(cardinality(sqlc.arg(skill_ids)::bigint[]), 0) = 0
+       OR (SELECT count(*) FROM career_skills
+           WHERE career_skills.jobid = career.jobid AND career_skills.skill_id = ANY(sqlc.arg(skill_ids)::bigint[]))
+          >= CASE WHEN sqlc.arg(all_skills)::bool THEN cardinality(sqlc.arg(skill_ids)::bigint[]) ELSE 1 END)
+ORDER BY jobid;
 
 -- name: UpdateCareerByJobId :one
 UPDATE career
-SET company=$1,position=$2,jobtype=$3,description=$4
-WHERE jobid = $5
+SET company=$1,position=$2,jobtype=$3,description=$4,company_id=$5,version=version+1,
+    description_html=$7,description_excerpt=$8
+WHERE jobid = $6 AND deleted_at IS NULL
 RETURNING *;
 
 -- name: DeleteCareerByJobId :one
-DELETE
-FROM career
-WHERE jobid = $1
+UPDATE career
+SET deleted_at = now()
+WHERE jobid = $1 AND deleted_at IS NULL
+RETURNING *;
+
+-- name: GetDeletedCareerByJobIdForUpdate :one
+SELECT * FROM career
+WHERE jobid = $1 AND deleted_at IS NOT NULL LIMIT 1
+FOR UPDATE;
+
+-- name: RestoreCareerByJobId :one
+UPDATE career
+SET deleted_at = NULL, version = version + 1
+WHERE jobid = $1 AND deleted_at IS NOT NULL
 RETURNING *;
 
+-- name: ListDeletedCareers :many
+SELECT * FROM career
+WHERE deleted_at IS NOT NULL
+ORDER BY deleted_at DESC, jobid;
+
+-- name: PurgeCareers :execrows
+DELETE FROM career
+WHERE deleted_at < @before::timestamptz;
+
 
 -- name: CreateProfile :one
-INSERT INTO profile (FullName,Age,Gender,Address)
-VALUES ($1, $2,$3,$4)
+INSERT INTO profile (UserID,FullName,Age,Gender,Address,PhoneNumber,
+    locations,remote_preference,expected_salary,salary_currency,years_experience)
+VALUES ($1, $2,$3,$4,$5,$6,$7,$8,$9,$10,$11)
 RETURNING *;
 
 -- name: GetProfileByuserId :one
 SELECT * FROM profile
-WHERE userid = $1 LIMIT 1;
+WHERE userid = $1 AND deleted_at IS NULL LIMIT 1;
+
+-- name: GetProfileByuserIdForUpdate :one
+SELECT * FROM profile
+WHERE userid = $1 AND deleted_at IS NULL LIMIT 1
+FOR UPDATE;
 
 -- name: GetAllProfileDetails :many
-SELECT * FROM profile;
+SELECT * FROM profile
+WHERE deleted_at IS NULL;
 
 -- name: UpdateProfileByuserId :one
 UPDATE profile
-SET FullName=$1,Age=$2,Gender=$3,Address=$4
-WHERE userid = $5
+SET FullName=$1,Age=$2,Gender=$3,Address=$4,version=version+1
+WHERE userid = $5 AND deleted_at IS NULL
 RETURNING *;
 
 -- name: DeleteProfileByUserId :one
-DELETE
-FROM profile
-WHERE userid = $1
+UPDATE profile
+SET deleted_at = now()
+WHERE userid = $1 AND deleted_at IS NULL
+RETURNING *;
+
+-- name: GetLatestDeletedProfileByUserIdForUpdate :one
+SELECT * FROM profile
+WHERE userid = $1 AND deleted_at IS NOT NULL
+ORDER BY deleted_at DESC, profileid DESC
+LIMIT 1
+FOR UPDATE;
+
+-- name: RestoreProfileById :one
+UPDATE profile
+SET deleted_at = NULL, version = version + 1
+WHERE profileid = $1 AND deleted_at IS NOT NULL
 RETURNING *;
 
+-- name: DeleteProfilesOfUser :exec
+UPDATE profile
+SET deleted_at = $2
+WHERE userid = $1 AND deleted_at IS NULL;
+
+-- name: RestoreProfilesOfUser :exec
+UPDATE profile
+SET deleted_at = NULL, version = version + 1
+WHERE profile.userid = $1 AND profile.deleted_at = (SELECT u.deleted_at FROM users u WHERE u.userid = $1);
+
+-- name: ListDeletedProfiles :many
+SELECT * FROM profile
+WHERE deleted_at IS NOT NULL
+ORDER BY deleted_at DESC, profileid;
+
+-- name: PurgeProfiles :execrows
+DELETE FROM profile
+WHERE deleted_at < @before::timestamptz;
+
 -- name: GetallusersEmail :many
-SELECT email FROM users WHERE role = 'user';
+SELECT email FROM users WHERE role = 'user' AND deleted_at IS NULL;
+
+-- name: CreateAuditEvent :exec
+INSERT INTO audit_events (actor_email, actor_role, action, resource_type, resource_id, changes, request_id, ip)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8);
+
+-- name: ListAuditEvents :many
+SELECT * FROM audit_events
+WHERE (sqlc.narg(actor)::text IS NULL OR actor_email = sqlc.narg(actor))
+  AND (sqlc.narg(resource_type)::text IS NULL OR resource_type = sqlc.narg(resource_type))
+  AND (sqlc.narg(resource_id)::bigint IS NULL OR resource_id = sqlc.narg(resource_id))
+  AND (sqlc.narg(since)::timestamptz IS NULL OR occurred_at >= sqlc.narg(since))
+  AND (sqlc.narg(until)::timestamptz IS NULL OR occurred_at < sqlc.narg(until))
+  AND (sqlc.narg(before_id)::bigint IS NULL OR id < sqlc.narg(before_id))
+ORDER BY id DESC
+LIMIT sqlc.arg(max_rows);
+
+-- name: CreateCareerRevision :exec
+INSERT INTO career_revisions (jobid, revision, company, position, jobtype, description, startdate, enddate, author_email,
+    salary_min, salary_max, salary_currency, pay_period, equity, salary_visible,
+    locations, remote_policy, remote_countries, seniority)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18, $19);
+
+-- name: ListCareerRevisions :many
+SELECT * FROM career_revisions
+WHERE jobid = sqlc.arg(jobid)
+  AND (sqlc.narg(at)::timestamptz IS NULL OR created_at <= sqlc.narg(at))
+ORDER BY revision DESC;
+
+-- name: GetCareerRevision :one
+SELECT * FROM career_revisions
+WHERE jobid = $1 AND revision = $2;
+
+-- name: CreateCompany :one
+INSERT INTO companies (name, slug, website, logo_url, description)
+VALUES ($1, $2, $3, $4, $5)
+RETURNING *;
+
+-- name: GetCompanyBySlug :one
+SELECT * FROM companies
+WHERE slug = $1;
+
+-- name: ListCompanies :many
+SELECT * FROM companies
+ORDER BY name, id;
+
+-- name: ListOpenCareersByCompany :many
+SELECT * FROM career
+WHERE company_id = $1 AND deleted_at IS NULL AND enddate >= current_date AND published_at IS NOT NULL
+ORDER BY startdate, jobid;
+
+-- name: AddCompanyRecruiter :exec
+INSERT INTO company_recruiters (company_id, userid)
+VALUES ($1, $2);
+
+-- name: RemoveCompanyRecruiter :execrows
+DELETE FROM company_recruiters
+WHERE company_id = $1 AND userid = $2;
+
+-- name: ListCompanyRecruiters :many
+SELECT users.* FROM users
+JOIN company_recruiters ON company_recruiters.userid = users.userid
+WHERE company_recruiters.company_id = $1 AND users.deleted_at IS NULL
+ORDER BY users.userid;
+
+-- name: IsCompanyRecruiter :one
+SELECT EXISTS (
+    SELECT 1 FROM company_recruiters
+    JOIN users ON users.userid = company_recruiters.userid
+    WHERE company_recruiters.company_id = $1 AND users.email = $2 AND users.deleted_at IS NULL
+);
+
+-- name: HasExchangeRate :one
+SELECT EXISTS (SELECT 1 FROM exchange_rates WHERE currency = $1);
+
+-- name: ListExchangeRates :many
+SELECT currency, units_per_usd::float8 AS units_per_usd FROM exchange_rates
+ORDER BY currency;
+
+-- name: CreateSkill :one
+INSERT INTO skills (name)
+VALUES ($1)
+RETURNING *;
+
+-- name: AddSkillAlias :exec
+INSERT INTO skill_aliases (alias, skill_id)
+VALUES (lower(sqlc.arg(alias)), sqlc.arg(skill_id));
+
+-- FindSkill resolves a skill by its name or one of its aliases, ignoring case
+-- name: FindSkill :one
+SELECT skills.* FROM skills
+WHERE lower(skills.name) = lower(sqlc.arg(name))
+   OR skills.id = (SELECT skill_aliases.skill_id FROM skill_aliases WHERE skill_aliases.alias = lower(sqlc.arg(name)));
+
+-- name: GetSkill :one
+SELECT * FROM skills
+WHERE id = $1;
+
+-- name: ListSkillAliases :many
+SELECT alias FROM skill_aliases
+WHERE skill_id = $1
+ORDER BY alias;
+
+-- ListSkills lists the skills whose name or one of whose aliases starts with
+-- prefix, ignoring case
+-- name: ListSkills :many
+SELECT skills.id, skills.name,
+    ARRAY(SELECT skill_aliases.alias FROM skill_aliases WHERE skill_aliases.skill_id = skills.id ORDER BY skill_aliases.alias)::text[] AS aliases
+FROM skills
+WHERE starts_with(lower(skills.name), lower(sqlc.arg(prefix)))
+   OR EXISTS (SELECT 1 FROM skill_aliases
+              WHERE skill_aliases.skill_id = skills.id AND starts_with(skill_aliases.alias, lower(sqlc.arg(prefix))))
+ORDER BY lower(skills.name), skills.id
+LIMIT sqlc.arg(max_rows);
+
+-- name: DeleteCareerSkills :exec
+DELETE FROM career_skills
+WHERE jobid = $1;
+
+-- name: AddCareerSkill :exec
+INSERT INTO career_skills (jobid, skill_id, required, min_years)
+VALUES ($1, $2, $3, $4);
+
+-- name: ListCareerSkills :many
+SELECT career_skills.skill_id, skills.name, career_skills.required, career_skills.min_years
+FROM career_skills
+JOIN skills ON skills.id = career_skills.skill_id
+WHERE career_skills.jobid = $1
+ORDER BY career_skills.required DESC, lower(skills.name);
+
+-- name: DeleteProfileSkills :exec
+DELETE FROM profile_skills
+WHERE profileid = $1;
+
+-- name: AddProfileSkill :exec
+INSERT INTO profile_skills (profileid, skill_id, proficiency)
+VALUES ($1, $2, $3);
+
+-- name: ListProfileSkills :many
+SELECT profile_skills.skill_id, skills.name, profile_skills.proficiency
+FROM profile_skills
+JOIN skills ON skills.id = profile_skills.skill_id
+WHERE profile_skills.profileid = $1
+ORDER BY lower(skills.name);
+
+-- TouchCareer bumps the version of a career whose skills changed
+-- name: TouchCareer :one
+UPDATE career
+SET version = version + 1
+WHERE jobid = $1 AND deleted_at IS NULL
+RETURNING *;
+
+-- TouchProfile bumps the version of a profile whose skills changed
+-- name: TouchProfile :one
+UPDATE profile
+SET version = version + 1
+WHERE profileid = $1 AND deleted_at IS NULL
+RETURNING *;
+
+-- name: ListOpenCareers :many
+SELECT * FROM career
+WHERE deleted_at IS NULL AND enddate >= current_date AND published_at IS NOT NULL
+ORDER BY jobid;
+
+-- name: ListSkillsOfCareers :many
+SELECT career_skills.jobid, career_skills.skill_id, skills.name, career_skills.required, career_skills.min_years
+FROM career_skills
+JOIN skills ON skills.id = career_skills.skill_id
+WHERE career_skills.jobid = ANY(sqlc.arg(jobids)::bigint[])
+ORDER BY career_skills.jobid, career_skills.required DESC, lower(skills.name);
+
+-- name: ListSkillsOfProfiles :many
+SELECT profile_skills.profileid, profile_skills.skill_id, skills.name, profile_skills.proficiency
+FROM profile_skills
+JOIN skills ON skills.id = profile_skills.skill_id
+WHERE profile_skills.profileid = ANY(sqlc.arg(profileids)::bigint[])
+ORDER BY profile_skills.profileid, lower(skills.name);
+
+-- name: GetSavedCareerForUpdate :one
+SELECT * FROM saved_careers
+WHERE userid = $1 AND jobid = $2
+FOR UPDATE;
+
+-- name: CreateSavedCareer :one
+INSERT INTO saved_careers (userid, jobid, note, list_name)
+VALUES ($1, $2, $3, $4)
+RETURNING *;
+
+-- name: UpdateSavedCareer :one
+UPDATE saved_careers
+SET note = $3, list_name = $4
+WHERE userid = $1 AND jobid = $2
+RETURNING *;
+
+-- name: DeleteSavedCareer :one
+DELETE FROM saved_careers
+WHERE userid = $1 AND jobid = $2
+RETURNING *;
+
+-- ListSavedCareers lists a user's saved careers, deleted ones included,
+-- newest save first. Given a list name, only the careers in that list are
+-- listed, ignoring case.
+-- name: ListSavedCareers :many
+SELECT sqlc.embed(saved_careers), sqlc.embed(career)
+FROM saved_careers
+JOIN career ON career.jobid = saved_careers.jobid
+WHERE saved_careers.userid = sqlc.arg(userid)
+  AND (sqlc.narg(list_name)::text IS NULL OR lower(saved_careers.list_name) = lower(sqlc.narg(list_name)))
+ORDER BY saved_careers.saved_at DESC, saved_careers.jobid;
+
+-- name: ListSavedCareerLists :many
+SELECT list_name::text AS name, count(*) AS careers
+FROM saved_careers
+WHERE userid = $1 AND list_name IS NOT NULL
+GROUP BY list_name
+ORDER BY lower(list_name), list_name;
+
+-- name: CountCareerSaves :one
+SELECT count(*) FROM saved_careers
+WHERE jobid = $1;
+
+-- name: CreateSavedSearch :one
+INSERT INTO saved_searches (userid, name, keywords, jobtype, near, radius_km, salary_min, currency, alerts, unsubscribe_token, last_published_seq)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+RETURNING *;
+
+-- name: GetSavedSearchForUpdate :one
+SELECT * FROM saved_searches
+WHERE id = $1 AND userid = $2
+FOR UPDATE;
+
+-- name: UpdateSavedSearch :one
+UPDATE saved_searches
+SET name = $3, keywords = $4, jobtype = $5, near = $6, radius_km = $7, salary_min = $8, currency = $9, alerts = $10
+WHERE id = $1 AND userid = $2
+RETURNING *;
+
+-- name: DeleteSavedSearch :one
+DELETE FROM saved_searches
+WHERE id = $1 AND userid = $2
+RETURNING *;
+
+-- name: ListSavedSearches :many
+SELECT * FROM saved_searches
+WHERE userid = $1
+ORDER BY id;
+
+-- ListAlertingSearches lists the searches with alerts on of the users who
+-- are not deleted, grouped by user
+-- name: ListAlertingSearches :many
+SELECT sqlc.embed(saved_searches), users.username, users.email
+FROM saved_searches
+JOIN users ON users.userid = saved_searches.userid
+WHERE saved_searches.alerts AND users.deleted_at IS NULL
+ORDER BY saved_searches.userid, saved_searches.id;
+
+-- name: AdvanceSavedSearch :exec
+UPDATE saved_searches
+SET last_published_seq = sqlc.arg(last_published_seq)
+WHERE id = sqlc.arg(id) AND last_published_seq < sqlc.arg(last_published_seq);
+
+-- name: GetSavedSearchByToken :one
+SELECT * FROM saved_searches
+WHERE unsubscribe_token = $1;
+
+-- name: GetSavedSearchByTokenForUpdate :one
+SELECT * FROM saved_searches
+WHERE unsubscribe_token = $1
+FOR UPDATE;
+
+-- name: DisableSavedSearchAlerts :one
+UPDATE saved_searches
+SET alerts = false
+WHERE id = $1
+RETURNING *;
+
+-- name: GetLastPublishedSeq :one
+SELECT COALESCE(max(published_seq), 0)::bigint FROM career;
+
+-- CloseExpiredCareers stamps closed_at on the careers whose enddate has
+-- passed
+-- name: CloseExpiredCareers :many
+UPDATE career
+SET closed_at = now(), version = version + 1
+WHERE deleted_at IS NULL AND closed_at IS NULL AND enddate < current_date
+RETURNING *;
+
+-- name: ExtendCareer :one
+UPDATE career
+SET enddate = sqlc.arg(enddate), closed_at = NULL, version = version + 1
+WHERE jobid = sqlc.arg(jobid) AND deleted_at IS NULL
+RETURNING *;
+
+-- ListExpiringCareers lists the open careers ending by until that the admin
+-- has not been reminded of for their current enddate
+-- name: ListExpiringCareers :many
+SELECT * FROM career
+WHERE deleted_at IS NULL AND enddate >= current_date AND enddate <= sqlc.arg(until)::date
+  AND NOT EXISTS (SELECT 1 FROM career_expiry_reminders
+                  WHERE career_expiry_reminders.jobid = career.jobid
+                    AND career_expiry_reminders.userid = sqlc.arg(userid)
+                    AND career_expiry_reminders.enddate = career.enddate)
+ORDER BY enddate, jobid;
+
+-- name: SaveExpiryReminder :exec
+INSERT INTO career_expiry_reminders (jobid, userid, enddate)
+VALUES ($1, $2, $3)
+ON CONFLICT (jobid, userid) DO UPDATE SET enddate = EXCLUDED.enddate, sent_at = now();
+
+-- name: ListAdmins :many
+SELECT userid, email FROM users
+WHERE role = 'admin' AND deleted_at IS NULL
+ORDER BY userid;
+
+-- PublishDueCareers publishes the careers whose publish_at has come
+-- name: PublishDueCareers :many
+UPDATE career
+SET published_at = now(), published_seq = nextval('career_published_seq'), version = version + 1
+WHERE deleted_at IS NULL AND published_at IS NULL AND publish_at <= now()
+RETURNING *;
+
+-- name: CreateCareerImport :one
+INSERT INTO career_imports (format, mode, dry_run, total_rows, ignored_columns, created_by)
+VALUES ($1, $2, $3, $4, $5, $6)
+RETURNING *;
+
+-- name: GetCareerImport :one
+SELECT * FROM career_imports
+WHERE id = $1;
+
+-- name: UpdateCareerImportProgress :exec
+UPDATE career_imports
+SET processed_rows = $2
+WHERE id = $1;
+
+-- name: FinishCareerImport :one
+UPDATE career_imports
+SET status = $2, processed_rows = $3, created_rows = $4, failed_rows = $5, jobids = $6, errors = $7, finished_at = now()
+WHERE id = $1
+RETURNING *;
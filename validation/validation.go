@@ -0,0 +1,241 @@
+package validation
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+	"strings"
+	"time"
+	"unicode"
+
+	"jobApps/apperror"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	"github.com/go-playground/validator/v10"
+)
+
+var validate = newValidator()
+
+func newValidator() *validator.Validate {
+	v := validator.New()
+
+	// report fields by their JSON names so error paths match the request body
+	v.RegisterTagNameFunc(func(field reflect.StructField) string {
+		name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+		if name == "-" {
+			return ""
+		}
+		if name == "" {
+			return field.Name
+		}
+		return name
+	})
+
+	mustRegister(v, "notblank", notBlank)
+	mustRegister(v, "password", strongPassword)
+	return v
+}
+
+func mustRegister(v *validator.Validate, tag string, fn validator.Func) {
+	if err := v.RegisterValidation(tag, fn); err != nil {
+		panic(fmt.Sprintf("registering %q validator: %v", tag, err))
+	}
+}
+
+// notBlank rejects strings made only of whitespace
+func notBlank(fl validator.FieldLevel) bool {
+	return strings.TrimSpace(fl.Field().String()) != ""
+}
+
+// strongPassword requires an upper case letter, a lower case letter, a digit
+// and a symbol; length limits are expressed with min/max tags
+func strongPassword(fl validator.FieldLevel) bool {
+	var upper, lower, digit, symbol bool
+	for _, r := range fl.Field().String() {
+		switch {
+		case unicode.IsUpper(r):
+			upper = true
+		case unicode.IsLower(r):
+			lower = true
+		case unicode.IsDigit(r):
+			digit = true
+		case unicode.IsPunct(r) || unicode.IsSymbol(r):
+			symbol = true
+		}
+	}
+	return upper && lower && digit && symbol
+}
+
+// Struct validates v and returns every failing field as one validation error
+func Struct(v any) error {
+	err := validate.Struct(v)
+	if err == nil {
+		return nil
+	}
+
+	var validationErrs validator.ValidationErrors
+	if !errors.As(err, &validationErrs) {
+		return apperror.Internal(err)
+	}
+
+	fields := make([]apperror.FieldError, 0, len(validationErrs))
+	for _, fe := range validationErrs {
+		fields = append(fields, apperror.FieldError{
+			Field:   fieldPath(fe.Namespace()),
+			Message: message(fe),
+		})
+	}
+	return apperror.Validation("request validation failed", fields...)
+}
+
+// BindJSON decodes the request body into v and validates it
+func BindJSON(g *gin.Context, v any) error {
+	if err := decodeJSON(g.Request.Body, v); err != nil {
+		return err
+	}
+	return Struct(v)
+}
+
+// BindQuery decodes the query string into v, using its form tags, and
+// validates it
+func BindQuery(g *gin.Context, v any) error {
+	if err := binding.Query.Bind(g.Request, v); err != nil {
+		return apperror.Validation("invalid query parameter: " + err.Error())
+	}
+	return Struct(v)
+}
+
+// Document decodes a whole resource document, such as the result of applying
+// a patch, into v and validates it. Members v has no field for are rejected
+// rather than ignored, so read-only fields cannot be smuggled in.
+func Document(data []byte, v any) error {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.DisallowUnknownFields()
+	if err := decodeError(decoder.Decode(v)); err != nil {
+		return err
+	}
+	return Struct(v)
+}
+
+func decodeJSON(body io.Reader, v any) error {
+	return decodeError(json.NewDecoder(body).Decode(v))
+}
+
+func decodeError(err error) error {
+	if err == nil {
+		return nil
+	}
+
+	var typeErr *json.UnmarshalTypeError
+	var syntaxErr *json.SyntaxError
+	switch {
+	case errors.Is(err, io.EOF):
+		return apperror.Validation("request body must not be empty")
+	case errors.As(err, &typeErr):
+		return apperror.Validation("request body has an invalid field type", apperror.FieldError{
+			Field:   typeErr.Field,
+			Message: "must be of type " + typeErr.Type.String(),
+		})
+	case errors.As(err, &syntaxErr):
+		return apperror.Validation(fmt.Sprintf("request body is not valid JSON (offset %d)", syntaxErr.Offset))
+	case strings.HasPrefix(err.Error(), "json: unknown field "):
+		field := strings.Trim(strings.TrimPrefix(err.Error(), "json: unknown field "), `"`)
+		return apperror.Validation("request body has a field that cannot be set", apperror.FieldError{
+			Field:   field,
+			Message: "cannot be set",
+		})
+	default:
+		return apperror.Validation("invalid JSON body: " + err.Error())
+	}
+}
+
+// fieldPath strips the root struct name from a validator namespace,
+// e.g. "SignUpRequest.email" becomes "email"
+func fieldPath(namespace string) string {
+	if _, path, ok := strings.Cut(namespace, "."); ok {
+		return path
+	}
+	return namespace
+}
+
+var timeType = reflect.TypeOf(time.Time{})
+
+// snakeCase turns a Go field name such as SalaryMin into its JSON name
+func snakeCase(name string) string {
+	var b strings.Builder
+	for i, r := range name {
+		if unicode.IsUpper(r) {
+			if i > 0 {
+				b.WriteByte('_')
+			}
+			r = unicode.ToLower(r)
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+func message(fe validator.FieldError) string {
+	switch fe.Tag() {
+	case "required", "notblank":
+		return "is required"
+	case "email":
+		return "must be a valid email address"
+	case "e164":
+		return "must be an E.164 phone number, e.g. +14155552671"
+	case "password":
+		return "must contain an upper case letter, a lower case letter, a digit and a symbol"
+	case "oneof":
+		return "must be one of: " + strings.ReplaceAll(fe.Param(), " ", ", ")
+	case "gtefield":
+		if fe.Type() == timeType {
+			return "must not be before " + strings.ToLower(fe.Param())
+		}
+		return "must be greater than or equal to " + snakeCase(fe.Param())
+	case "ltefield":
+		if fe.Type() == timeType {
+			return "must not be after " + strings.ToLower(fe.Param())
+		}
+		return "must be less than or equal to " + snakeCase(fe.Param())
+	case "required_with":
+		fields := strings.Fields(fe.Param())
+		for i, field := range fields {
+			fields[i] = snakeCase(field)
+		}
+		return "is required with " + strings.Join(fields, ", ")
+	case "iso4217":
+		return "must be an ISO 4217 currency code, e.g. USD"
+	case "url":
+		return "must be a URL"
+	case "iso3166_1_alpha2":
+		return "must be an ISO 3166-1 alpha-2 country code, e.g. US"
+	case "latitude":
+		return "must be a latitude between -90 and 90"
+	case "longitude":
+		return "must be a longitude between -180 and 180"
+	case "unique":
+		return "must not contain duplicates"
+	case "min":
+		if fe.Kind() == reflect.String {
+			return "must be at least " + fe.Param() + " characters"
+		}
+		return "must be at least " + fe.Param()
+	case "max":
+		if fe.Kind() == reflect.String {
+			return "must be at most " + fe.Param() + " characters"
+		}
+		return "must be at most " + fe.Param()
+	case "gt":
+		return "must be greater than " + fe.Param()
+	case "gte":
+		return "must be greater than or equal to " + fe.Param()
+	case "lte":
+		return "must be less than or equal to " + fe.Param()
+	default:
+		return "failed the " + fe.Tag() + " check"
+	}
+}